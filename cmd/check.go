@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/seedreap/seedreap/internal/server"
+)
+
+const defaultCheckTimeout = time.Minute
+
+//nolint:gochecknoglobals // cobra CLI flags require package-level variables
+var checkTimeout time.Duration
+
+// checkCmd checks the connections to every configured service.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the connections to downloaders, apps and the transfer backend",
+	Long: `Connect to every configured downloader, test the connection to every app and
+open the SFTP connection used for transfers, then print the result of each check.
+Exits non-zero if the configuration is invalid or any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: runCheck,
+}
+
+//nolint:gochecknoinits // cobra requires init for command registration
+func init() {
+	checkCmd.Flags().DurationVar(&checkTimeout, "timeout", defaultCheckTimeout, "time limit for all checks")
+	rootCmd.AddCommand(checkCmd)
+}
+
+func runCheck(cmd *cobra.Command, _ []string) error {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), checkTimeout)
+	defer cancel()
+
+	results := server.Check(ctx, cfg, log.With().Str("component", "check").Logger())
+
+	out := logRedactor.Writer(cmd.OutOrStdout())
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			_, _ = fmt.Fprintf(out, "FAIL  %-10s %s: %v\n", r.Target, r.Name, r.Err)
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "OK    %-10s %s\n", r.Target, r.Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
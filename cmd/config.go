@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/seedreap/seedreap/internal/config"
+)
+
+// errInvalidConfig is returned when the configuration fails validation, so the
+// command exits non-zero after the individual errors have been printed.
+var errInvalidConfig = errors.New("configuration is invalid")
+
+// configCmd groups the configuration subcommands.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+// configValidateCmd validates the configuration.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration and print every error",
+	Long: `Load the configuration from the config file and environment variables and
+validate it, printing every error found. Exits non-zero if it is invalid.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+// configPrintCmd prints the effective configuration.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the effective configuration with secrets redacted",
+	Long: `Print the configuration as YAML after merging the config file, environment
+variables and defaults. Passwords, API keys, tokens and webhook URLs are redacted.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigPrint,
+}
+
+//nolint:gochecknoinits // cobra requires init for command registration
+func init() {
+	configCmd.AddCommand(configValidateCmd, configPrintCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, _ []string) error {
+	out := cmd.OutOrStdout()
+
+	_, path, err := loadConfig()
+	if err != nil {
+		_, _ = fmt.Fprintln(out, "Configuration is invalid:")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			_, _ = fmt.Fprintf(out, "  - %s\n", line)
+		}
+		return errInvalidConfig
+	}
+
+	if path == "" {
+		_, _ = fmt.Fprintln(out, "Configuration is valid (no config file found, using environment variables)")
+	} else {
+		_, _ = fmt.Fprintf(out, "Configuration is valid: %s\n", path)
+	}
+	return nil
+}
+
+func runConfigPrint(cmd *cobra.Command, _ []string) error {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	data, err := config.Marshal(cfg.Redacted())
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/seedreap/seedreap/pkg/apitypes"
+	"github.com/seedreap/seedreap/pkg/client"
+)
+
+//nolint:gochecknoglobals // cobra CLI flags require package-level variables
+var jobsStates []string
+
+// jobsCmd groups the sync job subcommands.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "List, inspect, cancel and retry sync jobs on a running server",
+}
+
+// jobsListCmd lists sync jobs.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List sync jobs",
+	Args:  cobra.NoArgs,
+	RunE:  runJobsList,
+}
+
+// jobsShowCmd shows a sync job and its files.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var jobsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a sync job and the progress of its files",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runJobsShow,
+}
+
+// jobsCancelCmd cancels a sync job.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var jobsCancelCmd = &cobra.Command{
+	Use:   "cancel <id>",
+	Short: "Cancel a sync job that is waiting or syncing",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runJobAction(cmd, args[0], "cancelled", (*client.Client).CancelJob)
+	},
+}
+
+// jobsRetryCmd retries a failed or cancelled sync job.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var jobsRetryCmd = &cobra.Command{
+	Use:   "retry <id>",
+	Short: "Retry a failed or cancelled sync job",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runJobAction(cmd, args[0], "retried", (*client.Client).RetryJob)
+	},
+}
+
+//nolint:gochecknoinits // cobra requires init for command registration
+func init() {
+	addRemoteFlags(jobsCmd)
+	jobsListCmd.Flags().StringSliceVar(&jobsStates, "state", nil, "only list jobs in these states")
+	jobsCmd.AddCommand(jobsListCmd, jobsShowCmd, jobsCancelCmd, jobsRetryCmd)
+	rootCmd.AddCommand(jobsCmd)
+}
+
+func runJobsList(cmd *cobra.Command, _ []string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	opts := &client.ListOptions{States: jobsStates}
+	jobs := []apitypes.Job{}
+	for {
+		page, next, listErr := c.ListJobs(cmd.Context(), opts)
+		if listErr != nil {
+			return fmt.Errorf("failed to list jobs: %w", listErr)
+		}
+		jobs = append(jobs, page...)
+		if next == "" {
+			break
+		}
+		opts.Cursor = next
+	}
+
+	out := cmd.OutOrStdout()
+	if outputFormat == outputJSON {
+		return writeJSON(out, jobs)
+	}
+
+	tw := newTable(out)
+	_, _ = fmt.Fprintln(tw, "ID\tNAME\tSTATUS\tAPP\tSIZE\tPROGRESS\tSPEED")
+	for _, j := range jobs {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s/s\n",
+			j.ID, j.Name, j.Status, j.App,
+			formatBytes(j.TotalSize), formatPercent(j.CompletedSize, j.TotalSize), formatBytes(j.BytesPerSec))
+	}
+	return tw.Flush()
+}
+
+func runJobsShow(cmd *cobra.Command, args []string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	job, err := c.GetJob(cmd.Context(), args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if outputFormat == outputJSON {
+		return writeJSON(out, job)
+	}
+
+	tw := newTable(out)
+	_, _ = fmt.Fprintf(tw, "ID:\t%s\n", job.ID)
+	_, _ = fmt.Fprintf(tw, "Name:\t%s\n", job.Name)
+	_, _ = fmt.Fprintf(tw, "Downloader:\t%s\n", job.Downloader)
+	_, _ = fmt.Fprintf(tw, "Category:\t%s\n", job.Category)
+	_, _ = fmt.Fprintf(tw, "Status:\t%s\n", job.Status)
+	_, _ = fmt.Fprintf(tw, "Progress:\t%s of %s (%s)\n",
+		formatBytes(job.CompletedSize), formatBytes(job.TotalSize), formatPercent(job.CompletedSize, job.TotalSize))
+	if job.FinalPath != "" {
+		_, _ = fmt.Fprintf(tw, "Destination:\t%s\n", job.FinalPath)
+	}
+	if err = tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(job.Files) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(out)
+	tw = newTable(out)
+	_, _ = fmt.Fprintln(tw, "FILE\tSTATUS\tSIZE\tPROGRESS\tSPEED")
+	for _, f := range job.Files {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s/s\n",
+			f.Path, f.Status, formatBytes(f.Size), formatPercent(f.Transferred, f.Size), formatBytes(f.BytesPerSec))
+	}
+	return tw.Flush()
+}
+
+// runJobAction runs a job action and reports the result.
+func runJobAction(
+	cmd *cobra.Command,
+	id, done string,
+	action func(*client.Client, context.Context, string) error,
+) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	if err = action(c, cmd.Context(), id); err != nil {
+		return fmt.Errorf("failed to %s job: %w", cmd.Name(), err)
+	}
+
+	if outputFormat == outputJSON {
+		return writeJSON(cmd.OutOrStdout(), map[string]string{"id": id, "result": done})
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Job %s %s\n", id, done)
+	return nil
+}
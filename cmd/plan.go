@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+var errNotDryRun = errors.New("the server is not running in dry-run mode (start it with --dry-run or set sync.dryRun)")
+
+// planCmd prints the plan of a server running in dry-run mode.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Print what a server running in dry-run mode would do",
+	Long: `Print the downloads a server running with --dry-run (or sync.dryRun) has planned:
+the files it would sync and where, followed by every action it skipped, such as
+transfers, moves, imports and cleanups.`,
+	Args: cobra.NoArgs,
+	RunE: runPlan,
+}
+
+//nolint:gochecknoinits // cobra requires init for command registration
+func init() {
+	addRemoteFlags(planCmd)
+	rootCmd.AddCommand(planCmd)
+}
+
+func runPlan(cmd *cobra.Command, _ []string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	plan, err := c.Plan(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get plan: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if outputFormat == outputJSON {
+		return writeJSON(out, plan)
+	}
+	if !plan.DryRun {
+		return errNotDryRun
+	}
+	return printPlan(out, *plan)
+}
+
+// printPlan prints the planned downloads, then the skipped actions in the order
+// they were planned.
+func printPlan(out io.Writer, plan apitypes.Plan) error {
+	if len(plan.Downloads) == 0 {
+		_, _ = fmt.Fprintln(out, "Nothing planned: no download matches an app")
+		return nil
+	}
+
+	tw := newTable(out)
+	_, _ = fmt.Fprintln(tw, "NAME\tDOWNLOADER\tSTATE\tFILES\tSIZE\tDESTINATION")
+	var actions []apitypes.PlannedAction
+	for _, d := range plan.Downloads {
+		destination := d.FinalPath
+		if destination == "" {
+			destination = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			d.Name, d.Downloader, d.State, len(d.Files), formatBytes(d.Size), destination)
+		actions = append(actions, d.Actions...)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(actions) == 0 {
+		return nil
+	}
+
+	slices.SortStableFunc(actions, func(a, b apitypes.PlannedAction) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	_, _ = fmt.Fprintln(out)
+	tw = newTable(out)
+	_, _ = fmt.Fprintln(tw, "TIME\tACTION\tMESSAGE")
+	for _, a := range actions {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", a.Timestamp.Local().Format(time.DateTime), a.Type, a.Message)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/seedreap/seedreap/pkg/client"
+)
+
+const (
+	defaultServerURL = "http://localhost:8423"
+
+	outputTable = "table"
+	outputJSON  = "json"
+
+	tabPadding = 2
+)
+
+var errInvalidOutput = errors.New("invalid output format")
+
+//nolint:gochecknoglobals // cobra CLI flags require package-level variables
+var (
+	serverURL    string
+	serverAPIKey string
+	outputFormat string
+)
+
+// addRemoteFlags adds the flags of commands that talk to a running server.
+// Defaults come from SEEDREAP_URL and SEEDREAP_API_KEY.
+func addRemoteFlags(cmd *cobra.Command) {
+	url := os.Getenv("SEEDREAP_URL")
+	if url == "" {
+		url = defaultServerURL
+	}
+	cmd.PersistentFlags().StringVar(&serverURL, "url", url, "server URL (env SEEDREAP_URL)")
+	cmd.PersistentFlags().StringVar(&serverAPIKey, "api-key", os.Getenv("SEEDREAP_API_KEY"),
+		"API key for commands that change state (env SEEDREAP_API_KEY)")
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "output format (table, json)")
+}
+
+// newClient returns a client for the server given by the remote flags.
+func newClient() (*client.Client, error) {
+	if outputFormat != outputTable && outputFormat != outputJSON {
+		return nil, fmt.Errorf("%w %q: must be %s or %s", errInvalidOutput, outputFormat, outputTable, outputJSON)
+	}
+
+	var opts []client.Option
+	if serverAPIKey != "" {
+		opts = append(opts, client.WithAPIKey(serverAPIKey))
+	}
+	return client.New(serverURL, opts...)
+}
+
+// newTable returns a tabwriter for aligned table output. Callers must Flush it.
+func newTable(out io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(out, 0, 0, tabPadding, ' ', 0)
+}
+
+// writeJSON writes v as indented JSON.
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// formatBytes formats a byte count with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatPercent formats done out of total as a percentage.
+func formatPercent(done, total int64) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(done)*100/float64(total)) //nolint:mnd // percentage
+}
@@ -4,6 +4,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/seedreap/seedreap/internal/config"
+	"github.com/seedreap/seedreap/internal/redact"
 	"github.com/seedreap/seedreap/internal/server"
 	"github.com/seedreap/seedreap/ui"
 )
@@ -37,9 +39,15 @@ var (
 	logLevel  string
 	logPretty bool
 	listen    string
+	dryRun    bool
 
-	showVersion bool
-	appConfig   config.Config
+	showVersion   bool
+	appConfig     config.Config
+	appConfigFile string
+
+	// logRedactor scrubs the secrets of every loaded configuration from the
+	// log output and API responses
+	logRedactor = redact.New()
 )
 
 // rootCmd represents the base command.
@@ -55,7 +63,10 @@ the files.
 
 Inspired by seedsync (https://github.com/ipsingh06/seedsync).`,
 	SilenceUsage: true,
-	RunE:         run,
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		setupLogging()
+	},
+	RunE: run,
 }
 
 // Execute runs the root command.
@@ -68,6 +79,9 @@ func Execute() {
 		}
 	}
 
+	// Errors may quote secrets of the configuration
+	rootCmd.SetErr(logRedactor.Writer(os.Stderr))
+
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -75,13 +89,12 @@ func Execute() {
 
 //nolint:gochecknoinits // cobra requires init for flag registration
 func init() {
-	cobra.OnInitialize(initConfig)
-
-	rootCmd.Flags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.seedreap.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.seedreap.yaml)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().BoolVar(&logPretty, "log-pretty", false, "enable pretty (human-readable) logging")
 	rootCmd.Flags().BoolVarP(&showVersion, "version", "V", false, "print version information and exit")
 	rootCmd.Flags().StringVar(&listen, "listen", "", "address to listen on (default \"[::]:8423\")")
-	rootCmd.Flags().StringVar(&logLevel, "log-level", "info", "log level (debug, info, warn, error)")
-	rootCmd.Flags().BoolVar(&logPretty, "log-pretty", false, "enable pretty (human-readable) logging")
+	addDryRunFlag(rootCmd)
 }
 
 func run(_ *cobra.Command, _ []string) error {
@@ -91,10 +104,18 @@ func run(_ *cobra.Command, _ []string) error {
 		return nil
 	}
 
+	initConfig()
+
 	opts := server.Options{
 		UIFS:   ui.FS,
 		UIPath: "dist",
 		Logger: log.With().Str("component", "main").Logger(),
+		LoadConfig: func() (config.Config, error) {
+			cfg, _, err := loadConfig()
+			return cfg, err
+		},
+		ConfigFile: appConfigFile,
+		Redactor:   logRedactor,
 	}
 
 	srv, err := server.New(appConfig, opts)
@@ -144,23 +165,41 @@ func printVersion() {
 }
 
 func initConfig() {
-	// Load config from file and environment variables
-	cfg, err := config.Load(config.LoadOptions{
+	cfg, path, err := loadConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load config")
+	}
+
+	appConfig = cfg
+	appConfigFile = path
+}
+
+// loadConfig loads config from file and environment variables and applies CLI
+// flag overrides. It is also used to reload the configuration while running.
+func loadConfig() (config.Config, string, error) {
+	cfg, path, err := config.LoadWithSource(config.LoadOptions{
 		ConfigFile: cfgFile,
 	})
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to load config")
+		return config.Config{}, "", err
 	}
+	logRedactor.Add(cfg.Secrets()...)
 
 	// Apply CLI flag overrides
 	if listen != "" {
 		cfg.Server.Listen = listen
 	}
+	if dryRun {
+		cfg.Sync.DryRun = true
+	}
 
-	appConfig = cfg
+	return cfg, path, nil
+}
 
-	// Setup logging based on CLI flags
-	setupLogging()
+// addDryRunFlag adds the --dry-run flag to a command that syncs.
+func addDryRunFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"plan syncs without transferring, moving, importing or deleting anything (sync.dryRun)")
 }
 
 func setupLogging() {
@@ -179,7 +218,9 @@ func setupLogging() {
 	}
 
 	// Setup output based on CLI flag
+	var out io.Writer = os.Stderr
 	if logPretty {
-		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr}) //nolint:reassign // standard zerolog pattern
+		out = zerolog.ConsoleWriter{Out: os.Stderr}
 	}
+	log.Logger = log.Output(logRedactor.Writer(out)) //nolint:reassign // standard zerolog pattern
 }
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// statsCmd prints download statistics.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Print download statistics from a running server",
+	Args:  cobra.NoArgs,
+	RunE:  runStats,
+}
+
+//nolint:gochecknoinits // cobra requires init for command registration
+func init() {
+	addRemoteFlags(statsCmd)
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStats(cmd *cobra.Command, _ []string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	stats, err := c.Stats(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if outputFormat == outputJSON {
+		return writeJSON(out, stats)
+	}
+
+	tw := newTable(out)
+	_, _ = fmt.Fprintf(tw, "Tracked:\t%d\n", stats.TotalTracked)
+	_, _ = fmt.Fprintf(tw, "Downloading on seedbox:\t%d\n", stats.DownloadingOnSeedbox)
+	_, _ = fmt.Fprintf(tw, "Paused on seedbox:\t%d\n", stats.PausedOnSeedbox)
+	for _, state := range slices.Sorted(maps.Keys(stats.ByState)) {
+		_, _ = fmt.Fprintf(tw, "  %s:\t%d\n", state, stats.ByState[state])
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/seedreap/seedreap/internal/orchestrator"
+	"github.com/seedreap/seedreap/internal/server"
+)
+
+var (
+	errSyncOnceRequired = errors.New("sync requires --once; run seedreap without a command to sync continuously")
+	errSyncInterrupted  = errors.New("sync interrupted")
+)
+
+//nolint:gochecknoglobals // cobra CLI flags require package-level variables
+var syncOnce bool
+
+// syncCmd runs a single sync cycle.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var syncCmd = &cobra.Command{
+	Use:   "sync --once",
+	Short: "Sync completed downloads once and exit",
+	Long: `Poll every downloader once, sync the completed files of matching downloads, move
+them to their final location and trigger imports, then print a summary and exit.
+The HTTP server is not started. Files still downloading on the seedbox are left for
+the next run. Exits non-zero if any download failed.
+
+With --dry-run, nothing is transferred, moved, imported or deleted; the plan of what
+would be done is printed instead.
+
+Use it to run SeedReap from cron or a script instead of as a daemon.`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+//nolint:gochecknoinits // cobra requires init for command registration
+func init() {
+	syncCmd.Flags().BoolVar(&syncOnce, "once", false, "run a single sync cycle and exit")
+	addDryRunFlag(syncCmd)
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSync(cmd *cobra.Command, _ []string) error {
+	if !syncOnce {
+		return errSyncOnceRequired
+	}
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	srv, err := server.New(cfg, server.Options{
+		Logger: log.With().Str("component", "main").Logger(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := srv.RunOnce(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Sync.DryRun {
+		if err = printPlan(cmd.OutOrStdout(), srv.Plan()); err != nil {
+			return err
+		}
+	} else {
+		printReport(cmd, report)
+	}
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return errSyncInterrupted
+	}
+	if failed := report.Count(orchestrator.StateError); failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed", failed, len(report.Downloads))
+	}
+	return nil
+}
+
+// printReport prints the outcome of each download and a summary.
+func printReport(cmd *cobra.Command, report orchestrator.Report) {
+	out := logRedactor.Writer(cmd.OutOrStdout())
+
+	tw := newTable(out)
+	for _, e := range report.Downloads {
+		switch e.State {
+		case orchestrator.StateError:
+			_, _ = fmt.Fprintf(tw, "FAILED\t%s\t%s\t%v\n", e.Downloader, e.Name, e.Error)
+		default:
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t\n", reportLabel(e.State), e.Downloader, e.Name)
+		}
+	}
+	_ = tw.Flush()
+
+	_, _ = fmt.Fprintf(out, "%d done, %d partial, %d waiting, %d failed\n",
+		report.Count(orchestrator.StateComplete),
+		report.Count(orchestrator.StateSyncing),
+		report.Count(orchestrator.StateDiscovered)+report.Count(orchestrator.StateWaitingForSpace),
+		report.Count(orchestrator.StateError))
+}
+
+// reportLabel describes the state a download was left in by a sync cycle.
+func reportLabel(state orchestrator.DownloadState) string {
+	switch state {
+	case orchestrator.StateComplete:
+		return "DONE"
+	case orchestrator.StateSyncing:
+		return "PARTIAL"
+	case orchestrator.StateDiscovered:
+		return "WAITING"
+	case orchestrator.StateWaitingForSpace:
+		return "NO SPACE"
+	case orchestrator.StateSynced, orchestrator.StateMoving, orchestrator.StateImporting, orchestrator.StateError:
+		// Only left behind when interrupted or failed
+	}
+	return strings.ToUpper(string(state))
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/seedreap/seedreap/pkg/apitypes"
+	"github.com/seedreap/seedreap/pkg/client"
+)
+
+const (
+	defaultTimelineLimit    = 20
+	defaultTimelineInterval = 2 * time.Second
+)
+
+//nolint:gochecknoglobals // cobra CLI flags require package-level variables
+var (
+	timelineFollow    bool
+	timelineInterval  time.Duration
+	timelineLimit     int
+	timelineTypes     []string
+	timelineDownloads []string
+)
+
+// timelineCmd prints timeline events.
+//
+//nolint:gochecknoglobals // cobra requires package-level command variables
+var timelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "Print recent timeline events from a running server",
+	Long: `Print the most recent timeline events, oldest first. With --follow, keep polling
+the server and print new events as they are recorded until interrupted. JSON output
+prints one event object per line.`,
+	Args: cobra.NoArgs,
+	RunE: runTimeline,
+}
+
+//nolint:gochecknoinits // cobra requires init for command registration
+func init() {
+	addRemoteFlags(timelineCmd)
+	timelineCmd.Flags().BoolVarP(&timelineFollow, "follow", "f", false, "keep printing new events")
+	timelineCmd.Flags().DurationVar(&timelineInterval, "interval", defaultTimelineInterval,
+		"how often to poll for new events with --follow")
+	timelineCmd.Flags().IntVarP(&timelineLimit, "limit", "n", defaultTimelineLimit, "number of recent events to print")
+	timelineCmd.Flags().StringSliceVar(&timelineTypes, "type", nil, "only print events of these types")
+	timelineCmd.Flags().StringSliceVar(&timelineDownloads, "download", nil, "only print events for these download IDs")
+	rootCmd.AddCommand(timelineCmd)
+}
+
+func runTimeline(cmd *cobra.Command, _ []string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+
+	// The server returns the newest events first
+	events, _, err := c.Timeline(ctx, &client.ListOptions{
+		Types:     timelineTypes,
+		Downloads: timelineDownloads,
+		Limit:     timelineLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get timeline: %w", err)
+	}
+	slices.Reverse(events)
+
+	if !timelineFollow {
+		if outputFormat == outputJSON {
+			return writeJSONLines(out, events)
+		}
+		tw := newTable(out)
+		_, _ = fmt.Fprintln(tw, "TIME\tTYPE\tMESSAGE")
+		writeEvents(tw, events)
+		return tw.Flush()
+	}
+
+	if err = printFollowed(out, events); err != nil {
+		return err
+	}
+
+	// Events recorded at the same instant as the last one printed are returned
+	// again by the next poll, so remember which of them were already printed.
+	var since time.Time
+	seen := map[string]bool{}
+	remember := func(events []apitypes.Event) {
+		for _, e := range events {
+			if e.Timestamp.After(since) {
+				since = e.Timestamp
+				clear(seen)
+			}
+			seen[e.ID] = true
+		}
+	}
+	remember(events)
+
+	ticker := time.NewTicker(timelineInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		opts := &client.ListOptions{
+			Types:     timelineTypes,
+			Downloads: timelineDownloads,
+			Since:     since,
+			Order:     "asc",
+		}
+		for {
+			page, next, pollErr := c.Timeline(ctx, opts)
+			if pollErr != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("failed to get timeline: %w", pollErr)
+			}
+
+			page = slices.DeleteFunc(page, func(e apitypes.Event) bool { return seen[e.ID] })
+			if err = printFollowed(out, page); err != nil {
+				return err
+			}
+			remember(page)
+
+			if next == "" {
+				break
+			}
+			opts.Cursor = next
+		}
+	}
+}
+
+// printFollowed prints events in the format of timeline --follow.
+func printFollowed(out io.Writer, events []apitypes.Event) error {
+	if outputFormat == outputJSON {
+		return writeJSONLines(out, events)
+	}
+	// Without a header, columns are not aligned across polls
+	tw := newTable(out)
+	writeEvents(tw, events)
+	return tw.Flush()
+}
+
+// writeEvents writes events as table rows.
+func writeEvents(out io.Writer, events []apitypes.Event) {
+	for _, e := range events {
+		_, _ = fmt.Fprintf(out, "%s\t%s\t%s\n", e.Timestamp.Local().Format(time.DateTime), e.Type, e.Message)
+	}
+}
+
+// writeJSONLines writes each event as a JSON object on its own line.
+func writeJSONLines(out io.Writer, events []apitypes.Event) error {
+	enc := json.NewEncoder(out)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
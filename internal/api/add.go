@@ -0,0 +1,221 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/seedreap/seedreap/internal/download"
+	"github.com/seedreap/seedreap/internal/timeline"
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+// maxAddBodySize limits the size of POST /api/downloads requests.
+const maxAddBodySize = "10M"
+
+var (
+	errNothingToAdd      = errors.New("at least one URL or torrent file is required")
+	errDownloaderNeeded  = errors.New("downloader is required when more than one is configured")
+	errUnsupportedFormat = errors.New("content type must be application/json or multipart/form-data")
+)
+
+// addDownloadHandler submits magnet links, torrent URLs and .torrent files to a downloader.
+func (s *Server) addDownloadHandler(c echo.Context) error {
+	req, err := parseAddRequest(c)
+	if err != nil {
+		return badRequest(c, err)
+	}
+
+	dlName, dl, err := s.resolveDownloader(req.Downloader)
+	if err != nil {
+		return badRequest(c, err)
+	}
+
+	adder, ok := dl.(download.Adder)
+	if !ok {
+		return badRequest(c, fmt.Errorf("downloader %q does not support adding downloads", dlName))
+	}
+
+	// Only accept downloads the sync pipeline will pick up
+	if len(s.apps.GetForDownload(dlName, req.Category, req.Tags)) == 0 {
+		return badRequest(c, fmt.Errorf("no app handles category %q on downloader %q", req.Category, dlName))
+	}
+
+	torrents := make([]download.TorrentFile, 0, len(req.Torrents))
+	for _, t := range req.Torrents {
+		torrents = append(torrents, download.TorrentFile{Name: t.Name, Data: t.Data})
+	}
+
+	err = adder.Add(c.Request().Context(), download.AddRequest{
+		URLs:     req.URLs,
+		Torrents: torrents,
+		Category: req.Category,
+		Tags:     req.Tags,
+		Paused:   req.Paused,
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Str("downloader", dlName).Msg("failed to add downloads")
+		return c.JSON(http.StatusBadGateway, apitypes.Error{Error: "failed to add downloads: " + err.Error()})
+	}
+
+	added := len(req.URLs) + len(req.Torrents)
+
+	if tl := s.orchestrator.GetTimeline(); tl != nil {
+		tl.Record(timeline.Event{
+			Type:       timeline.EventAdded,
+			Message:    fmt.Sprintf("Added %d download(s) to %s", added, dlName),
+			Downloader: dlName,
+			Details: map[string]any{
+				"category": req.Category,
+				"count":    added,
+			},
+		})
+	}
+
+	return c.JSON(http.StatusAccepted, apitypes.AddDownloadResponse{
+		Downloader: dlName,
+		Added:      added,
+	})
+}
+
+// resolveDownloader looks up the named downloader, or the only configured one if name is empty.
+func (s *Server) resolveDownloader(name string) (string, download.Downloader, error) {
+	if name != "" {
+		dl, ok := s.downloaders.Get(name)
+		if !ok {
+			return "", nil, fmt.Errorf("unknown downloader %q", name)
+		}
+		return name, dl, nil
+	}
+
+	all := s.downloaders.All()
+	if len(all) != 1 {
+		return "", nil, errDownloaderNeeded
+	}
+	for n, dl := range all {
+		return n, dl, nil
+	}
+	return "", nil, errDownloaderNeeded
+}
+
+// parseAddRequest reads a JSON or multipart add request and validates it.
+func parseAddRequest(c echo.Context) (apitypes.AddDownloadRequest, error) {
+	var req apitypes.AddDownloadRequest
+
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	switch {
+	case strings.HasPrefix(contentType, echo.MIMEApplicationJSON):
+		if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+			return req, fmt.Errorf("invalid request body: %w", err)
+		}
+
+	case strings.HasPrefix(contentType, echo.MIMEMultipartForm):
+		form, err := c.MultipartForm()
+		if err != nil {
+			return req, fmt.Errorf("invalid form: %w", err)
+		}
+		if req, err = addRequestFromForm(form); err != nil {
+			return req, err
+		}
+
+	default:
+		return req, errUnsupportedFormat
+	}
+
+	return req, validateAddRequest(&req)
+}
+
+// addRequestFromForm builds an add request from multipart form fields. Torrent
+// files are uploaded in the "torrents" field, as with qBittorrent.
+func addRequestFromForm(form *multipart.Form) (apitypes.AddDownloadRequest, error) {
+	first := func(name string) string {
+		if v := form.Value[name]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	req := apitypes.AddDownloadRequest{
+		Downloader: first("downloader"),
+		Category:   first("category"),
+		URLs:       splitFormValues(form.Value["urls"], "\n"),
+		Tags:       splitFormValues(form.Value["tags"], ","),
+	}
+
+	if paused := first("paused"); paused != "" {
+		var err error
+		if req.Paused, err = strconv.ParseBool(paused); err != nil {
+			return req, fmt.Errorf("invalid paused %q: must be true or false", paused)
+		}
+	}
+
+	for _, fh := range form.File["torrents"] {
+		data, err := readFormFile(fh)
+		if err != nil {
+			return req, fmt.Errorf("failed to read %s: %w", fh.Filename, err)
+		}
+		req.Torrents = append(req.Torrents, apitypes.TorrentFile{Name: fh.Filename, Data: data})
+	}
+
+	return req, nil
+}
+
+func readFormFile(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// splitFormValues splits repeated form values on sep and drops blanks.
+func splitFormValues(values []string, sep string) []string {
+	var result []string
+	for _, raw := range values {
+		for v := range strings.SplitSeq(raw, sep) {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// validateAddRequest normalizes and checks an add request.
+func validateAddRequest(req *apitypes.AddDownloadRequest) error {
+	req.URLs = splitFormValues(req.URLs, "\n")
+	req.Tags = splitFormValues(req.Tags, ",")
+	req.Category = strings.TrimSpace(req.Category)
+
+	if len(req.URLs) == 0 && len(req.Torrents) == 0 {
+		return errNothingToAdd
+	}
+
+	for _, raw := range req.URLs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "magnet" && u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid URL %q: must be a magnet link or an HTTP(S) URL", raw)
+		}
+	}
+
+	for i, t := range req.Torrents {
+		if len(t.Data) == 0 {
+			return fmt.Errorf("torrent file %d (%q) is empty", i, t.Name)
+		}
+		if t.Name == "" {
+			req.Torrents[i].Name = fmt.Sprintf("upload-%d.torrent", i)
+		}
+	}
+
+	return nil
+}
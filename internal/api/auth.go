@@ -0,0 +1,37 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+// headerAPIKey carries the API key on requests that change state.
+const headerAPIKey = "X-Api-Key"
+
+// requireAPIKey rejects requests that change state unless they carry the API
+// key. Without a configured key such requests are refused outright, since any
+// website open in a browser that can reach the API could otherwise send them.
+func (s *Server) requireAPIKey(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		switch c.Request().Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return next(c)
+		}
+
+		if s.apiKey == "" {
+			return c.JSON(http.StatusForbidden, apitypes.Error{
+				Error: "requests that change state are disabled, set server.apiKey to enable them",
+			})
+		}
+
+		key := c.Request().Header.Get(headerAPIKey)
+		if subtle.ConstantTimeCompare([]byte(key), []byte(s.apiKey)) != 1 {
+			return c.JSON(http.StatusUnauthorized, apitypes.Error{Error: "missing or invalid API key"})
+		}
+		return next(c)
+	}
+}
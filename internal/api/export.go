@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+// Timeline export formats.
+const (
+	exportFormatJSONL = "jsonl"
+	exportFormatCSV   = "csv"
+)
+
+// exportCSVHeader is the header row of CSV timeline exports.
+var exportCSVHeader = []string{
+	"id", "timestamp", "type", "message", "download_id", "download_name", "app_name", "downloader", "details",
+}
+
+// timelineExportHandler streams timeline events as JSON Lines or CSV, oldest
+// first. It accepts the same filters as the timeline endpoint; without a limit
+// every matching event is exported.
+func (s *Server) timelineExportHandler(c echo.Context) error {
+	params, err := parseTimelineParams(c, false)
+	if err != nil {
+		return badRequest(c, err)
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = exportFormatJSONL
+	}
+
+	var (
+		contentType string
+		write       func(io.Writer, []apitypes.Event) error
+	)
+	switch format {
+	case exportFormatJSONL:
+		contentType, write = "application/x-ndjson", writeEventsJSONL
+	case exportFormatCSV:
+		contentType, write = "text/csv; charset=utf-8", writeEventsCSV
+	default:
+		return badRequest(c, fmt.Errorf("invalid format %q: must be %s or %s", format, exportFormatJSONL, exportFormatCSV))
+	}
+
+	var events []apitypes.Event
+	if tl := s.orchestrator.GetTimeline(); tl != nil {
+		found, next := tl.Query(timelineQuery(params))
+		events = toEvents(found)
+		setNextCursor(c, next)
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, contentType)
+	resp.Header().Set(echo.HeaderContentDisposition, `attachment; filename="timeline.`+format+`"`)
+	resp.WriteHeader(http.StatusOK)
+
+	// The status is sent, so a failed write can only be logged
+	if err = write(resp, events); err != nil {
+		s.logger.Warn().Err(err).Str("format", format).Msg("timeline export interrupted")
+	}
+
+	return nil
+}
+
+// writeEventsJSONL writes one JSON-encoded event per line.
+func writeEventsJSONL(w io.Writer, events []apitypes.Event) error {
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEventsCSV writes events as CSV with a header row. Details are
+// JSON-encoded into a single column.
+func writeEventsCSV(w io.Writer, events []apitypes.Event) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVHeader); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		var details string
+		if len(e.Details) > 0 {
+			data, err := json.Marshal(e.Details)
+			if err != nil {
+				return err
+			}
+			details = string(data)
+		}
+
+		err := cw.Write([]string{
+			e.ID,
+			e.Timestamp.Format(time.RFC3339Nano),
+			e.Type,
+			e.Message,
+			e.DownloadID,
+			e.DownloadName,
+			e.AppName,
+			e.Downloader,
+			details,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
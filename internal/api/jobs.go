@@ -0,0 +1,33 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/seedreap/seedreap/internal/orchestrator"
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+// cancelJobHandler stops syncing a download.
+func (s *Server) cancelJobHandler(c echo.Context) error {
+	return jobActionResponse(c, s.orchestrator.CancelJob(c.Param("id")))
+}
+
+// retryJobHandler syncs a failed or cancelled download again.
+func (s *Server) retryJobHandler(c echo.Context) error {
+	return jobActionResponse(c, s.orchestrator.RetryJob(c.Param("id")))
+}
+
+// jobActionResponse maps the result of a job action to a response.
+func jobActionResponse(c echo.Context, err error) error {
+	switch {
+	case err == nil:
+		return c.NoContent(http.StatusNoContent)
+	case errors.Is(err, orchestrator.ErrJobNotFound):
+		return notFound(c, "job not found")
+	default:
+		return c.JSON(http.StatusConflict, apitypes.Error{Error: err.Error()})
+	}
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// openAPISpec is the OpenAPI 3 description of the HTTP API. It is written by
+// hand: keep it in sync with the routes in setupRoutes, the types in
+// pkg/apitypes and the client in pkg/client.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+func (s *Server) openAPIHandler(c echo.Context) error {
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, openAPISpec)
+}
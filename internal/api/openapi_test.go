@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seedreap/seedreap/internal/app"
+	"github.com/seedreap/seedreap/internal/download"
+	"github.com/seedreap/seedreap/internal/filesync"
+	"github.com/seedreap/seedreap/internal/orchestrator"
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+type openAPIDoc struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPI(t *testing.T) openAPIDoc {
+	t.Helper()
+
+	var doc openAPIDoc
+	require.NoError(t, json.Unmarshal(openAPISpec, &doc))
+	require.True(t, strings.HasPrefix(doc.OpenAPI, "3."), "openapi version %q", doc.OpenAPI)
+	return doc
+}
+
+// TestOpenAPICoversRoutes ensures every API route is documented and every
+// documented path exists.
+func TestOpenAPICoversRoutes(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	syncer := filesync.New(t.TempDir())
+	orch := orchestrator.New(download.NewRegistry(), app.NewRegistry(), syncer, t.TempDir())
+	s := New(orch, download.NewRegistry(), app.NewRegistry(), syncer)
+
+	param := regexp.MustCompile(`:(\w+)`)
+	routes := make(map[string]bool)
+	for _, r := range s.echo.Routes() {
+		if !strings.HasPrefix(r.Path, "/api/") || r.Method == echo.RouteNotFound {
+			continue
+		}
+		path := param.ReplaceAllString(r.Path, "{$1}")
+		key := strings.ToLower(r.Method) + " " + path
+		routes[key] = true
+
+		_, ok := doc.Paths[path][strings.ToLower(r.Method)]
+		assert.True(t, ok, "route %s missing from openapi.json", key)
+	}
+
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			if method == "parameters" {
+				continue
+			}
+			assert.True(t, routes[method+" "+path], "openapi.json documents unknown route %s %s", method, path)
+		}
+	}
+}
+
+// TestOpenAPIMatchesTypes ensures the documented schemas have the same
+// properties as the JSON encoding of the apitypes they describe.
+func TestOpenAPIMatchesTypes(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	types := map[string]any{
+		"Health":              apitypes.Health{},
+		"Stats":               apitypes.Stats{},
+		"Download":            apitypes.Download{},
+		"DownloadDetail":      apitypes.DownloadDetail{},
+		"FileProgress":        apitypes.FileProgress{},
+		"Job":                 apitypes.Job{},
+		"JobDetail":           apitypes.JobDetail{},
+		"SpeedSample":         apitypes.SpeedSample{},
+		"Downloader":          apitypes.Downloader{},
+		"App":                 apitypes.App{},
+		"Event":               apitypes.Event{},
+		"Error":               apitypes.Error{},
+		"AddDownloadRequest":  apitypes.AddDownloadRequest{},
+		"TorrentFile":         apitypes.TorrentFile{},
+		"AddDownloadResponse": apitypes.AddDownloadResponse{},
+		"Plan":                apitypes.Plan{},
+		"PlannedDownload":     apitypes.PlannedDownload{},
+		"PlannedFile":         apitypes.PlannedFile{},
+		"PlannedAction":       apitypes.PlannedAction{},
+	}
+
+	for name, v := range types {
+		t.Run(name, func(t *testing.T) {
+			schema, ok := doc.Components.Schemas[name]
+			require.True(t, ok, "schema %s missing from openapi.json", name)
+
+			var fields []string
+			rt := reflect.TypeOf(v)
+			for i := range rt.NumField() {
+				tag, _, _ := strings.Cut(rt.Field(i).Tag.Get("json"), ",")
+				fields = append(fields, tag)
+			}
+
+			var props []string
+			for prop := range schema.Properties {
+				props = append(props, prop)
+			}
+
+			slices.Sort(fields)
+			slices.Sort(props)
+			assert.Equal(t, fields, props)
+		})
+	}
+}
+
+// TestOpenAPIRefsResolve ensures every $ref points at a defined component.
+func TestOpenAPIRefsResolve(t *testing.T) {
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(openAPISpec, &raw))
+
+	refs := regexp.MustCompile(`"\$ref":\s*"#/components/(\w+)/(\w+)"`).FindAllStringSubmatch(string(openAPISpec), -1)
+	require.NotEmpty(t, refs)
+
+	components, _ := raw["components"].(map[string]any)
+	for _, ref := range refs {
+		section, _ := components[ref[1]].(map[string]any)
+		_, ok := section[ref[2]]
+		assert.True(t, ok, "unresolved $ref %s", ref[0])
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/seedreap/seedreap/internal/orchestrator"
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+// planHandler returns what a dry run would do with each tracked download.
+func (s *Server) planHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, NewPlan(s.orchestrator))
+}
+
+// NewPlan returns the dry-run plan of an orchestrator, as served by GET /api/plan.
+func NewPlan(o *orchestrator.Orchestrator) apitypes.Plan {
+	resp := apitypes.Plan{
+		DryRun:    o.DryRun(),
+		Downloads: []apitypes.PlannedDownload{},
+	}
+
+	for _, p := range o.Plan() {
+		d := apitypes.PlannedDownload{
+			ID:         p.ID,
+			Name:       p.Name,
+			Downloader: p.Downloader,
+			Category:   p.Category,
+			State:      string(p.State),
+			Apps:       p.Apps,
+			FinalPath:  p.FinalPath,
+			Files:      make([]apitypes.PlannedFile, 0, len(p.Files)),
+			Actions:    make([]apitypes.PlannedAction, 0, len(p.Actions)),
+		}
+		for _, f := range p.Files {
+			d.Size += f.Size
+			d.Files = append(d.Files, apitypes.PlannedFile{Path: f.Path, Size: f.Size})
+		}
+		for _, a := range p.Actions {
+			d.Actions = append(d.Actions, apitypes.PlannedAction{
+				Type:      string(a.Type),
+				Timestamp: a.Time,
+				Message:   a.Message,
+			})
+		}
+		resp.Downloads = append(resp.Downloads, d)
+	}
+
+	return resp
+}
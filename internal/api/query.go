@@ -0,0 +1,267 @@
+package api
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/seedreap/seedreap/internal/app"
+	"github.com/seedreap/seedreap/internal/orchestrator"
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+const (
+	// headerNextCursor carries the cursor for the next page of a paginated list.
+	headerNextCursor = "X-Next-Cursor"
+
+	// maxPageLimit caps the page size a client can request.
+	maxPageLimit = 1000
+)
+
+var (
+	errInvalidCursor = errors.New("invalid cursor")
+	errTimelineState = errors.New("state is not supported for timeline events, use type instead")
+)
+
+// listParams holds the filtering, sorting and pagination query parameters
+// shared by the list endpoints.
+type listParams struct {
+	states      []string
+	types       []string
+	downloads   []string
+	categories  []string
+	downloaders []string
+	apps        []string
+	search      string
+	since       time.Time
+	until       time.Time
+	sort        string
+	desc        bool
+	limit       int
+	cursor      string
+}
+
+// parseListParams reads list query parameters. sortFields lists the accepted
+// values for "sort"; the first entry is the default.
+func parseListParams(c echo.Context, sortFields []string, defaultDesc bool) (listParams, error) {
+	p := listParams{
+		states:      queryList(c, "state"),
+		types:       queryList(c, "type"),
+		downloads:   queryList(c, "download"),
+		categories:  queryList(c, "category"),
+		downloaders: queryList(c, "downloader"),
+		apps:        queryList(c, "app"),
+		search:      strings.TrimSpace(c.QueryParam("q")),
+		sort:        sortFields[0],
+		desc:        defaultDesc,
+		cursor:      c.QueryParam("cursor"),
+	}
+
+	var err error
+	if p.since, err = queryTime(c, "since"); err != nil {
+		return p, err
+	}
+	if p.until, err = queryTime(c, "until"); err != nil {
+		return p, err
+	}
+
+	if s := c.QueryParam("sort"); s != "" {
+		if !slices.Contains(sortFields, s) {
+			return p, fmt.Errorf("invalid sort %q: must be one of %s", s, strings.Join(sortFields, ", "))
+		}
+		p.sort = s
+	}
+
+	switch order := c.QueryParam("order"); order {
+	case "":
+	case "asc":
+		p.desc = false
+	case "desc":
+		p.desc = true
+	default:
+		return p, fmt.Errorf("invalid order %q: must be asc or desc", order)
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		p.limit, err = strconv.Atoi(l)
+		if err != nil || p.limit <= 0 {
+			return p, fmt.Errorf("invalid limit %q: must be a positive integer", l)
+		}
+		p.limit = min(p.limit, maxPageLimit)
+	}
+
+	return p, nil
+}
+
+// queryList returns the values of a query parameter that may be repeated
+// and/or comma-separated.
+func queryList(c echo.Context, name string) []string {
+	var values []string
+	for _, raw := range c.QueryParams()[name] {
+		for v := range strings.SplitSeq(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// queryTime parses an RFC 3339 timestamp query parameter.
+func queryTime(c echo.Context, name string) (time.Time, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: must be an RFC 3339 timestamp", name, v)
+	}
+	return t, nil
+}
+
+// matches reports whether a tracked download passes the filters.
+// state is the state shown for the item, which may come from its sync job.
+func (p listParams) matches(td *orchestrator.TrackedDownload, state string, apps []app.App) bool {
+	dl := td.GetDownload()
+
+	if len(p.states) > 0 && !slices.Contains(p.states, state) {
+		return false
+	}
+	if len(p.categories) > 0 && !slices.Contains(p.categories, dl.Category) {
+		return false
+	}
+	if len(p.downloaders) > 0 && !slices.Contains(p.downloaders, td.DownloaderName) {
+		return false
+	}
+	if len(p.apps) > 0 && !slices.ContainsFunc(apps, func(a app.App) bool {
+		return slices.Contains(p.apps, a.Name())
+	}) {
+		return false
+	}
+
+	discoveredAt, _ := td.GetTimes()
+	if !p.since.IsZero() && discoveredAt.Before(p.since) {
+		return false
+	}
+	if !p.until.IsZero() && !discoveredAt.Before(p.until) {
+		return false
+	}
+
+	if p.search != "" && !strings.Contains(strings.ToLower(dl.Name), strings.ToLower(p.search)) {
+		return false
+	}
+	return true
+}
+
+// sortKey is the value a list item is ordered by. Numeric fields set Num and
+// text fields set Str.
+type sortKey struct {
+	Num float64 `json:"n,omitempty"`
+	Str string  `json:"s,omitempty"`
+}
+
+func textKey(s string) sortKey {
+	return sortKey{Str: strings.ToLower(s)}
+}
+
+func numKey[N int | int64 | float64](n N) sortKey {
+	return sortKey{Num: float64(n)}
+}
+
+func timeKey(t time.Time) sortKey {
+	return sortKey{Num: float64(t.UnixNano())}
+}
+
+// pageCursor identifies the last item of a page. Encoding the sort key rather
+// than a position keeps pages stable when items are added or removed.
+type pageCursor struct {
+	Key sortKey `json:"k"`
+	ID  string  `json:"id"`
+}
+
+func encodeCursor(cur pageCursor) string {
+	data, _ := json.Marshal(cur) //nolint:errchkjson // pageCursor always marshals
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	var cur pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, errInvalidCursor
+	}
+	if err = json.Unmarshal(data, &cur); err != nil {
+		return cur, errInvalidCursor
+	}
+	return cur, nil
+}
+
+// paginate sorts items by keyOf (ties broken by idOf), skips past the cursor
+// and trims the result to the page limit. It returns the page and the cursor
+// for the next one, which is empty on the last page.
+func paginate[T any](items []T, p listParams, keyOf func(T) sortKey, idOf func(T) string) ([]T, string, error) {
+	compare := func(aKey sortKey, aID string, bKey sortKey, bID string) int {
+		c := cmp.Or(
+			cmp.Compare(aKey.Num, bKey.Num),
+			strings.Compare(aKey.Str, bKey.Str),
+			strings.Compare(aID, bID),
+		)
+		if p.desc {
+			return -c
+		}
+		return c
+	}
+
+	slices.SortFunc(items, func(a, b T) int {
+		return compare(keyOf(a), idOf(a), keyOf(b), idOf(b))
+	})
+
+	if p.cursor != "" {
+		cur, err := decodeCursor(p.cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start := slices.IndexFunc(items, func(item T) bool {
+			return compare(keyOf(item), idOf(item), cur.Key, cur.ID) > 0
+		})
+		if start < 0 {
+			start = len(items)
+		}
+		items = items[start:]
+	}
+
+	if p.limit > 0 && len(items) > p.limit {
+		items = items[:p.limit]
+		last := items[len(items)-1]
+		return items, encodeCursor(pageCursor{Key: keyOf(last), ID: idOf(last)}), nil
+	}
+
+	return items, "", nil
+}
+
+// badRequest responds with a 400 and the error message.
+func badRequest(c echo.Context, err error) error {
+	return c.JSON(http.StatusBadRequest, apitypes.Error{Error: err.Error()})
+}
+
+// notFound responds with a 404 and the message.
+func notFound(c echo.Context, msg string) error {
+	return c.JSON(http.StatusNotFound, apitypes.Error{Error: msg})
+}
+
+// setNextCursor advertises the next page, if there is one.
+func setNextCursor(c echo.Context, next string) {
+	if next != "" {
+		c.Response().Header().Set(headerNextCursor, next)
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// redactResponses scrubs secrets from API responses, such as passwords in the
+// error messages of failed transfers or reloads.
+func (s *Server) redactResponses(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		res := c.Response()
+		res.Writer = &redactingResponseWriter{ResponseWriter: res.Writer, body: s.redactor.Writer(res.Writer)}
+		return next(c)
+	}
+}
+
+// redactingResponseWriter writes the response body through a redacting writer.
+type redactingResponseWriter struct {
+	http.ResponseWriter
+
+	body io.Writer
+}
+
+// Write writes the scrubbed body.
+func (w *redactingResponseWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *redactingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
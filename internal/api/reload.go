@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/seedreap/seedreap/internal/config"
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+// ConfigReloader reloads the configuration and applies it to the running server.
+type ConfigReloader func(ctx context.Context) (config.Changes, error)
+
+// WithConfigReloader enables POST /api/config/reload.
+func WithConfigReloader(reload ConfigReloader) Option {
+	return func(s *Server) {
+		s.reload = reload
+	}
+}
+
+// reloadConfigHandler reloads the configuration file and applies downloader and app changes.
+func (s *Server) reloadConfigHandler(c echo.Context) error {
+	if s.reload == nil {
+		return c.JSON(http.StatusNotImplemented, apitypes.Error{Error: "configuration reload is not available"})
+	}
+
+	changes, err := s.reload(c.Request().Context())
+	if errors.Is(err, config.ErrRestartRequired) {
+		return c.JSON(http.StatusConflict, apitypes.Error{Error: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, apitypes.Error{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, apitypes.ConfigReload{
+		Downloaders: configChanges(changes.AddedDownloaders, changes.RemovedDownloaders, changes.ChangedDownloaders),
+		Apps:        configChanges(changes.AddedApps, changes.RemovedApps, changes.ChangedApps),
+	})
+}
+
+// configChanges converts change lists, using empty lists rather than null.
+func configChanges(added, removed, changed []string) apitypes.ConfigChanges {
+	orEmpty := func(names []string) []string {
+		if names == nil {
+			return []string{}
+		}
+		return names
+	}
+
+	return apitypes.ConfigChanges{
+		Added:   orEmpty(added),
+		Removed: orEmpty(removed),
+		Changed: orEmpty(changed),
+	}
+}
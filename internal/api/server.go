@@ -6,7 +6,6 @@ import (
 	"embed"
 	"io/fs"
 	"net/http"
-	"sort"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -16,6 +15,9 @@ import (
 	"github.com/seedreap/seedreap/internal/download"
 	"github.com/seedreap/seedreap/internal/filesync"
 	"github.com/seedreap/seedreap/internal/orchestrator"
+	"github.com/seedreap/seedreap/internal/redact"
+	"github.com/seedreap/seedreap/internal/timeline"
+	"github.com/seedreap/seedreap/pkg/apitypes"
 )
 
 // Server is the HTTP API server.
@@ -27,6 +29,9 @@ type Server struct {
 	syncer       *filesync.Syncer
 	logger       zerolog.Logger
 	uiFS         fs.FS
+	reload       ConfigReloader
+	apiKey       string
+	redactor     *redact.Redactor
 }
 
 // Option is a functional option for configuring the server.
@@ -51,6 +56,21 @@ func WithUI(uiFS embed.FS, subdir string) Option {
 	}
 }
 
+// WithAPIKey requires requests that change state to send the key in the
+// X-Api-Key header. Read-only requests, and with them the UI, stay open.
+func WithAPIKey(key string) Option {
+	return func(s *Server) {
+		s.apiKey = key
+	}
+}
+
+// WithRedactor scrubs the secrets known to r from API responses.
+func WithRedactor(r *redact.Redactor) Option {
+	return func(s *Server) {
+		s.redactor = r
+	}
+}
+
 // New creates a new API server.
 func New(
 	orch *orchestrator.Orchestrator,
@@ -110,16 +130,21 @@ func (s *Server) setupMiddleware() {
 	// Recovery
 	s.echo.Use(middleware.Recover())
 
-	// CORS
+	// CORS, for reading only: other websites must not be able to change state
 	s.echo.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{http.MethodGet, http.MethodHead},
+		ExposeHeaders: []string{headerNextCursor},
 	}))
 }
 
 func (s *Server) setupRoutes() {
 	// API routes
 	api := s.echo.Group("/api")
+	if s.redactor != nil {
+		api.Use(s.redactResponses)
+	}
+	api.Use(s.requireAPIKey)
 
 	// Health check
 	api.GET("/health", s.healthHandler)
@@ -129,11 +154,17 @@ func (s *Server) setupRoutes() {
 
 	// Downloads
 	api.GET("/downloads", s.listDownloadsHandler)
+	api.POST("/downloads", s.addDownloadHandler, middleware.BodyLimit(maxAddBodySize))
 	api.GET("/downloads/:id", s.getDownloadHandler)
 
 	// Sync jobs
 	api.GET("/jobs", s.listJobsHandler)
 	api.GET("/jobs/:id", s.getJobHandler)
+	api.POST("/jobs/:id/cancel", s.cancelJobHandler)
+	api.POST("/jobs/:id/retry", s.retryJobHandler)
+
+	// Dry-run plan
+	api.GET("/plan", s.planHandler)
 
 	// Speed history for sparkline
 	api.GET("/speed-history", s.speedHistoryHandler)
@@ -146,10 +177,17 @@ func (s *Server) setupRoutes() {
 
 	// Timeline
 	api.GET("/timeline", s.timelineHandler)
+	api.GET("/timeline/export", s.timelineExportHandler)
 	api.GET("/apps/:id/timeline", s.appTimelineHandler)
 	api.GET("/downloaders/:id/timeline", s.downloaderTimelineHandler)
 	api.GET("/jobs/:id/timeline", s.jobTimelineHandler)
 
+	// Configuration
+	api.POST("/config/reload", s.reloadConfigHandler)
+
+	// OpenAPI specification
+	api.GET("/openapi.json", s.openAPIHandler)
+
 	// Serve UI if available
 	if s.uiFS != nil {
 		s.echo.GET("/*", echo.WrapHandler(http.FileServer(http.FS(s.uiFS))))
@@ -178,64 +216,66 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // Handlers
 
 func (s *Server) healthHandler(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{
-		"status": "ok",
-	})
+	return c.JSON(http.StatusOK, apitypes.Health{Status: "ok"})
 }
 
 func (s *Server) statsHandler(c echo.Context) error {
 	stats := s.orchestrator.GetStats()
-	return c.JSON(http.StatusOK, stats)
+
+	var resp apitypes.Stats
+	resp.TotalTracked, _ = stats["total_tracked"].(int)
+	resp.ByState, _ = stats["by_state"].(map[string]int)
+	resp.DownloadingOnSeedbox, _ = stats["downloading_on_seedbox"].(int)
+	resp.PausedOnSeedbox, _ = stats["paused_on_seedbox"].(int)
+
+	return c.JSON(http.StatusOK, resp)
 }
 
-func (s *Server) listDownloadsHandler(c echo.Context) error {
-	tracked := s.orchestrator.GetTrackedDownloads()
+// downloadSortFields are the accepted "sort" values for /api/downloads; the first is the default.
+var downloadSortFields = []string{"name", "state", "category", "downloader", "size", "progress", "discovered"}
 
-	type downloadResponse struct {
-		ID           string  `json:"id"`
-		Name         string  `json:"name"`
-		Downloader   string  `json:"downloader"`
-		Category     string  `json:"category"`
-		State        string  `json:"state"`
-		Progress     float64 `json:"progress"`
-		Size         int64   `json:"size"`
-		SyncedBytes  int64   `json:"synced_bytes"`
-		Error        string  `json:"error,omitempty"`
-		DiscoveredAt string  `json:"discovered_at"`
-		CompletedAt  string  `json:"completed_at,omitempty"`
+func (s *Server) listDownloadsHandler(c echo.Context) error {
+	params, err := parseListParams(c, downloadSortFields, false)
+	if err != nil {
+		return badRequest(c, err)
 	}
 
-	downloads := make([]downloadResponse, 0, len(tracked))
+	tracked := s.orchestrator.GetTrackedDownloads()
+
+	downloads := make([]apitypes.Download, 0, len(tracked))
 	for _, td := range tracked {
 		dl := td.GetDownload()
 
-		// Only include downloads for categories that have matching apps
-		if len(s.apps.GetByCategory(dl.Category)) == 0 {
+		// Only include downloads that have matching apps
+		apps := s.apps.GetForDownload(td.DownloaderName, dl.Category, dl.Tags)
+		if len(apps) == 0 {
 			continue
 		}
 
 		state := td.GetState()
-		err := td.GetError()
+		if !params.matches(td, string(state), apps) {
+			continue
+		}
+
+		dlErr := td.GetError()
 		discoveredAt, completedAt := td.GetTimes()
 		job := td.GetSyncJob()
 
-		resp := downloadResponse{
+		resp := apitypes.Download{
 			ID:           dl.ID,
 			Name:         dl.Name,
 			Downloader:   td.DownloaderName,
 			Category:     dl.Category,
+			Tags:         dl.Tags,
 			State:        string(state),
 			Progress:     dl.Progress,
 			Size:         dl.Size,
-			DiscoveredAt: discoveredAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
-
-		if err != nil {
-			resp.Error = err.Error()
+			DiscoveredAt: discoveredAt,
+			CompletedAt:  completedAt,
 		}
 
-		if !completedAt.IsZero() {
-			resp.CompletedAt = completedAt.Format("2006-01-02T15:04:05Z07:00")
+		if dlErr != nil {
+			resp.Error = dlErr.Error()
 		}
 
 		if job != nil {
@@ -246,11 +286,32 @@ func (s *Server) listDownloadsHandler(c echo.Context) error {
 		downloads = append(downloads, resp)
 	}
 
-	// Sort by name for consistent ordering
-	sort.Slice(downloads, func(i, j int) bool {
-		return downloads[i].Name < downloads[j].Name
-	})
+	downloads, next, err := paginate(downloads, params,
+		func(d apitypes.Download) sortKey {
+			switch params.sort {
+			case "state":
+				return textKey(d.State)
+			case "category":
+				return textKey(d.Category)
+			case "downloader":
+				return textKey(d.Downloader)
+			case "size":
+				return numKey(d.Size)
+			case "progress":
+				return numKey(d.Progress)
+			case "discovered":
+				return timeKey(d.DiscoveredAt)
+			default:
+				return textKey(d.Name)
+			}
+		},
+		func(d apitypes.Download) string { return d.Downloader + "/" + d.ID },
+	)
+	if err != nil {
+		return badRequest(c, err)
+	}
 
+	setNextCursor(c, next)
 	return c.JSON(http.StatusOK, downloads)
 }
 
@@ -264,65 +325,47 @@ func (s *Server) getDownloadHandler(c echo.Context) error {
 			state := td.GetState()
 			job := td.GetSyncJob()
 
-			resp := map[string]any{
-				"id":         dl.ID,
-				"name":       dl.Name,
-				"downloader": td.DownloaderName,
-				"category":   dl.Category,
-				"state":      string(state),
-				"progress":   dl.Progress,
-				"size":       dl.Size,
-				"save_path":  dl.SavePath,
+			resp := apitypes.DownloadDetail{
+				ID:         dl.ID,
+				Name:       dl.Name,
+				Downloader: td.DownloaderName,
+				Category:   dl.Category,
+				Tags:       dl.Tags,
+				State:      string(state),
+				Progress:   dl.Progress,
+				Size:       dl.Size,
+				SavePath:   dl.SavePath,
 			}
 
 			if job != nil {
-				snapshot := job.Snapshot()
-				files := make([]map[string]any, 0, len(snapshot.Files))
-				for _, f := range snapshot.Files {
-					files = append(files, map[string]any{
-						"path":          f.Path,
-						"size":          f.Size,
-						"transferred":   f.Transferred,
-						"status":        string(f.Status),
-						"bytes_per_sec": f.BytesPerSec,
-					})
-				}
-				resp["files"] = files
+				resp.Files = fileProgress(job.Snapshot().Files)
 			}
 
 			return c.JSON(http.StatusOK, resp)
 		}
 	}
 
-	return c.JSON(http.StatusNotFound, map[string]string{
-		"error": "download not found",
-	})
+	return notFound(c, "download not found")
 }
 
+// jobSortFields are the accepted "sort" values for /api/jobs; the first is the default.
+var jobSortFields = []string{"name", "status", "category", "downloader", "files", "size", "progress", "speed"}
+
+//nolint:funlen // builds responses from two data sources before filtering and paginating
 func (s *Server) listJobsHandler(c echo.Context) error {
-	type jobResponse struct {
-		ID              string  `json:"id"`
-		Name            string  `json:"name"`
-		Downloader      string  `json:"downloader"`
-		Category        string  `json:"category"`
-		App             string  `json:"app"`
-		Status          string  `json:"status"`
-		SeedboxState    string  `json:"seedbox_state"`
-		SeedboxProgress float64 `json:"seedbox_progress"`
-		TotalSize       int64   `json:"total_size"`
-		CompletedSize   int64   `json:"completed_size"`
-		TotalFiles      int     `json:"total_files"`
-		BytesPerSec     int64   `json:"bytes_per_sec"`
+	params, err := parseListParams(c, jobSortFields, false)
+	if err != nil {
+		return badRequest(c, err)
 	}
 
-	response := make([]jobResponse, 0)
+	response := make([]apitypes.Job, 0)
 
 	// Add all tracked downloads (includes those with and without sync jobs)
 	for _, td := range s.orchestrator.GetTrackedDownloads() {
 		dl := td.GetDownload()
 
-		// Only include downloads for categories that have matching apps
-		apps := s.apps.GetByCategory(dl.Category)
+		// Only include downloads that have matching apps
+		apps := s.apps.GetForDownload(td.DownloaderName, dl.Category, dl.Tags)
 		if len(apps) == 0 {
 			continue
 		}
@@ -333,11 +376,11 @@ func (s *Server) listJobsHandler(c echo.Context) error {
 		job := td.GetSyncJob()
 		state := td.GetState()
 
-		var resp jobResponse
+		var resp apitypes.Job
 		if job != nil {
 			// Has a sync job - use its data
 			snapshot := job.Snapshot()
-			resp = jobResponse{
+			resp = apitypes.Job{
 				ID:              snapshot.ID,
 				Name:            snapshot.Name,
 				Downloader:      snapshot.Downloader,
@@ -354,7 +397,7 @@ func (s *Server) listJobsHandler(c echo.Context) error {
 		} else {
 			// No sync job - use tracked download data
 			// This happens when files already exist at final destination or still downloading
-			resp = jobResponse{
+			resp = apitypes.Job{
 				ID:              dl.ID,
 				Name:            dl.Name,
 				Downloader:      td.DownloaderName,
@@ -375,17 +418,47 @@ func (s *Server) listJobsHandler(c echo.Context) error {
 			}
 		}
 
+		if !params.matches(td, resp.Status, apps) {
+			continue
+		}
+
 		response = append(response, resp)
 	}
 
 	// Record total speed for sparkline history from the transferer
 	s.syncer.RecordSpeed(s.syncer.GetAggregateSpeed())
 
-	// Sort by name for consistent ordering
-	sort.Slice(response, func(i, j int) bool {
-		return response[i].Name < response[j].Name
-	})
+	response, next, err := paginate(response, params,
+		func(j apitypes.Job) sortKey {
+			switch params.sort {
+			case "status":
+				return textKey(j.Status)
+			case "category":
+				return textKey(j.Category)
+			case "downloader":
+				return textKey(j.Downloader)
+			case "files":
+				return numKey(j.TotalFiles)
+			case "size":
+				return numKey(j.TotalSize)
+			case "progress":
+				if j.TotalSize == 0 {
+					return numKey(0)
+				}
+				return numKey(float64(j.CompletedSize) / float64(j.TotalSize))
+			case "speed":
+				return numKey(j.BytesPerSec)
+			default:
+				return textKey(j.Name)
+			}
+		},
+		func(j apitypes.Job) string { return j.Downloader + "/" + j.ID },
+	)
+	if err != nil {
+		return badRequest(c, err)
+	}
 
+	setNextCursor(c, next)
 	return c.JSON(http.StatusOK, response)
 }
 
@@ -398,30 +471,19 @@ func (s *Server) getJobHandler(c echo.Context) error {
 	if ok {
 		snapshot := job.Snapshot()
 
-		files := make([]map[string]any, 0, len(snapshot.Files))
-		for _, f := range snapshot.Files {
-			files = append(files, map[string]any{
-				"path":          f.Path,
-				"size":          f.Size,
-				"transferred":   f.Transferred,
-				"status":        string(f.Status),
-				"bytes_per_sec": f.BytesPerSec,
-			})
-		}
-
-		return c.JSON(http.StatusOK, map[string]any{
-			"id":             snapshot.ID,
-			"name":           snapshot.Name,
-			"downloader":     snapshot.Downloader,
-			"category":       snapshot.Category,
-			"status":         string(snapshot.Status),
-			"total_size":     snapshot.TotalSize,
-			"completed_size": snapshot.CompletedSize,
-			"total_files":    snapshot.TotalFiles,
-			"remote_base":    snapshot.RemoteBase,
-			"local_base":     snapshot.LocalBase,
-			"final_path":     snapshot.FinalPath,
-			"files":          files,
+		return c.JSON(http.StatusOK, apitypes.JobDetail{
+			ID:            snapshot.ID,
+			Name:          snapshot.Name,
+			Downloader:    snapshot.Downloader,
+			Category:      snapshot.Category,
+			Status:        string(snapshot.Status),
+			TotalSize:     snapshot.TotalSize,
+			CompletedSize: snapshot.CompletedSize,
+			TotalFiles:    snapshot.TotalFiles,
+			RemoteBase:    snapshot.RemoteBase,
+			LocalBase:     snapshot.LocalBase,
+			FinalPath:     snapshot.FinalPath,
+			Files:         fileProgress(snapshot.Files),
 		})
 	}
 
@@ -432,7 +494,7 @@ func (s *Server) getJobHandler(c echo.Context) error {
 			state := td.GetState()
 
 			// Build file list from download info
-			files := make([]map[string]any, 0, len(dl.Files))
+			files := make([]apitypes.FileProgress, 0, len(dl.Files))
 			var totalDownloaded int64
 			for _, f := range dl.Files {
 				if f.Priority == 0 {
@@ -450,43 +512,40 @@ func (s *Server) getJobHandler(c echo.Context) error {
 					}
 				}
 
-				files = append(files, map[string]any{
-					"path":          f.Path,
-					"size":          f.Size,
-					"transferred":   f.Downloaded,
-					"status":        status,
-					"bytes_per_sec": int64(0),
+				files = append(files, apitypes.FileProgress{
+					Path:        f.Path,
+					Size:        f.Size,
+					Transferred: f.Downloaded,
+					Status:      status,
 				})
 				totalDownloaded += f.Downloaded
 			}
 
-			return c.JSON(http.StatusOK, map[string]any{
-				"id":             dl.ID,
-				"name":           dl.Name,
-				"downloader":     td.DownloaderName,
-				"category":       dl.Category,
-				"status":         string(state),
-				"total_size":     dl.Size,
-				"completed_size": totalDownloaded,
-				"total_files":    len(files),
-				"files":          files,
+			return c.JSON(http.StatusOK, apitypes.JobDetail{
+				ID:            dl.ID,
+				Name:          dl.Name,
+				Downloader:    td.DownloaderName,
+				Category:      dl.Category,
+				Status:        string(state),
+				TotalSize:     dl.Size,
+				CompletedSize: totalDownloaded,
+				TotalFiles:    len(files),
+				Files:         files,
 			})
 		}
 	}
 
-	return c.JSON(http.StatusNotFound, map[string]string{
-		"error": "job not found",
-	})
+	return notFound(c, "job not found")
 }
 
 func (s *Server) listDownloadersHandler(c echo.Context) error {
 	downloaders := s.downloaders.All()
 
-	response := make([]map[string]string, 0, len(downloaders))
+	response := make([]apitypes.Downloader, 0, len(downloaders))
 	for name, dl := range downloaders {
-		response = append(response, map[string]string{
-			"name": name,
-			"type": dl.Type(),
+		response = append(response, apitypes.Downloader{
+			Name: name,
+			Type: dl.Type(),
 		})
 	}
 
@@ -496,13 +555,18 @@ func (s *Server) listDownloadersHandler(c echo.Context) error {
 func (s *Server) listAppsHandler(c echo.Context) error {
 	apps := s.apps.All()
 
-	response := make([]map[string]string, 0, len(apps))
+	response := make([]apitypes.App, 0, len(apps))
 	for name, a := range apps {
-		response = append(response, map[string]string{
-			"name":     name,
-			"type":     a.Type(),
-			"category": a.Category(),
-		})
+		entry := apitypes.App{
+			Name:     name,
+			Type:     a.Type(),
+			Category: a.Category(),
+		}
+		if tags := a.Tags(); len(tags) > 0 {
+			entry.Tags = tags
+			entry.TagMatch = string(a.TagMatch())
+		}
+		response = append(response, entry)
 	}
 
 	return c.JSON(http.StatusOK, response)
@@ -531,6 +595,9 @@ func (s *Server) indexHandler(c echo.Context) error {
         <li><a href="/api/jobs">/api/jobs</a> - List sync jobs</li>
         <li><a href="/api/downloaders">/api/downloaders</a> - List configured downloaders</li>
         <li><a href="/api/apps">/api/apps</a> - List configured apps</li>
+        <li><a href="/api/timeline">/api/timeline</a> - Timeline events</li>
+        <li><a href="/api/timeline/export">/api/timeline/export</a> - Timeline export (JSONL or CSV)</li>
+        <li><a href="/api/openapi.json">/api/openapi.json</a> - OpenAPI specification</li>
     </ul>
 </body>
 </html>`
@@ -539,16 +606,65 @@ func (s *Server) indexHandler(c echo.Context) error {
 
 func (s *Server) speedHistoryHandler(c echo.Context) error {
 	history := s.syncer.GetSpeedHistory()
-	return c.JSON(http.StatusOK, history)
+
+	response := make([]apitypes.SpeedSample, 0, len(history))
+	for _, sample := range history {
+		response = append(response, apitypes.SpeedSample{
+			Speed:     sample.Speed,
+			Timestamp: sample.Timestamp,
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
 }
 
 func (s *Server) timelineHandler(c echo.Context) error {
+	params, err := parseTimelineParams(c, true)
+	if err != nil {
+		return badRequest(c, err)
+	}
+
 	tl := s.orchestrator.GetTimeline()
 	if tl == nil {
-		return c.JSON(http.StatusOK, []any{})
+		return c.JSON(http.StatusOK, []apitypes.Event{})
 	}
 
-	return c.JSON(http.StatusOK, tl.GetAll())
+	events, next := tl.Query(timelineQuery(params))
+
+	setNextCursor(c, next)
+	return c.JSON(http.StatusOK, toEvents(events))
+}
+
+// parseTimelineParams reads the list query parameters of the timeline endpoints.
+// Events have no state, so a state filter is rejected rather than ignored.
+func parseTimelineParams(c echo.Context, defaultDesc bool) (listParams, error) {
+	params, err := parseListParams(c, []string{"timestamp"}, defaultDesc)
+	if err == nil && len(params.states) > 0 {
+		err = errTimelineState
+	}
+	return params, err
+}
+
+// timelineQuery builds a timeline query from list parameters.
+func timelineQuery(params listParams) timeline.Query {
+	types := make([]timeline.EventType, 0, len(params.types))
+	for _, t := range params.types {
+		types = append(types, timeline.EventType(t))
+	}
+
+	return timeline.Query{
+		Types:       types,
+		Downloads:   params.downloads,
+		Downloaders: params.downloaders,
+		Apps:        params.apps,
+		Categories:  params.categories,
+		Search:      params.search,
+		Since:       params.since,
+		Until:       params.until,
+		Oldest:      !params.desc,
+		After:       params.cursor,
+		Limit:       params.limit,
+	}
 }
 
 func (s *Server) appTimelineHandler(c echo.Context) error {
@@ -556,10 +672,10 @@ func (s *Server) appTimelineHandler(c echo.Context) error {
 
 	tl := s.orchestrator.GetTimeline()
 	if tl == nil {
-		return c.JSON(http.StatusOK, []any{})
+		return c.JSON(http.StatusOK, []apitypes.Event{})
 	}
 
-	return c.JSON(http.StatusOK, tl.GetByApp(id))
+	return c.JSON(http.StatusOK, toEvents(tl.GetByApp(id)))
 }
 
 func (s *Server) downloaderTimelineHandler(c echo.Context) error {
@@ -567,10 +683,10 @@ func (s *Server) downloaderTimelineHandler(c echo.Context) error {
 
 	tl := s.orchestrator.GetTimeline()
 	if tl == nil {
-		return c.JSON(http.StatusOK, []any{})
+		return c.JSON(http.StatusOK, []apitypes.Event{})
 	}
 
-	return c.JSON(http.StatusOK, tl.GetByDownloader(id))
+	return c.JSON(http.StatusOK, toEvents(tl.GetByDownloader(id)))
 }
 
 func (s *Server) jobTimelineHandler(c echo.Context) error {
@@ -578,8 +694,42 @@ func (s *Server) jobTimelineHandler(c echo.Context) error {
 
 	tl := s.orchestrator.GetTimeline()
 	if tl == nil {
-		return c.JSON(http.StatusOK, []any{})
+		return c.JSON(http.StatusOK, []apitypes.Event{})
 	}
 
-	return c.JSON(http.StatusOK, tl.GetByDownload(id))
+	return c.JSON(http.StatusOK, toEvents(tl.GetByDownload(id)))
+}
+
+// fileProgress converts sync job file snapshots to API responses.
+func fileProgress(files []filesync.FileProgressSnapshot) []apitypes.FileProgress {
+	result := make([]apitypes.FileProgress, 0, len(files))
+	for _, f := range files {
+		result = append(result, apitypes.FileProgress{
+			Path:        f.Path,
+			Size:        f.Size,
+			Transferred: f.Transferred,
+			Status:      string(f.Status),
+			BytesPerSec: f.BytesPerSec,
+		})
+	}
+	return result
+}
+
+// toEvents converts timeline events to API responses.
+func toEvents(events []timeline.Event) []apitypes.Event {
+	result := make([]apitypes.Event, 0, len(events))
+	for _, e := range events {
+		result = append(result, apitypes.Event{
+			ID:           e.ID,
+			Type:         string(e.Type),
+			Timestamp:    e.Timestamp,
+			Message:      e.Message,
+			DownloadID:   e.DownloadID,
+			DownloadName: e.DownloadName,
+			AppName:      e.AppName,
+			Downloader:   e.Downloader,
+			Details:      e.Details,
+		})
+	}
+	return result
 }
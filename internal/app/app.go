@@ -3,8 +3,13 @@ package app
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"sync"
 
 	"github.com/rs/zerolog"
+
+	"github.com/seedreap/seedreap/internal/config"
 )
 
 // configurable is implemented by all apps to support shared options.
@@ -12,8 +17,20 @@ type configurable interface {
 	setLogger(zerolog.Logger)
 	setCleanupOnCategoryChange(bool)
 	setCleanupOnRemove(bool)
+	setPostImport(config.PostImportConfig)
+	setTags([]string, TagMatch)
 }
 
+// TagMatch controls how an app's tags are matched against a download's tags.
+type TagMatch string
+
+const (
+	// TagMatchAny matches downloads that have at least one of the app's tags.
+	TagMatchAny TagMatch = "any"
+	// TagMatchAll matches downloads that have all of the app's tags.
+	TagMatchAll TagMatch = "all"
+)
+
 // Option is a functional option for configuring apps.
 type Option func(configurable)
 
@@ -38,6 +55,24 @@ func WithCleanupOnRemove(cleanup bool) Option {
 	}
 }
 
+// WithTags sets the download tags this app handles and how they are matched.
+// An empty match mode defaults to TagMatchAny.
+func WithTags(tags []string, match TagMatch) Option {
+	return func(c configurable) {
+		if match == "" {
+			match = TagMatchAny
+		}
+		c.setTags(tags, match)
+	}
+}
+
+// WithPostImport sets the actions applied in the downloader after a successful import.
+func WithPostImport(postImport config.PostImportConfig) Option {
+	return func(c configurable) {
+		c.setPostImport(postImport)
+	}
+}
+
 // App is the interface that applications (Sonarr, Radarr, passthrough, etc.) must implement.
 type App interface {
 	// Name returns the configured name of this app instance.
@@ -47,8 +82,15 @@ type App interface {
 	Type() string
 
 	// Category returns the download category this app handles.
+	// An empty category matches downloads in any category.
 	Category() string
 
+	// Tags returns the download tags this app handles, if any.
+	Tags() []string
+
+	// TagMatch returns whether any or all of Tags must be present on a download.
+	TagMatch() TagMatch
+
 	// DownloadsPath returns the path where completed downloads should be placed.
 	DownloadsPath() string
 
@@ -60,6 +102,10 @@ type App interface {
 	// is removed from the downloader.
 	CleanupOnRemove() bool
 
+	// PostImport returns the actions to apply to the download in its downloader
+	// after a successful import.
+	PostImport() config.PostImportConfig
+
 	// TriggerImport tells the app to scan for and import completed downloads.
 	// If path is specified, it scans only that path.
 	TriggerImport(ctx context.Context, path string) error
@@ -68,11 +114,45 @@ type App interface {
 	TestConnection(ctx context.Context) error
 }
 
-// Registry holds all configured apps.
+// Matches reports whether an app handles a download with the given category and tags.
+// Apps with a category only match downloads in that category; apps with tags only match
+// downloads carrying any (or all, per TagMatch) of those tags. Both must hold if both are set.
+func Matches(a App, category string, tags []string) bool {
+	if a.Category() != "" && a.Category() != category {
+		return false
+	}
+
+	appTags := a.Tags()
+	if len(appTags) == 0 {
+		return true
+	}
+
+	if a.TagMatch() == TagMatchAll {
+		for _, tag := range appTags {
+			if !slices.Contains(tags, tag) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, tag := range appTags {
+		if slices.Contains(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry holds all configured apps. It is safe for concurrent use, so apps can
+// be added, replaced and removed while the orchestrator is running.
 type Registry struct {
+	mu           sync.RWMutex
+	ordered      []App
 	apps         map[string]App
 	byCategory   map[string][]App
 	byDownloader map[string][]App
+	bound        map[string]bool // registry names of apps bound to specific downloaders
 }
 
 // NewRegistry creates a new app registry.
@@ -81,43 +161,176 @@ func NewRegistry() *Registry {
 		apps:         make(map[string]App),
 		byCategory:   make(map[string][]App),
 		byDownloader: make(map[string][]App),
+		bound:        make(map[string]bool),
 	}
 }
 
-// Register adds an app to the registry.
+// Register adds an app to the registry. An app registered under an existing name
+// replaces it in place, keeping its position in registration order but dropping its
+// downloader associations.
 func (r *Registry) Register(name string, a App) {
+	r.RegisterWithDownloaders(name, a, nil)
+}
+
+// RegisterWithDownloaders adds an app to the registry like Register and
+// associates it with the given downloaders, as RegisterForDownloader does. The
+// app and its associations are added at once, so a concurrent lookup never
+// sees the app serving every downloader in between.
+func (r *Registry) RegisterWithDownloaders(name string, a App, downloaders []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if old, ok := r.apps[name]; ok {
+		r.unindex(name, old)
+		r.ordered[slices.Index(r.ordered, old)] = a
+	} else {
+		r.ordered = append(r.ordered, a)
+	}
+
 	r.apps[name] = a
 	r.byCategory[a.Category()] = append(r.byCategory[a.Category()], a)
+
+	for _, downloaderName := range downloaders {
+		r.byDownloader[downloaderName] = append(r.byDownloader[downloaderName], a)
+		r.bound[name] = true
+	}
 }
 
-// RegisterForDownloader associates an app with a downloader.
+// Unregister removes an app and its downloader associations from the registry and returns it.
+func (r *Registry) Unregister(name string) (App, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.apps[name]
+	if !ok {
+		return nil, false
+	}
+
+	r.unindex(name, a)
+	r.ordered = slices.DeleteFunc(r.ordered, func(other App) bool { return other == a })
+	delete(r.apps, name)
+
+	return a, true
+}
+
+// unindex removes an app from the category and downloader indexes. r.mu must be held.
+func (r *Registry) unindex(name string, a App) {
+	isApp := func(other App) bool { return other == a }
+
+	r.byCategory[a.Category()] = slices.DeleteFunc(r.byCategory[a.Category()], isApp)
+	if len(r.byCategory[a.Category()]) == 0 {
+		delete(r.byCategory, a.Category())
+	}
+
+	for dl, apps := range r.byDownloader {
+		r.byDownloader[dl] = slices.DeleteFunc(apps, isApp)
+		if len(r.byDownloader[dl]) == 0 {
+			delete(r.byDownloader, dl)
+		}
+	}
+	delete(r.bound, name)
+}
+
+// RegisterForDownloader associates a registered app with a downloader. Once an app has been
+// associated with any downloader, it only handles downloads from those downloaders;
+// apps without associations handle downloads from every downloader.
 func (r *Registry) RegisterForDownloader(downloaderName string, a App) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.byDownloader[downloaderName] = append(r.byDownloader[downloaderName], a)
+	if name, ok := r.nameOf(a); ok {
+		r.bound[name] = true
+	}
+}
+
+// nameOf returns the name an app is registered under, which can differ from
+// its own name. r.mu must be held.
+func (r *Registry) nameOf(a App) (string, bool) {
+	for name, other := range r.apps {
+		if other == a {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Serves reports whether an app handles downloads from the given downloader.
+func (r *Registry) Serves(a App, downloaderName string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.serves(a, downloaderName)
+}
+
+// serves implements Serves. r.mu must be held.
+func (r *Registry) serves(a App, downloaderName string) bool {
+	if name, ok := r.nameOf(a); !ok || !r.bound[name] {
+		return true
+	}
+	return slices.Contains(r.byDownloader[downloaderName], a)
+}
+
+// GetForDownloader returns all apps that handle downloads from the given downloader,
+// in registration order.
+func (r *Registry) GetForDownloader(downloaderName string) []App {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []App
+	for _, a := range r.ordered {
+		if r.serves(a, downloaderName) {
+			result = append(result, a)
+		}
+	}
+	return result
 }
 
 // Get returns an app by name.
 func (r *Registry) Get(name string) (App, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	a, ok := r.apps[name]
 	return a, ok
 }
 
 // GetByCategory returns all apps that handle the given category.
 func (r *Registry) GetByCategory(category string) []App {
-	return r.byCategory[category]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.byCategory[category])
+}
+
+// GetForDownload returns all apps that handle a download from the given downloader
+// with the given category and tags, in registration order.
+func (r *Registry) GetForDownload(downloaderName, category string, tags []string) []App {
+	var result []App
+	for _, a := range r.GetForDownloader(downloaderName) {
+		if Matches(a, category, tags) {
+			result = append(result, a)
+		}
+	}
+	return result
 }
 
 // GetByDownloader returns all apps associated with a downloader.
 func (r *Registry) GetByDownloader(downloaderName string) []App {
-	return r.byDownloader[downloaderName]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.byDownloader[downloaderName])
 }
 
-// All returns all registered apps.
+// All returns a snapshot of all registered apps.
 func (r *Registry) All() map[string]App {
-	return r.apps
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.apps)
 }
 
 // Categories returns all unique categories.
 func (r *Registry) Categories() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	cats := make([]string, 0, len(r.byCategory))
 	for cat := range r.byCategory {
 		cats = append(cats, cat)
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/seedreap/seedreap/internal/app"
+	"github.com/seedreap/seedreap/internal/config"
 )
 
 // --- Registry Tests ---
@@ -108,6 +109,110 @@ func TestRegistry(t *testing.T) {
 		assert.Empty(t, byDownloader)
 	})
 
+	t.Run("RegisterForDownloader_RestrictsApp", func(t *testing.T) {
+		r := app.NewRegistry()
+
+		sonarrA := app.NewPassthrough("sonarr-a", "tv", "/downloads/a")
+		sonarrB := app.NewPassthrough("sonarr-b", "tv", "/downloads/b")
+		misc := app.NewPassthrough("misc", "misc", "/downloads/misc")
+		r.Register("sonarr-a", sonarrA)
+		r.Register("sonarr-b", sonarrB)
+		r.Register("misc", misc)
+		r.RegisterForDownloader("seedbox-a", sonarrA)
+		r.RegisterForDownloader("seedbox-b", sonarrB)
+
+		// Unbound apps serve every downloader
+		assert.Equal(t, []app.App{sonarrA, misc}, r.GetForDownloader("seedbox-a"))
+		assert.Equal(t, []app.App{misc}, r.GetForDownloader("seedbox-c"))
+
+		// Same category routes to different apps per downloader
+		assert.Equal(t, []app.App{sonarrA}, r.GetForDownload("seedbox-a", "tv", nil))
+		assert.Equal(t, []app.App{sonarrB}, r.GetForDownload("seedbox-b", "tv", nil))
+		assert.Empty(t, r.GetForDownload("seedbox-c", "tv", nil))
+	})
+
+	t.Run("RegisterWithDownloaders", func(t *testing.T) {
+		r := app.NewRegistry()
+
+		sonarr := app.NewPassthrough("sonarr", "tv", "/downloads/tv")
+		r.RegisterWithDownloaders("sonarr", sonarr, []string{"seedbox-a", "seedbox-b"})
+
+		assert.Equal(t, []app.App{sonarr}, r.GetForDownload("seedbox-a", "tv", nil))
+		assert.Equal(t, []app.App{sonarr}, r.GetForDownload("seedbox-b", "tv", nil))
+		assert.Empty(t, r.GetForDownload("seedbox-c", "tv", nil))
+
+		// Without downloaders it serves every downloader
+		misc := app.NewPassthrough("misc", "misc", "/downloads/misc")
+		r.RegisterWithDownloaders("misc", misc, nil)
+		assert.Equal(t, []app.App{misc}, r.GetForDownload("seedbox-c", "misc", nil))
+	})
+
+	t.Run("RegisterReplacesInPlace", func(t *testing.T) {
+		r := app.NewRegistry()
+
+		sonarr := app.NewPassthrough("sonarr", "tv", "/downloads/tv")
+		misc := app.NewPassthrough("misc", "misc", "/downloads/misc")
+		r.Register("sonarr", sonarr)
+		r.Register("misc", misc)
+		r.RegisterForDownloader("seedbox-a", sonarr)
+
+		replacement := app.NewPassthrough("sonarr", "tv-new", "/downloads/tv")
+		r.Register("sonarr", replacement)
+
+		got, _ := r.Get("sonarr")
+		assert.Equal(t, replacement, got)
+		assert.Empty(t, r.GetByCategory("tv"))
+		assert.Equal(t, []app.App{replacement}, r.GetByCategory("tv-new"))
+
+		// Keeps its position but drops its downloader bindings
+		assert.Equal(t, []app.App{replacement, misc}, r.GetForDownloader("seedbox-b"))
+		assert.Empty(t, r.GetByDownloader("seedbox-a"))
+	})
+
+	t.Run("RegisterReplacesAppWithOtherName", func(t *testing.T) {
+		r := app.NewRegistry()
+
+		// The registry name differs from the app's own name
+		sonarr := app.NewPassthrough("sonarr", "tv", "/downloads/tv")
+		r.RegisterWithDownloaders("sonarr-4k", sonarr, []string{"seedbox-a"})
+		assert.Empty(t, r.GetForDownloader("seedbox-b"))
+
+		replacement := app.NewPassthrough("sonarr", "tv", "/downloads/tv")
+		r.Register("sonarr-4k", replacement)
+		assert.Equal(t, []app.App{replacement}, r.GetForDownloader("seedbox-b"))
+
+		r.RegisterForDownloader("seedbox-a", replacement)
+		assert.Empty(t, r.GetForDownloader("seedbox-b"))
+
+		r.Unregister("sonarr-4k")
+		r.Register("sonarr-4k", sonarr)
+		assert.Equal(t, []app.App{sonarr}, r.GetForDownloader("seedbox-b"))
+	})
+
+	t.Run("Unregister", func(t *testing.T) {
+		r := app.NewRegistry()
+
+		sonarr := app.NewPassthrough("sonarr", "tv", "/downloads/tv")
+		misc := app.NewPassthrough("misc", "misc", "/downloads/misc")
+		r.Register("sonarr", sonarr)
+		r.Register("misc", misc)
+		r.RegisterForDownloader("seedbox", sonarr)
+
+		got, ok := r.Unregister("sonarr")
+		require.True(t, ok)
+		assert.Equal(t, sonarr, got)
+
+		_, ok = r.Get("sonarr")
+		assert.False(t, ok)
+		assert.Empty(t, r.GetByCategory("tv"))
+		assert.Empty(t, r.GetByDownloader("seedbox"))
+		assert.Equal(t, []string{"misc"}, r.Categories())
+		assert.Equal(t, []app.App{misc}, r.GetForDownloader("seedbox"))
+
+		_, ok = r.Unregister("sonarr")
+		assert.False(t, ok)
+	})
+
 	t.Run("Categories", func(t *testing.T) {
 		r := app.NewRegistry()
 
@@ -119,6 +224,49 @@ func TestRegistry(t *testing.T) {
 		assert.Contains(t, cats, "tv")
 		assert.Contains(t, cats, "movies")
 	})
+
+	t.Run("GetForDownload", func(t *testing.T) {
+		r := app.NewRegistry()
+
+		tv := app.NewPassthrough("tv", "tv", "/downloads/tv")
+		tagged := app.NewPassthrough("tagged", "", "/downloads/tagged", app.WithTags([]string{"seedreap"}, ""))
+		r.Register("tv", tv)
+		r.Register("tagged", tagged)
+
+		assert.Equal(t, []app.App{tv}, r.GetForDownload("seedbox", "tv", nil))
+		assert.Equal(t, []app.App{tv, tagged}, r.GetForDownload("seedbox", "tv", []string{"seedreap"}))
+		assert.Equal(t, []app.App{tagged}, r.GetForDownload("seedbox", "", []string{"seedreap"}))
+		assert.Empty(t, r.GetForDownload("seedbox", "movies", []string{"other"}))
+	})
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		tags     []string
+		match    app.TagMatch
+		dlCat    string
+		dlTags   []string
+		expected bool
+	}{
+		{"CategoryOnly_Match", "tv", nil, "", "tv", nil, true},
+		{"CategoryOnly_NoMatch", "tv", nil, "", "movies", nil, false},
+		{"TagsAny_Match", "", []string{"a", "b"}, app.TagMatchAny, "whatever", []string{"b"}, true},
+		{"TagsAny_NoMatch", "", []string{"a", "b"}, app.TagMatchAny, "", []string{"c"}, false},
+		{"TagsAll_Match", "", []string{"a", "b"}, app.TagMatchAll, "", []string{"b", "c", "a"}, true},
+		{"TagsAll_Partial", "", []string{"a", "b"}, app.TagMatchAll, "", []string{"a"}, false},
+		{"CategoryAndTags_Match", "tv", []string{"a"}, app.TagMatchAny, "tv", []string{"a"}, true},
+		{"CategoryAndTags_WrongCategory", "tv", []string{"a"}, app.TagMatchAny, "movies", []string{"a"}, false},
+		{"CategoryAndTags_MissingTag", "tv", []string{"a"}, app.TagMatchAny, "tv", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := app.NewPassthrough("test", tt.category, "/downloads", app.WithTags(tt.tags, tt.match))
+			assert.Equal(t, tt.expected, app.Matches(a, tt.dlCat, tt.dlTags))
+		})
+	}
 }
 
 // --- Passthrough Tests ---
@@ -236,6 +384,36 @@ func TestSonarr(t *testing.T) {
 		assert.Equal(t, "/downloads/tv/Show.S01E01", receivedRequest["path"])
 	})
 
+	t.Run("TriggerImport_AppliesImportPathMappings", func(t *testing.T) {
+		var receivedRequest map[string]any
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&receivedRequest)
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		cfg := app.ArrConfig{
+			URL:      server.URL,
+			APIKey:   "test-api-key",
+			Category: "tv",
+			MapImportPath: config.PathMappings{
+				{From: "/downloads", To: "/data/downloads"},
+				{From: "/downloads/seedbox/tv", To: "/tv-incoming"},
+			}.Map,
+		}
+
+		s := app.NewSonarr("sonarr", cfg)
+
+		err := s.TriggerImport(context.Background(), "/downloads/seedbox/tv/Show.S01E01")
+		require.NoError(t, err)
+		assert.Equal(t, "/tv-incoming/Show.S01E01", receivedRequest["path"])
+
+		err = s.TriggerImport(context.Background(), "/downloads/other/Show.S01E02")
+		require.NoError(t, err)
+		assert.Equal(t, "/data/downloads/other/Show.S01E02", receivedRequest["path"])
+	})
+
 	t.Run("TriggerImport_NoPath", func(t *testing.T) {
 		var receivedRequest map[string]any
 
@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+
+	"github.com/seedreap/seedreap/internal/config"
 )
 
 // arrClient implements the App interface for *arr applications (Sonarr, Radarr, etc).
@@ -23,8 +25,12 @@ type arrClient struct {
 	apiKey                  string
 	category                string
 	downloadsPath           string
+	mapImportPath           func(string) string
 	cleanupOnCategoryChange bool
 	cleanupOnRemove         bool
+	postImport              config.PostImportConfig
+	tags                    []string
+	tagMatch                TagMatch
 	httpClient              *http.Client
 	logger                  zerolog.Logger
 }
@@ -48,6 +54,10 @@ type ArrConfig struct {
 	Category      string
 	DownloadsPath string
 	HTTPTimeout   time.Duration
+
+	// MapImportPath, if set, translates a local path into the path the app
+	// sees, like the import path mappings of the app's configuration.
+	MapImportPath func(string) string
 }
 
 // setLogger implements configurable for shared options.
@@ -65,6 +75,17 @@ func (c *arrClient) setCleanupOnRemove(cleanup bool) {
 	c.cleanupOnRemove = cleanup
 }
 
+// setTags implements configurable for shared options.
+func (c *arrClient) setTags(tags []string, match TagMatch) {
+	c.tags = tags
+	c.tagMatch = match
+}
+
+// setPostImport implements configurable for shared options.
+func (c *arrClient) setPostImport(postImport config.PostImportConfig) {
+	c.postImport = postImport
+}
+
 // newArrClient creates a new *arr client.
 func newArrClient(name, appType, scanCommand string, cfg ArrConfig, opts ...Option) App {
 	c := &arrClient{
@@ -75,6 +96,7 @@ func newArrClient(name, appType, scanCommand string, cfg ArrConfig, opts ...Opti
 		apiKey:        cfg.APIKey,
 		category:      cfg.Category,
 		downloadsPath: cfg.DownloadsPath,
+		mapImportPath: cfg.MapImportPath,
 		httpClient: &http.Client{
 			Timeout: cfg.HTTPTimeout,
 		},
@@ -113,6 +135,16 @@ func (c *arrClient) Category() string {
 	return c.category
 }
 
+// Tags returns the download tags this app handles.
+func (c *arrClient) Tags() []string {
+	return c.tags
+}
+
+// TagMatch returns whether any or all of Tags must be present on a download.
+func (c *arrClient) TagMatch() TagMatch {
+	return c.tagMatch
+}
+
 // DownloadsPath returns the path where completed downloads should be placed.
 func (c *arrClient) DownloadsPath() string {
 	return c.downloadsPath
@@ -130,13 +162,20 @@ func (c *arrClient) CleanupOnRemove() bool {
 	return c.cleanupOnRemove
 }
 
+// PostImport returns the actions to apply in the downloader after a successful import.
+func (c *arrClient) PostImport() config.PostImportConfig {
+	return c.postImport
+}
+
 // TriggerImport tells the *arr app to scan for and import completed downloads.
+// The path is translated with MapImportPath before it is sent.
 func (c *arrClient) TriggerImport(ctx context.Context, path string) error {
 	cmd := arrCommandRequest{
 		Name: c.scanCommand,
+		Path: path,
 	}
-	if path != "" {
-		cmd.Path = path
+	if path != "" && c.mapImportPath != nil {
+		cmd.Path = c.mapImportPath(path)
 	}
 
 	body, err := json.Marshal(cmd)
@@ -166,6 +205,7 @@ func (c *arrClient) TriggerImport(ctx context.Context, path string) error {
 	c.logger.Info().
 		Str("name", c.name).
 		Str("path", path).
+		Str("app_path", cmd.Path).
 		Msgf("triggered %s import scan", c.appType)
 
 	return nil
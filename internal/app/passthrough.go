@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/rs/zerolog"
+
+	"github.com/seedreap/seedreap/internal/config"
 )
 
 // passthroughClient implements the App interface for passthrough downloads.
@@ -15,6 +17,9 @@ type passthroughClient struct {
 	downloadsPath           string
 	cleanupOnCategoryChange bool
 	cleanupOnRemove         bool
+	postImport              config.PostImportConfig
+	tags                    []string
+	tagMatch                TagMatch
 	logger                  zerolog.Logger
 }
 
@@ -33,6 +38,17 @@ func (c *passthroughClient) setCleanupOnRemove(cleanup bool) {
 	c.cleanupOnRemove = cleanup
 }
 
+// setTags implements configurable for shared options.
+func (c *passthroughClient) setTags(tags []string, match TagMatch) {
+	c.tags = tags
+	c.tagMatch = match
+}
+
+// setPostImport implements configurable for shared options.
+func (c *passthroughClient) setPostImport(postImport config.PostImportConfig) {
+	c.postImport = postImport
+}
+
 // NewPassthrough creates a new passthrough client and returns it as App.
 func NewPassthrough(name, category, downloadsPath string, opts ...Option) App {
 	c := &passthroughClient{
@@ -64,6 +80,16 @@ func (c *passthroughClient) Category() string {
 	return c.category
 }
 
+// Tags returns the download tags this app handles.
+func (c *passthroughClient) Tags() []string {
+	return c.tags
+}
+
+// TagMatch returns whether any or all of Tags must be present on a download.
+func (c *passthroughClient) TagMatch() TagMatch {
+	return c.tagMatch
+}
+
 // DownloadsPath returns the path where completed downloads should be placed.
 func (c *passthroughClient) DownloadsPath() string {
 	return c.downloadsPath
@@ -81,6 +107,11 @@ func (c *passthroughClient) CleanupOnRemove() bool {
 	return c.cleanupOnRemove
 }
 
+// PostImport returns the actions to apply in the downloader after a successful import.
+func (c *passthroughClient) PostImport() config.PostImportConfig {
+	return c.postImport
+}
+
 // TriggerImport is a no-op for passthrough. Files are synced but no import is triggered.
 func (c *passthroughClient) TriggerImport(_ context.Context, path string) error {
 	c.logger.Debug().
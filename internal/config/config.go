@@ -4,8 +4,13 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"net"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,19 +23,34 @@ const (
 	DefaultSSHTimeout    = 10 * time.Second
 	DefaultSSHPort       = 22
 	DefaultMaxConcurrent = 2
+	DefaultTagMatch      = "any"
+	DefaultStallTimeout  = 5 * time.Minute
+	DefaultStallRetries  = 3
+
+	DefaultTimelineMaxEvents = 10000
 )
 
+// maxPort is the highest TCP port number.
+const maxPort = 65535
+
 // Config is the application configuration.
 type Config struct {
 	Server      ServerConfig                `mapstructure:"server"`
 	Downloaders map[string]DownloaderConfig `mapstructure:"downloaders"`
 	Apps        map[string]AppEntryConfig   `mapstructure:"apps"`
 	Sync        SyncConfig                  `mapstructure:"sync"`
+	Retention   RetentionConfig             `mapstructure:"retention"`
+	Timeline    TimelineConfig              `mapstructure:"timeline"`
+
+	Notifications map[string]NotificationConfig `mapstructure:"notifications"`
 }
 
 // ServerConfig holds HTTP server configuration.
 type ServerConfig struct {
 	Listen string `mapstructure:"listen"`
+	APIKey string `mapstructure:"apiKey"` // Required in the X-Api-Key header of API requests that change state
+
+	APIKeyFile string `mapstructure:"apiKeyFile"` // File holding APIKey, e.g. a mounted Docker or Kubernetes secret
 }
 
 // SyncConfig holds sync-related configuration.
@@ -41,7 +61,28 @@ type SyncConfig struct {
 	PollInterval        time.Duration `mapstructure:"pollInterval"`
 	TransferSpeedMax    int64         `mapstructure:"transferSpeedMax"`    // bytes/sec per file, 0 = unlimited (total max = this * maxConcurrent)
 	ParallelConnections int           `mapstructure:"parallelConnections"` // parallel connections per file (default 8)
-	TransferBackend     string        `mapstructure:"transferBackend"`     // transfer backend: "rclone" (default)
+	TransferBackend     string        `mapstructure:"transferBackend"`     // transfer backend: "rclone" (default) or "sftp"
+	DryRun              bool          `mapstructure:"dryRun"`              // plan syncs without transferring, moving, importing or deleting
+	MinFreeSpace        int64         `mapstructure:"minFreeSpace"`        // bytes to keep free on the syncing and final paths
+	StallTimeout        time.Duration `mapstructure:"stallTimeout"`        // cancel and retry transfers without progress for this long, 0 = never
+	MinSpeed            int64         `mapstructure:"minSpeed"`            // bytes/sec a transfer must average over stallTimeout, 0 = any progress
+	StallRetries        int           `mapstructure:"stallRetries"`        // retries of a stalled transfer before its files fail (default 3)
+}
+
+// RetentionConfig controls how long finished downloads are kept before they are
+// removed from tracking. Negative durations keep items until restart.
+type RetentionConfig struct {
+	Completed     time.Duration `mapstructure:"completed"`     // Keep completed downloads (default 24h)
+	Errored       time.Duration `mapstructure:"errored"`       // Keep errored downloads (default 72h)
+	Cancelled     time.Duration `mapstructure:"cancelled"`     // Keep cancelled sync jobs (default 1h)
+	SweepInterval time.Duration `mapstructure:"sweepInterval"` // How often to sweep, including orphaned staging dirs (default 5m)
+}
+
+// TimelineConfig controls how timeline events are stored and retained.
+type TimelineConfig struct {
+	Path      string        `mapstructure:"path"`      // JSON Lines file to persist events to; empty keeps them in memory only
+	MaxEvents int           `mapstructure:"maxEvents"` // Maximum number of events to keep (default 10000)
+	MaxAge    time.Duration `mapstructure:"maxAge"`    // Drop events older than this, 0 = no age limit (default 0)
 }
 
 // DownloaderConfig holds configuration for a downloader instance.
@@ -52,6 +93,30 @@ type DownloaderConfig struct {
 	Password    string        `mapstructure:"password"`
 	HTTPTimeout time.Duration `mapstructure:"httpTimeout"`
 	SSH         SSHConfig     `mapstructure:"ssh"`
+
+	PasswordFile string `mapstructure:"passwordFile"` // File holding Password, e.g. a mounted secret
+
+	// Transfer picks how files of this downloader are transferred, instead of the
+	// shared sync.transferBackend set up with the first downloader's SSH settings.
+	Transfer TransferConfig `mapstructure:"transfer"`
+
+	// PathMappings rewrite paths reported by the download client into paths reachable
+	// over SSH, for clients running in a container with a different mount layout.
+	PathMappings PathMappings `mapstructure:"pathMappings"`
+}
+
+// SharedTransferDownloader returns the name of the downloader whose SSH
+// settings the shared sync.transferBackend is set up with: the first, by name,
+// that has them and does not set its own transfer backend. It returns "" if no
+// downloader does.
+func (c Config) SharedTransferDownloader() string {
+	for _, name := range slices.Sorted(maps.Keys(c.Downloaders)) {
+		dlCfg := c.Downloaders[name]
+		if dlCfg.Transfer.Backend == "" && dlCfg.SSH.Host != "" {
+			return name
+		}
+	}
+	return ""
 }
 
 // SSHConfig holds SSH connection configuration.
@@ -63,6 +128,69 @@ type SSHConfig struct {
 	KnownHostsFile string        `mapstructure:"knownHostsFile"` // Path to known_hosts file (mutually exclusive with IgnoreHostKey)
 	IgnoreHostKey  bool          `mapstructure:"ignoreHostKey"`  // Skip host key verification (mutually exclusive with KnownHostsFile)
 	Timeout        time.Duration `mapstructure:"timeout"`
+
+	Password          string `mapstructure:"password"`          // Password for password or keyboard-interactive login
+	UseAgent          bool   `mapstructure:"useAgent"`          // Use the keys of the ssh-agent at SSH_AUTH_SOCK
+	CertFile          string `mapstructure:"certFile"`          // OpenSSH certificate signing KeyFile
+	KeyPassphrase     string `mapstructure:"keyPassphrase"`     // Passphrase of an encrypted KeyFile
+	KeyPassphraseFile string `mapstructure:"keyPassphraseFile"` // File holding the passphrase of KeyFile
+	ProxyJump         string `mapstructure:"proxyJump"`         // Jump hosts as [user@]host[:port], comma separated
+}
+
+// SSHJumpHost is a host that SSH connections are relayed through.
+type SSHJumpHost struct {
+	Host string
+	Port int
+	User string // Empty for the user of the final host
+}
+
+// JumpHosts parses ProxyJump into the jump hosts, in the order they are
+// connected through.
+func (s SSHConfig) JumpHosts() ([]SSHJumpHost, error) {
+	if strings.TrimSpace(s.ProxyJump) == "" {
+		return nil, nil
+	}
+
+	var hosts []SSHJumpHost
+	for hop := range strings.SplitSeq(s.ProxyJump, ",") {
+		hop = strings.TrimPrefix(strings.TrimSpace(hop), "ssh://")
+
+		jump := SSHJumpHost{Port: DefaultSSHPort}
+		if i := strings.LastIndex(hop, "@"); i >= 0 {
+			jump.User, hop = hop[:i], hop[i+1:]
+		}
+
+		jump.Host = strings.Trim(hop, "[]")
+		if host, port, err := net.SplitHostPort(hop); err == nil {
+			jump.Host = host
+			if jump.Port, err = strconv.Atoi(port); err != nil || jump.Port < 1 || jump.Port > maxPort {
+				return nil, fmt.Errorf("invalid port in jump host %q", hop)
+			}
+		}
+		if jump.Host == "" {
+			return nil, fmt.Errorf("missing host in jump host %q", hop)
+		}
+
+		hosts = append(hosts, jump)
+	}
+	return hosts, nil
+}
+
+// TransferConfig holds the transfer backend of a downloader.
+type TransferConfig struct {
+	Backend    string        `mapstructure:"backend"`    // rclone, sftp, http, ftp, local; empty: sync.transferBackend
+	URL        string        `mapstructure:"url"`        // http, ftp: URL serving remotePath
+	RemotePath string        `mapstructure:"remotePath"` // http, ftp, local: seedbox directory at url or mountPath
+	Username   string        `mapstructure:"username"`   // http, ftp: username to log in with
+	Password   string        `mapstructure:"password"`   // http, ftp: password to log in with
+	Timeout    time.Duration `mapstructure:"timeout"`    // http, ftp: connect and response timeout (default 30s)
+
+	InsecureSkipVerify bool `mapstructure:"insecureSkipVerify"` // ftp: skip verification of the TLS certificate
+	DisableEPSV        bool `mapstructure:"disableEPSV"`        // ftp: use PASV instead of EPSV
+	IgnorePassiveIP    bool `mapstructure:"ignorePassiveIP"`    // ftp: ignore the address in PASV replies
+
+	MountPath  string `mapstructure:"mountPath"`  // local: where remotePath is mounted locally (default: same path)
+	NoHardlink bool   `mapstructure:"noHardlink"` // local: always copy, even on the same filesystem
 }
 
 // AppEntryConfig holds configuration for an application instance.
@@ -70,11 +198,29 @@ type AppEntryConfig struct {
 	Type                    string        `mapstructure:"type"`
 	URL                     string        `mapstructure:"url"`
 	APIKey                  string        `mapstructure:"apiKey"`
+	APIKeyFile              string        `mapstructure:"apiKeyFile"` // File holding APIKey, e.g. a mounted secret
 	Category                string        `mapstructure:"category"`
 	DownloadsPath           string        `mapstructure:"downloadsPath"`           // Override path, defaults to global downloadsPath/<category>
 	HTTPTimeout             time.Duration `mapstructure:"httpTimeout"`             // HTTP client timeout
 	CleanupOnCategoryChange bool          `mapstructure:"cleanupOnCategoryChange"` // Delete synced files when category changes (default: false)
 	CleanupOnRemove         bool          `mapstructure:"cleanupOnRemove"`         // Delete synced files when removed from downloader (default: false)
+
+	// Tags route downloads to this app by tag instead of (or in addition to) category.
+	// TagMatch selects whether "any" (default) or "all" of the tags must be present.
+	Tags     []string `mapstructure:"tags"`
+	TagMatch string   `mapstructure:"tagMatch"`
+
+	// Downloaders restricts this app to downloads from the named downloaders.
+	// If empty, the app handles downloads from every downloader.
+	Downloaders []string `mapstructure:"downloaders"`
+
+	// ImportPathMappings rewrite local paths into the paths the app sees when it is
+	// asked to import, for apps running in a container with a different mount layout.
+	ImportPathMappings PathMappings `mapstructure:"importPathMappings"`
+
+	// PostImport actions are applied to the download in its downloader after a
+	// successful import, e.g. to pause seeding or remove the torrent.
+	PostImport PostImportConfig `mapstructure:"postImport"`
 }
 
 // LoadOptions configures how configuration is loaded.
@@ -89,9 +235,18 @@ type LoadOptions struct {
 // for files named .seedreap.yaml, seedreap.yaml, or config.yaml.
 //
 // Environment variables with prefix SEEDREAP_ override config file values.
+// Values may reference secrets as ${file:/path} or ${env:NAME}, and passwords
+// and API keys can be read from files with the *File variants of their keys.
 // For dynamic maps (downloaders, apps), set SEEDREAP_DOWNLOADERS and SEEDREAP_APPS
 // to comma-separated lists of names to enable env var binding for those entries.
 func Load(opts LoadOptions) (Config, error) {
+	cfg, _, err := LoadWithSource(opts)
+	return cfg, err
+}
+
+// LoadWithSource reads configuration like Load and also returns the path of the
+// config file that was read, or "" if none was found.
+func LoadWithSource(opts LoadOptions) (Config, string, error) {
 	v := viper.NewWithOptions(viper.ExperimentalBindStruct())
 
 	if opts.ConfigFile != "" {
@@ -114,9 +269,12 @@ func Load(opts LoadOptions) (Config, error) {
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
-	// Bind env vars for dynamic map keys if specified
+	// Bind env vars for dynamic map keys if specified. The binders unset the list
+	// env vars; restore them afterwards so the configuration can be loaded again.
+	defer preserveEnv("SEEDREAP_DOWNLOADERS", "SEEDREAP_APPS", "SEEDREAP_NOTIFICATIONS")()
 	bindDownloaderEnvVars(v)
 	bindAppEnvVars(v)
+	bindNotificationEnvVars(v)
 
 	// Set defaults
 	v.SetDefault("server.listen", "[::]:8423")
@@ -124,22 +282,58 @@ func Load(opts LoadOptions) (Config, error) {
 	v.SetDefault("sync.syncingPath", "/downloads/syncing")
 	v.SetDefault("sync.maxConcurrent", DefaultMaxConcurrent)
 	v.SetDefault("sync.pollInterval", "30s")
-
-	// Read config file (ignore error if not found)
-	_ = v.ReadInConfig()
+	v.SetDefault("sync.stallTimeout", DefaultStallTimeout)
+	v.SetDefault("sync.stallRetries", DefaultStallRetries)
+	v.SetDefault("retention.completed", "24h")
+	v.SetDefault("retention.errored", "72h")
+	v.SetDefault("retention.cancelled", "1h")
+	v.SetDefault("retention.sweepInterval", "5m")
+	v.SetDefault("timeline.maxEvents", DefaultTimelineMaxEvents)
+
+	// Read config file (ignore error if not found when searching default locations)
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return Config{}, "", fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
 
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
-		return Config{}, err
+		return Config{}, "", err
+	}
+
+	if err := expandSecrets(&cfg); err != nil {
+		return Config{}, "", err
+	}
+	if err := readSecretFiles(&cfg); err != nil {
+		return Config{}, "", err
 	}
 
 	setDefaultsOnListConfigs(&cfg)
 
 	if err := validate(&cfg); err != nil {
-		return Config{}, err
+		return Config{}, "", err
 	}
 
-	return cfg, nil
+	return cfg, v.ConfigFileUsed(), nil
+}
+
+// preserveEnv returns a function that restores the given environment variables
+// to their current values.
+func preserveEnv(keys ...string) func() {
+	saved := make(map[string]string)
+	for _, key := range keys {
+		if value, ok := os.LookupEnv(key); ok {
+			saved[key] = value
+		}
+	}
+
+	return func() {
+		for key, value := range saved {
+			_ = os.Setenv(key, value)
+		}
+	}
 }
 
 // setDefaultsOnListConfigs applies default values to config fields that can't
@@ -164,8 +358,17 @@ func setDefaultsOnListConfigs(cfg *Config) {
 		if app.HTTPTimeout == 0 {
 			app.HTTPTimeout = DefaultHTTPTimeout
 		}
+		if app.TagMatch == "" {
+			app.TagMatch = DefaultTagMatch
+		}
 		cfg.Apps[name] = app
 	}
+
+	// Set defaults for notifications
+	for name, n := range cfg.Notifications {
+		n.setDefaults()
+		cfg.Notifications[name] = n
+	}
 }
 
 // Valid downloader types.
@@ -184,12 +387,126 @@ var validAppTypes = map[string]bool{
 	"passthrough": true,
 }
 
+// Valid app tag match modes.
+//
+//nolint:gochecknoglobals // validation lookup table
+var validTagMatches = map[string]bool{
+	"any": true,
+	"all": true,
+}
+
 // Valid transfer backends.
 //
 //nolint:gochecknoglobals // validation lookup table
 var validTransferBackends = map[string]bool{
 	"":       true, // empty means default (rclone)
 	"rclone": true,
+	"sftp":   true,
+}
+
+// validDownloaderTransferBackends are the backends a downloader can pick. The
+// http and ftp backends need a URL and the local backend a mount, so they
+// cannot be the shared backend.
+//
+//nolint:gochecknoglobals // lookup table for validation
+var validDownloaderTransferBackends = map[string]bool{
+	"":       true, // empty means sync.transferBackend
+	"rclone": true,
+	"sftp":   true,
+	"http":   true,
+	"ftp":    true,
+	"local":  true,
+}
+
+// validFTPSchemes are the URL schemes of the ftp backend: plain FTP, explicit
+// TLS and implicit TLS.
+//
+//nolint:gochecknoglobals // lookup table for validation
+var validFTPSchemes = map[string]bool{
+	"ftp":   true,
+	"ftpes": true,
+	"ftps":  true,
+}
+
+// validateTransfer checks the settings of the transfer backend of a downloader:
+// the http and ftp backends need a URL, the local backend an absolute mount
+// path if set, and the others SSH settings.
+func validateTransfer(dl DownloaderConfig) []error {
+	var errs []error
+
+	if !validDownloaderTransferBackends[dl.Transfer.Backend] {
+		return []error{fmt.Errorf("transfer.backend: unknown backend %q", dl.Transfer.Backend)}
+	}
+
+	if dl.Transfer.Backend == "http" {
+		if dl.Transfer.URL == "" {
+			errs = append(errs, errors.New("transfer.url is required for the http backend"))
+		} else if u, err := url.Parse(dl.Transfer.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, errors.New("transfer.url must be an http or https URL"))
+		}
+		return errs
+	}
+
+	if dl.Transfer.Backend == "ftp" {
+		if dl.Transfer.URL == "" {
+			errs = append(errs, errors.New("transfer.url is required for the ftp backend"))
+		} else if u, err := url.Parse(dl.Transfer.URL); err != nil || !validFTPSchemes[u.Scheme] {
+			errs = append(errs, errors.New("transfer.url must be an ftp, ftpes or ftps URL"))
+		}
+		return errs
+	}
+
+	if dl.Transfer.Backend == "local" {
+		if dl.Transfer.MountPath != "" && !filepath.IsAbs(dl.Transfer.MountPath) {
+			errs = append(errs, errors.New("transfer.mountPath must be an absolute path"))
+		}
+		return errs
+	}
+
+	// SSH config is required for file transfers
+	if dl.SSH.Host == "" {
+		errs = append(errs, errors.New("ssh.host is required"))
+	}
+	if dl.SSH.User == "" {
+		errs = append(errs, errors.New("ssh.user is required"))
+	}
+	errs = append(errs, validateSSHAuth(dl.SSH)...)
+
+	// Host key verification: must specify knownHostsFile OR ignoreHostKey, but not both
+	if dl.SSH.KnownHostsFile != "" && dl.SSH.IgnoreHostKey {
+		errs = append(errs, errors.New("ssh.knownHostsFile and ssh.ignoreHostKey are mutually exclusive"))
+	}
+	if dl.SSH.KnownHostsFile == "" && !dl.SSH.IgnoreHostKey {
+		errs = append(errs, errors.New("ssh.knownHostsFile is required (or set ssh.ignoreHostKey to true)"))
+	}
+
+	return errs
+}
+
+// validateSSHAuth checks the SSH authentication settings: at least one way to
+// log in, key options that need a key file, and the jump hosts.
+func validateSSHAuth(sshCfg SSHConfig) []error {
+	var errs []error
+
+	if sshCfg.KeyFile == "" && sshCfg.Password == "" && !sshCfg.UseAgent {
+		errs = append(errs, errors.New("ssh.keyFile is required (or set ssh.password or ssh.useAgent)"))
+	}
+	if sshCfg.KeyPassphrase != "" && sshCfg.KeyPassphraseFile != "" {
+		errs = append(errs, errors.New("ssh.keyPassphrase and ssh.keyPassphraseFile are mutually exclusive"))
+	}
+	if sshCfg.KeyFile == "" {
+		if sshCfg.CertFile != "" {
+			errs = append(errs, errors.New("ssh.certFile requires ssh.keyFile"))
+		}
+		if sshCfg.KeyPassphrase != "" || sshCfg.KeyPassphraseFile != "" {
+			errs = append(errs, errors.New("ssh.keyPassphrase requires ssh.keyFile"))
+		}
+	}
+	if _, err := sshCfg.JumpHosts(); err != nil {
+		errs = append(errs, fmt.Errorf("ssh.proxyJump: %w", err))
+	}
+
+	return errs
 }
 
 // validate checks that the configuration is valid.
@@ -209,28 +526,15 @@ func validate(cfg *Config) error {
 		if dl.URL == "" {
 			errs = append(errs, fmt.Errorf("downloader %q: url is required", name))
 		} else if _, err := url.Parse(dl.URL); err != nil {
-			errs = append(errs, fmt.Errorf("downloader %q: invalid url: %w", name, err))
+			errs = append(errs, fmt.Errorf("downloader %q: invalid url: %w", name, withoutURL(err)))
 		}
 
-		// SSH config is required for file transfers
-		if dl.SSH.Host == "" {
-			errs = append(errs, fmt.Errorf("downloader %q: ssh.host is required", name))
-		}
-		if dl.SSH.User == "" {
-			errs = append(errs, fmt.Errorf("downloader %q: ssh.user is required", name))
-		}
-		if dl.SSH.KeyFile == "" {
-			errs = append(errs, fmt.Errorf("downloader %q: ssh.keyFile is required", name))
+		for _, err := range validateTransfer(dl) {
+			errs = append(errs, fmt.Errorf("downloader %q: %w", name, err))
 		}
 
-		// Host key verification: must specify knownHostsFile OR ignoreHostKey, but not both
-		if dl.SSH.KnownHostsFile != "" && dl.SSH.IgnoreHostKey {
-			errs = append(errs, fmt.Errorf(
-				"downloader %q: ssh.knownHostsFile and ssh.ignoreHostKey are mutually exclusive", name))
-		}
-		if dl.SSH.KnownHostsFile == "" && !dl.SSH.IgnoreHostKey {
-			errs = append(errs, fmt.Errorf(
-				"downloader %q: ssh.knownHostsFile is required (or set ssh.ignoreHostKey to true)", name))
+		for _, err := range dl.PathMappings.validate("pathMappings", true) {
+			errs = append(errs, fmt.Errorf("downloader %q: %w", name, err))
 		}
 	}
 
@@ -242,8 +546,24 @@ func validate(cfg *Config) error {
 			errs = append(errs, fmt.Errorf("app %q: unknown type %q", name, app.Type))
 		}
 
-		if app.Category == "" {
-			errs = append(errs, fmt.Errorf("app %q: category is required", name))
+		if app.Category == "" && len(app.Tags) == 0 {
+			errs = append(errs, fmt.Errorf("app %q: category is required unless tags are set", name))
+		}
+
+		for i, tag := range app.Tags {
+			if tag == "" {
+				errs = append(errs, fmt.Errorf("app %q: tags[%d] must not be empty", name, i))
+			}
+		}
+
+		for _, dlName := range app.Downloaders {
+			if _, ok := cfg.Downloaders[dlName]; !ok {
+				errs = append(errs, fmt.Errorf("app %q: unknown downloader %q", name, dlName))
+			}
+		}
+
+		if !validTagMatches[app.TagMatch] {
+			errs = append(errs, fmt.Errorf("app %q: unknown tagMatch %q (expected \"any\" or \"all\")", name, app.TagMatch))
 		}
 
 		// URL and API key required for non-passthrough apps
@@ -251,13 +571,27 @@ func validate(cfg *Config) error {
 			if app.URL == "" {
 				errs = append(errs, fmt.Errorf("app %q: url is required", name))
 			} else if _, err := url.Parse(app.URL); err != nil {
-				errs = append(errs, fmt.Errorf("app %q: invalid url: %w", name, err))
+				errs = append(errs, fmt.Errorf("app %q: invalid url: %w", name, withoutURL(err)))
 			}
 
 			if app.APIKey == "" {
 				errs = append(errs, fmt.Errorf("app %q: apiKey is required", name))
 			}
 		}
+
+		for _, err := range app.ImportPathMappings.validate("importPathMappings", false) {
+			errs = append(errs, fmt.Errorf("app %q: %w", name, err))
+		}
+
+		for _, err := range app.PostImport.validate() {
+			errs = append(errs, fmt.Errorf("app %q: %w", name, err))
+		}
+	}
+
+	for name, n := range cfg.Notifications {
+		for _, err := range n.validate() {
+			errs = append(errs, fmt.Errorf("notification %q: %w", name, err))
+		}
 	}
 
 	// Validate sync config
@@ -267,6 +601,31 @@ func validate(cfg *Config) error {
 	if cfg.Sync.SyncingPath == "" {
 		errs = append(errs, errors.New("sync.syncingPath is required"))
 	}
+	if cfg.Retention.SweepInterval <= 0 {
+		errs = append(errs, errors.New("retention.sweepInterval must be positive"))
+	}
+	if cfg.Timeline.MaxEvents <= 0 {
+		errs = append(errs, errors.New("timeline.maxEvents must be positive"))
+	}
+	if cfg.Timeline.MaxAge < 0 {
+		errs = append(errs, errors.New("timeline.maxAge must not be negative"))
+	}
+	if cfg.Sync.MinFreeSpace < 0 {
+		errs = append(errs, errors.New("sync.minFreeSpace must not be negative"))
+	}
+	if cfg.Sync.StallTimeout < 0 {
+		errs = append(errs, errors.New("sync.stallTimeout must not be negative"))
+	}
+	if cfg.Sync.MinSpeed < 0 {
+		errs = append(errs, errors.New("sync.minSpeed must not be negative"))
+	}
+	if cfg.Sync.TransferSpeedMax > 0 && cfg.Sync.MinSpeed > cfg.Sync.TransferSpeedMax {
+		// Every transfer would be retried as too slow
+		errs = append(errs, errors.New("sync.minSpeed must not exceed sync.transferSpeedMax"))
+	}
+	if cfg.Sync.StallRetries < 0 {
+		errs = append(errs, errors.New("sync.stallRetries must not be negative"))
+	}
 	if !validTransferBackends[cfg.Sync.TransferBackend] {
 		errs = append(errs, fmt.Errorf("sync.transferBackend: unknown backend %q", cfg.Sync.TransferBackend))
 	}
@@ -278,9 +637,20 @@ func validate(cfg *Config) error {
 	return nil
 }
 
+// withoutURL drops the URL from a url.Parse error, since it may contain a
+// password.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
 // downloaderEnvFields lists all DownloaderConfig fields for env var binding.
 // This must be kept in sync with DownloaderConfig and SSHConfig structs.
-// Tests verify this list matches the struct fields.
+// Tests verify this list matches the struct fields. pathMappings is left out,
+// since a list of mappings can't be set from a single env var.
 //
 //nolint:gochecknoglobals // env var binding field list
 var downloaderEnvFields = []string{
@@ -288,6 +658,7 @@ var downloaderEnvFields = []string{
 	"url",
 	"username",
 	"password",
+	"passwordFile",
 	"httpTimeout",
 	"ssh.host",
 	"ssh.port",
@@ -296,22 +667,75 @@ var downloaderEnvFields = []string{
 	"ssh.knownHostsFile",
 	"ssh.ignoreHostKey",
 	"ssh.timeout",
+	"ssh.password",
+	"ssh.useAgent",
+	"ssh.certFile",
+	"ssh.keyPassphrase",
+	"ssh.keyPassphraseFile",
+	"ssh.proxyJump",
+	"transfer.backend",
+	"transfer.url",
+	"transfer.remotePath",
+	"transfer.username",
+	"transfer.password",
+	"transfer.timeout",
+	"transfer.insecureSkipVerify",
+	"transfer.disableEPSV",
+	"transfer.ignorePassiveIP",
+	"transfer.mountPath",
+	"transfer.noHardlink",
 }
 
 // appEnvFields lists all AppEntryConfig fields for env var binding.
 // This must be kept in sync with AppEntryConfig struct.
-// Tests verify this list matches the struct fields.
+// Tests verify this list matches the struct fields. importPathMappings is left
+// out, since a list of mappings can't be set from a single env var.
 //
 //nolint:gochecknoglobals // env var binding field list
 var appEnvFields = []string{
 	"type",
 	"url",
 	"apiKey",
+	"apiKeyFile",
 	"category",
 	"downloadsPath",
 	"httpTimeout",
 	"cleanupOnCategoryChange",
 	"cleanupOnRemove",
+	"tags",
+	"tagMatch",
+	"downloaders",
+	"postImport.pause",
+	"postImport.category",
+	"postImport.tags",
+	"postImport.delete",
+	"postImport.ratioLimit",
+	"postImport.seedingTimeLimit",
+}
+
+// notificationEnvFields lists all NotificationConfig fields for env var binding.
+// This must be kept in sync with NotificationConfig and SMTPConfig structs.
+// Tests verify this list matches the struct fields.
+//
+//nolint:gochecknoglobals // env var binding field list
+var notificationEnvFields = []string{
+	"type",
+	"url",
+	"token",
+	"user",
+	"username",
+	"password",
+	"priority",
+	"httpTimeout",
+	"smtp.host",
+	"smtp.port",
+	"smtp.from",
+	"smtp.to",
+	"smtp.tls",
+	"events",
+	"title",
+	"message",
+	"rateLimit",
 }
 
 // bindDownloaderEnvVars reads SEEDREAP_DOWNLOADERS env var to get the list of
@@ -367,3 +791,29 @@ func bindAppEnvVars(v *viper.Viper) {
 		}
 	}
 }
+
+// bindNotificationEnvVars reads SEEDREAP_NOTIFICATIONS env var to get the list of
+// notification names, then binds all notification fields for each name.
+// The list env var is unset after reading to prevent viper from treating it as
+// the "notifications" config key (which would cause a type mismatch).
+func bindNotificationEnvVars(v *viper.Viper) {
+	notificationsEnv := os.Getenv("SEEDREAP_NOTIFICATIONS")
+	if notificationsEnv == "" {
+		return
+	}
+
+	// Unset the list env var so viper doesn't interpret it as notifications=string
+	_ = os.Unsetenv("SEEDREAP_NOTIFICATIONS")
+
+	for name := range strings.SplitSeq(notificationsEnv, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		for _, field := range notificationEnvFields {
+			key := "notifications." + name + "." + field
+			v.MustBindEnv(key)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrRestartRequired is returned by Diff when a configuration change can only
+// take effect after a restart.
+var ErrRestartRequired = errors.New("changes require a restart")
+
+// Changes lists the downloaders and apps that differ between two configurations.
+type Changes struct {
+	AddedDownloaders   []string
+	RemovedDownloaders []string
+	ChangedDownloaders []string
+	AddedApps          []string
+	RemovedApps        []string
+	ChangedApps        []string
+}
+
+// Empty reports whether there are no changes.
+func (c Changes) Empty() bool {
+	return len(c.AddedDownloaders)+len(c.RemovedDownloaders)+len(c.ChangedDownloaders)+
+		len(c.AddedApps)+len(c.RemovedApps)+len(c.ChangedApps) == 0
+}
+
+// Diff compares the running configuration with an updated one. Downloaders and
+// apps can be added, removed and changed while running; any other change returns
+// an error wrapping ErrRestartRequired that names the changed settings.
+func Diff(running, updated Config) (Changes, error) {
+	var restart []string
+	restart = append(restart, changedFields("server", running.Server, updated.Server)...)
+	restart = append(restart, changedFields("sync", running.Sync, updated.Sync)...)
+	restart = append(restart, changedFields("retention", running.Retention, updated.Retention)...)
+	restart = append(restart, changedFields("timeline", running.Timeline, updated.Timeline)...)
+	if !reflect.DeepEqual(running.Notifications, updated.Notifications) {
+		restart = append(restart, "notifications")
+	}
+
+	var c Changes
+	c.AddedDownloaders, c.RemovedDownloaders, c.ChangedDownloaders = diffMap(running.Downloaders, updated.Downloaders)
+	c.AddedApps, c.RemovedApps, c.ChangedApps = diffMap(running.Apps, updated.Apps)
+
+	// Transfer backends are set up at startup, the shared one with a downloader's
+	// SSH settings. A downloader added without its own backend uses the shared
+	// one, which only reaches its files if it connects to the same server.
+	if len(running.Downloaders) == 0 && len(updated.Downloaders) > 0 {
+		restart = append(restart, "downloaders")
+	}
+	shared := running.SharedTransferDownloader()
+	for _, name := range c.AddedDownloaders {
+		dlCfg := updated.Downloaders[name]
+		if dlCfg.Transfer.Backend != "" ||
+			shared == "" || !reflect.DeepEqual(dlCfg.SSH, running.Downloaders[shared].SSH) {
+			restart = append(restart, "downloaders."+name+".transfer")
+		}
+	}
+	if shared != "" && slices.Contains(c.RemovedDownloaders, shared) {
+		// The downloaders left would need the shared backend set up for another server
+		next := updated.SharedTransferDownloader()
+		if next != "" && !reflect.DeepEqual(updated.Downloaders[next].SSH, running.Downloaders[shared].SSH) {
+			restart = append(restart, "downloaders."+shared)
+		}
+	}
+	for _, name := range c.ChangedDownloaders {
+		if !reflect.DeepEqual(running.Downloaders[name].SSH, updated.Downloaders[name].SSH) {
+			restart = append(restart, "downloaders."+name+".ssh")
+		}
+		if !reflect.DeepEqual(running.Downloaders[name].Transfer, updated.Downloaders[name].Transfer) {
+			restart = append(restart, "downloaders."+name+".transfer")
+		}
+	}
+
+	if len(restart) > 0 {
+		return Changes{}, fmt.Errorf("%w: %s", ErrRestartRequired, strings.Join(restart, ", "))
+	}
+
+	return c, nil
+}
+
+// changedFields returns the keys of the fields that differ between two config sections.
+func changedFields(prefix string, running, updated any) []string {
+	rv, uv := reflect.ValueOf(running), reflect.ValueOf(updated)
+
+	var changed []string
+	for i := range rv.NumField() {
+		if !reflect.DeepEqual(rv.Field(i).Interface(), uv.Field(i).Interface()) {
+			changed = append(changed, prefix+"."+rv.Type().Field(i).Tag.Get("mapstructure"))
+		}
+	}
+	return changed
+}
+
+// diffMap returns the sorted keys added to, removed from and changed between two maps.
+func diffMap[V any](running, updated map[string]V) ([]string, []string, []string) {
+	var added, removed, changed []string
+
+	for _, name := range slices.Sorted(maps.Keys(updated)) {
+		old, ok := running[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case !reflect.DeepEqual(old, updated[name]):
+			changed = append(changed, name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(running)) {
+		if _, ok := updated[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	return added, removed, changed
+}
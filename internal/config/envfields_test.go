@@ -3,6 +3,7 @@ package config
 
 import (
 	"reflect"
+	"slices"
 	"sort"
 	"testing"
 
@@ -15,7 +16,7 @@ import (
 // added to the corresponding env fields list.
 func TestEnvFieldsCoverStructFields(t *testing.T) {
 	t.Run("downloaderEnvFields covers DownloaderConfig", func(t *testing.T) {
-		expected := extractMapstructureFields(reflect.TypeFor[DownloaderConfig](), "")
+		expected := withoutFields(extractMapstructureFields(reflect.TypeFor[DownloaderConfig](), ""), "pathMappings")
 		sort.Strings(expected)
 
 		actual := make([]string, len(downloaderEnvFields))
@@ -28,7 +29,7 @@ func TestEnvFieldsCoverStructFields(t *testing.T) {
 	})
 
 	t.Run("appEnvFields covers AppEntryConfig", func(t *testing.T) {
-		expected := extractMapstructureFields(reflect.TypeFor[AppEntryConfig](), "")
+		expected := withoutFields(extractMapstructureFields(reflect.TypeFor[AppEntryConfig](), ""), "importPathMappings")
 		sort.Strings(expected)
 
 		actual := make([]string, len(appEnvFields))
@@ -39,6 +40,20 @@ func TestEnvFieldsCoverStructFields(t *testing.T) {
 			"appEnvFields must contain all fields from AppEntryConfig.\n"+
 				"If you added a new field to AppEntryConfig, add it to appEnvFields in config.go")
 	})
+
+	t.Run("notificationEnvFields covers NotificationConfig", func(t *testing.T) {
+		expected := extractMapstructureFields(reflect.TypeFor[NotificationConfig](), "")
+		sort.Strings(expected)
+
+		actual := make([]string, len(notificationEnvFields))
+		copy(actual, notificationEnvFields)
+		sort.Strings(actual)
+
+		assert.Equal(t, expected, actual,
+			"notificationEnvFields must contain all fields from NotificationConfig and SMTPConfig.\n"+
+				"If you added a new field to NotificationConfig or SMTPConfig, "+
+				"add it to notificationEnvFields in config.go")
+	})
 }
 
 // extractMapstructureFields recursively extracts all mapstructure tag values from a struct type.
@@ -71,3 +86,11 @@ func extractMapstructureFields(t reflect.Type, prefix string) []string {
 
 	return fields
 }
+
+// withoutFields returns fields without the given ones, which are lists of
+// structs that can't be set from an env var.
+func withoutFields(fields []string, excluded ...string) []string {
+	return slices.DeleteFunc(fields, func(field string) bool {
+		return slices.Contains(excluded, field)
+	})
+}
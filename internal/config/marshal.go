@@ -0,0 +1,90 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// yamlIndent matches the indentation of the example configuration.
+const yamlIndent = 2
+
+// Marshal encodes the configuration as YAML using the same keys as the config
+// file, with durations written like "30s". Use Redacted first to hide secrets.
+func Marshal(c Config) ([]byte, error) {
+	node, err := yamlNode(reflect.ValueOf(c))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent)
+	if err = enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err = enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlNode converts a config value into a YAML node, keeping struct fields in
+// declaration order and sorting map keys.
+func yamlNode(v reflect.Value) (*yaml.Node, error) {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: d.String()}, nil
+	}
+
+	switch v.Kind() { //nolint:exhaustive // other kinds are encoded as scalars
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := range v.NumField() {
+			value, err := yamlNode(v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			key := &yaml.Node{Kind: yaml.ScalarNode, Value: v.Type().Field(i).Tag.Get("mapstructure")}
+			node.Content = append(node.Content, key, value)
+		}
+		return node, nil
+
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		for _, key := range keys {
+			value, err := yamlNode(v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key.String()}, value)
+		}
+		return node, nil
+
+	case reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := range v.Len() {
+			value, err := yamlNode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if value.Kind != yaml.ScalarNode {
+				node.Style = 0
+			}
+			node.Content = append(node.Content, value)
+		}
+		return node, nil
+
+	default:
+		node := &yaml.Node{}
+		if err := node.Encode(v.Interface()); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Notification types.
+const (
+	NotificationDiscord  = "discord"
+	NotificationSlack    = "slack"
+	NotificationNtfy     = "ntfy"
+	NotificationGotify   = "gotify"
+	NotificationPushover = "pushover"
+	NotificationEmail    = "email"
+)
+
+// SMTP TLS modes.
+const (
+	// SMTPTLSStartTLS upgrades a plain connection with STARTTLS.
+	SMTPTLSStartTLS = "starttls"
+	// SMTPTLSImplicit connects over TLS from the start (SMTPS).
+	SMTPTLSImplicit = "tls"
+	// SMTPTLSNone sends mail without TLS.
+	SMTPTLSNone = "none"
+)
+
+// Default SMTP ports.
+const (
+	DefaultSMTPPort    = 587
+	DefaultSMTPSPort   = 465
+	DefaultSMTPTLSMode = SMTPTLSStartTLS
+)
+
+// NotificationConfig holds configuration for a notification target.
+type NotificationConfig struct {
+	Type        string        `mapstructure:"type"`        // discord, slack, ntfy, gotify, pushover or email
+	URL         string        `mapstructure:"url"`         // Webhook, topic or server URL
+	Token       string        `mapstructure:"token"`       // ntfy access token, Gotify app token or Pushover API token
+	User        string        `mapstructure:"user"`        // Pushover user or group key
+	Username    string        `mapstructure:"username"`    // ntfy or SMTP username
+	Password    string        `mapstructure:"password"`    // ntfy or SMTP password
+	Priority    string        `mapstructure:"priority"`    // Provider-specific message priority
+	HTTPTimeout time.Duration `mapstructure:"httpTimeout"` // Request timeout, also used for SMTP (default 30s)
+	SMTP        SMTPConfig    `mapstructure:"smtp"`
+
+	// Events lists the timeline event types to notify about; "*" matches all.
+	// If empty, import_complete, import_failed, post_import_failed and error are sent.
+	Events []string `mapstructure:"events"`
+
+	// Title and Message are Go templates rendered with the timeline event.
+	Title   string `mapstructure:"title"`
+	Message string `mapstructure:"message"`
+
+	// RateLimit caps the notifications sent per minute, 0 = unlimited.
+	RateLimit int `mapstructure:"rateLimit"`
+}
+
+// SMTPConfig holds the mail server settings of an email notification.
+type SMTPConfig struct {
+	Host string   `mapstructure:"host"`
+	Port int      `mapstructure:"port"`
+	From string   `mapstructure:"from"`
+	To   []string `mapstructure:"to"`
+	TLS  string   `mapstructure:"tls"` // starttls (default), tls or none
+}
+
+// setDefaults fills in defaults that depend on the notification type.
+func (n *NotificationConfig) setDefaults() {
+	if n.HTTPTimeout == 0 {
+		n.HTTPTimeout = DefaultHTTPTimeout
+	}
+
+	if n.Type != NotificationEmail {
+		return
+	}
+	if n.SMTP.TLS == "" {
+		n.SMTP.TLS = DefaultSMTPTLSMode
+	}
+	if n.SMTP.Port == 0 {
+		n.SMTP.Port = DefaultSMTPPort
+		if n.SMTP.TLS == SMTPTLSImplicit {
+			n.SMTP.Port = DefaultSMTPSPort
+		}
+	}
+}
+
+// validate checks that the fields the notification type needs are set.
+func (n NotificationConfig) validate() []error {
+	var errs []error
+
+	needURL := func() {
+		if n.URL == "" {
+			errs = append(errs, errors.New("url is required"))
+		} else if u, err := url.Parse(n.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("invalid url %q: must be an HTTP(S) URL", n.URL))
+		}
+	}
+
+	switch n.Type {
+	case "":
+		errs = append(errs, errors.New("type is required"))
+	case NotificationDiscord, NotificationSlack, NotificationNtfy:
+		needURL()
+	case NotificationGotify:
+		needURL()
+		if n.Token == "" {
+			errs = append(errs, errors.New("token is required"))
+		}
+	case NotificationPushover:
+		if n.Token == "" {
+			errs = append(errs, errors.New("token is required"))
+		}
+		if n.User == "" {
+			errs = append(errs, errors.New("user is required"))
+		}
+	case NotificationEmail:
+		errs = append(errs, n.SMTP.validate()...)
+	default:
+		errs = append(errs, fmt.Errorf("unknown type %q", n.Type))
+	}
+
+	if n.RateLimit < 0 {
+		errs = append(errs, errors.New("rateLimit must not be negative"))
+	}
+
+	return errs
+}
+
+// validate checks the mail server settings.
+func (s SMTPConfig) validate() []error {
+	var errs []error
+
+	if s.Host == "" {
+		errs = append(errs, errors.New("smtp.host is required"))
+	}
+	if s.From == "" {
+		errs = append(errs, errors.New("smtp.from is required"))
+	}
+	if len(s.To) == 0 {
+		errs = append(errs, errors.New("smtp.to is required"))
+	}
+
+	switch s.TLS {
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		errs = append(errs, fmt.Errorf("smtp.tls: unknown mode %q (expected %q, %q or %q)",
+			s.TLS, SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone))
+	}
+
+	return errs
+}
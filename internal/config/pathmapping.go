@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PathMapping rewrites a path prefix as seen by one system into the same location
+// as seen by another, similar to the Remote Path Mappings in *arr applications.
+type PathMapping struct {
+	From string `mapstructure:"from"`
+	To   string `mapstructure:"to"`
+}
+
+// PathMappings is an ordered list of path mappings.
+type PathMappings []PathMapping
+
+// Map rewrites p using the mapping with the longest matching From prefix.
+// Prefixes only match on whole path components, so "/data" matches "/data/tv"
+// but not "/database". If To uses backslashes (e.g. an *arr instance running on
+// Windows), the remainder of the path is converted to backslashes as well.
+// Paths that match no mapping are returned unchanged.
+func (m PathMappings) Map(p string) string {
+	best := -1
+	bestLen := -1
+
+	for i, pm := range m {
+		from := trimTrailingSeparators(pm.From)
+		if !hasPathPrefix(p, from) {
+			continue
+		}
+		if len(from) > bestLen {
+			best = i
+			bestLen = len(from)
+		}
+	}
+
+	if best < 0 {
+		return p
+	}
+
+	to := trimTrailingSeparators(m[best].To)
+	rest := p[bestLen:]
+	if strings.Contains(to, `\`) && !strings.Contains(to, "/") {
+		rest = strings.ReplaceAll(rest, "/", `\`)
+	}
+
+	if to == "" && rest == "" {
+		return m[best].To
+	}
+	return to + rest
+}
+
+// validate checks that every mapping is usable. When remote is true both sides are
+// POSIX paths on the seedbox; otherwise only From (a local path) must be absolute,
+// since To is interpreted by another application and may use any path style.
+func (m PathMappings) validate(field string, remote bool) []error {
+	var errs []error
+	seen := make(map[string]bool, len(m))
+
+	for i, pm := range m {
+		if pm.From == "" {
+			errs = append(errs, fmt.Errorf("%s[%d].from is required", field, i))
+		} else if !path.IsAbs(pm.From) {
+			errs = append(errs, fmt.Errorf("%s[%d].from must be an absolute path: %q", field, i, pm.From))
+		}
+
+		if pm.To == "" {
+			errs = append(errs, fmt.Errorf("%s[%d].to is required", field, i))
+		} else if remote && !path.IsAbs(pm.To) {
+			errs = append(errs, fmt.Errorf("%s[%d].to must be an absolute path: %q", field, i, pm.To))
+		}
+
+		from := trimTrailingSeparators(pm.From)
+		if pm.From != "" && seen[from] {
+			errs = append(errs, fmt.Errorf("%s[%d]: duplicate mapping for %q", field, i, pm.From))
+		}
+		seen[from] = true
+	}
+
+	return errs
+}
+
+// hasPathPrefix reports whether p is prefix or lies beneath it.
+// An empty prefix is the filesystem root and matches any absolute path.
+func hasPathPrefix(p, prefix string) bool {
+	if prefix == "" {
+		return strings.HasPrefix(p, "/")
+	}
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
+// trimTrailingSeparators removes trailing slashes and backslashes.
+func trimTrailingSeparators(p string) string {
+	return strings.TrimRight(p, `/\`)
+}
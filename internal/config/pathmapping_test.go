@@ -0,0 +1,70 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/seedreap/seedreap/internal/config"
+)
+
+func TestPathMappingsMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings config.PathMappings
+		path     string
+		want     string
+	}{
+		{
+			name:     "no mappings returns path unchanged",
+			mappings: nil,
+			path:     "/downloads/tv/Show",
+			want:     "/downloads/tv/Show",
+		},
+		{
+			name:     "prefix is replaced",
+			mappings: config.PathMappings{{From: "/downloads", To: "/home/user/torrents"}},
+			path:     "/downloads/tv/Show",
+			want:     "/home/user/torrents/tv/Show",
+		},
+		{
+			name:     "exact match",
+			mappings: config.PathMappings{{From: "/downloads/", To: "/data/"}},
+			path:     "/downloads",
+			want:     "/data",
+		},
+		{
+			name:     "only whole path components match",
+			mappings: config.PathMappings{{From: "/data", To: "/mnt"}},
+			path:     "/database/file",
+			want:     "/database/file",
+		},
+		{
+			name: "longest prefix wins",
+			mappings: config.PathMappings{
+				{From: "/downloads", To: "/a"},
+				{From: "/downloads/tv", To: "/b"},
+			},
+			path: "/downloads/tv/Show",
+			want: "/b/Show",
+		},
+		{
+			name:     "root mapping",
+			mappings: config.PathMappings{{From: "/", To: "/mnt/seedbox"}},
+			path:     "/downloads/Show",
+			want:     "/mnt/seedbox/downloads/Show",
+		},
+		{
+			name:     "windows target converts separators",
+			mappings: config.PathMappings{{From: "/downloads", To: `D:\Downloads\`}},
+			path:     "/downloads/tv/Show",
+			want:     `D:\Downloads\tv\Show`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.mappings.Map(tt.path))
+		})
+	}
+}
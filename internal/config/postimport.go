@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Post-import delete modes.
+const (
+	// PostImportDeleteTorrent removes the download from the downloader but keeps its data.
+	PostImportDeleteTorrent = "torrent"
+	// PostImportDeleteTorrentAndData removes the download and its data from the downloader.
+	PostImportDeleteTorrentAndData = "torrentAndData"
+)
+
+// PostImportConfig describes actions applied to a download in its downloader once
+// it has been synced and imported successfully, e.g. to stop seeding on the seedbox.
+type PostImportConfig struct {
+	Pause            bool          `mapstructure:"pause"`            // Pause (stop) the download
+	Category         string        `mapstructure:"category"`         // Move the download to this category
+	Tags             []string      `mapstructure:"tags"`             // Tags to add to the download
+	Delete           string        `mapstructure:"delete"`           // "torrent" or "torrentAndData"
+	RatioLimit       float64       `mapstructure:"ratioLimit"`       // Share ratio limit (0 = unchanged, -1 = unlimited)
+	SeedingTimeLimit time.Duration `mapstructure:"seedingTimeLimit"` // Seeding time limit (0 = unchanged, negative = unlimited)
+}
+
+// IsZero reports whether no post-import actions are configured.
+func (p PostImportConfig) IsZero() bool {
+	return !p.Pause && p.Category == "" && len(p.Tags) == 0 && p.Delete == "" &&
+		p.RatioLimit == 0 && p.SeedingTimeLimit == 0
+}
+
+// validate checks that the configured actions are valid and can be combined.
+func (p PostImportConfig) validate() []error {
+	var errs []error
+
+	switch p.Delete {
+	case "", PostImportDeleteTorrent, PostImportDeleteTorrentAndData:
+	default:
+		errs = append(errs, fmt.Errorf("postImport.delete: unknown mode %q (expected %q or %q)",
+			p.Delete, PostImportDeleteTorrent, PostImportDeleteTorrentAndData))
+	}
+
+	if p.Delete != "" && (p.Pause || p.Category != "" || len(p.Tags) > 0 ||
+		p.RatioLimit != 0 || p.SeedingTimeLimit != 0) {
+		errs = append(errs, errors.New("postImport.delete cannot be combined with other post-import actions"))
+	}
+
+	if p.RatioLimit < 0 && p.RatioLimit != -1 {
+		errs = append(errs, fmt.Errorf("postImport.ratioLimit must be positive or -1 (unlimited): %v", p.RatioLimit))
+	}
+
+	for i, tag := range p.Tags {
+		if tag == "" {
+			errs = append(errs, fmt.Errorf("postImport.tags[%d] must not be empty", i))
+		}
+	}
+
+	return errs
+}
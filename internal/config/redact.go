@@ -0,0 +1,112 @@
+package config
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+)
+
+// RedactedValue replaces secret values in printed configuration.
+const RedactedValue = "REDACTED"
+
+// Redacted returns a copy of the configuration with passwords, API keys, tokens
+// and webhook URLs replaced by RedactedValue, and passwords in URLs masked.
+func (c Config) Redacted() Config {
+	c.Server.APIKey = redact(c.Server.APIKey)
+
+	c.Downloaders = maps.Clone(c.Downloaders)
+	for name, dl := range c.Downloaders {
+		dl.URL = redactURL(dl.URL)
+		dl.Password = redact(dl.Password)
+		dl.SSH.Password = redact(dl.SSH.Password)
+		dl.SSH.KeyPassphrase = redact(dl.SSH.KeyPassphrase)
+		dl.Transfer.URL = redactURL(dl.Transfer.URL)
+		dl.Transfer.Password = redact(dl.Transfer.Password)
+		c.Downloaders[name] = dl
+	}
+
+	c.Apps = maps.Clone(c.Apps)
+	for name, a := range c.Apps {
+		a.URL = redactURL(a.URL)
+		a.APIKey = redact(a.APIKey)
+		c.Apps[name] = a
+	}
+
+	c.Notifications = maps.Clone(c.Notifications)
+	for name, n := range c.Notifications {
+		// Discord and Slack webhook URLs carry their token in the path
+		if n.Type == NotificationDiscord || n.Type == NotificationSlack {
+			n.URL = redact(n.URL)
+		} else {
+			n.URL = redactURL(n.URL)
+		}
+		n.Token = redact(n.Token)
+		n.User = redact(n.User)
+		n.Password = redact(n.Password)
+		c.Notifications[name] = n
+	}
+
+	return c
+}
+
+// Secrets returns the secret values in the configuration: passwords, API keys,
+// tokens, webhook URLs and passwords in URLs. Logs and API responses are
+// scrubbed of them.
+func (c Config) Secrets() []string {
+	secrets := []string{c.Server.APIKey}
+
+	for _, dl := range c.Downloaders {
+		secrets = append(secrets,
+			dl.Password,
+			dl.SSH.Password,
+			dl.SSH.KeyPassphrase,
+			dl.Transfer.Password,
+			urlPassword(dl.URL),
+			urlPassword(dl.Transfer.URL),
+		)
+	}
+
+	for _, a := range c.Apps {
+		secrets = append(secrets, a.APIKey, urlPassword(a.URL))
+	}
+
+	for _, n := range c.Notifications {
+		if n.Type == NotificationDiscord || n.Type == NotificationSlack {
+			secrets = append(secrets, n.URL)
+		}
+		secrets = append(secrets, n.Token, n.User, n.Password, urlPassword(n.URL))
+	}
+
+	return slices.DeleteFunc(secrets, func(s string) bool { return s == "" })
+}
+
+// redact replaces a non-empty secret with RedactedValue.
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
+// urlPassword returns the password in a URL, or "" if it has none.
+func urlPassword(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return ""
+	}
+	password, _ := u.User.Password()
+	return password
+}
+
+// redactURL masks the password in a URL, if it has one.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+	u.User = url.UserPassword(u.User.Username(), RedactedValue)
+	return u.String()
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// secretRefPattern matches ${file:path} and ${env:NAME} references, and $${,
+// which escapes a literal ${.
+//
+//nolint:gochecknoglobals // compiled once, read-only
+var secretRefPattern = regexp.MustCompile(`\$\$\{|\$\{(file|env):([^}]*)\}`)
+
+// expandSecrets replaces ${file:path} and ${env:NAME} references in every
+// string of the configuration, whether it came from the config file or an
+// environment variable, so secrets can be kept in mounted files.
+func expandSecrets(cfg *Config) error {
+	return errors.Join(expandStrings(reflect.ValueOf(cfg).Elem(), "")...)
+}
+
+// expandStrings expands the references in the strings of v, which is found at
+// path in the configuration.
+func expandStrings(v reflect.Value, path string) []error {
+	var errs []error
+
+	switch v.Kind() { //nolint:exhaustive // other kinds hold no strings
+	case reflect.String:
+		expanded, err := expandSecretRefs(v.String())
+		if err != nil {
+			return []error{fmt.Errorf("%s: %w", path, err)}
+		}
+		v.SetString(expanded)
+
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if field.IsExported() {
+				errs = append(errs, expandStrings(v.Field(i), joinKey(path, field.Tag.Get("mapstructure")))...)
+			}
+		}
+
+	case reflect.Slice:
+		for i := range v.Len() {
+			errs = append(errs, expandStrings(v.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+
+	case reflect.Map:
+		// Map values are not addressable, so expand a copy and store it back
+		for _, key := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			errs = append(errs, expandStrings(value, joinKey(path, key.String()))...)
+			v.SetMapIndex(key, value)
+		}
+	}
+
+	return errs
+}
+
+// joinKey appends key to a configuration path.
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// expandSecretRefs replaces the references in s. Errors never include the
+// value being expanded, since it may be a secret.
+func expandSecretRefs(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var errs []error
+	expanded := secretRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+
+		match := secretRefPattern.FindStringSubmatch(ref)
+		source, name := match[1], match[2]
+		if source == "env" {
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("environment variable %s is not set", name))
+			}
+			return value
+		}
+
+		value, err := readSecretFile(name)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return value
+	})
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	return expanded, nil
+}
+
+// readSecretFile returns the contents of a secret file without the trailing
+// newline that editors and `echo` add.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// readSecretFiles fills in the passwords and API keys set as files.
+func readSecretFiles(cfg *Config) error {
+	var errs []error
+
+	if err := readSecretField(&cfg.Server.APIKey, cfg.Server.APIKeyFile, "apiKey"); err != nil {
+		errs = append(errs, fmt.Errorf("server: %w", err))
+	}
+
+	for name, dl := range cfg.Downloaders {
+		if err := readSecretField(&dl.Password, dl.PasswordFile, "password"); err != nil {
+			errs = append(errs, fmt.Errorf("downloader %q: %w", name, err))
+		}
+		cfg.Downloaders[name] = dl
+	}
+
+	for name, a := range cfg.Apps {
+		if err := readSecretField(&a.APIKey, a.APIKeyFile, "apiKey"); err != nil {
+			errs = append(errs, fmt.Errorf("app %q: %w", name, err))
+		}
+		cfg.Apps[name] = a
+	}
+
+	return errors.Join(errs...)
+}
+
+// readSecretField sets *value to the contents of path, if set. Setting both the
+// value and the file is an error.
+func readSecretField(value *string, path, field string) error {
+	if path == "" {
+		return nil
+	}
+	if *value != "" {
+		return fmt.Errorf("%s and %sFile are mutually exclusive", field, field)
+	}
+
+	secret, err := readSecretFile(path)
+	if err != nil {
+		return fmt.Errorf("%sFile: %w", field, err)
+	}
+	*value = secret
+	return nil
+}
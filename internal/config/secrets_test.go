@@ -0,0 +1,174 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seedreap/seedreap/internal/config"
+)
+
+// writeSecret writes a secret file with a trailing newline, like a mounted
+// Docker or Kubernetes secret usually has.
+func writeSecret(t *testing.T, value string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte(value+"\n"), 0600))
+	return path
+}
+
+// loadConfigErrorFromYAML loads a config file that is expected to fail.
+func loadConfigErrorFromYAML(t *testing.T, yaml string) error {
+	t.Helper()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(yaml), 0600))
+
+	_, err := config.Load(config.LoadOptions{ConfigFile: configFile})
+	require.Error(t, err)
+	return err
+}
+
+func TestSecretFiles(t *testing.T) {
+	t.Run("ReadsFiles", func(t *testing.T) {
+		cfg := loadConfigFromYAML(t, `
+server:
+  apiKeyFile: `+writeSecret(t, "server-key")+`
+downloaders:
+  seedbox:
+    type: qbittorrent
+    url: http://seedbox:8080
+    passwordFile: `+writeSecret(t, "qbit-password")+`
+    ssh:
+      host: seedbox
+      user: seeduser
+      keyFile: /path/to/key
+      ignoreHostKey: true
+apps:
+  sonarr:
+    type: sonarr
+    url: http://sonarr:8989
+    apiKeyFile: `+writeSecret(t, "sonarr-key")+`
+    category: tv
+`)
+
+		assert.Equal(t, "server-key", cfg.Server.APIKey)
+		assert.Equal(t, "qbit-password", cfg.Downloaders["seedbox"].Password)
+		assert.Equal(t, "sonarr-key", cfg.Apps["sonarr"].APIKey)
+	})
+
+	t.Run("FromEnvironment", func(t *testing.T) {
+		t.Setenv("SEEDREAP_APPS", "sonarr")
+		t.Setenv("SEEDREAP_APPS_SONARR_TYPE", "sonarr")
+		t.Setenv("SEEDREAP_APPS_SONARR_URL", "http://sonarr:8989")
+		t.Setenv("SEEDREAP_APPS_SONARR_CATEGORY", "tv")
+		t.Setenv("SEEDREAP_APPS_SONARR_APIKEYFILE", writeSecret(t, "sonarr-key"))
+
+		cfg, err := config.Load(config.LoadOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "sonarr-key", cfg.Apps["sonarr"].APIKey)
+	})
+
+	t.Run("MutuallyExclusive", func(t *testing.T) {
+		err := loadConfigErrorFromYAML(t, `
+apps:
+  sonarr:
+    type: sonarr
+    url: http://sonarr:8989
+    apiKey: inline
+    apiKeyFile: `+writeSecret(t, "sonarr-key")+`
+    category: tv
+`)
+		assert.ErrorContains(t, err, `app "sonarr": apiKey and apiKeyFile are mutually exclusive`)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		err := loadConfigErrorFromYAML(t, `
+server:
+  apiKeyFile: /nonexistent/secret
+`)
+		assert.ErrorContains(t, err, "server: apiKeyFile: failed to read secret file")
+	})
+}
+
+func TestSecretReferences(t *testing.T) {
+	t.Run("FileAndEnv", func(t *testing.T) {
+		t.Setenv("TEST_QBIT_USER", "admin")
+		cfg := loadConfigFromYAML(t, `
+downloaders:
+  seedbox:
+    type: qbittorrent
+    url: http://seedbox:8080
+    username: ${env:TEST_QBIT_USER}
+    password: ${file:`+writeSecret(t, "qbit-password")+`}
+    ssh:
+      host: seedbox
+      user: seeduser
+      password: ssh-${file:`+writeSecret(t, "password")+`}
+      ignoreHostKey: true
+`)
+
+		dl := cfg.Downloaders["seedbox"]
+		assert.Equal(t, "admin", dl.Username)
+		assert.Equal(t, "qbit-password", dl.Password)
+		assert.Equal(t, "ssh-password", dl.SSH.Password)
+	})
+
+	t.Run("InEnvironmentVariables", func(t *testing.T) {
+		t.Setenv("SEEDREAP_SERVER_APIKEY", "${file:"+writeSecret(t, "server-key")+"}")
+
+		cfg, err := config.Load(config.LoadOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "server-key", cfg.Server.APIKey)
+	})
+
+	t.Run("InLists", func(t *testing.T) {
+		t.Setenv("TEST_TAG", "tv")
+		cfg := loadConfigFromYAML(t, `
+apps:
+  misc:
+    type: passthrough
+    tags: ["${env:TEST_TAG}", anime]
+`)
+
+		assert.Equal(t, []string{"tv", "anime"}, cfg.Apps["misc"].Tags)
+	})
+
+	t.Run("Escaped", func(t *testing.T) {
+		cfg := loadConfigFromYAML(t, `
+server:
+  apiKey: pa$${env:NOT_EXPANDED}ss
+`)
+
+		assert.Equal(t, "pa${env:NOT_EXPANDED}ss", cfg.Server.APIKey)
+	})
+
+	t.Run("OtherDollarSignsKept", func(t *testing.T) {
+		cfg := loadConfigFromYAML(t, `
+server:
+  apiKey: a$b${c}
+`)
+
+		assert.Equal(t, "a$b${c}", cfg.Server.APIKey)
+	})
+
+	t.Run("UnsetEnvironmentVariable", func(t *testing.T) {
+		err := loadConfigErrorFromYAML(t, `
+server:
+  apiKey: ${env:TEST_SEEDREAP_UNSET}
+`)
+		assert.ErrorContains(t, err, "server.apiKey: environment variable TEST_SEEDREAP_UNSET is not set")
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		err := loadConfigErrorFromYAML(t, `
+server:
+  apiKey: ${file:/nonexistent/secret}
+`)
+		assert.ErrorContains(t, err, "server.apiKey: failed to read secret file")
+	})
+}
@@ -2,8 +2,10 @@ package download_test
 
 import (
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -78,6 +80,21 @@ func TestRegistry(t *testing.T) {
 		assert.Nil(t, got)
 	})
 
+	t.Run("Unregister", func(t *testing.T) {
+		r := download.NewRegistry()
+
+		dl := download.NewQBittorrent("seedbox", config.DownloaderConfig{URL: "http://seedbox:8080"})
+		r.Register("seedbox", dl)
+
+		got, ok := r.Unregister("seedbox")
+		require.True(t, ok)
+		assert.Equal(t, dl, got)
+		assert.Empty(t, r.All())
+
+		_, ok = r.Unregister("seedbox")
+		assert.False(t, ok)
+	})
+
 	t.Run("All", func(t *testing.T) {
 		r := download.NewRegistry()
 
@@ -323,6 +340,79 @@ func TestQBittorrentListDownloads_ReturnsAllTorrents(t *testing.T) {
 	assert.Equal(t, download.TorrentStateDownloading, downloads[1].State)
 }
 
+func TestQBittorrentListDownloads_AppliesPathMappings(t *testing.T) {
+	torrents := []map[string]any{
+		{
+			"hash":         "abc123",
+			"name":         "Show.S01E01",
+			"category":     "tv",
+			"state":        "uploading",
+			"save_path":    "/downloads/tv",
+			"content_path": "/downloads/tv/Show.S01E01",
+			"progress":     1.0,
+		},
+		{
+			"hash":         "def456",
+			"name":         "Movie.2024",
+			"category":     "movies",
+			"state":        "uploading",
+			"save_path":    "/other/movies",
+			"content_path": "/other/movies/Movie.2024",
+			"progress":     1.0,
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/torrents/info" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(torrents)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.DownloaderConfig{
+		URL: server.URL,
+		PathMappings: config.PathMappings{
+			{From: "/downloads", To: "/home/user/torrents"},
+		},
+	}
+	dl := download.NewQBittorrent("seedbox", cfg)
+
+	downloads, err := dl.ListDownloads(t.Context(), nil)
+	require.NoError(t, err)
+	require.Len(t, downloads, 2)
+
+	assert.Equal(t, "/home/user/torrents/tv", downloads[0].SavePath)
+	assert.Equal(t, "/home/user/torrents/tv/Show.S01E01", downloads[0].ContentPath)
+
+	// Paths outside any mapping are left untouched
+	assert.Equal(t, "/other/movies", downloads[1].SavePath)
+}
+
+func TestQBittorrentListDownloads_ParsesTags(t *testing.T) {
+	torrents := []map[string]any{
+		{"hash": "abc123", "name": "Show", "category": "", "tags": "seedreap, tv", "state": "uploading"},
+		{"hash": "def456", "name": "Movie", "category": "movies", "tags": "", "state": "uploading"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/torrents/info" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(torrents)
+		}
+	}))
+	defer server.Close()
+
+	dl := download.NewQBittorrent("seedbox", config.DownloaderConfig{URL: server.URL})
+
+	downloads, err := dl.ListDownloads(t.Context(), nil)
+	require.NoError(t, err)
+	require.Len(t, downloads, 2)
+
+	assert.Equal(t, []string{"seedreap", "tv"}, downloads[0].Tags)
+	assert.Empty(t, downloads[1].Tags)
+}
+
 func TestQBittorrentListDownloads_FilterByCategory(t *testing.T) {
 	torrents := []map[string]any{
 		{"hash": "abc123", "name": "Show", "category": "tv", "state": "uploading", "progress": 1.0},
@@ -652,3 +742,178 @@ func TestQBittorrentClose(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestQBittorrentActions(t *testing.T) {
+	type request struct {
+		path string
+		form url.Values
+	}
+
+	newServer := func(t *testing.T, notFound string) (*httptest.Server, *[]request) {
+		t.Helper()
+		var requests []request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			require.NoError(t, r.ParseForm())
+			requests = append(requests, request{path: r.URL.Path, form: r.PostForm})
+			if r.URL.Path == notFound {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+		return server, &requests
+	}
+
+	actioner := func(t *testing.T, serverURL string) download.Actioner {
+		t.Helper()
+		dl := download.NewQBittorrent("seedbox", config.DownloaderConfig{URL: serverURL})
+		a, ok := dl.(download.Actioner)
+		require.True(t, ok, "qBittorrent should implement Actioner")
+		return a
+	}
+
+	t.Run("Pause", func(t *testing.T) {
+		server, requests := newServer(t, "")
+		require.NoError(t, actioner(t, server.URL).Pause(t.Context(), "abc123"))
+
+		require.Len(t, *requests, 1)
+		assert.Equal(t, "/api/v2/torrents/stop", (*requests)[0].path)
+		assert.Equal(t, "abc123", (*requests)[0].form.Get("hashes"))
+	})
+
+	t.Run("Pause_FallsBackToLegacyEndpoint", func(t *testing.T) {
+		server, requests := newServer(t, "/api/v2/torrents/stop")
+		require.NoError(t, actioner(t, server.URL).Pause(t.Context(), "abc123"))
+
+		require.Len(t, *requests, 2)
+		assert.Equal(t, "/api/v2/torrents/pause", (*requests)[1].path)
+	})
+
+	t.Run("SetCategory", func(t *testing.T) {
+		server, requests := newServer(t, "")
+		require.NoError(t, actioner(t, server.URL).SetCategory(t.Context(), "abc123", "tv-imported"))
+
+		require.Len(t, *requests, 1)
+		assert.Equal(t, "/api/v2/torrents/setCategory", (*requests)[0].path)
+		assert.Equal(t, "tv-imported", (*requests)[0].form.Get("category"))
+	})
+
+	t.Run("AddTags", func(t *testing.T) {
+		server, requests := newServer(t, "")
+		require.NoError(t, actioner(t, server.URL).AddTags(t.Context(), "abc123", []string{"synced", "seedreap"}))
+
+		require.Len(t, *requests, 1)
+		assert.Equal(t, "/api/v2/torrents/addTags", (*requests)[0].path)
+		assert.Equal(t, "synced,seedreap", (*requests)[0].form.Get("tags"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		server, requests := newServer(t, "")
+		require.NoError(t, actioner(t, server.URL).Delete(t.Context(), "abc123", true))
+
+		require.Len(t, *requests, 1)
+		assert.Equal(t, "/api/v2/torrents/delete", (*requests)[0].path)
+		assert.Equal(t, "true", (*requests)[0].form.Get("deleteFiles"))
+	})
+
+	t.Run("SetShareLimits", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			limits          download.ShareLimits
+			expectedRatio   string
+			expectedSeeding string
+		}{
+			{"Values", download.ShareLimits{RatioLimit: 1.5, SeedingTimeLimit: 2 * time.Hour}, "1.5", "120"},
+			{"Unlimited", download.ShareLimits{RatioLimit: -1, SeedingTimeLimit: -1}, "-1", "-1"},
+			{"GlobalDefaults", download.ShareLimits{}, "-2", "-2"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				server, requests := newServer(t, "")
+				require.NoError(t, actioner(t, server.URL).SetShareLimits(t.Context(), "abc123", tt.limits))
+
+				require.Len(t, *requests, 1)
+				form := (*requests)[0].form
+				assert.Equal(t, "/api/v2/torrents/setShareLimits", (*requests)[0].path)
+				assert.Equal(t, tt.expectedRatio, form.Get("ratioLimit"))
+				assert.Equal(t, tt.expectedSeeding, form.Get("seedingTimeLimit"))
+				assert.Equal(t, "-2", form.Get("inactiveSeedingTimeLimit"))
+			})
+		}
+	})
+
+	t.Run("ErrorStatus", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		err := actioner(t, server.URL).SetCategory(t.Context(), "abc123", "tv")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "403")
+	})
+}
+
+func TestQBittorrentAdd(t *testing.T) {
+	newAdder := func(t *testing.T, handler http.HandlerFunc) download.Adder {
+		t.Helper()
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		dl := download.NewQBittorrent("seedbox", config.DownloaderConfig{URL: server.URL})
+		a, ok := dl.(download.Adder)
+		require.True(t, ok, "qBittorrent should implement Adder")
+		return a
+	}
+
+	t.Run("SendsURLsAndTorrentFiles", func(t *testing.T) {
+		var form *multipart.Form
+		a := newAdder(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v2/torrents/add", r.URL.Path)
+			require.NoError(t, r.ParseMultipartForm(1<<20))
+			form = r.MultipartForm
+			_, _ = w.Write([]byte("Ok."))
+		})
+
+		err := a.Add(t.Context(), download.AddRequest{
+			URLs:     []string{"magnet:?xt=urn:btih:abc", "https://example.com/b.torrent"},
+			Torrents: []download.TorrentFile{{Name: "c.torrent", Data: []byte("d8:announce0:e")}},
+			Category: "tv",
+			Tags:     []string{"seedreap", "manual"},
+			Paused:   true,
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, form)
+		assert.Equal(t, []string{"magnet:?xt=urn:btih:abc\nhttps://example.com/b.torrent"}, form.Value["urls"])
+		assert.Equal(t, []string{"tv"}, form.Value["category"])
+		assert.Equal(t, []string{"seedreap,manual"}, form.Value["tags"])
+		assert.Equal(t, []string{"true"}, form.Value["paused"])
+		assert.Equal(t, []string{"true"}, form.Value["stopped"])
+		require.Len(t, form.File["torrents"], 1)
+		assert.Equal(t, "c.torrent", form.File["torrents"][0].Filename)
+	})
+
+	t.Run("FailsResponse", func(t *testing.T) {
+		a := newAdder(t, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("Fails."))
+		})
+
+		err := a.Add(t.Context(), download.AddRequest{URLs: []string{"magnet:?xt=urn:btih:abc"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidTorrent", func(t *testing.T) {
+		a := newAdder(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+		})
+
+		err := a.Add(t.Context(), download.AddRequest{
+			Torrents: []download.TorrentFile{{Name: "bad.torrent", Data: []byte("nope")}},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -3,6 +3,8 @@ package download
 
 import (
 	"context"
+	"maps"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -73,11 +75,15 @@ type Download struct {
 	Hash string
 	// Category is the category/label assigned to this download.
 	Category string
+	// Tags are the tags assigned to this download.
+	Tags []string
 	// State is the overall download state.
 	State TorrentState
-	// SavePath is the path where files are saved on the remote system.
+	// SavePath is the path where files are saved on the remote system, with the
+	// downloader's path mappings applied so it is reachable over SSH.
 	SavePath string
-	// ContentPath is the full path to the content (file or directory).
+	// ContentPath is the full path to the content (file or directory), with the
+	// downloader's path mappings applied.
 	ContentPath string
 	// Size is the total size in bytes.
 	Size int64
@@ -121,8 +127,68 @@ type Downloader interface {
 	SSHConfig() config.SSHConfig
 }
 
-// Registry holds all configured downloaders.
+// ShareLimits holds seeding limits for a download.
+// Zero values leave the client's global default in effect; negative values mean unlimited.
+type ShareLimits struct {
+	// RatioLimit is the share ratio at which seeding stops.
+	RatioLimit float64
+	// SeedingTimeLimit is how long to seed before stopping.
+	SeedingTimeLimit time.Duration
+}
+
+// Actioner is an optional interface for downloaders that can modify downloads,
+// for example to stop seeding once files have been synced and imported.
+// Check for support with a type assertion on a Downloader.
+type Actioner interface {
+	// Pause pauses (stops) a download.
+	Pause(ctx context.Context, id string) error
+
+	// SetCategory changes a download's category.
+	SetCategory(ctx context.Context, id, category string) error
+
+	// AddTags adds tags to a download.
+	AddTags(ctx context.Context, id string, tags []string) error
+
+	// Delete removes a download from the client, optionally deleting its data.
+	Delete(ctx context.Context, id string, deleteFiles bool) error
+
+	// SetShareLimits sets seeding limits for a download.
+	SetShareLimits(ctx context.Context, id string, limits ShareLimits) error
+}
+
+// TorrentFile is the contents of a .torrent file.
+type TorrentFile struct {
+	// Name is the file name, used for logging and error messages.
+	Name string
+	// Data is the raw .torrent file.
+	Data []byte
+}
+
+// AddRequest describes downloads to add to a client.
+type AddRequest struct {
+	// URLs are magnet links or HTTP(S) URLs of .torrent files.
+	URLs []string
+	// Torrents are uploaded .torrent files.
+	Torrents []TorrentFile
+	// Category is assigned to the added downloads.
+	Category string
+	// Tags are assigned to the added downloads.
+	Tags []string
+	// Paused adds the downloads without starting them.
+	Paused bool
+}
+
+// Adder is an optional interface for downloaders that can add new downloads.
+// Check for support with a type assertion on a Downloader.
+type Adder interface {
+	// Add submits new downloads to the client.
+	Add(ctx context.Context, req AddRequest) error
+}
+
+// Registry holds all configured downloaders. It is safe for concurrent use, so
+// downloaders can be added and removed while the orchestrator is running.
 type Registry struct {
+	mu          sync.RWMutex
 	downloaders map[string]Downloader
 }
 
@@ -133,18 +199,33 @@ func NewRegistry() *Registry {
 	}
 }
 
-// Register adds a downloader to the registry.
+// Register adds a downloader to the registry, replacing any downloader with the same name.
 func (r *Registry) Register(name string, d Downloader) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.downloaders[name] = d
 }
 
+// Unregister removes a downloader from the registry and returns it.
+func (r *Registry) Unregister(name string) (Downloader, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.downloaders[name]
+	delete(r.downloaders, name)
+	return d, ok
+}
+
 // Get returns a downloader by name.
 func (r *Registry) Get(name string) (Downloader, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	d, ok := r.downloaders[name]
 	return d, ok
 }
 
-// All returns all registered downloaders.
+// All returns a snapshot of all registered downloaders.
 func (r *Registry) All() map[string]Downloader {
-	return r.downloaders
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.downloaders)
 }
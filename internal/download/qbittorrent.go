@@ -1,14 +1,17 @@
 package download
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +28,7 @@ type qbittorrentClient struct {
 	username   string
 	password   string
 	sshConfig  config.SSHConfig
+	pathMaps   config.PathMappings
 	httpClient *http.Client
 	logger     zerolog.Logger
 }
@@ -34,6 +38,7 @@ type qbittorrentAPITorrent struct {
 	Hash           string  `json:"hash"`
 	Name           string  `json:"name"`
 	Category       string  `json:"category"`
+	Tags           string  `json:"tags"`
 	State          string  `json:"state"`
 	SavePath       string  `json:"save_path"`
 	ContentPath    string  `json:"content_path"`
@@ -74,6 +79,7 @@ func NewQBittorrent(name string, cfg config.DownloaderConfig, opts ...Option) Do
 		username:  cfg.Username,
 		password:  cfg.Password,
 		sshConfig: cfg.SSH,
+		pathMaps:  cfg.PathMappings,
 		httpClient: &http.Client{
 			Jar:     jar,
 			Timeout: cfg.HTTPTimeout,
@@ -324,9 +330,10 @@ func (c *qbittorrentClient) toDownload(t qbittorrentAPITorrent) Download {
 		Name:        t.Name,
 		Hash:        t.Hash,
 		Category:    t.Category,
+		Tags:        parseQBittorrentTags(t.Tags),
 		State:       state,
-		SavePath:    t.SavePath,
-		ContentPath: t.ContentPath,
+		SavePath:    c.pathMaps.Map(t.SavePath),
+		ContentPath: c.pathMaps.Map(t.ContentPath),
 		Size:        t.Size,
 		Downloaded:  t.Downloaded,
 		Progress:    t.Progress,
@@ -334,3 +341,178 @@ func (c *qbittorrentClient) toDownload(t qbittorrentAPITorrent) Download {
 		CompletedOn: completedOn,
 	}
 }
+
+// parseQBittorrentTags splits qBittorrent's comma-separated tag list.
+func parseQBittorrentTags(s string) []string {
+	var tags []string
+	for tag := range strings.SplitSeq(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// qBittorrent share limit sentinel values.
+const (
+	qbittorrentLimitGlobal    = -2
+	qbittorrentLimitUnlimited = -1
+)
+
+// Pause pauses a torrent. qBittorrent v5 renamed the endpoint to "stop", so the
+// legacy "pause" endpoint is used as a fallback for older versions.
+func (c *qbittorrentClient) Pause(ctx context.Context, id string) error {
+	params := url.Values{"hashes": {id}}
+
+	err := c.postForm(ctx, "/api/v2/torrents/stop", params)
+	if errors.Is(err, errQBittorrentNotFound) {
+		err = c.postForm(ctx, "/api/v2/torrents/pause", params)
+	}
+	return err
+}
+
+// SetCategory changes a torrent's category.
+func (c *qbittorrentClient) SetCategory(ctx context.Context, id, category string) error {
+	return c.postForm(ctx, "/api/v2/torrents/setCategory", url.Values{
+		"hashes":   {id},
+		"category": {category},
+	})
+}
+
+// AddTags adds tags to a torrent.
+func (c *qbittorrentClient) AddTags(ctx context.Context, id string, tags []string) error {
+	return c.postForm(ctx, "/api/v2/torrents/addTags", url.Values{
+		"hashes": {id},
+		"tags":   {strings.Join(tags, ",")},
+	})
+}
+
+// Delete removes a torrent, optionally deleting its data.
+func (c *qbittorrentClient) Delete(ctx context.Context, id string, deleteFiles bool) error {
+	return c.postForm(ctx, "/api/v2/torrents/delete", url.Values{
+		"hashes":      {id},
+		"deleteFiles": {strconv.FormatBool(deleteFiles)},
+	})
+}
+
+// SetShareLimits sets seeding limits for a torrent.
+func (c *qbittorrentClient) SetShareLimits(ctx context.Context, id string, limits ShareLimits) error {
+	ratio := float64(qbittorrentLimitGlobal)
+	switch {
+	case limits.RatioLimit > 0:
+		ratio = limits.RatioLimit
+	case limits.RatioLimit < 0:
+		ratio = qbittorrentLimitUnlimited
+	}
+
+	seedingMinutes := int64(qbittorrentLimitGlobal)
+	switch {
+	case limits.SeedingTimeLimit > 0:
+		seedingMinutes = max(1, int64(limits.SeedingTimeLimit/time.Minute))
+	case limits.SeedingTimeLimit < 0:
+		seedingMinutes = qbittorrentLimitUnlimited
+	}
+
+	return c.postForm(ctx, "/api/v2/torrents/setShareLimits", url.Values{
+		"hashes":                   {id},
+		"ratioLimit":               {strconv.FormatFloat(ratio, 'f', -1, 64)},
+		"seedingTimeLimit":         {strconv.FormatInt(seedingMinutes, 10)},
+		"inactiveSeedingTimeLimit": {strconv.Itoa(qbittorrentLimitGlobal)},
+	})
+}
+
+// Add submits magnet links, torrent URLs and .torrent files to qBittorrent.
+func (c *qbittorrentClient) Add(ctx context.Context, req AddRequest) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	paused := strconv.FormatBool(req.Paused)
+	fields := [][2]string{
+		{"urls", strings.Join(req.URLs, "\n")},
+		{"category", req.Category},
+		{"tags", strings.Join(req.Tags, ",")},
+		// qBittorrent v5 renamed "paused" to "stopped"; send both
+		{"paused", paused},
+		{"stopped", paused},
+	}
+	for _, f := range fields {
+		if f[1] == "" {
+			continue
+		}
+		if err := w.WriteField(f[0], f[1]); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range req.Torrents {
+		part, err := w.CreateFormFile("torrents", t.Name)
+		if err != nil {
+			return err
+		}
+		if _, err = part.Write(t.Data); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v2/torrents/add", &body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", w.FormDataContentType())
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// qBittorrent answers 200 "Fails." when none of the torrents could be added
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(respBody)) == "Fails." {
+		return fmt.Errorf("add torrents failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	c.logger.Info().
+		Int("urls", len(req.URLs)).
+		Int("torrents", len(req.Torrents)).
+		Str("category", req.Category).
+		Msg("added torrents")
+
+	return nil
+}
+
+// errQBittorrentNotFound is returned by postForm when the endpoint does not exist.
+var errQBittorrentNotFound = errors.New("endpoint not found")
+
+// postForm sends a form-encoded POST request to a qBittorrent API endpoint.
+func (c *qbittorrentClient) postForm(ctx context.Context, endpoint string, data url.Values) error {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost,
+		c.baseURL+endpoint,
+		strings.NewReader(data.Encode()),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: %w", endpoint, errQBittorrentNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s returned status %d: %s", endpoint, resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package filesync
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/seedreap/seedreap/internal/fileutil"
+)
+
+// publishDirPrefix names the hidden directory in a final path where the files
+// of a download are gathered before being published. Apps ignore hidden
+// directories when scanning for imports.
+const publishDirPrefix = ".seedreap-"
+
+// PublishDir returns the hidden directory in the job's final path where its
+// files are gathered before being published.
+func (j *SyncJob) PublishDir() string {
+	return filepath.Join(j.GetFinalPath(), publishDirPrefix+j.ID)
+}
+
+// gatherFiles moves the synced files of job into publishDir, which is on the
+// same filesystem as the final path, and returns their paths. Moving across
+// filesystems copies the files, which can take a while, so this is done before
+// anything becomes visible in the final path. Files gathered by an earlier,
+// interrupted attempt are kept.
+func gatherFiles(job *SyncJob, publishDir string) ([]string, error) {
+	var paths []string
+
+	for _, file := range job.Files {
+		file.mu.RLock()
+		status := file.Status
+		file.mu.RUnlock()
+
+		if status != FileStatusComplete && status != FileStatusSkipped {
+			continue
+		}
+
+		gatheredPath := filepath.Join(publishDir, file.Path)
+		if _, err := os.Stat(file.LocalPath); errors.Is(err, fs.ErrNotExist) {
+			if _, statErr := os.Stat(gatheredPath); statErr == nil {
+				paths = append(paths, file.Path)
+				continue
+			}
+			if status == FileStatusSkipped {
+				// Already at the final destination
+				continue
+			}
+		}
+
+		if err := fileutil.MoveFile(file.LocalPath, gatheredPath); err != nil {
+			return nil, fmt.Errorf("failed to move file %s: %w", file.Path, err)
+		}
+		paths = append(paths, file.Path)
+	}
+
+	return paths, nil
+}
+
+// restoreFiles moves the files gathered into publishDir back to the staging
+// directory of job, where a retry picks them up, and removes publishDir.
+func restoreFiles(job *SyncJob, publishDir string) error {
+	var errs []error
+	for _, file := range job.Files {
+		gatheredPath := filepath.Join(publishDir, file.Path)
+		if _, err := os.Lstat(gatheredPath); err != nil {
+			continue
+		}
+		if err := fileutil.MoveFile(gatheredPath, file.LocalPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore file %s: %w", file.Path, err))
+		}
+	}
+
+	if err := os.RemoveAll(publishDir); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// publishFiles renames the files at paths in publishDir into finalPath. Each
+// top-level file or directory of a download is published in one rename, so all
+// of its files appear at once. A top-level directory that already exists,
+// because some of its files were already in place, is replaced by the gathered
+// one, into which its other entries are moved first.
+func publishFiles(publishDir, finalPath string, paths []string) error {
+	published := make(map[string]bool)
+
+	for _, path := range paths {
+		top, _, nested := strings.Cut(filepath.ToSlash(path), "/")
+		if published[top] {
+			continue
+		}
+		published[top] = true
+
+		src := filepath.Join(publishDir, top)
+		dst := filepath.Join(finalPath, top)
+
+		info, err := os.Stat(dst)
+		switch {
+		case err == nil && nested && info.IsDir():
+			err = replaceDir(src, dst, filepath.Join(publishDir, replacedDirName))
+		case err == nil || errors.Is(err, fs.ErrNotExist):
+			err = os.Rename(src, dst)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return fileutil.SyncDir(finalPath)
+}
+
+// replacedDirName names the directory in the publish directory where an
+// existing top-level directory is moved while it is being replaced.
+const replacedDirName = publishDirPrefix + "replaced"
+
+// replaceDir replaces the existing directory dst with the gathered directory
+// src in one rename. dst is first moved aside, and its entries that were not
+// gathered are moved into src. If that fails, they are moved back and dst is
+// restored.
+func replaceDir(src, dst, aside string) error {
+	if err := os.Rename(dst, aside); err != nil {
+		return err
+	}
+
+	moved, err := moveMissing(aside, src, "", nil)
+	if err == nil {
+		err = os.Rename(src, dst)
+	}
+	if err != nil {
+		errs := []error{err}
+		for i := len(moved) - 1; i >= 0; i-- {
+			errs = append(errs, os.Rename(filepath.Join(src, moved[i]), filepath.Join(aside, moved[i])))
+		}
+		errs = append(errs, os.Rename(aside, dst))
+		return errors.Join(errs...)
+	}
+
+	// Only entries that were gathered again are left
+	_ = os.RemoveAll(aside)
+	return nil
+}
+
+// moveMissing moves the entries of the directory rel in dir that are missing
+// from the same directory in target into it, descending into directories that
+// are in both. It returns moved with the paths of the moved entries appended,
+// relative to dir.
+func moveMissing(dir, target, rel string, moved []string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, rel))
+	if err != nil {
+		return moved, err
+	}
+
+	for _, e := range entries {
+		entryRel := filepath.Join(rel, e.Name())
+		info, statErr := os.Lstat(filepath.Join(target, entryRel))
+		switch {
+		case errors.Is(statErr, fs.ErrNotExist):
+			if err = os.Rename(filepath.Join(dir, entryRel), filepath.Join(target, entryRel)); err != nil {
+				return moved, err
+			}
+			moved = append(moved, entryRel)
+		case statErr != nil:
+			return moved, statErr
+		case e.IsDir() && info.IsDir():
+			if moved, err = moveMissing(dir, target, entryRel, moved); err != nil {
+				return moved, err
+			}
+		}
+	}
+	return moved, nil
+}
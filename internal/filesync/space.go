@@ -0,0 +1,66 @@
+package filesync
+
+import "fmt"
+
+// InsufficientSpaceError is returned by CheckSpace when a download does not fit
+// on disk.
+type InsufficientSpaceError struct {
+	Path     string // Directory without enough space
+	Required int64  // Bytes needed, including in-flight transfers and the minimum free space
+	Free     int64  // Bytes available
+}
+
+func (e *InsufficientSpaceError) Error() string {
+	return fmt.Sprintf("not enough free disk space in %s: %d bytes needed, %d available", e.Path, e.Required, e.Free)
+}
+
+// WithMinFreeSpace sets the number of bytes CheckSpace keeps free on the
+// syncing and final paths.
+func WithMinFreeSpace(n int64) Option {
+	return func(s *Syncer) {
+		s.minFreeSpace = n
+	}
+}
+
+// WithFreeSpaceFunc sets how CheckSpace measures the free space of a path,
+// instead of fileutil.FreeSpace.
+func WithFreeSpaceFunc(fn func(path string) (int64, error)) Option {
+	return func(s *Syncer) {
+		s.freeSpace = fn
+	}
+}
+
+// CheckSpace checks that size more bytes fit on the syncing path and on
+// finalPath, on top of the bytes the jobs in progress have yet to transfer and
+// the minimum free space. It returns an *InsufficientSpaceError if they do not.
+func (s *Syncer) CheckSpace(size int64, finalPath string) error {
+	required := size + s.ReservedBytes() + s.minFreeSpace
+
+	for _, path := range []string{s.syncingPath, finalPath} {
+		free, err := s.freeSpace(path)
+		if err != nil {
+			return fmt.Errorf("failed to check free space in %s: %w", path, err)
+		}
+		if free < required {
+			return &InsufficientSpaceError{Path: path, Required: required, Free: free}
+		}
+	}
+
+	return nil
+}
+
+// ReservedBytes returns the number of bytes the jobs in progress have yet to
+// transfer, which the free space of the disk does not account for yet.
+func (s *Syncer) ReservedBytes() int64 {
+	s.jobsMu.RLock()
+	defer s.jobsMu.RUnlock()
+
+	var reserved int64
+	for _, job := range s.jobs {
+		completed, status := job.GetProgress()
+		if status == FileStatusPending || status == FileStatusSyncing {
+			reserved += max(job.TotalSize-completed, 0)
+		}
+	}
+	return reserved
+}
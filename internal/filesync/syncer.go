@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sync"
@@ -36,6 +37,15 @@ const (
 // Default configuration values.
 const (
 	defaultMaxConcurrent = 2
+
+	// batchMinFiles is the number of small files a job needs before they are
+	// transferred as a batch, if the transfer backend supports it.
+	batchMinFiles = 16
+
+	// batchMaxFileSize is the size of the largest file transferred as part of a
+	// batch. Larger files are transferred on their own, so that they can be
+	// split over parallel connections.
+	batchMaxFileSize = 10 * 1024 * 1024
 )
 
 // FileProgress tracks the progress of syncing a single file.
@@ -160,6 +170,13 @@ func (j *SyncJob) IsCancelled() bool {
 	return !j.CancelledAt.IsZero()
 }
 
+// GetCancelledAt returns when the job was cancelled, or the zero time if it was not.
+func (j *SyncJob) GetCancelledAt() time.Time {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.CancelledAt
+}
+
 // Context returns the job's context.
 func (j *SyncJob) Context() context.Context {
 	return j.ctx
@@ -244,6 +261,13 @@ type Syncer struct {
 	maxConcurrent int
 	logger        zerolog.Logger
 	transferer    transfer.Transferer
+	transferers   map[string]transfer.Transferer // Per-downloader overrides of transferer
+	dryRun        bool
+	minFreeSpace  int64
+	freeSpace     func(path string) (int64, error)
+	stallTimeout  time.Duration
+	minSpeed      int64
+	stallRetries  int
 
 	jobs      map[string]*SyncJob
 	jobsMu    sync.RWMutex
@@ -256,6 +280,7 @@ type Syncer struct {
 	// Callbacks
 	onJobComplete  func(job *SyncJob)
 	onFileComplete func(job *SyncJob, file *FileProgress)
+	onStalled      func(stall Stall)
 }
 
 // Option is a functional option for configuring the syncer.
@@ -283,6 +308,25 @@ func WithTransferer(t transfer.Transferer) Option {
 	}
 }
 
+// WithDownloaderTransferer sets the transfer backend to use for the files of
+// one downloader, instead of the one set with WithTransferer.
+func WithDownloaderTransferer(downloader string, t transfer.Transferer) Option {
+	return func(s *Syncer) {
+		if s.transferers == nil {
+			s.transferers = make(map[string]transfer.Transferer)
+		}
+		s.transferers[downloader] = t
+	}
+}
+
+// WithDryRun makes the syncer mark files as synced without transferring them,
+// and skip moving and deleting files.
+func WithDryRun(dryRun bool) Option {
+	return func(s *Syncer) {
+		s.dryRun = dryRun
+	}
+}
+
 // WithOnJobComplete sets a callback for when a job completes.
 func WithOnJobComplete(fn func(job *SyncJob)) Option {
 	return func(s *Syncer) {
@@ -303,6 +347,8 @@ func New(syncingPath string, opts ...Option) *Syncer {
 		syncingPath:   syncingPath,
 		maxConcurrent: defaultMaxConcurrent,
 		logger:        zerolog.Nop(),
+		freeSpace:     fileutil.FreeSpace,
+		stallRetries:  defaultStallRetries,
 		jobs:          make(map[string]*SyncJob),
 		semaphore:     make(chan struct{}, defaultMaxConcurrent),
 	}
@@ -317,17 +363,42 @@ func New(syncingPath string, opts ...Option) *Syncer {
 // PrepareShutdown prepares for graceful shutdown by suppressing expected errors.
 // Call this before cancelling contexts.
 func (s *Syncer) PrepareShutdown() {
-	if s.transferer != nil {
-		s.transferer.PrepareShutdown()
+	for _, t := range s.allTransferers() {
+		t.PrepareShutdown()
 	}
 }
 
 // Close releases resources held by the syncer.
 func (s *Syncer) Close() error {
+	var errs []error
+	for _, t := range s.allTransferers() {
+		if err := t.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// allTransferers returns the default transfer backend and the per-downloader
+// ones.
+func (s *Syncer) allTransferers() []transfer.Transferer {
+	var all []transfer.Transferer
 	if s.transferer != nil {
-		return s.transferer.Close()
+		all = append(all, s.transferer)
 	}
-	return nil
+	for _, t := range s.transferers {
+		all = append(all, t)
+	}
+	return all
+}
+
+// transfererFor returns the transfer backend for the files of a downloader, or
+// nil if none is configured.
+func (s *Syncer) transfererFor(downloader string) transfer.Transferer {
+	if t, ok := s.transferers[downloader]; ok {
+		return t
+	}
+	return s.transferer
 }
 
 // CreateJob creates a new sync job for a download.
@@ -393,6 +464,10 @@ func (s *Syncer) CreateJob(dl *download.Download, downloaderName, finalPath stri
 		job.TotalFiles++
 	}
 
+	if !s.dryRun {
+		s.markStaging(job.LocalBase)
+	}
+
 	s.jobs[dl.ID] = job
 	return job
 }
@@ -447,17 +522,16 @@ func (s *Syncer) GetSpeedHistory() []SpeedSample {
 	return result
 }
 
-// GetAggregateSpeed returns the current aggregate transfer speed from the transferer.
+// GetAggregateSpeed returns the current aggregate transfer speed from the transferers.
 func (s *Syncer) GetAggregateSpeed() int64 {
-	if s.transferer == nil {
-		return 0
+	var speed int64
+	for _, t := range s.allTransferers() {
+		speed += t.GetSpeed()
 	}
-	return s.transferer.GetSpeed()
+	return speed
 }
 
 // SyncFile syncs a single file from remote to local.
-//
-//nolint:funlen // file sync requires multiple phases
 func (s *Syncer) SyncFile(ctx context.Context, _ download.Downloader, job *SyncJob, file *FileProgress) error {
 	// Acquire semaphore
 	select {
@@ -467,14 +541,10 @@ func (s *Syncer) SyncFile(ctx context.Context, _ download.Downloader, job *SyncJ
 		return ctx.Err()
 	}
 
-	file.mu.Lock()
-	file.Status = FileStatusSyncing
-	file.StartedAt = time.Now()
-	file.mu.Unlock()
-
+	transferer := s.transfererFor(job.Downloader)
 	backendName := "unknown"
-	if s.transferer != nil {
-		backendName = s.transferer.Name()
+	if transferer != nil {
+		backendName = transferer.Name()
 	}
 
 	s.logger.Debug().
@@ -485,13 +555,244 @@ func (s *Syncer) SyncFile(ctx context.Context, _ download.Downloader, job *SyncJ
 		Str("backend", backendName).
 		Msg("starting file sync")
 
+	skip, err := s.startFile(job, file)
+	if err != nil || skip {
+		return err
+	}
+
+	// Transfer the file using the configured backend
+	if transferer == nil {
+		return errors.New("no transfer backend configured")
+	}
+
+	return s.finishFile(job, file, s.transferFile(ctx, job, file, transferer))
+}
+
+// transferFile transfers a file, retrying the transfers the watchdog cancels
+// as stalled up to the configured number of retries.
+func (s *Syncer) transferFile(
+	ctx context.Context,
+	job *SyncJob,
+	file *FileProgress,
+	transferer transfer.Transferer,
+) error {
+	req := transfer.Request{
+		RemotePath: file.RemotePath,
+		LocalPath:  file.LocalPath,
+		Size:       file.Size,
+	}
+
+	for attempt := 1; ; attempt++ {
+		watchCtx, cancel := s.watch(ctx, func() int64 {
+			transferred, _ := file.Progress()
+			return transferred
+		})
+		err := transferer.Transfer(watchCtx, req, func(p transfer.Progress) {
+			file.SetProgress(p.Transferred, p.BytesPerSec)
+		})
+		stallErr := stallCause(watchCtx)
+		cancel()
+
+		if err == nil || stallErr == nil {
+			return err
+		}
+		if !s.stalled(job, []*FileProgress{file}, stallErr, attempt) {
+			return stallErr
+		}
+	}
+}
+
+// acquireSlots takes a slot of the concurrency limit, waiting for it, and up
+// to n-1 more if they are free. It returns the number of slots taken, which
+// must be released with releaseSlots.
+func (s *Syncer) acquireSlots(ctx context.Context, n int) (int, error) {
+	select {
+	case s.semaphore <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	taken := 1
+	for taken < n {
+		select {
+		case s.semaphore <- struct{}{}:
+			taken++
+		default:
+			return taken, nil
+		}
+	}
+	return taken, nil
+}
+
+// releaseSlots releases n slots of the concurrency limit.
+func (s *Syncer) releaseSlots(n int) {
+	for range n {
+		<-s.semaphore
+	}
+}
+
+// syncBatch syncs small files of a job as one batch over the shared connections
+// of the transfer backend. The batch transfers as many files at once as it
+// holds slots of the concurrency limit: at least one, and any others that are
+// free when it starts. It returns the errors of the files that failed.
+func (s *Syncer) syncBatch(
+	ctx context.Context,
+	job *SyncJob,
+	transferer transfer.BatchTransferer,
+	files []*FileProgress,
+) []error {
+	workers, err := s.acquireSlots(ctx, len(files))
+	if err != nil {
+		return []error{err}
+	}
+	defer s.releaseSlots(workers)
+
+	s.logger.Debug().
+		Str("job", job.ID).
+		Int("files", len(files)).
+		Int("workers", workers).
+		Str("backend", transferer.Name()).
+		Msg("starting batch sync")
+
+	var errs []error
+	var pending []*FileProgress
+	for _, file := range files {
+		skip, err := s.startFile(job, file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("file %s: %w", file.Path, err))
+		}
+		if err != nil || skip {
+			continue
+		}
+		pending = append(pending, file)
+	}
+
+	// Retry the files of a batch the watchdog cancelled as another batch
+	for attempt := 1; len(pending) > 0; attempt++ {
+		batch := pending
+		watchCtx, cancel := s.watch(ctx, func() int64 { return totalTransferred(batch) })
+		remaining, batchErrs, batchErr := s.transferBatch(watchCtx, job, transferer, workers, batch)
+		stallErr := stallCause(watchCtx)
+		cancel()
+		errs = append(errs, batchErrs...)
+
+		if stallErr != nil && len(remaining) > 0 {
+			if s.stalled(job, remaining, stallErr, attempt) {
+				pending = remaining
+				continue
+			}
+			batchErr = stallErr
+		}
+
+		// Files the batch did not get to fail with the batch's error
+		if batchErr == nil {
+			batchErr = errors.New("file was not transferred")
+		}
+		for _, file := range remaining {
+			errs = append(errs, fmt.Errorf("file %s: %w", file.Path, s.finishFile(job, file, batchErr)))
+		}
+		break
+	}
+
+	return errs
+}
+
+// transferBatch transfers files as one batch, up to workers at a time. It
+// returns the files that were not transferred, the errors of the files that
+// failed and the error of the batch. Files whose transfer was cancelled by the
+// watchdog count as not transferred, so that they can be retried.
+func (s *Syncer) transferBatch(
+	ctx context.Context,
+	job *SyncJob,
+	transferer transfer.BatchTransferer,
+	workers int,
+	files []*FileProgress,
+) ([]*FileProgress, []error, error) {
+	reqs := make([]transfer.Request, len(files))
+	for i, file := range files {
+		reqs[i] = transfer.Request{
+			RemotePath: file.RemotePath,
+			LocalPath:  file.LocalPath,
+			Size:       file.Size,
+		}
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	done := make([]bool, len(files))
+	batchErr := transferer.TransferBatch(ctx, reqs, workers,
+		func(i int, p transfer.Progress) {
+			files[i].SetProgress(p.Transferred, p.BytesPerSec)
+		},
+		func(i int, err error) {
+			if err != nil && stallCause(ctx) != nil {
+				return
+			}
+			fileErr := s.finishFile(job, files[i], err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			done[i] = true
+			if fileErr != nil {
+				errs = append(errs, fmt.Errorf("file %s: %w", files[i].Path, fileErr))
+			}
+		})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var remaining []*FileProgress
+	for i, file := range files {
+		if !done[i] {
+			remaining = append(remaining, file)
+		}
+	}
+	return remaining, errs, batchErr
+}
+
+// totalTransferred returns the number of bytes transferred of files.
+func totalTransferred(files []*FileProgress) int64 {
+	var total int64
+	for _, file := range files {
+		transferred, _ := file.Progress()
+		total += transferred
+	}
+	return total
+}
+
+// startFile marks a file as syncing and prepares its local directory. It
+// reports whether the file needs no transfer, because this is a dry run or the
+// file already exists locally.
+func (s *Syncer) startFile(job *SyncJob, file *FileProgress) (bool, error) {
+	file.mu.Lock()
+	file.Status = FileStatusSyncing
+	file.StartedAt = time.Now()
+	file.mu.Unlock()
+
+	if s.dryRun {
+		s.logger.Info().
+			Str("job", job.ID).
+			Str("file", file.Path).
+			Str("remote", file.RemotePath).
+			Str("local", file.LocalPath).
+			Int64("size", file.Size).
+			Msg("dry run: would transfer file")
+
+		file.mu.Lock()
+		file.Status = FileStatusComplete
+		file.Transferred = file.Size
+		file.CompletedAt = time.Now()
+		file.mu.Unlock()
+		return true, nil
+	}
+
 	// Create local directory
 	if err := os.MkdirAll(filepath.Dir(file.LocalPath), 0750); err != nil {
 		file.mu.Lock()
 		file.Status = FileStatusError
 		file.Error = err
 		file.mu.Unlock()
-		return fmt.Errorf("failed to create directory: %w", err)
+		return false, fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	// Check if file already exists and is complete
@@ -503,30 +804,22 @@ func (s *Syncer) SyncFile(ctx context.Context, _ download.Downloader, job *SyncJ
 			file.CompletedAt = time.Now()
 			file.mu.Unlock()
 			s.logger.Debug().Str("file", file.Path).Msg("file already exists, skipping")
-			return nil
+			return true, nil
 		}
 	}
 
-	// Transfer the file using the configured backend
-	if s.transferer == nil {
-		return errors.New("no transfer backend configured")
-	}
-
-	req := transfer.Request{
-		RemotePath: file.RemotePath,
-		LocalPath:  file.LocalPath,
-		Size:       file.Size,
-	}
+	return false, nil
+}
 
-	err := s.transferer.Transfer(ctx, req, func(p transfer.Progress) {
-		file.SetProgress(p.Transferred, p.BytesPerSec)
-	})
-	if err != nil {
+// finishFile records the result of transferring a file. If the transfer
+// succeeded, it verifies the local file and marks the file complete.
+func (s *Syncer) finishFile(job *SyncJob, file *FileProgress, transferErr error) error {
+	if transferErr != nil {
 		file.mu.Lock()
 		file.Status = FileStatusError
-		file.Error = err
+		file.Error = transferErr
 		file.mu.Unlock()
-		return fmt.Errorf("transfer failed: %w", err)
+		return fmt.Errorf("transfer failed: %w", transferErr)
 	}
 
 	// Verify file was transferred completely
@@ -574,6 +867,33 @@ func (s *Syncer) SyncFile(ctx context.Context, _ download.Downloader, job *SyncJ
 	return nil
 }
 
+// batchFiles splits the files to sync into those transferred together as a
+// batch and those transferred on their own. A batch is only used if the
+// transfer backend supports it and there are at least batchMinFiles files of
+// up to batchMaxFileSize.
+func (s *Syncer) batchFiles(
+	transferer transfer.Transferer,
+	files []*FileProgress,
+) (transfer.BatchTransferer, []*FileProgress, []*FileProgress) {
+	batcher, ok := transferer.(transfer.BatchTransferer)
+	if !ok || s.dryRun {
+		return nil, nil, files
+	}
+
+	var batch, single []*FileProgress
+	for _, f := range files {
+		if f.Size <= batchMaxFileSize {
+			batch = append(batch, f)
+		} else {
+			single = append(single, f)
+		}
+	}
+	if len(batch) < batchMinFiles {
+		return nil, nil, files
+	}
+	return batcher, batch, single
+}
+
 // SyncJob syncs all ready files in a job.
 //
 //nolint:gocognit,funlen // sync logic requires multiple phases and error handling paths
@@ -604,8 +924,8 @@ func (s *Syncer) SyncJob(ctx context.Context, dl download.Downloader, job *SyncJ
 	job.mu.Unlock()
 
 	backendName := "unknown"
-	if s.transferer != nil {
-		backendName = s.transferer.Name()
+	if transferer := s.transfererFor(job.Downloader); transferer != nil {
+		backendName = transferer.Name()
 	}
 
 	s.logger.Info().
@@ -627,10 +947,8 @@ func (s *Syncer) SyncJob(ctx context.Context, dl download.Downloader, job *SyncJ
 		fileStates[f.Path] = f.State
 	}
 
-	// Sync files that are complete in the download
-	var wg sync.WaitGroup
-	errChan := make(chan error, len(job.Files))
-
+	// Collect files that are complete in the download
+	var ready []*FileProgress
 	for _, file := range job.Files {
 		// Check if file is complete in downloader
 		if state, ok := fileStates[file.Path]; ok && state != download.FileStateComplete {
@@ -650,13 +968,28 @@ func (s *Syncer) SyncJob(ctx context.Context, dl download.Downloader, job *SyncJ
 			continue
 		}
 
-		wg.Add(1)
-		go func(f *FileProgress) {
-			defer wg.Done()
-			if syncErr := s.SyncFile(mergedCtx, dl, job, f); syncErr != nil {
-				errChan <- fmt.Errorf("file %s: %w", f.Path, syncErr)
+		ready = append(ready, file)
+	}
+
+	// Sync many small files as a batch and the others on their own
+	var wg sync.WaitGroup
+	errChan := make(chan error, len(job.Files))
+
+	batcher, batch, single := s.batchFiles(s.transfererFor(job.Downloader), ready)
+	if len(batch) > 0 {
+		wg.Go(func() {
+			for _, batchErr := range s.syncBatch(mergedCtx, job, batcher, batch) {
+				errChan <- batchErr
+			}
+		})
+	}
+
+	for _, file := range single {
+		wg.Go(func() {
+			if syncErr := s.SyncFile(mergedCtx, dl, job, file); syncErr != nil {
+				errChan <- fmt.Errorf("file %s: %w", file.Path, syncErr)
 			}
-		}(file)
+		})
 	}
 
 	wg.Wait()
@@ -710,7 +1043,9 @@ func (s *Syncer) SyncJob(ctx context.Context, dl download.Downloader, job *SyncJ
 	return nil
 }
 
-// MoveToFinal moves synced files from staging to final destination.
+// MoveToFinal moves synced files from staging to final destination. The files
+// are first gathered next to the destination, then published together, so apps
+// watching the final path never see a partially moved download.
 func (s *Syncer) MoveToFinal(job *SyncJob) error {
 	s.logger.Info().
 		Str("id", job.ID).
@@ -718,39 +1053,40 @@ func (s *Syncer) MoveToFinal(job *SyncJob) error {
 		Str("to", job.FinalPath).
 		Msg("moving to final destination")
 
+	if s.dryRun {
+		s.logger.Info().Str("id", job.ID).Msg("dry run: not moving files")
+		return nil
+	}
+
 	// Create final directory
 	if err := os.MkdirAll(job.FinalPath, 0750); err != nil {
 		return fmt.Errorf("failed to create final directory: %w", err)
 	}
 
-	// Move each file
-	for _, file := range job.Files {
-		file.mu.RLock()
-		status := file.Status
-		file.mu.RUnlock()
+	publishDir := job.PublishDir()
+	s.recordPublishDir(job.LocalBase, publishDir)
 
-		if status != FileStatusComplete && status != FileStatusSkipped {
-			continue
+	paths, err := gatherFiles(job, publishDir)
+	if err == nil {
+		err = publishFiles(publishDir, job.FinalPath, paths)
+		if err != nil {
+			err = fmt.Errorf("failed to publish files: %w", err)
 		}
-
-		finalFilePath := filepath.Join(job.FinalPath, file.Path)
-
-		// Create parent directory
-		if err := os.MkdirAll(filepath.Dir(finalFilePath), 0750); err != nil {
-			return fmt.Errorf("failed to create directory for %s: %w", file.Path, err)
-		}
-
-		// Move file
-		if renameErr := os.Rename(file.LocalPath, finalFilePath); renameErr != nil {
-			// If rename fails (cross-device), try copy+delete
-			if copyErr := fileutil.CopyFile(file.LocalPath, finalFilePath); copyErr != nil {
-				return fmt.Errorf("failed to move file %s: %w", file.Path, copyErr)
-			}
-			_ = os.Remove(file.LocalPath)
+	}
+	if err != nil {
+		// Don't leave the publish directory behind in the final path
+		if restoreErr := restoreFiles(job, publishDir); restoreErr != nil {
+			s.logger.Warn().
+				Err(restoreErr).
+				Str("id", job.ID).
+				Str("path", publishDir).
+				Msg("failed to move gathered files back to staging")
 		}
+		return err
 	}
 
-	// Clean up staging directory
+	// Clean up the publish and staging directories
+	_ = os.RemoveAll(publishDir)
 	_ = os.RemoveAll(job.LocalBase)
 
 	return nil
@@ -775,6 +1111,10 @@ func (s *Syncer) CancelJob(id string) error {
 		Msg("cancelled sync job")
 
 	// Clean up staging directory
+	if s.dryRun {
+		s.logger.Info().Str("path", job.LocalBase).Msg("dry run: not removing staging directory")
+		return nil
+	}
 	if job.LocalBase != "" {
 		if err := os.RemoveAll(job.LocalBase); err != nil {
 			s.logger.Warn().
@@ -797,3 +1137,143 @@ func (s *Syncer) RemoveJob(id string) {
 	defer s.jobsMu.Unlock()
 	delete(s.jobs, id)
 }
+
+// RemoveOrphans deletes staging directories under the syncing path that do not
+// belong to a known job, such as those left behind by a crash. Staging directories
+// live at <syncingPath>/<downloader>/<id>, and only those carrying the marker file
+// written by CreateJob are removed, so a syncing path that was set to a directory
+// holding anything else loses nothing. It returns the paths that were removed.
+func (s *Syncer) RemoveOrphans() ([]string, error) {
+	if s.dryRun {
+		return nil, nil
+	}
+
+	// Take the directories of the known jobs, so the syncing path can be
+	// inspected without holding the jobs lock
+	s.jobsMu.Lock()
+	owned := make(map[string]bool, len(s.jobs))
+	for _, job := range s.jobs {
+		owned[job.LocalBase] = true
+	}
+	s.jobsMu.Unlock()
+
+	downloaders, err := os.ReadDir(s.syncingPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	var errs []error
+	for _, d := range downloaders {
+		if !d.IsDir() {
+			continue
+		}
+
+		downloaderPath := filepath.Join(s.syncingPath, d.Name())
+		entries, readErr := os.ReadDir(downloaderPath)
+		if readErr != nil {
+			errs = append(errs, readErr)
+			continue
+		}
+
+		for _, e := range entries {
+			path := filepath.Join(downloaderPath, e.Name())
+			if owned[path] || !isStagingDir(e, path) {
+				continue
+			}
+
+			if publishDir := orphanPublishDir(path); publishDir != "" && s.isOrphan(path) {
+				s.logger.Info().Str("path", publishDir).Msg("removing orphaned publish directory")
+				if rmErr := os.RemoveAll(publishDir); rmErr != nil {
+					errs = append(errs, rmErr)
+					continue
+				}
+			}
+
+			if !s.isOrphan(path) {
+				continue
+			}
+			s.logger.Info().Str("path", path).Msg("removing orphaned staging directory")
+			if rmErr := os.RemoveAll(path); rmErr != nil {
+				errs = append(errs, rmErr)
+				continue
+			}
+			removed = append(removed, path)
+		}
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// isOrphan reports whether the staging directory at path still carries its
+// marker and belongs to no job. It is checked right before the directory is
+// removed, since a job may have been created for it in the meantime.
+func (s *Syncer) isOrphan(path string) bool {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	for _, job := range s.jobs {
+		if job.LocalBase == path {
+			return false
+		}
+	}
+	info, err := os.Lstat(filepath.Join(path, stagingMarker))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// stagingMarker is the file CreateJob writes into a job's staging directory,
+// marking it as one RemoveOrphans may delete. MoveToFinal records the job's
+// publish directory in it.
+const stagingMarker = ".seedreap-staging"
+
+// markStaging creates a job's staging directory with its marker file. An
+// existing marker, left by an earlier run, is kept with its contents.
+func (s *Syncer) markStaging(dir string) {
+	err := os.MkdirAll(dir, 0750)
+	if err == nil {
+		var f *os.File
+		f, err = os.OpenFile(filepath.Join(dir, stagingMarker), os.O_WRONLY|os.O_CREATE, 0600)
+		if err == nil {
+			err = f.Close()
+		}
+	}
+	if err != nil {
+		s.logger.Warn().Err(err).Str("path", dir).Msg("failed to mark staging directory")
+	}
+}
+
+// recordPublishDir writes the publish directory of a job into the marker of its
+// staging directory, so RemoveOrphans can delete it after a crash.
+func (s *Syncer) recordPublishDir(dir, publishDir string) {
+	if err := os.WriteFile(filepath.Join(dir, stagingMarker), []byte(publishDir), 0600); err != nil {
+		s.logger.Warn().Err(err).Str("path", dir).Msg("failed to record publish directory")
+	}
+}
+
+// orphanPublishDir returns the publish directory recorded in the marker of the
+// staging directory at path, or "" if there is none. Only a publish directory
+// named after the job is returned.
+func orphanPublishDir(path string) string {
+	data, err := os.ReadFile(filepath.Join(path, stagingMarker))
+	if err != nil {
+		return ""
+	}
+	publishDir := string(data)
+	if !filepath.IsAbs(publishDir) || filepath.Base(publishDir) != publishDirPrefix+filepath.Base(path) {
+		return ""
+	}
+	return publishDir
+}
+
+// isStagingDir reports whether the entry at path is a staging directory with
+// its marker file.
+func isStagingDir(e fs.DirEntry, path string) bool {
+	if !e.IsDir() {
+		return false
+	}
+	info, err := os.Lstat(filepath.Join(path, stagingMarker))
+	return err == nil && info.Mode().IsRegular()
+}
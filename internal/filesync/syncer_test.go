@@ -3,10 +3,12 @@ package filesync_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -441,6 +443,21 @@ func TestGetAggregateSpeed(t *testing.T) {
 		assert.Equal(t, int64(500*1024), speed)
 	})
 
+	t.Run("WithDownloaderTransferer", func(t *testing.T) {
+		mockTransfer := testutil.NewMockTransferer()
+		mockTransfer.SetSpeed(500 * 1024)
+		webTransfer := testutil.NewMockTransferer()
+		webTransfer.SetSpeed(200 * 1024)
+
+		syncer := filesync.New("/syncing",
+			filesync.WithTransferer(mockTransfer),
+			filesync.WithDownloaderTransferer("web", webTransfer),
+		)
+
+		speed := syncer.GetAggregateSpeed()
+		assert.Equal(t, int64(700*1024), speed)
+	})
+
 	t.Run("WithoutTransferer", func(t *testing.T) {
 		syncer := filesync.New("/syncing")
 
@@ -477,6 +494,29 @@ func TestSyncFile(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("UsesDownloaderTransferer", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockTransferer()
+		webTransfer := testutil.NewMockTransferer()
+		mockDL := testutil.NewMockDownloader("web")
+
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+			filesync.WithDownloaderTransferer("web", webTransfer),
+			filesync.WithMaxConcurrent(1),
+		)
+
+		dl := createTestDownload("hash1", "TestTorrent", "tv")
+		job := syncer.CreateJob(dl, "web", filepath.Join(tmpDir, "downloads/tv"))
+
+		err := syncer.SyncFile(context.Background(), mockDL, job, job.Files[0])
+		require.NoError(t, err)
+
+		assert.Len(t, webTransfer.GetTransferCalls(), 1)
+		assert.Empty(t, mockTransfer.GetTransferCalls())
+	})
+
 	t.Run("SkipsExistingFile", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		mockTransfer := testutil.NewMockTransferer()
@@ -732,6 +772,223 @@ func TestSyncerSyncJob(t *testing.T) {
 	})
 }
 
+func TestSyncerSyncJobBatch(t *testing.T) {
+	t.Run("BatchesManySmallFiles", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockBatchTransferer()
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		var completed sync.Map
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+			filesync.WithMaxConcurrent(2),
+			filesync.WithOnFileComplete(func(_ *filesync.SyncJob, file *filesync.FileProgress) {
+				completed.Store(file.Path, true)
+			}),
+		)
+
+		// 20 small tracks and one large file that is transferred on its own
+		dl := createDownloadWithFiles("hash1", "Discography", 20, 64*1024)
+		dl.Files = append(dl.Files, download.File{
+			Path:     "Discography/booklet.iso",
+			Size:     20 * 1024 * 1024,
+			State:    download.FileStateComplete,
+			Priority: 1,
+		})
+		mockDL.AddDownload(dl, dl.Files)
+
+		job := syncer.CreateJob(dl, "test-downloader", filepath.Join(tmpDir, "downloads/music"))
+
+		err := syncer.SyncJob(context.Background(), mockDL, job)
+		require.NoError(t, err)
+
+		_, status := job.GetProgress()
+		assert.Equal(t, filesync.FileStatusComplete, status)
+
+		batches := mockTransfer.GetBatchCalls()
+		require.Len(t, batches, 1)
+		assert.Len(t, batches[0], 20)
+
+		transfers := mockTransfer.GetTransferCalls()
+		require.Len(t, transfers, 1)
+		assert.Equal(t, "/remote/downloads/Discography/booklet.iso", transfers[0].RemotePath)
+
+		for _, file := range job.Files {
+			assert.Equal(t, filesync.FileStatusComplete, file.GetStatus(), file.Path)
+			assert.Equal(t, file.Size, file.Transferred, file.Path)
+			_, ok := completed.Load(file.Path)
+			assert.True(t, ok, "file complete callback for %s", file.Path)
+		}
+	})
+
+	t.Run("FewFilesTransferredOnTheirOwn", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockBatchTransferer()
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+		)
+
+		dl := createDownloadWithFiles("hash1", "Album", 3, 64*1024)
+		mockDL.AddDownload(dl, dl.Files)
+
+		job := syncer.CreateJob(dl, "test-downloader", filepath.Join(tmpDir, "downloads/music"))
+
+		require.NoError(t, syncer.SyncJob(context.Background(), mockDL, job))
+
+		assert.Empty(t, mockTransfer.GetBatchCalls())
+		assert.Len(t, mockTransfer.GetTransferCalls(), 3)
+	})
+
+	t.Run("FileErrorsInBatch", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockBatchTransferer()
+		mockTransfer.OnTransfer = func(_ context.Context, req transfer.Request, _ transfer.ProgressFunc) error {
+			if strings.HasSuffix(req.RemotePath, "track-03.flac") {
+				return errors.New("permission denied")
+			}
+			if err := os.MkdirAll(filepath.Dir(req.LocalPath), 0750); err != nil {
+				return err
+			}
+			return os.WriteFile(req.LocalPath, make([]byte, req.Size), 0600)
+		}
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+		)
+
+		dl := createDownloadWithFiles("hash1", "Discography", 20, 1024)
+		mockDL.AddDownload(dl, dl.Files)
+
+		job := syncer.CreateJob(dl, "test-downloader", filepath.Join(tmpDir, "downloads/music"))
+
+		require.NoError(t, syncer.SyncJob(context.Background(), mockDL, job))
+
+		_, status := job.GetProgress()
+		assert.Equal(t, filesync.FileStatusError, status)
+		assert.ErrorContains(t, job.Error, "track-03.flac")
+
+		for _, file := range job.Files {
+			if strings.HasSuffix(file.Path, "track-03.flac") {
+				assert.Equal(t, filesync.FileStatusError, file.GetStatus())
+			} else {
+				assert.Equal(t, filesync.FileStatusComplete, file.GetStatus(), file.Path)
+			}
+		}
+	})
+
+	t.Run("BatchFailsToStart", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockBatchTransferer()
+		mockTransfer.OnBatch = func(
+			_ context.Context,
+			_ []transfer.Request,
+			_ int,
+			_ transfer.BatchProgressFunc,
+			_ transfer.BatchDoneFunc,
+		) error {
+			return errors.New("connection refused")
+		}
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+		)
+
+		dl := createDownloadWithFiles("hash1", "Discography", 20, 1024)
+		mockDL.AddDownload(dl, dl.Files)
+
+		job := syncer.CreateJob(dl, "test-downloader", filepath.Join(tmpDir, "downloads/music"))
+
+		require.NoError(t, syncer.SyncJob(context.Background(), mockDL, job))
+
+		_, status := job.GetProgress()
+		assert.Equal(t, filesync.FileStatusError, status)
+		for _, file := range job.Files {
+			assert.Equal(t, filesync.FileStatusError, file.GetStatus(), file.Path)
+			assert.ErrorContains(t, file.Error, "connection refused")
+		}
+	})
+
+	t.Run("BatchUsesFreeSlotsOfConcurrencyLimit", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockBatchTransferer()
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		// A large file of another download holds one of the three slots
+		started := make(chan struct{})
+		release := make(chan struct{})
+		mockTransfer.OnTransfer = func(_ context.Context, req transfer.Request, _ transfer.ProgressFunc) error {
+			if req.Size > 1024 {
+				close(started)
+				<-release
+			}
+			if err := os.MkdirAll(filepath.Dir(req.LocalPath), 0750); err != nil {
+				return err
+			}
+			return os.WriteFile(req.LocalPath, make([]byte, req.Size), 0600)
+		}
+
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+			filesync.WithMaxConcurrent(3),
+		)
+
+		large := createDownloadWithFiles("hash1", "Movie", 1, 20*1024*1024)
+		mockDL.AddDownload(large, large.Files)
+		largeJob := syncer.CreateJob(large, "test-downloader", filepath.Join(tmpDir, "downloads/movies"))
+
+		largeDone := make(chan error, 1)
+		go func() { largeDone <- syncer.SyncJob(context.Background(), mockDL, largeJob) }()
+		<-started
+
+		dl := createDownloadWithFiles("hash2", "Discography", 20, 1024)
+		mockDL.AddDownload(dl, dl.Files)
+		job := syncer.CreateJob(dl, "test-downloader", filepath.Join(tmpDir, "downloads/music"))
+
+		require.NoError(t, syncer.SyncJob(context.Background(), mockDL, job))
+		assert.Equal(t, []int{2}, mockTransfer.GetBatchWorkers())
+
+		close(release)
+		require.NoError(t, <-largeDone)
+	})
+
+	t.Run("SkipsExistingFiles", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockBatchTransferer()
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+		)
+
+		dl := createDownloadWithFiles("hash1", "Discography", 20, 1024)
+		mockDL.AddDownload(dl, dl.Files)
+
+		job := syncer.CreateJob(dl, "test-downloader", filepath.Join(tmpDir, "downloads/music"))
+
+		// The first file is already in staging
+		existing := job.Files[0]
+		require.NoError(t, os.MkdirAll(filepath.Dir(existing.LocalPath), 0750))
+		require.NoError(t, os.WriteFile(existing.LocalPath, make([]byte, existing.Size), 0600))
+
+		require.NoError(t, syncer.SyncJob(context.Background(), mockDL, job))
+
+		batches := mockTransfer.GetBatchCalls()
+		require.Len(t, batches, 1)
+		assert.Len(t, batches[0], 19)
+		assert.Equal(t, filesync.FileStatusSkipped, existing.GetStatus())
+	})
+}
+
 // --- MoveToFinal Tests ---
 
 func TestMoveToFinal(t *testing.T) {
@@ -801,6 +1058,508 @@ func TestMoveToFinal(t *testing.T) {
 		_, err = os.Stat(finalFile2)
 		assert.True(t, os.IsNotExist(err))
 	})
+
+	t.Run("LeavesNoPublishDirectory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		syncer := filesync.New(filepath.Join(tmpDir, "syncing"))
+
+		dl := createTestDownload("hash1", "TestTorrent", "tv")
+		finalPath := filepath.Join(tmpDir, "downloads/tv")
+		job := syncer.CreateJob(dl, "test-downloader", finalPath)
+		writeStagedFiles(t, job.Files...)
+
+		require.NoError(t, syncer.MoveToFinal(job))
+
+		entries, err := os.ReadDir(finalPath)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "TestTorrent", entries[0].Name())
+	})
+
+	t.Run("PublishesNothingUntilAllFilesAreInPlace", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		syncer := filesync.New(filepath.Join(tmpDir, "syncing"))
+
+		dl := createTestDownload("hash1", "TestTorrent", "tv")
+		finalPath := filepath.Join(tmpDir, "downloads/tv")
+		job := syncer.CreateJob(dl, "test-downloader", finalPath)
+		writeStagedFiles(t, job.Files[0])
+
+		// The second file is complete but missing from staging, so moving it fails
+		job.Files[1].Status = filesync.FileStatusComplete
+		require.Error(t, syncer.MoveToFinal(job))
+		assert.NoDirExists(t, filepath.Join(finalPath, "TestTorrent"), "no file should be published")
+		assert.NoDirExists(t, job.PublishDir(), "publish directory should be removed")
+		assert.FileExists(t, job.Files[0].LocalPath, "gathered file should be back in staging")
+
+		// Retrying publishes the file moved back by the first attempt too
+		writeStagedFiles(t, job.Files[1])
+		require.NoError(t, syncer.MoveToFinal(job))
+		for _, f := range job.Files {
+			assert.FileExists(t, filepath.Join(finalPath, f.Path))
+		}
+		assert.NoDirExists(t, filepath.Join(finalPath, ".seedreap-hash1"))
+	})
+
+	t.Run("KeepsFilesAlreadyInPlace", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		finalPath := filepath.Join(tmpDir, "downloads/tv")
+		dl := createTestDownload("hash1", "TestTorrent", "tv")
+
+		// The first file was moved before a restart
+		existing := filepath.Join(finalPath, dl.Files[0].Path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(existing), 0750))
+		require.NoError(t, os.WriteFile(existing, make([]byte, dl.Files[0].Size), 0600))
+
+		// A file that is not part of the download, like a subtitle added by hand
+		extra := filepath.Join(finalPath, "TestTorrent", "Subs", "episode.srt")
+		require.NoError(t, os.MkdirAll(filepath.Dir(extra), 0750))
+		require.NoError(t, os.WriteFile(extra, []byte("subtitle"), 0600))
+
+		syncer := filesync.New(filepath.Join(tmpDir, "syncing"))
+		job := syncer.CreateJob(dl, "test-downloader", finalPath)
+		require.Equal(t, filesync.FileStatusSkipped, job.Files[0].GetStatus())
+		writeStagedFiles(t, job.Files[1])
+
+		require.NoError(t, syncer.MoveToFinal(job))
+		for _, f := range job.Files {
+			assert.FileExists(t, filepath.Join(finalPath, f.Path))
+		}
+		assert.FileExists(t, extra)
+
+		entries, err := os.ReadDir(finalPath)
+		require.NoError(t, err)
+		require.Len(t, entries, 1, "only the download should be left in the final path")
+		assert.Equal(t, "TestTorrent", entries[0].Name())
+	})
+}
+
+// writeStagedFiles creates files at their staging location and marks them
+// complete.
+func writeStagedFiles(t *testing.T, files ...*filesync.FileProgress) {
+	t.Helper()
+
+	for _, f := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(f.LocalPath), 0750))
+		require.NoError(t, os.WriteFile(f.LocalPath, make([]byte, f.Size), 0600))
+		f.Status = filesync.FileStatusComplete
+	}
+}
+
+// --- Dry Run Tests ---
+
+func TestDryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	syncingPath := filepath.Join(tmpDir, "syncing")
+	mockTransfer := testutil.NewMockTransferer()
+	mockDL := testutil.NewMockDownloader("test-downloader")
+
+	syncer := filesync.New(
+		syncingPath,
+		filesync.WithTransferer(mockTransfer),
+		filesync.WithDryRun(true),
+	)
+
+	dl := createTestDownload("hash1", "TestTorrent", "tv")
+	mockDL.AddDownload(dl, dl.Files)
+	finalPath := filepath.Join(tmpDir, "downloads/tv")
+	job := syncer.CreateJob(dl, "test-downloader", finalPath)
+
+	// An orphaned staging directory from an earlier run
+	orphan := filepath.Join(syncingPath, "test-downloader", "old")
+	require.NoError(t, os.MkdirAll(orphan, 0750))
+
+	require.NoError(t, syncer.SyncJob(context.Background(), mockDL, job))
+	_, status := job.GetProgress()
+	assert.Equal(t, filesync.FileStatusComplete, status, "files should be marked synced")
+	assert.Empty(t, mockTransfer.GetTransferCalls(), "nothing should be transferred")
+
+	require.NoError(t, syncer.MoveToFinal(job))
+	require.NoError(t, syncer.CancelJob(job.ID))
+	removed, err := syncer.RemoveOrphans()
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+
+	assert.NoDirExists(t, finalPath, "nothing should be moved")
+	assert.NoDirExists(t, job.LocalBase, "no staging directory should be created")
+	assert.DirExists(t, orphan, "orphans should not be removed")
+}
+
+// --- RemoveOrphans Tests ---
+
+func TestRemoveOrphans(t *testing.T) {
+	t.Run("RemovesDirectoriesWithoutJobs", func(t *testing.T) {
+		syncingPath := filepath.Join(t.TempDir(), "syncing")
+		syncer := filesync.New(syncingPath)
+
+		job := syncer.CreateJob(createTestDownload("hash1", "Active", "tv"), "seedbox", "/downloads/tv")
+		require.DirExists(t, job.LocalBase)
+
+		// Jobs of a previous run that crashed
+		previous := filesync.New(syncingPath)
+		orphan := previous.CreateJob(createTestDownload("crashed", "Crashed", "tv"), "seedbox", "/downloads/tv").LocalBase
+		require.NoError(t, os.MkdirAll(filepath.Join(orphan, "sub"), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(orphan, "sub", "file.mkv"), []byte("x"), 0600))
+		otherOrphan := previous.CreateJob(createTestDownload("hash9", "Old", "tv"), "old-seedbox", "/downloads/tv").LocalBase
+
+		// Stray files at the top level are left alone
+		stray := filepath.Join(syncingPath, "README")
+		require.NoError(t, os.WriteFile(stray, []byte("x"), 0600))
+
+		removed, err := syncer.RemoveOrphans()
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{orphan, otherOrphan}, removed)
+
+		assert.DirExists(t, job.LocalBase)
+		assert.NoDirExists(t, orphan)
+		assert.NoDirExists(t, otherOrphan)
+		assert.FileExists(t, stray)
+	})
+
+	t.Run("RemovesPublishDirectoryOfInterruptedPublish", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		syncingPath := filepath.Join(tmpDir, "syncing")
+		finalPath := filepath.Join(tmpDir, "downloads", "tv")
+		syncer := filesync.New(syncingPath)
+
+		// A job of a previous run that crashed while publishing its files
+		crashed := filesync.New(syncingPath).CreateJob(createTestDownload("crashed", "Crashed", "tv"), "seedbox", finalPath)
+		publishDir := crashed.PublishDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(publishDir, "Crashed"), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(publishDir, "Crashed", "file.mkv"), []byte("x"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(crashed.LocalBase, ".seedreap-staging"), []byte(publishDir), 0600))
+
+		removed, err := syncer.RemoveOrphans()
+		require.NoError(t, err)
+		assert.Equal(t, []string{crashed.LocalBase}, removed)
+		assert.NoDirExists(t, publishDir)
+		assert.DirExists(t, finalPath)
+	})
+
+	t.Run("KeepsDirectoriesWithoutMarker", func(t *testing.T) {
+		// A syncing path mistakenly pointing at a library
+		syncingPath := t.TempDir()
+		syncer := filesync.New(syncingPath)
+
+		show := filepath.Join(syncingPath, "tv", "Some Show")
+		require.NoError(t, os.MkdirAll(show, 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(show, "episode.mkv"), []byte("x"), 0600))
+		movie := filepath.Join(syncingPath, "movies", "Some Movie.mkv")
+		require.NoError(t, os.MkdirAll(filepath.Dir(movie), 0750))
+		require.NoError(t, os.WriteFile(movie, []byte("x"), 0600))
+
+		removed, err := syncer.RemoveOrphans()
+		require.NoError(t, err)
+		assert.Empty(t, removed)
+		assert.DirExists(t, show)
+		assert.FileExists(t, movie)
+	})
+
+	t.Run("MissingSyncingPath", func(t *testing.T) {
+		syncer := filesync.New(filepath.Join(t.TempDir(), "does-not-exist"))
+
+		removed, err := syncer.RemoveOrphans()
+		require.NoError(t, err)
+		assert.Empty(t, removed)
+	})
+}
+
+// --- Watchdog Tests ---
+
+// stallRecorder collects the stalls reported by the watchdog.
+type stallRecorder struct {
+	mu     sync.Mutex
+	stalls []filesync.Stall
+}
+
+func (r *stallRecorder) record(stall filesync.Stall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stalls = append(r.stalls, stall)
+}
+
+func (r *stallRecorder) get() []filesync.Stall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]filesync.Stall(nil), r.stalls...)
+}
+
+// hangUntilCancelled is a transfer that makes no progress.
+func hangUntilCancelled(ctx context.Context, _ transfer.Request, _ transfer.ProgressFunc) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// writeRequestFile creates the local file of a transfer request.
+func writeRequestFile(req transfer.Request) error {
+	if err := os.MkdirAll(filepath.Dir(req.LocalPath), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(req.LocalPath, make([]byte, req.Size), 0600)
+}
+
+func TestWatchdog(t *testing.T) {
+	const stallTimeout = 50 * time.Millisecond
+
+	t.Run("RetriesStalledTransfer", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockTransferer()
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		var calls atomic.Int32
+		mockTransfer.OnTransfer = func(ctx context.Context, req transfer.Request, onProgress transfer.ProgressFunc) error {
+			if calls.Add(1) == 1 {
+				onProgress(transfer.Progress{Transferred: 1024})
+				return hangUntilCancelled(ctx, req, onProgress)
+			}
+			onProgress(transfer.Progress{Transferred: req.Size})
+			return writeRequestFile(req)
+		}
+
+		var stalls stallRecorder
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+			filesync.WithStallTimeout(stallTimeout),
+			filesync.WithOnStalled(stalls.record),
+		)
+
+		job := syncer.CreateJob(createTestDownload("hash1", "TestTorrent", "tv"), "test-downloader", tmpDir)
+
+		require.NoError(t, syncer.SyncFile(context.Background(), mockDL, job, job.Files[0]))
+		assert.Equal(t, filesync.FileStatusComplete, job.Files[0].GetStatus())
+		assert.Equal(t, int32(2), calls.Load())
+
+		got := stalls.get()
+		require.Len(t, got, 1)
+		assert.Same(t, job, got[0].Job)
+		assert.Equal(t, []string{"TestTorrent/file1.mkv"}, got[0].Files)
+		require.ErrorIs(t, got[0].Err, filesync.ErrStalled)
+		assert.Contains(t, got[0].Err.Error(), "no progress for 50ms")
+		assert.Equal(t, 1, got[0].Attempt)
+		assert.True(t, got[0].Retrying)
+	})
+
+	t.Run("FailsAfterRetries", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockTransferer()
+		mockTransfer.OnTransfer = hangUntilCancelled
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		var stalls stallRecorder
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+			filesync.WithStallTimeout(stallTimeout),
+			filesync.WithStallRetries(1),
+			filesync.WithOnStalled(stalls.record),
+		)
+
+		job := syncer.CreateJob(createTestDownload("hash1", "TestTorrent", "tv"), "test-downloader", tmpDir)
+
+		err := syncer.SyncFile(context.Background(), mockDL, job, job.Files[0])
+		require.ErrorIs(t, err, filesync.ErrStalled)
+		assert.Equal(t, filesync.FileStatusError, job.Files[0].GetStatus())
+		assert.Len(t, mockTransfer.GetTransferCalls(), 2)
+
+		got := stalls.get()
+		require.Len(t, got, 2)
+		assert.True(t, got[0].Retrying)
+		assert.Equal(t, 2, got[1].Attempt)
+		assert.False(t, got[1].Retrying)
+	})
+
+	t.Run("BelowMinimumSpeed", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockTransferer()
+		mockTransfer.OnTransfer = func(ctx context.Context, _ transfer.Request, onProgress transfer.ProgressFunc) error {
+			// A trickle of 10 bytes every 5ms is 2 KB/s
+			for transferred := int64(10); ; transferred += 10 {
+				onProgress(transfer.Progress{Transferred: transferred})
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(5 * time.Millisecond):
+				}
+			}
+		}
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+			filesync.WithStallTimeout(stallTimeout),
+			filesync.WithMinSpeed(1024*1024),
+			filesync.WithStallRetries(0),
+		)
+
+		job := syncer.CreateJob(createTestDownload("hash1", "TestTorrent", "tv"), "test-downloader", tmpDir)
+
+		err := syncer.SyncFile(context.Background(), mockDL, job, job.Files[0])
+		require.ErrorIs(t, err, filesync.ErrStalled)
+		assert.Contains(t, err.Error(), "below the minimum of 1048576 bytes/sec")
+	})
+
+	t.Run("SlowButSteadyTransferNotCancelled", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockTransferer()
+		mockTransfer.OnTransfer = func(ctx context.Context, req transfer.Request, onProgress transfer.ProgressFunc) error {
+			for transferred := req.Size / 20; transferred < req.Size; transferred += req.Size / 20 {
+				onProgress(transfer.Progress{Transferred: transferred})
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+			return writeRequestFile(req)
+		}
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		var stalls stallRecorder
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+			filesync.WithStallTimeout(stallTimeout),
+			filesync.WithOnStalled(stalls.record),
+		)
+
+		job := syncer.CreateJob(createTestDownload("hash1", "TestTorrent", "tv"), "test-downloader", tmpDir)
+
+		require.NoError(t, syncer.SyncFile(context.Background(), mockDL, job, job.Files[0]))
+		assert.Empty(t, stalls.get())
+		assert.Len(t, mockTransfer.GetTransferCalls(), 1)
+	})
+
+	t.Run("RetriesUnfinishedFilesOfStalledBatch", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mockTransfer := testutil.NewMockBatchTransferer()
+		mockDL := testutil.NewMockDownloader("test-downloader")
+
+		var batches atomic.Int32
+		mockTransfer.OnBatch = func(
+			ctx context.Context,
+			reqs []transfer.Request,
+			_ int,
+			onProgress transfer.BatchProgressFunc,
+			onDone transfer.BatchDoneFunc,
+		) error {
+			// The first batch hangs after transferring half of the files
+			stall := batches.Add(1) == 1
+			for i, req := range reqs {
+				if stall && i >= len(reqs)/2 {
+					<-ctx.Done()
+					onDone(i, ctx.Err())
+					continue
+				}
+				err := writeRequestFile(req)
+				if err == nil {
+					onProgress(i, transfer.Progress{Transferred: req.Size})
+				}
+				onDone(i, err)
+			}
+			return ctx.Err()
+		}
+
+		var stalls stallRecorder
+		syncer := filesync.New(
+			filepath.Join(tmpDir, "syncing"),
+			filesync.WithTransferer(mockTransfer),
+			filesync.WithStallTimeout(stallTimeout),
+			filesync.WithOnStalled(stalls.record),
+		)
+
+		dl := createDownloadWithFiles("hash1", "Discography", 20, 1024)
+		mockDL.AddDownload(dl, dl.Files)
+		job := syncer.CreateJob(dl, "test-downloader", filepath.Join(tmpDir, "downloads/music"))
+
+		require.NoError(t, syncer.SyncJob(context.Background(), mockDL, job))
+
+		_, status := job.GetProgress()
+		assert.Equal(t, filesync.FileStatusComplete, status)
+
+		calls := mockTransfer.GetBatchCalls()
+		require.Len(t, calls, 2)
+		assert.Len(t, calls[0], 20)
+		assert.Len(t, calls[1], 10)
+
+		got := stalls.get()
+		require.Len(t, got, 1)
+		assert.Len(t, got[0].Files, 10)
+		assert.True(t, got[0].Retrying)
+	})
+}
+
+// --- Disk Space Tests ---
+
+func TestCheckSpace(t *testing.T) {
+	// freeSpace reports the free space of each path
+	freeSpace := func(free map[string]int64) filesync.Option {
+		return filesync.WithFreeSpaceFunc(func(path string) (int64, error) {
+			return free[path], nil
+		})
+	}
+
+	t.Run("Fits", func(t *testing.T) {
+		syncer := filesync.New("/syncing",
+			filesync.WithMinFreeSpace(100),
+			freeSpace(map[string]int64{"/syncing": 1100, "/downloads": 1100}),
+		)
+
+		assert.NoError(t, syncer.CheckSpace(1000, "/downloads"))
+	})
+
+	t.Run("KeepsMinimumFree", func(t *testing.T) {
+		syncer := filesync.New("/syncing",
+			filesync.WithMinFreeSpace(100),
+			freeSpace(map[string]int64{"/syncing": 1099, "/downloads": 2000}),
+		)
+
+		err := syncer.CheckSpace(1000, "/downloads")
+		var spaceErr *filesync.InsufficientSpaceError
+		require.ErrorAs(t, err, &spaceErr)
+		assert.Equal(t, filesync.InsufficientSpaceError{Path: "/syncing", Required: 1100, Free: 1099}, *spaceErr)
+	})
+
+	t.Run("ChecksFinalPath", func(t *testing.T) {
+		syncer := filesync.New("/syncing",
+			freeSpace(map[string]int64{"/syncing": 2000, "/downloads": 500}),
+		)
+
+		var spaceErr *filesync.InsufficientSpaceError
+		require.ErrorAs(t, syncer.CheckSpace(1000, "/downloads"), &spaceErr)
+		assert.Equal(t, "/downloads", spaceErr.Path)
+	})
+
+	t.Run("ReservesInFlightJobs", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		syncer := filesync.New(filepath.Join(tmpDir, "syncing"),
+			filesync.WithFreeSpaceFunc(func(string) (int64, error) { return 2 * 1024 * 1024, nil }),
+		)
+
+		// Half of the first file of a 1 MB job has been transferred
+		job := syncer.CreateJob(createTestDownload("hash1", "TestTorrent", "tv"), "test-downloader", tmpDir)
+		job.Files[0].SetProgress(256*1024, 0)
+		assert.Equal(t, int64(768*1024), syncer.ReservedBytes())
+
+		require.NoError(t, syncer.CheckSpace(1280*1024, tmpDir))
+		require.Error(t, syncer.CheckSpace(1280*1024+1, tmpDir))
+
+		// Cancelled jobs reserve nothing
+		require.NoError(t, syncer.CancelJob("hash1"))
+		assert.Zero(t, syncer.ReservedBytes())
+	})
+
+	t.Run("FreeSpaceError", func(t *testing.T) {
+		syncer := filesync.New("/syncing",
+			filesync.WithFreeSpaceFunc(func(string) (int64, error) { return 0, errors.ErrUnsupported }),
+		)
+
+		err := syncer.CheckSpace(1000, "/downloads")
+		require.ErrorIs(t, err, errors.ErrUnsupported)
+		var spaceErr *filesync.InsufficientSpaceError
+		assert.NotErrorAs(t, err, &spaceErr)
+	})
 }
 
 // --- Close/PrepareShutdown Tests ---
@@ -865,6 +1624,28 @@ func createTestDownload(id, name, category string) *download.Download {
 
 // createTestJob creates a SyncJob via the Syncer.CreateJob method to ensure
 // proper initialization of unexported fields like ctx and cancel.
+// createDownloadWithFiles creates a completed download with count files of the
+// given size.
+func createDownloadWithFiles(id, name string, count int, size int64) *download.Download {
+	dl := &download.Download{
+		ID:       id,
+		Name:     name,
+		Hash:     id,
+		Category: "music",
+		SavePath: "/remote/downloads",
+		State:    download.TorrentStateComplete,
+	}
+	for i := range count {
+		dl.Files = append(dl.Files, download.File{
+			Path:     fmt.Sprintf("%s/track-%02d.flac", name, i+1),
+			Size:     size,
+			State:    download.FileStateComplete,
+			Priority: 1,
+		})
+	}
+	return dl
+}
+
 func createTestJob() *filesync.SyncJob {
 	const (
 		name     = "TestTorrent"
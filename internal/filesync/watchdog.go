@@ -0,0 +1,141 @@
+package filesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStalled is wrapped by the errors of transfers the watchdog cancelled.
+var ErrStalled = errors.New("transfer stalled")
+
+// defaultStallRetries is how often a stalled transfer is retried by default.
+const defaultStallRetries = 3
+
+// Stall describes a transfer the watchdog cancelled.
+type Stall struct {
+	Job      *SyncJob
+	Files    []string // Paths of the files being transferred
+	Err      error    // Why the transfer was considered stalled, wrapping ErrStalled
+	Attempt  int      // Number of times the transfer has stalled so far
+	Retrying bool     // Whether the transfer is retried, or its files failed
+}
+
+// WithStallTimeout sets how long a transfer may go without progress before the
+// watchdog cancels and retries it. Zero disables the watchdog.
+func WithStallTimeout(d time.Duration) Option {
+	return func(s *Syncer) {
+		s.stallTimeout = d
+	}
+}
+
+// WithMinSpeed sets the average speed, in bytes/sec, a transfer must keep up
+// over each stall timeout for the watchdog not to cancel it. Zero only cancels
+// transfers that make no progress at all.
+func WithMinSpeed(bytesPerSec int64) Option {
+	return func(s *Syncer) {
+		s.minSpeed = bytesPerSec
+	}
+}
+
+// WithStallRetries sets how many times a stalled transfer is retried before its
+// files fail.
+func WithStallRetries(n int) Option {
+	return func(s *Syncer) {
+		s.stallRetries = n
+	}
+}
+
+// WithOnStalled sets a callback for when the watchdog cancels a transfer.
+func WithOnStalled(fn func(stall Stall)) Option {
+	return func(s *Syncer) {
+		s.onStalled = fn
+	}
+}
+
+// watch returns a context for a transfer that the watchdog cancels, with an
+// error wrapping ErrStalled as its cause, if transferred reports less progress
+// than required over a stall timeout. Call the returned function once the
+// transfer is over.
+func (s *Syncer) watch(ctx context.Context, transferred func() int64) (context.Context, context.CancelFunc) {
+	if s.stallTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	watchCtx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		ticker := time.NewTicker(s.stallTimeout)
+		defer ticker.Stop()
+
+		last := transferred()
+		for {
+			select {
+			case <-watchCtx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current := transferred()
+			if err := s.checkProgress(current - last); err != nil {
+				cancel(err)
+				return
+			}
+			last = current
+		}
+	}()
+
+	return watchCtx, func() { cancel(context.Canceled) }
+}
+
+// checkProgress returns an error wrapping ErrStalled if n bytes transferred
+// over a stall timeout are not enough.
+func (s *Syncer) checkProgress(n int64) error {
+	if n <= 0 {
+		return fmt.Errorf("%w: no progress for %s", ErrStalled, s.stallTimeout)
+	}
+
+	speed := int64(float64(n) / s.stallTimeout.Seconds())
+	if speed < s.minSpeed {
+		return fmt.Errorf("%w: %d bytes/sec over %s, below the minimum of %d bytes/sec",
+			ErrStalled, speed, s.stallTimeout, s.minSpeed)
+	}
+	return nil
+}
+
+// stallCause returns the error the watchdog cancelled ctx with, or nil if it
+// did not.
+func stallCause(ctx context.Context) error {
+	if cause := context.Cause(ctx); errors.Is(cause, ErrStalled) {
+		return cause
+	}
+	return nil
+}
+
+// stalled reports a transfer of files the watchdog cancelled, and whether it
+// is retried. It resets the progress of the files that are retried.
+func (s *Syncer) stalled(job *SyncJob, files []*FileProgress, err error, attempt int) bool {
+	retrying := attempt <= s.stallRetries
+
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+		if retrying {
+			file.SetProgress(0, 0)
+		}
+	}
+
+	s.logger.Warn().
+		Err(err).
+		Str("job", job.ID).
+		Strs("files", paths).
+		Int("attempt", attempt).
+		Bool("retrying", retrying).
+		Msg("transfer stalled")
+
+	if s.onStalled != nil {
+		s.onStalled(Stall{Job: job, Files: paths, Err: err, Attempt: attempt, Retrying: retrying})
+	}
+
+	return retrying
+}
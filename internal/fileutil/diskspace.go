@@ -0,0 +1,39 @@
+package fileutil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FreeSpace returns the number of bytes available to unprivileged users on the
+// filesystem holding path. If path does not exist yet, as with a destination
+// directory created on first use, its closest existing parent is used.
+func FreeSpace(path string) (int64, error) {
+	dir, err := existingParent(path)
+	if err != nil {
+		return 0, err
+	}
+	return freeSpace(dir)
+}
+
+// existingParent returns path, or its closest parent that exists.
+func existingParent(path string) (string, error) {
+	path = filepath.Clean(path)
+	for {
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		path = parent
+	}
+}
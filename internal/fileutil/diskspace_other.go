@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !windows
+
+package fileutil
+
+import "errors"
+
+// freeSpace is not supported on this platform.
+func freeSpace(string) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,21 @@
+//go:build linux || darwin || dragonfly || freebsd
+
+package fileutil
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// freeSpace returns the bytes available to unprivileged users on the
+// filesystem holding dir.
+func freeSpace(dir string) (int64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(dir, &st); err != nil {
+		return 0, &os.PathError{Op: "statfs", Path: dir, Err: err}
+	}
+	// The field types differ between platforms, Bsize is an int32 on linux/386 and linux/arm
+	//nolint:gosec,unconvert // block counts of real filesystems fit in an int64
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
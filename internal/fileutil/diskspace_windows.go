@@ -0,0 +1,22 @@
+package fileutil
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// freeSpace returns the bytes available to the current user on the volume
+// holding dir.
+func freeSpace(dir string) (int64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, &os.PathError{Op: "GetDiskFreeSpaceEx", Path: dir, Err: err}
+	}
+
+	var available uint64
+	if err = windows.GetDiskFreeSpaceEx(path, &available, nil, nil); err != nil {
+		return 0, &os.PathError{Op: "GetDiskFreeSpaceEx", Path: dir, Err: err}
+	}
+	return int64(available), nil //nolint:gosec // free bytes of real volumes fit in an int64
+}
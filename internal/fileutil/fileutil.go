@@ -2,12 +2,16 @@
 package fileutil
 
 import (
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"syscall"
 )
 
 // CopyFile copies a file from src to dst, creating parent directories as needed.
+// The copy is written to a temporary file next to dst, synced to disk and then
+// renamed, so dst never appears partially written.
 func CopyFile(src, dst string) (retErr error) {
 	srcFile, err := os.Open(src)
 	if err != nil {
@@ -19,20 +23,81 @@ func CopyFile(src, dst string) (retErr error) {
 		}
 	}()
 
-	if err = os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+	info, err := srcFile.Stat()
+	if err != nil {
 		return err
 	}
 
-	dstFile, err := os.Create(dst)
+	dir := filepath.Dir(dst)
+	if err = os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(dst)+".*.tmp")
 	if err != nil {
 		return err
 	}
 	defer func() {
-		if closeErr := dstFile.Close(); closeErr != nil && retErr == nil {
+		if retErr != nil {
+			_ = tmpFile.Close()
+			_ = os.Remove(tmpFile.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmpFile, srcFile); err != nil {
+		return err
+	}
+	if err = tmpFile.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err = tmpFile.Sync(); err != nil {
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpFile.Name(), dst); err != nil {
+		return err
+	}
+	return SyncDir(dir)
+}
+
+// MoveFile moves a file from src to dst, creating parent directories as needed.
+// It renames the file, and only when src and dst are on different filesystems
+// copies it with CopyFile and removes src. Any other rename error is returned.
+func MoveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+
+	err := os.Rename(src, dst)
+	var linkErr *os.LinkError
+	if err == nil || !errors.As(err, &linkErr) || !errors.Is(linkErr.Err, syscall.EXDEV) {
+		return err
+	}
+
+	if err = CopyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// SyncDir flushes a directory to disk, so entries created or renamed in it
+// survive a crash.
+func SyncDir(dir string) (retErr error) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := d.Close(); closeErr != nil && retErr == nil {
 			retErr = closeErr
 		}
 	}()
 
-	_, err = io.Copy(dstFile, srcFile)
-	return err
+	// Some filesystems do not support syncing directories
+	if err = d.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, errors.ErrUnsupported) {
+		return err
+	}
+	return nil
 }
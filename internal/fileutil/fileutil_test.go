@@ -112,6 +112,40 @@ func TestCopyFile(t *testing.T) {
 		assert.Equal(t, srcContent, dstContent)
 	})
 
+	t.Run("PreservesPermissions", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		srcPath := filepath.Join(tmpDir, "source.txt")
+		dstPath := filepath.Join(tmpDir, "dest.txt")
+
+		require.NoError(t, os.WriteFile(srcPath, []byte("content"), 0600))
+		require.NoError(t, os.Chmod(srcPath, 0640))
+
+		require.NoError(t, fileutil.CopyFile(srcPath, dstPath))
+
+		info, err := os.Stat(dstPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	})
+
+	t.Run("LeavesNoTemporaryFiles", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		dstDir := filepath.Join(tmpDir, "dst")
+
+		srcPath := filepath.Join(tmpDir, "source.txt")
+		require.NoError(t, os.WriteFile(srcPath, []byte("content"), 0600))
+		srcDir := filepath.Join(tmpDir, "srcdir")
+		require.NoError(t, os.MkdirAll(srcDir, 0750))
+
+		require.NoError(t, fileutil.CopyFile(srcPath, filepath.Join(dstDir, "dest.txt")))
+		require.Error(t, fileutil.CopyFile(srcDir, filepath.Join(dstDir, "failed.txt")))
+
+		entries, err := os.ReadDir(dstDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "dest.txt", entries[0].Name())
+	})
+
 	t.Run("ErrorCases", func(t *testing.T) {
 		t.Run("SourceDoesNotExist", func(t *testing.T) {
 			tmpDir := t.TempDir()
@@ -205,3 +239,64 @@ func TestCopyFile(t *testing.T) {
 		})
 	})
 }
+
+func TestMoveFile(t *testing.T) {
+	t.Run("MovesFile", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		srcPath := filepath.Join(tmpDir, "source.txt")
+		dstPath := filepath.Join(tmpDir, "deep", "nested", "dest.txt")
+		require.NoError(t, os.WriteFile(srcPath, []byte("content"), 0600))
+
+		require.NoError(t, fileutil.MoveFile(srcPath, dstPath))
+
+		dstContent, err := os.ReadFile(dstPath)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("content"), dstContent)
+		assert.NoFileExists(t, srcPath)
+	})
+
+	t.Run("SourceDoesNotExist", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		err := fileutil.MoveFile(filepath.Join(tmpDir, "nonexistent.txt"), filepath.Join(tmpDir, "dest.txt"))
+		require.Error(t, err)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("ReturnsRenameErrors", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		srcPath := filepath.Join(tmpDir, "source.txt")
+		require.NoError(t, os.WriteFile(srcPath, []byte("content"), 0600))
+
+		// A non-empty directory in the way fails the rename, which must not fall back to copying
+		dstPath := filepath.Join(tmpDir, "dest")
+		require.NoError(t, os.MkdirAll(filepath.Join(dstPath, "child"), 0750))
+
+		err := fileutil.MoveFile(srcPath, dstPath)
+		var linkErr *os.LinkError
+		require.ErrorAs(t, err, &linkErr)
+		assert.Equal(t, srcPath, linkErr.Old)
+		assert.FileExists(t, srcPath)
+	})
+}
+
+func TestSyncDir(t *testing.T) {
+	require.NoError(t, fileutil.SyncDir(t.TempDir()))
+	require.Error(t, fileutil.SyncDir(filepath.Join(t.TempDir(), "nonexistent")))
+}
+
+func TestFreeSpace(t *testing.T) {
+	t.Run("ExistingDirectory", func(t *testing.T) {
+		free, err := fileutil.FreeSpace(t.TempDir())
+		require.NoError(t, err)
+		assert.Positive(t, free)
+	})
+
+	t.Run("MissingDirectoryUsesParent", func(t *testing.T) {
+		free, err := fileutil.FreeSpace(filepath.Join(t.TempDir(), "not", "created", "yet"))
+		require.NoError(t, err)
+		assert.Positive(t, free)
+	})
+}
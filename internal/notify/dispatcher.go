@@ -0,0 +1,361 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/seedreap/seedreap/internal/timeline"
+)
+
+// Default templates, rendered with the timeline event.
+const (
+	DefaultTitle   = `{{ label .Type }}{{ with .DownloadName }}: {{ . }}{{ end }}`
+	DefaultMessage = `{{ .Message }}{{ with .Details.error }}` + "\n\n" + `{{ . }}{{ end }}`
+)
+
+// allEvents matches every event type in a rule.
+const allEvents = "*"
+
+const (
+	// eventBuffer is the number of timeline events buffered for the dispatcher.
+	eventBuffer = 256
+
+	// queueSize is the number of messages buffered per notifier.
+	queueSize = 64
+
+	// rateWindow is the period RateLimit applies to.
+	rateWindow = time.Minute
+)
+
+// DefaultEvents are the event types notified about when a rule lists none.
+func DefaultEvents() []timeline.EventType {
+	return []timeline.EventType{
+		timeline.EventImportComplete,
+		timeline.EventImportFailed,
+		timeline.EventPostImportFailed,
+		timeline.EventError,
+	}
+}
+
+// Rule selects and formats the events sent to a notifier.
+type Rule struct {
+	// Events lists the event types to send; "*" matches all. If empty, DefaultEvents are sent.
+	Events []string
+	// Title is the title template. If empty, DefaultTitle is used.
+	Title string
+	// Message is the body template. If empty, DefaultMessage is used.
+	Message string
+	// RateLimit caps the messages sent per minute, 0 = unlimited.
+	RateLimit int
+}
+
+// route delivers the events matching a rule to a notifier.
+type route struct {
+	notifier Notifier
+	events   []timeline.EventType
+	title    *template.Template
+	message  *template.Template
+	limiter  *limiter
+	queue    chan Message
+}
+
+// Dispatcher sends timeline events to notifiers as they are recorded.
+type Dispatcher struct {
+	recorder timeline.Recorder
+	routes   []*route
+	logger   zerolog.Logger
+
+	cancel context.CancelFunc
+	drain  chan struct{}
+	wg     sync.WaitGroup
+}
+
+// Option is a functional option for configuring the dispatcher.
+type Option func(*Dispatcher)
+
+// WithLogger sets the logger.
+func WithLogger(logger zerolog.Logger) Option {
+	return func(d *Dispatcher) {
+		d.logger = logger
+	}
+}
+
+// NewDispatcher creates a dispatcher for the events of a timeline recorder.
+func NewDispatcher(recorder timeline.Recorder, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		recorder: recorder,
+		logger:   zerolog.Nop(),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Add sends the events matching rule to a notifier. It must be called before Start.
+func (d *Dispatcher) Add(n Notifier, rule Rule) error {
+	r := &route{
+		notifier: n,
+		limiter:  newLimiter(rule.RateLimit, rateWindow),
+	}
+
+	events, err := parseEvents(rule.Events)
+	if err != nil {
+		return err
+	}
+	r.events = events
+
+	if r.title, err = parseTemplate("title", rule.Title, DefaultTitle); err != nil {
+		return err
+	}
+	if r.message, err = parseTemplate("message", rule.Message, DefaultMessage); err != nil {
+		return err
+	}
+
+	d.routes = append(d.routes, r)
+	return nil
+}
+
+// Len returns the number of notifiers.
+func (d *Dispatcher) Len() int {
+	return len(d.routes)
+}
+
+// Start subscribes to the timeline and delivers notifications until ctx is
+// cancelled or Stop is called.
+func (d *Dispatcher) Start(ctx context.Context) {
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.drain = make(chan struct{})
+
+	events, unsubscribe := d.recorder.Subscribe(eventBuffer)
+
+	for _, r := range d.routes {
+		r.queue = make(chan Message, queueSize)
+		d.wg.Add(1)
+		go d.deliver(ctx, r)
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer func() {
+			unsubscribe()
+			for _, r := range d.routes {
+				close(r.queue)
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-d.drain:
+				// Dispatch the events recorded so far, then stop
+				for {
+					select {
+					case e, ok := <-events:
+						if !ok {
+							return
+						}
+						d.dispatch(e)
+					default:
+						return
+					}
+				}
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				d.dispatch(e)
+			}
+		}
+	}()
+}
+
+// Drain stops the dispatcher after sending the notifications for every event
+// recorded so far. Use it instead of Stop when exiting after a unit of work, so
+// the last notifications are not dropped. It must not be called more than once.
+func (d *Dispatcher) Drain() {
+	if d.cancel == nil {
+		return
+	}
+	close(d.drain)
+	d.wg.Wait()
+	d.cancel()
+}
+
+// Stop stops the dispatcher and waits for in-flight notifications. Queued
+// notifications are dropped.
+func (d *Dispatcher) Stop() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	d.wg.Wait()
+}
+
+// dispatch queues an event for every route that matches it.
+func (d *Dispatcher) dispatch(e timeline.Event) {
+	for _, r := range d.routes {
+		if !slices.Contains(r.events, e.Type) {
+			continue
+		}
+
+		logger := d.logger.With().
+			Str("notifier", r.notifier.Name()).
+			Str("event", string(e.Type)).
+			Logger()
+
+		if !r.limiter.allow(time.Now()) {
+			logger.Warn().Msg("notification rate limit reached, dropping notification")
+			continue
+		}
+
+		msg, err := r.render(e)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to render notification")
+			continue
+		}
+
+		select {
+		case r.queue <- msg:
+		default:
+			logger.Warn().Msg("notification queue is full, dropping notification")
+		}
+	}
+}
+
+// deliver sends the messages queued for a route.
+func (d *Dispatcher) deliver(ctx context.Context, r *route) {
+	defer d.wg.Done()
+
+	for msg := range r.queue {
+		if ctx.Err() != nil {
+			continue
+		}
+
+		err := r.notifier.Send(ctx, msg)
+		if err != nil && ctx.Err() == nil {
+			d.logger.Error().
+				Err(err).
+				Str("notifier", r.notifier.Name()).
+				Str("type", r.notifier.Type()).
+				Str("event", string(msg.Event.Type)).
+				Msg("failed to send notification")
+			continue
+		}
+
+		d.logger.Debug().
+			Str("notifier", r.notifier.Name()).
+			Str("event", string(msg.Event.Type)).
+			Msg("notification sent")
+	}
+}
+
+// render executes the route's templates for an event.
+func (r *route) render(e timeline.Event) (Message, error) {
+	var title, body bytes.Buffer
+
+	if err := r.title.Execute(&title, e); err != nil {
+		return Message{}, fmt.Errorf("title template: %w", err)
+	}
+	if err := r.message.Execute(&body, e); err != nil {
+		return Message{}, fmt.Errorf("message template: %w", err)
+	}
+
+	return Message{
+		Title: strings.TrimSpace(title.String()),
+		Body:  strings.TrimSpace(body.String()),
+		Event: e,
+	}, nil
+}
+
+// parseEvents validates event type names.
+func parseEvents(names []string) ([]timeline.EventType, error) {
+	if len(names) == 0 {
+		return DefaultEvents(), nil
+	}
+
+	known := timeline.EventTypes()
+	events := make([]timeline.EventType, 0, len(names))
+	for _, name := range names {
+		if name == allEvents {
+			return known, nil
+		}
+		if !slices.Contains(known, timeline.EventType(name)) {
+			return nil, fmt.Errorf("unknown event type %q", name)
+		}
+		events = append(events, timeline.EventType(name))
+	}
+
+	return events, nil
+}
+
+// parseTemplate parses a message template, or the fallback if text is empty.
+func parseTemplate(name, text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+
+	t, err := template.New(name).Funcs(template.FuncMap{"label": label}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return t, nil
+}
+
+// label turns an event type into a readable label, e.g. "import_failed" into "Import failed".
+func label(t timeline.EventType) string {
+	s := strings.ReplaceAll(string(t), "_", " ")
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// limiter allows at most limit events per window.
+type limiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	sent   []time.Time
+}
+
+func newLimiter(limit int, window time.Duration) *limiter {
+	return &limiter{limit: limit, window: window}
+}
+
+// allow reports whether an event may be sent at now, and records it if so.
+func (l *limiter) allow(now time.Time) bool {
+	if l.limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Forget sends that have left the window
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(l.sent) && !l.sent[i].After(cutoff) {
+		i++
+	}
+	l.sent = l.sent[i:]
+
+	if len(l.sent) >= l.limit {
+		return false
+	}
+
+	l.sent = append(l.sent, now)
+	return true
+}
@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seedreap/seedreap/internal/config"
+)
+
+// email sends messages over SMTP.
+type email struct {
+	name     string
+	smtp     config.SMTPConfig
+	username string
+	password string
+	timeout  time.Duration
+}
+
+func newEmail(name string, cfg config.NotificationConfig) *email {
+	return &email{
+		name:     name,
+		smtp:     cfg.SMTP,
+		username: cfg.Username,
+		password: cfg.Password,
+		timeout:  cfg.HTTPTimeout,
+	}
+}
+
+func (e *email) Name() string { return e.name }
+func (e *email) Type() string { return config.NotificationEmail }
+
+// Send delivers the message to every recipient in a single mail transaction.
+func (e *email) Send(ctx context.Context, msg Message) error {
+	c, err := e.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if e.smtp.TLS == config.SMTPTLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %s does not support STARTTLS", e.smtp.Host)
+		}
+		if err = c.StartTLS(e.tlsConfig()); err != nil {
+			return fmt.Errorf("smtp STARTTLS failed: %w", err)
+		}
+	}
+
+	if e.username != "" {
+		if err = c.Auth(smtp.PlainAuth("", e.username, e.password, e.smtp.Host)); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err = c.Mail(e.smtp.From); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	for _, to := range e.smtp.To {
+		if err = c.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s failed: %w", to, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err = w.Write(e.compose(msg)); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("smtp server rejected email: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// dial connects to the SMTP server, over TLS for implicit TLS.
+func (e *email) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(e.smtp.Host, strconv.Itoa(e.smtp.Port))
+
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if e.smtp.TLS == config.SMTPTLSImplicit {
+		dialer := &tls.Dialer{Config: e.tlsConfig()}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to smtp server %s: %w", addr, err)
+	}
+
+	// Bound the whole conversation, not just the dial
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, e.smtp.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("smtp handshake with %s failed: %w", addr, err)
+	}
+
+	return c, nil
+}
+
+func (e *email) tlsConfig() *tls.Config {
+	return &tls.Config{ServerName: e.smtp.Host, MinVersion: tls.VersionTLS12}
+}
+
+// compose builds a plain-text email with CRLF line endings.
+func (e *email) compose(msg Message) []byte {
+	var b bytes.Buffer
+
+	header := func(key, value string) {
+		b.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", e.smtp.From)
+	header("To", strings.Join(e.smtp.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", singleLine(msg.Title)))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return b.Bytes()
+}
@@ -0,0 +1,127 @@
+// Package notify sends notifications about timeline events to chat services,
+// push services and email.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/seedreap/seedreap/internal/config"
+	"github.com/seedreap/seedreap/internal/timeline"
+)
+
+// Message is a rendered notification.
+type Message struct {
+	// Title is a one-line summary.
+	Title string
+	// Body is the message text.
+	Body string
+	// Event is the timeline event the message is about.
+	Event timeline.Event
+}
+
+// Failure reports whether the message is about something that went wrong.
+func (m Message) Failure() bool {
+	switch m.Event.Type {
+	case timeline.EventError, timeline.EventImportFailed, timeline.EventPostImportFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Notifier delivers messages to a notification service.
+type Notifier interface {
+	// Name returns the configured name of this notifier.
+	Name() string
+
+	// Type returns the type of notifier (e.g., "discord", "email").
+	Type() string
+
+	// Send delivers a message.
+	Send(ctx context.Context, msg Message) error
+}
+
+// New creates a notifier from its configuration.
+func New(name string, cfg config.NotificationConfig) (Notifier, error) {
+	client := &http.Client{Timeout: cfg.HTTPTimeout}
+
+	switch cfg.Type {
+	case config.NotificationDiscord:
+		return &discord{base: newBase(name, cfg.Type, client), url: cfg.URL}, nil
+	case config.NotificationSlack:
+		return &slack{base: newBase(name, cfg.Type, client), url: cfg.URL}, nil
+	case config.NotificationNtfy:
+		return newNtfy(name, cfg, client)
+	case config.NotificationGotify:
+		return newGotify(name, cfg, client)
+	case config.NotificationPushover:
+		return newPushover(name, cfg, client)
+	case config.NotificationEmail:
+		return newEmail(name, cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown notification type %q", cfg.Type)
+	}
+}
+
+// base holds what every HTTP notifier shares.
+type base struct {
+	name   string
+	typ    string
+	client *http.Client
+}
+
+func newBase(name, typ string, client *http.Client) base {
+	return base{name: name, typ: typ, client: client}
+}
+
+func (b *base) Name() string { return b.name }
+func (b *base) Type() string { return b.typ }
+
+// maxErrorBody limits how much of an error response is included in errors.
+const maxErrorBody = 512
+
+// do sends a request and fails on non-2xx responses.
+func (b *base) do(req *http.Request) error {
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", b.typ, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("%s returned %s: %s", b.typ, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// post sends a POST request with the given body and content type.
+func (b *base) post(ctx context.Context, url, contentType string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", b.typ, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	return b.do(req)
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// singleLine replaces line breaks so s can be used in a header.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,407 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seedreap/seedreap/internal/config"
+	"github.com/seedreap/seedreap/internal/notify"
+	"github.com/seedreap/seedreap/internal/timeline"
+)
+
+// capturedRequest is a request received by a fake notification service.
+type capturedRequest struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// newCaptureServer starts a server that records requests and responds with status.
+func newCaptureServer(t *testing.T, status int) (*httptest.Server, <-chan capturedRequest) {
+	t.Helper()
+
+	requests := make(chan capturedRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- capturedRequest{path: r.URL.Path, header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("response body"))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, requests
+}
+
+func testMessage() notify.Message {
+	return notify.Message{
+		Title: "Import failed: Show.S01E01",
+		Body:  "Sonarr rejected the import",
+		Event: timeline.Event{
+			Type:      timeline.EventImportFailed,
+			Timestamp: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.NotificationConfig
+		wantErr bool
+	}{
+		{"Discord", config.NotificationConfig{Type: "discord", URL: "https://discord.example"}, false},
+		{"NtfyPriority", config.NotificationConfig{Type: "ntfy", URL: "https://ntfy.sh/x", Priority: "high"}, false},
+		{"NtfyBadPriority", config.NotificationConfig{Type: "ntfy", URL: "https://ntfy.sh/x", Priority: "loud"}, true},
+		{"GotifyBadPriority", config.NotificationConfig{Type: "gotify", URL: "https://g", Priority: "high"}, true},
+		{"PushoverEmergency", config.NotificationConfig{Type: "pushover", Priority: "2"}, true},
+		{"Email", config.NotificationConfig{Type: "email"}, false},
+		{"Unknown", config.NotificationConfig{Type: "pager"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := notify.New("test", tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "test", n.Name())
+			assert.Equal(t, tt.cfg.Type, n.Type())
+		})
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	send := func(t *testing.T, cfg config.NotificationConfig) {
+		t.Helper()
+
+		cfg.HTTPTimeout = 5 * time.Second
+		n, err := notify.New("test", cfg)
+		require.NoError(t, err)
+		require.NoError(t, n.Send(t.Context(), testMessage()))
+	}
+
+	t.Run("Discord", func(t *testing.T) {
+		srv, requests := newCaptureServer(t, http.StatusNoContent)
+		send(t, config.NotificationConfig{Type: "discord", URL: srv.URL + "/api/webhooks/1/abc"})
+
+		req := <-requests
+		var body struct {
+			Embeds []struct {
+				Title       string `json:"title"`
+				Description string `json:"description"`
+				Timestamp   string `json:"timestamp"`
+				Color       int    `json:"color"`
+			} `json:"embeds"`
+		}
+		require.NoError(t, json.Unmarshal(req.body, &body))
+		require.Len(t, body.Embeds, 1)
+		assert.Equal(t, "Import failed: Show.S01E01", body.Embeds[0].Title)
+		assert.Equal(t, "Sonarr rejected the import", body.Embeds[0].Description)
+		assert.Equal(t, "2025-01-01T12:00:00Z", body.Embeds[0].Timestamp)
+		assert.Equal(t, 0xE74C3C, body.Embeds[0].Color)
+	})
+
+	t.Run("Slack", func(t *testing.T) {
+		srv, requests := newCaptureServer(t, http.StatusOK)
+		send(t, config.NotificationConfig{Type: "slack", URL: srv.URL})
+
+		var body map[string]string
+		require.NoError(t, json.Unmarshal((<-requests).body, &body))
+		assert.Equal(t, "*Import failed: Show.S01E01*\nSonarr rejected the import", body["text"])
+	})
+
+	t.Run("Ntfy", func(t *testing.T) {
+		srv, requests := newCaptureServer(t, http.StatusOK)
+		send(t, config.NotificationConfig{Type: "ntfy", URL: srv.URL + "/seedreap", Priority: "high", Token: "tk"})
+
+		req := <-requests
+		assert.Equal(t, "/seedreap", req.path)
+		assert.Equal(t, "Sonarr rejected the import", string(req.body))
+		assert.Equal(t, "Import failed: Show.S01E01", req.header.Get("Title"))
+		assert.Equal(t, "high", req.header.Get("Priority"))
+		assert.Equal(t, "warning", req.header.Get("Tags"))
+		assert.Equal(t, "Bearer tk", req.header.Get("Authorization"))
+	})
+
+	t.Run("Gotify", func(t *testing.T) {
+		srv, requests := newCaptureServer(t, http.StatusOK)
+		send(t, config.NotificationConfig{Type: "gotify", URL: srv.URL + "/", Token: "app-token", Priority: "8"})
+
+		req := <-requests
+		assert.Equal(t, "/message", req.path)
+		assert.Equal(t, "app-token", req.header.Get("X-Gotify-Key"))
+		assert.JSONEq(t,
+			`{"title":"Import failed: Show.S01E01","message":"Sonarr rejected the import","priority":8}`,
+			string(req.body))
+	})
+
+	t.Run("Pushover", func(t *testing.T) {
+		srv, requests := newCaptureServer(t, http.StatusOK)
+		send(t, config.NotificationConfig{Type: "pushover", URL: srv.URL, Token: "app", User: "user", Priority: "1"})
+
+		form, err := url.ParseQuery(string((<-requests).body))
+		require.NoError(t, err)
+		assert.Equal(t, "app", form.Get("token"))
+		assert.Equal(t, "user", form.Get("user"))
+		assert.Equal(t, "Import failed: Show.S01E01", form.Get("title"))
+		assert.Equal(t, "1", form.Get("priority"))
+		assert.Equal(t, "1735732800", form.Get("timestamp"))
+	})
+
+	t.Run("ErrorStatus", func(t *testing.T) {
+		srv, _ := newCaptureServer(t, http.StatusUnauthorized)
+
+		n, err := notify.New("test", config.NotificationConfig{Type: "slack", URL: srv.URL})
+		require.NoError(t, err)
+
+		err = n.Send(t.Context(), testMessage())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "401")
+		assert.Contains(t, err.Error(), "response body")
+	})
+}
+
+// fakeSMTP is a minimal SMTP server that accepts one message.
+type fakeSMTP struct {
+	addr     string
+	mu       sync.Mutex
+	commands []string
+	data     string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	s := &fakeSMTP{addr: ln.Addr().String()}
+	go func() {
+		conn, acceptErr := ln.Accept()
+		if acceptErr != nil {
+			return
+		}
+		defer conn.Close()
+		s.serve(textproto.NewConn(conn))
+	}()
+
+	return s
+}
+
+func (s *fakeSMTP) serve(c *textproto.Conn) {
+	_ = c.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		switch verb, _, _ := strings.Cut(strings.ToUpper(line), " "); verb {
+		case "EHLO", "HELO":
+			_ = c.PrintfLine("250 localhost")
+		case "DATA":
+			_ = c.PrintfLine("354 go ahead")
+			data, _ := c.ReadDotBytes()
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			_ = c.PrintfLine("250 queued")
+		case "QUIT":
+			_ = c.PrintfLine("221 bye")
+			return
+		default:
+			_ = c.PrintfLine("250 ok")
+		}
+	}
+}
+
+func TestEmail(t *testing.T) {
+	newEmail := func(t *testing.T, srv *fakeSMTP, tlsMode string) notify.Notifier {
+		t.Helper()
+
+		host, port, err := net.SplitHostPort(srv.addr)
+		require.NoError(t, err)
+		portNum, err := strconv.Atoi(port)
+		require.NoError(t, err)
+
+		n, err := notify.New("mail", config.NotificationConfig{
+			Type:        "email",
+			HTTPTimeout: 5 * time.Second,
+			SMTP: config.SMTPConfig{
+				Host: host,
+				Port: portNum,
+				From: "seedreap@example.com",
+				To:   []string{"a@example.com", "b@example.com"},
+				TLS:  tlsMode,
+			},
+		})
+		require.NoError(t, err)
+		return n
+	}
+
+	t.Run("SendsMessage", func(t *testing.T) {
+		srv := newFakeSMTP(t)
+		require.NoError(t, newEmail(t, srv, config.SMTPTLSNone).Send(t.Context(), testMessage()))
+
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+
+		assert.Contains(t, srv.commands, "MAIL FROM:<seedreap@example.com>")
+		assert.Contains(t, srv.commands, "RCPT TO:<a@example.com>")
+		assert.Contains(t, srv.commands, "RCPT TO:<b@example.com>")
+
+		msg, err := textproto.NewReader(bufio.NewReader(strings.NewReader(srv.data))).ReadMIMEHeader()
+		require.NoError(t, err)
+		assert.Equal(t, "Import failed: Show.S01E01", msg.Get("Subject"))
+		assert.Equal(t, "a@example.com, b@example.com", msg.Get("To"))
+		assert.Contains(t, srv.data, "\n\nSonarr rejected the import\n")
+	})
+
+	t.Run("RequiresStartTLS", func(t *testing.T) {
+		srv := newFakeSMTP(t)
+		err := newEmail(t, srv, config.SMTPTLSStartTLS).Send(t.Context(), testMessage())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not support STARTTLS")
+	})
+}
+
+// recordingNotifier records the messages it is asked to send.
+type recordingNotifier struct {
+	messages chan notify.Message
+}
+
+func (r *recordingNotifier) Name() string { return "recorder" }
+func (r *recordingNotifier) Type() string { return "test" }
+
+func (r *recordingNotifier) Send(_ context.Context, msg notify.Message) error {
+	r.messages <- msg
+	return nil
+}
+
+// receive returns the messages delivered within a short wait.
+func (r *recordingNotifier) receive() []notify.Message {
+	var result []notify.Message
+	for {
+		select {
+		case msg := <-r.messages:
+			result = append(result, msg)
+		case <-time.After(100 * time.Millisecond):
+			return result
+		}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	start := func(t *testing.T, rule notify.Rule) (timeline.Recorder, *recordingNotifier) {
+		t.Helper()
+
+		tl := timeline.NewRecorder()
+		n := &recordingNotifier{messages: make(chan notify.Message, 16)}
+
+		d := notify.NewDispatcher(tl)
+		require.NoError(t, d.Add(n, rule))
+		d.Start(t.Context())
+		t.Cleanup(d.Stop)
+
+		return tl, n
+	}
+
+	t.Run("DefaultEventsAndTemplates", func(t *testing.T) {
+		tl, n := start(t, notify.Rule{})
+
+		tl.Record(timeline.Event{Type: timeline.EventSyncStarted, Message: "ignored"})
+		tl.Record(timeline.Event{
+			Type:         timeline.EventError,
+			Message:      "Sync error: Show.S01E01",
+			DownloadName: "Show.S01E01",
+			Details:      map[string]any{"error": "connection reset"},
+		})
+		tl.Record(timeline.Event{Type: timeline.EventImportComplete, Message: "Imported"})
+
+		messages := n.receive()
+		require.Len(t, messages, 2)
+		assert.Equal(t, "Error: Show.S01E01", messages[0].Title)
+		assert.Equal(t, "Sync error: Show.S01E01\n\nconnection reset", messages[0].Body)
+		assert.Equal(t, "Import complete", messages[1].Title)
+		assert.Equal(t, "Imported", messages[1].Body)
+	})
+
+	t.Run("CustomEventsAndTemplates", func(t *testing.T) {
+		tl, n := start(t, notify.Rule{
+			Events:  []string{"discovered"},
+			Title:   "New: {{ .DownloadName }}",
+			Message: "{{ .Details.category }} on {{ .Downloader }}",
+		})
+
+		tl.Record(timeline.Event{Type: timeline.EventError})
+		tl.Record(timeline.Event{
+			Type:         timeline.EventDiscovered,
+			DownloadName: "Movie",
+			Downloader:   "seedbox",
+			Details:      map[string]any{"category": "movies"},
+		})
+
+		messages := n.receive()
+		require.Len(t, messages, 1)
+		assert.Equal(t, "New: Movie", messages[0].Title)
+		assert.Equal(t, "movies on seedbox", messages[0].Body)
+	})
+
+	t.Run("AllEventsWithRateLimit", func(t *testing.T) {
+		tl, n := start(t, notify.Rule{Events: []string{"*"}, RateLimit: 2})
+
+		for range 5 {
+			tl.Record(timeline.Event{Type: timeline.EventSyncProgress})
+		}
+
+		assert.Len(t, n.receive(), 2)
+	})
+
+	t.Run("DrainSendsRecordedEvents", func(t *testing.T) {
+		tl := timeline.NewRecorder()
+		n := &recordingNotifier{messages: make(chan notify.Message, 16)}
+
+		d := notify.NewDispatcher(tl)
+		require.NoError(t, d.Add(n, notify.Rule{Events: []string{"*"}}))
+		d.Start(t.Context())
+
+		for range 3 {
+			tl.Record(timeline.Event{Type: timeline.EventComplete})
+		}
+		d.Drain()
+
+		// Every message was sent before Drain returned
+		assert.Len(t, n.messages, 3)
+	})
+
+	t.Run("InvalidRules", func(t *testing.T) {
+		d := notify.NewDispatcher(timeline.NewRecorder())
+		n := &recordingNotifier{}
+
+		require.ErrorContains(t, d.Add(n, notify.Rule{Events: []string{"imported"}}), `unknown event type "imported"`)
+		require.ErrorContains(t, d.Add(n, notify.Rule{Title: "{{ .Nope"}), "invalid title template")
+		assert.Zero(t, d.Len())
+	})
+}
@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/seedreap/seedreap/internal/config"
+)
+
+// ntfy publishes messages to an ntfy topic.
+type ntfy struct {
+	base
+	url      string
+	priority string
+	token    string
+	username string
+	password string
+}
+
+// ntfyPriorities are the priority names and numbers ntfy accepts.
+//
+//nolint:gochecknoglobals // validation lookup table
+var ntfyPriorities = map[string]bool{
+	"1": true, "2": true, "3": true, "4": true, "5": true,
+	"min": true, "low": true, "default": true, "high": true, "max": true, "urgent": true,
+}
+
+func newNtfy(name string, cfg config.NotificationConfig, client *http.Client) (*ntfy, error) {
+	if cfg.Priority != "" && !ntfyPriorities[cfg.Priority] {
+		return nil, fmt.Errorf(
+			"invalid ntfy priority %q: must be 1-5 or min, low, default, high, max, urgent", cfg.Priority)
+	}
+
+	return &ntfy{
+		base:     newBase(name, cfg.Type, client),
+		url:      cfg.URL,
+		priority: cfg.Priority,
+		token:    cfg.Token,
+		username: cfg.Username,
+		password: cfg.Password,
+	}, nil
+}
+
+// Send publishes the message body with the title and priority as headers.
+func (n *ntfy) Send(ctx context.Context, msg Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, strings.NewReader(msg.Body))
+	if err != nil {
+		return fmt.Errorf("failed to create ntfy request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if msg.Title != "" {
+		req.Header.Set("Title", mime.QEncoding.Encode("utf-8", singleLine(msg.Title)))
+	}
+	if n.priority != "" {
+		req.Header.Set("Priority", n.priority)
+	}
+	if msg.Failure() {
+		req.Header.Set("Tags", "warning")
+	} else {
+		req.Header.Set("Tags", "white_check_mark")
+	}
+
+	switch {
+	case n.token != "":
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	case n.username != "":
+		req.SetBasicAuth(n.username, n.password)
+	}
+
+	return n.do(req)
+}
+
+// gotify pushes messages to a Gotify server.
+type gotify struct {
+	base
+	url      string
+	token    string
+	priority *int
+}
+
+func newGotify(name string, cfg config.NotificationConfig, client *http.Client) (*gotify, error) {
+	g := &gotify{
+		base:  newBase(name, cfg.Type, client),
+		url:   strings.TrimSuffix(cfg.URL, "/") + "/message",
+		token: cfg.Token,
+	}
+
+	if cfg.Priority != "" {
+		p, err := strconv.Atoi(cfg.Priority)
+		if err != nil || p < 0 {
+			return nil, fmt.Errorf("invalid gotify priority %q: must be a non-negative integer", cfg.Priority)
+		}
+		g.priority = &p
+	}
+
+	return g, nil
+}
+
+// Send pushes the message.
+func (g *gotify) Send(ctx context.Context, msg Message) error {
+	body := map[string]any{
+		"title":   msg.Title,
+		"message": msg.Body,
+	}
+	if g.priority != nil {
+		body["priority"] = *g.priority
+	}
+
+	req, err := newJSONRequest(ctx, g.url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Gotify-Key", g.token)
+
+	return g.do(req)
+}
+
+// defaultPushoverURL is the Pushover message API.
+const defaultPushoverURL = "https://api.pushover.net/1/messages.json"
+
+// Pushover message limits.
+const (
+	pushoverTitleLimit   = 250
+	pushoverMessageLimit = 1024
+)
+
+// pushover sends messages through the Pushover API.
+type pushover struct {
+	base
+	url      string
+	token    string
+	user     string
+	priority string
+}
+
+func newPushover(name string, cfg config.NotificationConfig, client *http.Client) (*pushover, error) {
+	// Emergency priority (2) needs retry settings, which are not supported
+	switch cfg.Priority {
+	case "", "-2", "-1", "0", "1":
+	default:
+		return nil, fmt.Errorf("invalid pushover priority %q: must be -2, -1, 0 or 1", cfg.Priority)
+	}
+
+	apiURL := cfg.URL
+	if apiURL == "" {
+		apiURL = defaultPushoverURL
+	}
+
+	return &pushover{
+		base:     newBase(name, cfg.Type, client),
+		url:      apiURL,
+		token:    cfg.Token,
+		user:     cfg.User,
+		priority: cfg.Priority,
+	}, nil
+}
+
+// Send posts the message as a form.
+func (p *pushover) Send(ctx context.Context, msg Message) error {
+	form := url.Values{
+		"token":   {p.token},
+		"user":    {p.user},
+		"message": {truncate(msg.Body, pushoverMessageLimit)},
+	}
+	if msg.Title != "" {
+		form.Set("title", truncate(msg.Title, pushoverTitleLimit))
+	}
+	if p.priority != "" {
+		form.Set("priority", p.priority)
+	}
+	if !msg.Event.Timestamp.IsZero() {
+		form.Set("timestamp", strconv.FormatInt(msg.Event.Timestamp.Unix(), 10))
+	}
+
+	return p.post(ctx, p.url, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+}
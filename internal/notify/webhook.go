@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Discord and Slack message limits.
+const (
+	discordTitleLimit       = 256
+	discordDescriptionLimit = 4096
+	slackTextLimit          = 3000
+)
+
+// Discord embed colors.
+const (
+	discordColorSuccess = 0x2ECC71
+	discordColorFailure = 0xE74C3C
+)
+
+// discord posts messages to a Discord channel webhook.
+type discord struct {
+	base
+	url string
+}
+
+type discordEmbed struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Timestamp   string `json:"timestamp"`
+	Color       int    `json:"color"`
+}
+
+// Send posts the message as an embed.
+func (d *discord) Send(ctx context.Context, msg Message) error {
+	color := discordColorSuccess
+	if msg.Failure() {
+		color = discordColorFailure
+	}
+
+	return postJSON(ctx, &d.base, d.url, map[string]any{
+		"embeds": []discordEmbed{{
+			Title:       truncate(msg.Title, discordTitleLimit),
+			Description: truncate(msg.Body, discordDescriptionLimit),
+			Timestamp:   msg.Event.Timestamp.UTC().Format(time.RFC3339),
+			Color:       color,
+		}},
+	})
+}
+
+// slack posts messages to a Slack incoming webhook.
+type slack struct {
+	base
+	url string
+}
+
+// Send posts the message as text with a bold title.
+func (s *slack) Send(ctx context.Context, msg Message) error {
+	text := msg.Body
+	if msg.Title != "" {
+		text = "*" + msg.Title + "*\n" + text
+	}
+
+	return postJSON(ctx, &s.base, s.url, map[string]string{
+		"text": truncate(text, slackTextLimit),
+	})
+}
+
+// postJSON posts v encoded as JSON.
+func postJSON(ctx context.Context, b *base, url string, v any) error {
+	req, err := newJSONRequest(ctx, url, v)
+	if err != nil {
+		return err
+	}
+	return b.do(req)
+}
+
+// newJSONRequest creates a POST request with v encoded as JSON.
+func newJSONRequest(ctx context.Context, url string, v any) (*http.Request, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
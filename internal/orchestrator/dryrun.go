@@ -0,0 +1,184 @@
+package orchestrator
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/seedreap/seedreap/internal/app"
+	"github.com/seedreap/seedreap/internal/timeline"
+)
+
+// PlannedDownload is what a dry run would do with a tracked download.
+type PlannedDownload struct {
+	ID         string
+	Name       string
+	Downloader string
+	Category   string
+	State      DownloadState
+	Apps       []string
+	// FinalPath is where the files would be moved. It is empty until a sync is planned.
+	FinalPath string
+	// Files are the files that would be synced.
+	Files []PlannedFile
+	// Actions are the actions skipped so far, oldest first.
+	Actions []PlannedAction
+}
+
+// PlannedFile is a file a dry run would sync.
+type PlannedFile struct {
+	Path string
+	Size int64
+}
+
+// PlannedAction is an action a dry run skipped.
+type PlannedAction struct {
+	Type    timeline.EventType
+	Time    time.Time
+	Message string
+}
+
+// WithDryRun makes the orchestrator plan syncs without transferring, moving,
+// importing or deleting anything. Skipped actions are logged, recorded as
+// "would" timeline events and collected in Plan.
+func WithDryRun(dryRun bool) Option {
+	return func(o *Orchestrator) {
+		o.dryRun = dryRun
+	}
+}
+
+// DryRun reports whether the orchestrator is running in dry-run mode.
+func (o *Orchestrator) DryRun() bool {
+	return o.dryRun
+}
+
+// Plan returns what the dry run would do with each tracked download, sorted by
+// name. It is empty unless the orchestrator runs in dry-run mode.
+func (o *Orchestrator) Plan() []PlannedDownload {
+	if !o.dryRun {
+		return nil
+	}
+
+	tracked := o.GetTrackedDownloads()
+
+	plan := make([]PlannedDownload, 0, len(tracked))
+	for _, td := range tracked {
+		td.mu.RLock()
+		p := PlannedDownload{
+			ID:         td.Download.ID,
+			Name:       td.Download.Name,
+			Downloader: td.DownloaderName,
+			Category:   td.Download.Category,
+			State:      td.State,
+			Apps:       appNames(td.Apps),
+		}
+		job := td.SyncJob
+		td.mu.RUnlock()
+
+		// Not taken while holding td.mu, which recordPlanned may be called with
+		o.plannedMu.Lock()
+		p.Actions = slices.Clone(o.planned[p.ID])
+		o.plannedMu.Unlock()
+
+		if job != nil {
+			snapshot := job.Snapshot()
+			p.FinalPath = snapshot.FinalPath
+			for _, f := range snapshot.Files {
+				p.Files = append(p.Files, PlannedFile{Path: f.Path, Size: f.Size})
+			}
+		}
+
+		plan = append(plan, p)
+	}
+
+	slices.SortFunc(plan, func(a, b PlannedDownload) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Downloader, b.Downloader))
+	})
+	return plan
+}
+
+// recordPlanned logs an action skipped by the dry run, adds it to the plan and
+// records it in the timeline.
+func (o *Orchestrator) recordPlanned(
+	eventType timeline.EventType,
+	message string,
+	downloadID, downloadName, appName, downloaderName string,
+	details map[string]any,
+) {
+	o.logger.Info().
+		Str("download", downloadName).
+		Str("action", string(eventType)).
+		Interface("details", details).
+		Msg("dry run: " + message)
+
+	o.plannedMu.Lock()
+	o.planned[downloadID] = append(o.planned[downloadID], PlannedAction{
+		Type:    eventType,
+		Time:    time.Now(),
+		Message: message,
+	})
+	o.plannedMu.Unlock()
+
+	o.recordEvent(eventType, message, downloadID, downloadName, appName, downloaderName, details)
+}
+
+// planImport records the imports a dry run would trigger for a download.
+func (o *Orchestrator) planImport(tracked *TrackedDownload, apps []app.App, importPath string) {
+	tracked.mu.RLock()
+	downloadID := tracked.Download.ID
+	downloadName := tracked.Download.Name
+	downloaderName := tracked.DownloaderName
+	tracked.mu.RUnlock()
+
+	for _, a := range apps {
+		o.recordPlanned(
+			timeline.EventWouldImport,
+			fmt.Sprintf("Would import: %s -> %s", downloadName, a.Name()),
+			downloadID,
+			downloadName,
+			a.Name(),
+			downloaderName,
+			map[string]any{
+				"path": importPath,
+			},
+		)
+	}
+}
+
+// planPostImport records the post-import actions a dry run would apply to a
+// download after its imports.
+func (o *Orchestrator) planPostImport(tracked *TrackedDownload, apps []app.App) {
+	tracked.mu.RLock()
+	downloadID := tracked.Download.ID
+	downloadName := tracked.Download.Name
+	downloaderName := tracked.DownloaderName
+	tracked.mu.RUnlock()
+
+	for _, a := range apps {
+		policy := a.PostImport()
+		if policy.IsZero() {
+			continue
+		}
+
+		actioner, ok := o.actioner(a, downloaderName)
+		if !ok {
+			continue
+		}
+
+		for _, action := range postImportActions(actioner, policy) {
+			details := map[string]any{"action": action.name}
+			maps.Copy(details, action.details)
+			o.recordPlanned(
+				timeline.EventWouldPostImport,
+				fmt.Sprintf("Would apply post-import %s: %s", action.name, downloadName),
+				downloadID,
+				downloadName,
+				a.Name(),
+				downloaderName,
+				details,
+			)
+		}
+	}
+}
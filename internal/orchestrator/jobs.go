@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/seedreap/seedreap/internal/timeline"
+)
+
+// Errors returned by CancelJob and RetryJob.
+var (
+	// ErrJobNotFound is returned when no tracked download has the given ID.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotCancellable is returned when a download is past syncing or already finished.
+	ErrJobNotCancellable = errors.New("job can only be cancelled while waiting or syncing")
+	// ErrJobNotRetryable is returned when a download has not failed.
+	ErrJobNotRetryable = errors.New("job can only be retried after it failed or was cancelled")
+)
+
+// errCancelled is recorded as the error of a download whose sync was cancelled.
+var errCancelled = errors.New("cancelled")
+
+// CancelJob stops syncing a download and removes its staging files. The download
+// stays tracked in the error state until it is retried or retention removes it.
+func (o *Orchestrator) CancelJob(id string) error {
+	tracked := o.findTracked(id)
+	if tracked == nil {
+		return ErrJobNotFound
+	}
+
+	tracked.mu.Lock()
+	if tracked.State != StateDiscovered && tracked.State != StateWaitingForSpace && tracked.State != StateSyncing {
+		tracked.mu.Unlock()
+		return ErrJobNotCancellable
+	}
+	hasJob := tracked.SyncJob != nil
+	tracked.State = StateError
+	tracked.Error = errCancelled
+	tracked.FailedAt = time.Now()
+	downloadName := tracked.Download.Name
+	downloaderName := tracked.DownloaderName
+	tracked.mu.Unlock()
+
+	if hasJob {
+		if err := o.syncer.CancelJob(id); err != nil {
+			o.logger.Warn().Err(err).Str("download", downloadName).Msg("error cancelling sync job")
+		}
+	}
+
+	o.logger.Info().Str("download", downloadName).Msg("sync cancelled")
+	o.recordEvent(
+		timeline.EventSyncCancelled,
+		fmt.Sprintf("Sync cancelled: %s", downloadName),
+		id,
+		downloadName,
+		"",
+		downloaderName,
+		nil,
+	)
+
+	return nil
+}
+
+// RetryJob starts over a download that failed or was cancelled. Its sync job is
+// discarded and the download is synced again from the next poll.
+func (o *Orchestrator) RetryJob(id string) error {
+	tracked := o.findTracked(id)
+	if tracked == nil {
+		return ErrJobNotFound
+	}
+
+	tracked.mu.Lock()
+	if tracked.State != StateError {
+		tracked.mu.Unlock()
+		return ErrJobNotRetryable
+	}
+	previous := tracked.Error
+	hasJob := tracked.SyncJob != nil
+	tracked.State = StateDiscovered
+	tracked.Error = nil
+	tracked.FailedAt = time.Time{}
+	tracked.SyncJob = nil
+	downloadName := tracked.Download.Name
+	downloaderName := tracked.DownloaderName
+	tracked.mu.Unlock()
+
+	if hasJob {
+		o.syncer.RemoveJob(id)
+	}
+
+	errMsg := ""
+	if previous != nil {
+		errMsg = previous.Error()
+	}
+
+	o.logger.Info().Str("download", downloadName).Msg("retrying sync")
+	o.recordEvent(
+		timeline.EventSyncRetried,
+		fmt.Sprintf("Sync retried: %s", downloadName),
+		id,
+		downloadName,
+		"",
+		downloaderName,
+		map[string]any{
+			"previous_error": errMsg,
+		},
+	)
+
+	return nil
+}
+
+// findTracked returns the tracked download with the given download ID, or nil.
+func (o *Orchestrator) findTracked(id string) *TrackedDownload {
+	o.trackedMu.RLock()
+	defer o.trackedMu.RUnlock()
+
+	for _, tracked := range o.tracked {
+		tracked.mu.RLock()
+		match := tracked.Download.ID == id
+		tracked.mu.RUnlock()
+		if match {
+			return tracked
+		}
+	}
+	return nil
+}
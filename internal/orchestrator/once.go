@@ -0,0 +1,125 @@
+package orchestrator
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/seedreap/seedreap/internal/filesync"
+)
+
+// Report summarizes the downloads handled by RunOnce.
+type Report struct {
+	Downloads []ReportEntry
+}
+
+// ReportEntry is the outcome of a download handled by RunOnce.
+type ReportEntry struct {
+	ID         string
+	Name       string
+	Downloader string
+	// State is StateComplete once synced and imported, StateDiscovered if no file
+	// had finished downloading, StateWaitingForSpace if the files did not fit on
+	// disk, StateSyncing if some files are still downloading on the seedbox, or
+	// StateError.
+	State DownloadState
+	Error error
+}
+
+// Count returns the number of downloads that ended in state.
+func (r Report) Count(state DownloadState) int {
+	n := 0
+	for _, e := range r.Downloads {
+		if e.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// RunOnce runs a single poll cycle instead of the orchestration loop: it connects
+// to the downloaders and apps, polls them once, waits for the syncs it started,
+// then moves and imports the synced downloads. Files that finish downloading on
+// the seedbox after the poll are left for the next run. The downloaders are
+// closed before it returns.
+func (o *Orchestrator) RunOnce(ctx context.Context) (Report, error) {
+	o.ctx, o.cancel = context.WithCancel(ctx)
+	defer o.Stop()
+
+	if err := o.connect(); err != nil {
+		return Report{}, err
+	}
+
+	o.poll()
+
+	// Each pass moves downloads at most one state further, e.g. from synced to
+	// importing, so repeat until nothing changes.
+	for {
+		o.wg.Wait()
+		if o.ctx.Err() != nil || !o.advanceAll() {
+			break
+		}
+	}
+
+	return o.report(), nil
+}
+
+// advanceAll advances every tracked download that can make progress without
+// polling the downloaders again, and reports whether any state changed.
+func (o *Orchestrator) advanceAll() bool {
+	changed := false
+
+	for _, tracked := range o.GetTrackedDownloads() {
+		tracked.mu.RLock()
+		state := tracked.State
+		downloaderName := tracked.DownloaderName
+		job := tracked.SyncJob
+		tracked.mu.RUnlock()
+
+		switch state {
+		case StateDiscovered, StateWaitingForSpace, StateComplete, StateError:
+			continue
+		case StateSyncing:
+			// Syncing the files still downloading would need another poll
+			if job != nil {
+				if _, status := job.GetProgress(); status == filesync.FileStatusPending {
+					continue
+				}
+			}
+		case StateSynced, StateMoving, StateImporting:
+		}
+
+		dl, ok := o.downloaders.Get(downloaderName)
+		if !ok {
+			continue
+		}
+
+		o.advanceState(tracked, dl)
+		if tracked.GetState() != state {
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// report returns the outcome of every tracked download, sorted by name.
+func (o *Orchestrator) report() Report {
+	var r Report
+	for _, tracked := range o.GetTrackedDownloads() {
+		tracked.mu.RLock()
+		r.Downloads = append(r.Downloads, ReportEntry{
+			ID:         tracked.Download.ID,
+			Name:       tracked.Download.Name,
+			Downloader: tracked.DownloaderName,
+			State:      tracked.State,
+			Error:      tracked.Error,
+		})
+		tracked.mu.RUnlock()
+	}
+
+	slices.SortFunc(r.Downloads, func(a, b ReportEntry) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Downloader, b.Downloader))
+	})
+	return r
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -31,6 +32,8 @@ const (
 const (
 	// StateDiscovered indicates the download was just discovered.
 	StateDiscovered DownloadState = "discovered"
+	// StateWaitingForSpace indicates there is not enough free disk space to start syncing.
+	StateWaitingForSpace DownloadState = "waiting_for_space"
 	// StateSyncing indicates files are being synced.
 	StateSyncing DownloadState = "syncing"
 	// StateSynced indicates all files have been synced.
@@ -56,6 +59,8 @@ type TrackedDownload struct {
 	Error            error
 	DiscoveredAt     time.Time
 	CompletedAt      time.Time
+	FailedAt         time.Time
+	PostImported     bool // Post-import actions ran, so later removals and category changes are their doing
 	mu               sync.RWMutex
 }
 
@@ -103,12 +108,21 @@ type Orchestrator struct {
 	syncer        *filesync.Syncer
 	timeline      timeline.Recorder
 	pollInterval  time.Duration
+	sweepInterval time.Duration
+	retention     Retention
 	downloadsPath string
+	dryRun        bool
 	logger        zerolog.Logger
 
 	tracked   map[string]*TrackedDownload // key: downloaderName:downloadID
+	swept     map[string]string           // key -> category of downloads removed by the sweeper
 	trackedMu sync.RWMutex
 
+	planned   map[string][]PlannedAction // download ID -> actions skipped by a dry run
+	plannedMu sync.Mutex
+
+	spaceUnsupported sync.Once // warns once that free space cannot be measured
+
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -152,8 +166,16 @@ func New(
 		syncer:        syncr,
 		downloadsPath: downloadsPath,
 		pollInterval:  defaultPollInterval,
-		logger:        zerolog.Nop(),
-		tracked:       make(map[string]*TrackedDownload),
+		sweepInterval: defaultSweepInterval,
+		retention: Retention{
+			Completed: defaultCompletedRetention,
+			Errored:   defaultErroredRetention,
+			Cancelled: defaultCancelledRetention,
+		},
+		logger:  zerolog.Nop(),
+		tracked: make(map[string]*TrackedDownload),
+		swept:   make(map[string]string),
+		planned: make(map[string][]PlannedAction),
 	}
 
 	for _, opt := range opts {
@@ -167,6 +189,21 @@ func New(
 func (o *Orchestrator) Start(ctx context.Context) error {
 	o.ctx, o.cancel = context.WithCancel(ctx)
 
+	if err := o.connect(); err != nil {
+		return err
+	}
+
+	// Start polling and retention loops
+	o.wg.Go(o.pollLoop)
+	o.wg.Go(o.sweepLoop)
+
+	o.logger.Info().Msg("orchestrator started")
+	return nil
+}
+
+// connect records the system start, connects to all downloaders and tests the
+// connections to all apps.
+func (o *Orchestrator) connect() error {
 	// Record system start
 	o.recordEvent(timeline.EventSystemStarted, "System started", "", "", "", "", map[string]any{
 		"downloaders": len(o.downloaders.All()),
@@ -203,10 +240,6 @@ func (o *Orchestrator) Start(ctx context.Context) error {
 		}
 	}
 
-	// Start polling loop
-	o.wg.Go(o.pollLoop)
-
-	o.logger.Info().Msg("orchestrator started")
 	return nil
 }
 
@@ -260,10 +293,17 @@ func (o *Orchestrator) pollLoop() {
 func (o *Orchestrator) poll() {
 	o.logger.Debug().Msg("polling downloaders")
 
-	// Track which downloads we've seen in this poll cycle
+	// Track which downloads we've seen in this poll cycle, and which downloaders were listed
 	seenKeys := make(map[string]bool)
+	listed := make(map[string]bool)
 
 	for name, dl := range o.downloaders.All() {
+		// Skip downloaders that no app is bound to
+		if len(o.apps.GetForDownloader(name)) == 0 {
+			o.logger.Debug().Str("downloader", name).Msg("no apps for downloader, skipping")
+			continue
+		}
+
 		// Get categories this downloader handles
 		categories := o.getCategoriesForDownloader(name)
 
@@ -278,6 +318,7 @@ func (o *Orchestrator) poll() {
 			o.logger.Error().Err(err).Str("downloader", name).Msg("failed to list downloads")
 			continue
 		}
+		listed[name] = true
 
 		o.logger.Debug().
 			Str("downloader", name).
@@ -300,14 +341,21 @@ func (o *Orchestrator) poll() {
 
 	// Check for category changes and removed downloads
 	o.checkForChangesAndRemovals(seenKeys)
+	o.forgetSwept(seenKeys, listed)
 }
 
-func (o *Orchestrator) getCategoriesForDownloader(_ string) []string {
+func (o *Orchestrator) getCategoriesForDownloader(name string) []string {
 	var categories []string
-	for _, a := range o.apps.All() {
-		// Check if this app is configured to use this downloader
-		// For now, we assume all apps can use any downloader
-		categories = append(categories, a.Category())
+	for _, a := range o.apps.GetForDownloader(name) {
+		// Apps that route by tag alone can match downloads in any category,
+		// so the downloader has to list everything.
+		if a.Category() == "" {
+			return nil
+		}
+
+		if !slices.Contains(categories, a.Category()) {
+			categories = append(categories, a.Category())
+		}
 	}
 	return categories
 }
@@ -318,8 +366,18 @@ func (o *Orchestrator) processDownload(downloaderName string, dl download.Downlo
 	o.trackedMu.Lock()
 	tracked, exists := o.tracked[key]
 	if !exists {
-		// Find apps for this category
-		apps := o.apps.GetByCategory(dlInfo.Category)
+		// Don't rediscover downloads the sweeper already removed,
+		// unless they have since moved to another category.
+		if category, ok := o.swept[key]; ok {
+			if category == dlInfo.Category {
+				o.trackedMu.Unlock()
+				return
+			}
+			delete(o.swept, key)
+		}
+
+		// Find apps for this category and tags
+		apps := o.apps.GetForDownload(downloaderName, dlInfo.Category, dlInfo.Tags)
 
 		// Only track downloads that have a matching app
 		if len(apps) == 0 {
@@ -327,7 +385,8 @@ func (o *Orchestrator) processDownload(downloaderName string, dl download.Downlo
 			o.logger.Debug().
 				Str("download", dlInfo.Name).
 				Str("category", dlInfo.Category).
-				Msg("no apps for category, skipping")
+				Strs("tags", dlInfo.Tags).
+				Msg("no apps for category or tags, skipping")
 			return
 		}
 
@@ -368,6 +427,7 @@ func (o *Orchestrator) processDownload(downloaderName string, dl download.Downlo
 			downloaderName,
 			map[string]any{
 				"category":   dlInfo.Category,
+				"tags":       dlInfo.Tags,
 				"file_count": len(dlInfo.Files),
 				"size":       dlInfo.Size,
 			},
@@ -375,14 +435,9 @@ func (o *Orchestrator) processDownload(downloaderName string, dl download.Downlo
 	}
 	o.trackedMu.Unlock()
 
-	// Check for category change before updating
-	tracked.mu.RLock()
-	originalCategory := tracked.OriginalCategory
-	tracked.mu.RUnlock()
-
-	if dlInfo.Category != originalCategory {
-		// Category changed - handle it
-		o.handleCategoryChanged(tracked, key, dlInfo.Category)
+	// Check for category or tag changes before updating
+	if o.routingChanged(tracked, downloaderName, dlInfo.Category, dlInfo.Tags) {
+		o.handleCategoryChanged(tracked, key, dlInfo.Category, dlInfo.Tags)
 		return
 	}
 
@@ -421,8 +476,8 @@ func (o *Orchestrator) advanceState(tracked *TrackedDownload, dl download.Downlo
 	tracked.mu.Unlock()
 
 	switch currentState {
-	case StateDiscovered:
-		// Check if download has any completed files to sync
+	case StateDiscovered, StateWaitingForSpace:
+		// Check if download has any completed files to sync, and room for them
 		o.startSyncing(tracked, dl)
 
 	case StateSyncing:
@@ -441,8 +496,7 @@ func (o *Orchestrator) advanceState(tracked *TrackedDownload, dl download.Downlo
 		o.triggerImport(tracked)
 
 	case StateComplete:
-		// Check if we should clean up
-		o.cleanup(tracked)
+		// Nothing to do - the sweeper removes it once retention expires
 
 	case StateError:
 		// Log and potentially retry
@@ -454,6 +508,11 @@ func (o *Orchestrator) startSyncing(tracked *TrackedDownload, dl download.Downlo
 	tracked.mu.Lock()
 	defer tracked.mu.Unlock()
 
+	// The download may have been cancelled since the state was read
+	if tracked.State != StateDiscovered && tracked.State != StateWaitingForSpace {
+		return
+	}
+
 	// Get file states
 	files, err := dl.GetFiles(o.ctx, tracked.Download.ID)
 	if err != nil {
@@ -529,6 +588,10 @@ func (o *Orchestrator) startSyncing(tracked *TrackedDownload, dl download.Downlo
 			Msg("some files already exist at final destination")
 	}
 
+	if o.waitForSpace(tracked, files, existingFiles, finalPath) {
+		return
+	}
+
 	// Create sync job
 	tracked.SyncJob = o.syncer.CreateJob(tracked.Download, tracked.DownloaderName, finalPath)
 	tracked.State = StateSyncing
@@ -539,25 +602,101 @@ func (o *Orchestrator) startSyncing(tracked *TrackedDownload, dl download.Downlo
 		Msg("starting sync")
 
 	// Record sync started event
+	if o.dryRun {
+		o.recordPlanned(
+			timeline.EventWouldSync,
+			fmt.Sprintf("Would sync: %s", tracked.Download.Name),
+			tracked.Download.ID,
+			tracked.Download.Name,
+			"",
+			tracked.DownloaderName,
+			map[string]any{
+				"file_count": len(files),
+				"final_path": finalPath,
+			},
+		)
+	} else {
+		o.recordEvent(
+			timeline.EventSyncStarted,
+			fmt.Sprintf("Sync started: %s", tracked.Download.Name),
+			tracked.Download.ID,
+			tracked.Download.Name,
+			"",
+			tracked.DownloaderName,
+			map[string]any{
+				"file_count": len(files),
+				"final_path": finalPath,
+			},
+		)
+	}
+
+	// Start sync in background - track in WaitGroup so Stop() waits for completion
+	o.wg.Go(func() {
+		if syncErr := o.syncer.SyncJob(o.ctx, dl, tracked.SyncJob); syncErr != nil {
+			o.logger.Error().Err(syncErr).Str("download", tracked.Download.Name).Msg("sync error")
+		}
+	})
+}
+
+// waitForSpace checks that the files missing at finalPath fit on disk. If they
+// do not, the download waits in StateWaitingForSpace and is checked again on
+// every poll until space frees up. Called with tracked.mu held.
+func (o *Orchestrator) waitForSpace(
+	tracked *TrackedDownload, files []download.File, existingFiles []string, finalPath string,
+) bool {
+	existing := make(map[string]bool, len(existingFiles))
+	for _, path := range existingFiles {
+		existing[path] = true
+	}
+
+	var size int64
+	for _, f := range files {
+		if f.Priority != 0 && !existing[f.Path] {
+			size += f.Size
+		}
+	}
+
+	err := o.syncer.CheckSpace(size, finalPath)
+	var spaceErr *filesync.InsufficientSpaceError
+	if !errors.As(err, &spaceErr) {
+		switch {
+		case errors.Is(err, errors.ErrUnsupported):
+			o.spaceUnsupported.Do(func() {
+				o.logger.Warn().Msg("free disk space cannot be measured on this platform, skipping space checks")
+			})
+		case err != nil:
+			o.logger.Warn().Err(err).Str("download", tracked.Download.Name).Msg("failed to check free disk space")
+		}
+		if tracked.State == StateWaitingForSpace {
+			o.logger.Info().Str("download", tracked.Download.Name).Msg("disk space available, resuming")
+			tracked.Error = nil
+		}
+		return false
+	}
+
+	if tracked.State == StateWaitingForSpace {
+		o.logger.Debug().Err(err).Str("download", tracked.Download.Name).Msg("still waiting for disk space")
+		tracked.Error = err
+		return true
+	}
+
+	tracked.State = StateWaitingForSpace
+	tracked.Error = err
+	o.logger.Warn().Err(err).Str("download", tracked.Download.Name).Msg("not enough disk space, waiting")
 	o.recordEvent(
-		timeline.EventSyncStarted,
-		fmt.Sprintf("Sync started: %s", tracked.Download.Name),
+		timeline.EventWaitingForSpace,
+		fmt.Sprintf("Waiting for disk space: %s", tracked.Download.Name),
 		tracked.Download.ID,
 		tracked.Download.Name,
 		"",
 		tracked.DownloaderName,
 		map[string]any{
-			"file_count": len(files),
-			"final_path": finalPath,
+			"path":     spaceErr.Path,
+			"required": spaceErr.Required,
+			"free":     spaceErr.Free,
 		},
 	)
-
-	// Start sync in background - track in WaitGroup so Stop() waits for completion
-	o.wg.Go(func() {
-		if syncErr := o.syncer.SyncJob(o.ctx, dl, tracked.SyncJob); syncErr != nil {
-			o.logger.Error().Err(syncErr).Str("download", tracked.Download.Name).Msg("sync error")
-		}
-	})
+	return true
 }
 
 // checkFilesAtFinal checks if files already exist at the final destination with correct sizes.
@@ -609,6 +748,11 @@ func (o *Orchestrator) continueSyncing(tracked *TrackedDownload, dl download.Dow
 	tracked.mu.Lock()
 	defer tracked.mu.Unlock()
 
+	// The job may have been cancelled since the state was read
+	if tracked.State != StateSyncing {
+		return
+	}
+
 	if tracked.SyncJob == nil {
 		tracked.State = StateDiscovered
 		return
@@ -624,6 +768,11 @@ func (o *Orchestrator) continueSyncing(tracked *TrackedDownload, dl download.Dow
 			Str("download", tracked.Download.Name).
 			Msg("sync complete")
 
+		// Nothing was transferred in a dry run
+		if o.dryRun {
+			return
+		}
+
 		// Record sync complete event
 		o.recordEvent(
 			timeline.EventSyncComplete,
@@ -640,6 +789,7 @@ func (o *Orchestrator) continueSyncing(tracked *TrackedDownload, dl download.Dow
 	case filesync.FileStatusError:
 		tracked.State = StateError
 		tracked.Error = tracked.SyncJob.Error
+		tracked.FailedAt = time.Now()
 		o.logger.Error().
 			Err(tracked.Error).
 			Str("download", tracked.Download.Name).
@@ -699,14 +849,36 @@ func (o *Orchestrator) moveToFinal(tracked *TrackedDownload) {
 		tracked.mu.Lock()
 		tracked.State = StateError
 		tracked.Error = errors.New("no sync job")
+		tracked.FailedAt = time.Now()
 		tracked.mu.Unlock()
 		return
 	}
 
+	if o.dryRun {
+		tracked.mu.Lock()
+		tracked.State = StateImporting
+		tracked.mu.Unlock()
+
+		o.recordPlanned(
+			timeline.EventWouldMove,
+			fmt.Sprintf("Would move to final location: %s", tracked.Download.Name),
+			tracked.Download.ID,
+			tracked.Download.Name,
+			"",
+			tracked.DownloaderName,
+			map[string]any{
+				"from": job.LocalBase,
+				"path": job.FinalPath,
+			},
+		)
+		return
+	}
+
 	if err := o.syncer.MoveToFinal(job); err != nil {
 		tracked.mu.Lock()
 		tracked.State = StateError
 		tracked.Error = err
+		tracked.FailedAt = time.Now()
 		tracked.mu.Unlock()
 		o.logger.Error().Err(err).Str("download", tracked.Download.Name).Msg("move error")
 		return
@@ -748,6 +920,17 @@ func (o *Orchestrator) triggerImport(tracked *TrackedDownload) {
 		return
 	}
 
+	if o.dryRun {
+		o.planImport(tracked, apps, filepath.Join(job.FinalPath, filepath.Base(downloadName)))
+		o.planPostImport(tracked, apps)
+
+		tracked.mu.Lock()
+		tracked.State = StateComplete
+		tracked.CompletedAt = time.Now()
+		tracked.mu.Unlock()
+		return
+	}
+
 	if len(apps) == 0 {
 		o.logger.Info().
 			Str("download", downloadName).
@@ -755,6 +938,7 @@ func (o *Orchestrator) triggerImport(tracked *TrackedDownload) {
 			Msg("sync complete, no apps to trigger import")
 	} else {
 		importPath := filepath.Join(job.FinalPath, filepath.Base(downloadName))
+		allImported := true
 
 		for _, a := range apps {
 			// Record import started event
@@ -774,6 +958,7 @@ func (o *Orchestrator) triggerImport(tracked *TrackedDownload) {
 					"path":  importPath,
 					"error": err.Error(),
 				})
+				allImported = false
 			} else {
 				o.logger.Info().
 					Str("download", downloadName).
@@ -786,6 +971,8 @@ func (o *Orchestrator) triggerImport(tracked *TrackedDownload) {
 				})
 			}
 		}
+
+		o.postImport(tracked, apps, allImported)
 	}
 
 	tracked.mu.Lock()
@@ -805,24 +992,6 @@ func (o *Orchestrator) triggerImport(tracked *TrackedDownload) {
 	)
 }
 
-func (o *Orchestrator) cleanup(tracked *TrackedDownload) {
-	// For now, just remove from tracking after some time
-	tracked.mu.RLock()
-	completedAt := tracked.CompletedAt
-	tracked.mu.RUnlock()
-
-	if time.Since(completedAt) > 24*time.Hour {
-		key := fmt.Sprintf("%s:%s", tracked.DownloaderName, tracked.Download.ID)
-		o.trackedMu.Lock()
-		delete(o.tracked, key)
-		o.trackedMu.Unlock()
-
-		if tracked.SyncJob != nil {
-			o.syncer.RemoveJob(tracked.Download.ID)
-		}
-	}
-}
-
 func (o *Orchestrator) handleError(tracked *TrackedDownload) {
 	// Log the error, could implement retry logic here
 	tracked.mu.RLock()
@@ -871,7 +1040,6 @@ func (o *Orchestrator) checkForChangesAndRemovals(seenKeys map[string]bool) {
 		downloaderName := tracked.DownloaderName
 		downloadID := tracked.Download.ID
 		downloadName := tracked.Download.Name
-		originalCategory := tracked.OriginalCategory
 		tracked.mu.RUnlock()
 
 		// Get the downloader
@@ -888,11 +1056,11 @@ func (o *Orchestrator) checkForChangesAndRemovals(seenKeys map[string]bool) {
 			continue
 		}
 
-		// Download still exists - check if category changed
-		if download.Category != originalCategory {
-			o.handleCategoryChanged(tracked, key, download.Category)
+		// Download still exists - check if its category or tags changed
+		if o.routingChanged(tracked, downloaderName, download.Category, download.Tags) {
+			o.handleCategoryChanged(tracked, key, download.Category, download.Tags)
 		} else {
-			// Category didn't change, download just not in our filtered list
+			// Nothing changed, download just not in our filtered list
 			// This could happen if we filter by category in ListDownloads
 			o.logger.Debug().
 				Str("download", downloadName).
@@ -902,6 +1070,29 @@ func (o *Orchestrator) checkForChangesAndRemovals(seenKeys map[string]bool) {
 	}
 }
 
+// routingChanged reports whether a tracked download moved to another category,
+// or its tags changed so that other apps now match it.
+func (o *Orchestrator) routingChanged(tracked *TrackedDownload, downloaderName, category string, tags []string) bool {
+	tracked.mu.RLock()
+	originalCategory := tracked.OriginalCategory
+	oldTags := tracked.Download.Tags
+	apps := tracked.Apps
+	tracked.mu.RUnlock()
+
+	if category != originalCategory {
+		return true
+	}
+	if sameTags(oldTags, tags) {
+		return false
+	}
+	return !sameApps(apps, o.apps.GetForDownload(downloaderName, category, tags))
+}
+
+// sameTags reports whether two tag lists hold the same tags, in any order.
+func sameTags(a, b []string) bool {
+	return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+}
+
 // handleDownloadRemoved handles when a download is removed from the download.
 func (o *Orchestrator) handleDownloadRemoved(tracked *TrackedDownload, key string) {
 	tracked.mu.RLock()
@@ -911,6 +1102,7 @@ func (o *Orchestrator) handleDownloadRemoved(tracked *TrackedDownload, key strin
 	job := tracked.SyncJob
 	state := tracked.State
 	downloaderName := tracked.DownloaderName
+	postImported := tracked.PostImported
 	tracked.mu.RUnlock()
 
 	o.logger.Info().
@@ -941,6 +1133,11 @@ func (o *Orchestrator) handleDownloadRemoved(tracked *TrackedDownload, key strin
 		}
 		// CancelJob cleans up staging files, now cleanup any final files if they exist
 		o.cleanupSyncedFiles(tracked, "removed while syncing")
+	} else if state == StateComplete && postImported {
+		// Deleted by a post-import action, the synced files are what was imported
+		o.logger.Info().
+			Str("download", downloadName).
+			Msg("download removed by post-import actions, keeping synced files")
 	} else if state == StateComplete {
 		// Check if any app wants cleanup on remove
 		shouldCleanup := false
@@ -960,29 +1157,39 @@ func (o *Orchestrator) handleDownloadRemoved(tracked *TrackedDownload, key strin
 	o.removeFromTracking(tracked, key)
 }
 
-// handleCategoryChanged handles when a download's category changes.
-func (o *Orchestrator) handleCategoryChanged(tracked *TrackedDownload, key string, newCategory string) {
+// handleCategoryChanged handles when a download's category changes, or its
+// tags change which apps it belongs to.
+func (o *Orchestrator) handleCategoryChanged(tracked *TrackedDownload, key, newCategory string, tags []string) {
 	tracked.mu.RLock()
 	downloadName := tracked.Download.Name
 	downloadID := tracked.Download.ID
 	originalCategory := tracked.OriginalCategory
+	oldTags := tracked.Download.Tags
 	apps := tracked.Apps
 	job := tracked.SyncJob
 	state := tracked.State
 	downloaderName := tracked.DownloaderName
+	postImported := tracked.PostImported
 	tracked.mu.RUnlock()
 
 	o.logger.Info().
 		Str("download", downloadName).
 		Str("old_category", originalCategory).
 		Str("new_category", newCategory).
+		Strs("old_tags", oldTags).
+		Strs("new_tags", tags).
 		Str("state", string(state)).
-		Msg("download category changed")
+		Msg("download category or tags changed")
+
+	message := fmt.Sprintf("Category changed: %s (%s -> %s)", downloadName, originalCategory, newCategory)
+	if newCategory == originalCategory {
+		message = fmt.Sprintf("Tags changed: %s", downloadName)
+	}
 
 	// Record category changed event
 	o.recordEvent(
 		timeline.EventCategoryChanged,
-		fmt.Sprintf("Category changed: %s (%s -> %s)", downloadName, originalCategory, newCategory),
+		message,
 		downloadID,
 		downloadName,
 		"",
@@ -990,12 +1197,34 @@ func (o *Orchestrator) handleCategoryChanged(tracked *TrackedDownload, key strin
 		map[string]any{
 			"old_category": originalCategory,
 			"new_category": newCategory,
+			"old_tags":     oldTags,
+			"new_tags":     tags,
 			"state":        string(state),
 		},
 	)
 
+	// A category or tags set by a post-import action neither migrate the
+	// download to another app nor clean up the files that were imported
+	if postImported {
+		tracked.mu.Lock()
+		tracked.OriginalCategory = newCategory
+		tracked.Download.Tags = tags
+		tracked.mu.Unlock()
+		return
+	}
+
 	// Check if new category belongs to another app
-	newApps := o.apps.GetByCategory(newCategory)
+	newApps := o.apps.GetForDownload(downloaderName, newCategory, tags)
+
+	// Downloads routed by tag can still belong to the same apps after a category
+	// change, in which case there is nothing to migrate or clean up.
+	if len(newApps) > 0 && sameApps(apps, newApps) {
+		tracked.mu.Lock()
+		tracked.OriginalCategory = newCategory
+		tracked.Download.Tags = tags
+		tracked.mu.Unlock()
+		return
+	}
 
 	if len(newApps) > 0 {
 		if state == StateComplete {
@@ -1041,6 +1270,23 @@ func (o *Orchestrator) handleCategoryChanged(tracked *TrackedDownload, key strin
 	o.removeFromTracking(tracked, key)
 }
 
+// sameApps reports whether two app lists contain the same apps.
+func sameApps(a, b []app.App) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	names := make(map[string]bool, len(a))
+	for _, x := range a {
+		names[x.Name()] = true
+	}
+	for _, x := range b {
+		if !names[x.Name()] {
+			return false
+		}
+	}
+	return true
+}
+
 // handleCategoryMigrationWhileSyncing updates a syncing job to use a new app's path.
 func (o *Orchestrator) handleCategoryMigrationWhileSyncing(
 	tracked *TrackedDownload, newCategory string, newApps []app.App,
@@ -1122,6 +1368,28 @@ func (o *Orchestrator) handleCategoryMigration(tracked *TrackedDownload, key str
 		Str("new_app", newApp.Name()).
 		Msg("migrating synced files to new app")
 
+	if o.dryRun {
+		tracked.mu.RLock()
+		downloadID := tracked.Download.ID
+		tracked.mu.RUnlock()
+
+		o.recordPlanned(
+			timeline.EventWouldMove,
+			fmt.Sprintf("Would move to new app: %s -> %s", downloadName, newApp.Name()),
+			downloadID,
+			downloadName,
+			newApp.Name(),
+			tracked.DownloaderName,
+			map[string]any{
+				"from": oldPath,
+				"path": newPath,
+			},
+		)
+		o.planImport(tracked, newApps, newPath)
+		o.removeFromTracking(tracked, key)
+		return
+	}
+
 	// Create destination directory
 	if err := os.MkdirAll(filepath.Dir(newPath), 0750); err != nil {
 		o.logger.Error().
@@ -1203,6 +1471,31 @@ func (o *Orchestrator) cleanupSyncedFiles(tracked *TrackedDownload, reason strin
 		Str("reason", reason).
 		Msg("cleaning up synced files")
 
+	if o.dryRun {
+		o.recordPlanned(
+			timeline.EventWouldCleanup,
+			fmt.Sprintf("Would clean up: %s", downloadName),
+			downloadID,
+			downloadName,
+			"",
+			downloaderName,
+			map[string]any{
+				"path":   cleanupPath,
+				"reason": reason,
+			},
+		)
+		return
+	}
+
+	// A publish that failed or was interrupted can leave gathered files behind
+	if err := os.RemoveAll(job.PublishDir()); err != nil {
+		o.logger.Warn().
+			Err(err).
+			Str("download", downloadName).
+			Str("path", job.PublishDir()).
+			Msg("failed to remove publish directory")
+	}
+
 	if err := os.RemoveAll(cleanupPath); err != nil {
 		o.logger.Error().
 			Err(err).
@@ -1234,6 +1527,10 @@ func (o *Orchestrator) removeFromTracking(tracked *TrackedDownload, key string)
 	delete(o.tracked, key)
 	o.trackedMu.Unlock()
 
+	o.plannedMu.Lock()
+	delete(o.planned, downloadID)
+	o.plannedMu.Unlock()
+
 	if syncJob != nil {
 		o.syncer.RemoveJob(downloadID)
 	}
@@ -3,19 +3,25 @@ package orchestrator_test
 import (
 	"context"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/seedreap/seedreap/internal/app"
+	"github.com/seedreap/seedreap/internal/config"
 	"github.com/seedreap/seedreap/internal/download"
 	"github.com/seedreap/seedreap/internal/filesync"
 	"github.com/seedreap/seedreap/internal/orchestrator"
 	testutil "github.com/seedreap/seedreap/internal/testing"
+	"github.com/seedreap/seedreap/internal/timeline"
 	"github.com/seedreap/seedreap/internal/transfer"
 )
 
@@ -36,7 +42,17 @@ type testOrchestrator struct {
 }
 
 // newTestOrchestrator creates a new test orchestrator with mocks.
-func newTestOrchestrator(t *testing.T) *testOrchestrator {
+// Additional options are applied after the test defaults.
+func newTestOrchestrator(t *testing.T, opts ...orchestrator.Option) *testOrchestrator {
+	t.Helper()
+	return newTestOrchestratorWithSyncer(t, nil, opts...)
+}
+
+// newTestOrchestratorWithSyncer creates a new test orchestrator whose syncer is
+// configured with syncerOpts on top of the test defaults.
+func newTestOrchestratorWithSyncer(
+	t *testing.T, syncerOpts []filesync.Option, opts ...orchestrator.Option,
+) *testOrchestrator {
 	t.Helper()
 
 	tmpDir := t.TempDir()
@@ -59,10 +75,10 @@ func newTestOrchestrator(t *testing.T) *testOrchestrator {
 	appRegistry := app.NewRegistry()
 
 	// Create syncer with mock transferer
-	syncr := filesync.New(syncingPath,
+	syncr := filesync.New(syncingPath, append([]filesync.Option{
 		filesync.WithMaxConcurrent(2),
 		filesync.WithTransferer(mockTransfer),
-	)
+	}, syncerOpts...)...)
 
 	// Create orchestrator with short poll interval for testing
 	orch := orchestrator.New(
@@ -70,7 +86,7 @@ func newTestOrchestrator(t *testing.T) *testOrchestrator {
 		appRegistry,
 		syncr,
 		downloadsPath,
-		orchestrator.WithPollInterval(50*time.Millisecond),
+		append([]orchestrator.Option{orchestrator.WithPollInterval(50 * time.Millisecond)}, opts...)...,
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -441,12 +457,16 @@ func (to *testOrchestrator) configureTransferWithFileCreation(delay time.Duratio
 		if onProgress != nil {
 			onProgress(transfer.Progress{Transferred: req.Size, BytesPerSec: 1024 * 1024})
 		}
+		return writeTransferredFile(req)
+	}
+}
 
-		if err := os.MkdirAll(filepath.Dir(req.LocalPath), 0750); err != nil {
-			return err
-		}
-		return os.WriteFile(req.LocalPath, make([]byte, req.Size), 0644)
+// writeTransferredFile creates the local file of a transfer request.
+func writeTransferredFile(req transfer.Request) error {
+	if err := os.MkdirAll(filepath.Dir(req.LocalPath), 0750); err != nil {
+		return err
 	}
+	return os.WriteFile(req.LocalPath, make([]byte, req.Size), 0644)
 }
 
 func TestCategoryChangedToUntracked_Complete(t *testing.T) {
@@ -1002,8 +1022,312 @@ func TestSyncErrors(t *testing.T) {
 	})
 }
 
+// --- Disk Space Tests ---
+
+func TestWaitingForSpace(t *testing.T) {
+	var free atomic.Int64
+	free.Store(1024 * 1024) // Room for the 1 MB download, but not the reserve
+
+	tl := timeline.NewRecorder()
+	to := newTestOrchestratorWithSyncer(t,
+		[]filesync.Option{
+			filesync.WithMinFreeSpace(512 * 1024),
+			filesync.WithFreeSpaceFunc(func(string) (int64, error) { return free.Load(), nil }),
+		},
+		orchestrator.WithTimeline(tl),
+	)
+	defer to.stop()
+
+	to.addApp("sonarr", "tv-sonarr")
+	to.configureTransferWithFileCreation(0)
+
+	dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+	to.mockDL.AddDownload(dl, files)
+
+	to.start()
+
+	require.True(t, to.waitForState("hash1", orchestrator.StateWaitingForSpace, 500*time.Millisecond),
+		"should wait for disk space")
+
+	// Several polls while waiting record a single event and start no transfer
+	time.Sleep(200 * time.Millisecond)
+	assert.Empty(t, to.mockTransfer.GetTransferCalls())
+
+	td := to.getTrackedDownload("hash1")
+	require.NotNil(t, td)
+	var spaceErr *filesync.InsufficientSpaceError
+	require.ErrorAs(t, td.GetError(), &spaceErr)
+	assert.Equal(t, int64(1536*1024), spaceErr.Required)
+	assert.Equal(t, int64(1024*1024), spaceErr.Free)
+
+	var waiting []timeline.Event
+	for _, e := range tl.GetAll() {
+		if e.Type == timeline.EventWaitingForSpace {
+			waiting = append(waiting, e)
+		}
+	}
+	require.Len(t, waiting, 1)
+	assert.Equal(t, to.syncingPath, waiting[0].Details["path"])
+
+	// Resumes once space frees up
+	free.Store(10 * 1024 * 1024)
+
+	require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second),
+		"should sync once space is available")
+	assert.NoError(t, td.GetError())
+}
+
+func TestSpaceCheckUnsupported(t *testing.T) {
+	var warnings atomic.Int32
+	logger := zerolog.New(io.Discard).Hook(zerolog.HookFunc(func(_ *zerolog.Event, level zerolog.Level, _ string) {
+		if level == zerolog.WarnLevel {
+			warnings.Add(1)
+		}
+	}))
+
+	to := newTestOrchestratorWithSyncer(t,
+		[]filesync.Option{
+			filesync.WithFreeSpaceFunc(func(string) (int64, error) { return 0, errors.ErrUnsupported }),
+		},
+		orchestrator.WithLogger(logger),
+	)
+	defer to.stop()
+
+	to.addApp("sonarr", "tv-sonarr")
+	to.configureTransferWithFileCreation(0)
+
+	for _, id := range []string{"hash1", "hash2"} {
+		dl, files := createTestDownload(id, "TestShow."+id, "tv-sonarr")
+		to.mockDL.AddDownload(dl, files)
+	}
+
+	to.start()
+
+	require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second))
+	require.True(t, to.waitForState("hash2", orchestrator.StateComplete, 2*time.Second))
+	assert.Equal(t, int32(1), warnings.Load(), "the unsupported check should be logged once")
+}
+
+// --- Cancel and Retry Tests ---
+
+func TestCancelAndRetryJob(t *testing.T) {
+	t.Run("CancelWhileSyncingThenRetry", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+
+		to.addApp("sonarr", "tv-sonarr")
+
+		// Block transfers until the job is cancelled, then let the retry through
+		var blocked atomic.Bool
+		blocked.Store(true)
+		to.mockTransfer.OnTransfer = func(ctx context.Context, req transfer.Request, _ transfer.ProgressFunc) error {
+			if !blocked.Load() {
+				return writeTransferredFile(req)
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		dl, _ := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+		to.mockDL.AddDownload(dl, dl.Files)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateSyncing, 500*time.Millisecond),
+			"download should start syncing")
+
+		require.NoError(t, to.orch.CancelJob("hash1"))
+
+		td := to.getTrackedDownload("hash1")
+		require.NotNil(t, td)
+		assert.Equal(t, orchestrator.StateError, td.GetState())
+		require.ErrorContains(t, td.GetError(), "cancelled")
+
+		stagingPath := filepath.Join(to.syncingPath, "test-downloader", "hash1")
+		assert.Eventually(t, func() bool { return !fileExists(stagingPath) }, time.Second, 10*time.Millisecond,
+			"staging directory should be cleaned up")
+
+		// Stays cancelled across polls
+		time.Sleep(150 * time.Millisecond)
+		assert.Equal(t, orchestrator.StateError, td.GetState())
+
+		require.ErrorIs(t, to.orch.CancelJob("hash1"), orchestrator.ErrJobNotCancellable)
+
+		blocked.Store(false)
+		require.NoError(t, to.orch.RetryJob("hash1"))
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second),
+			"retried download should complete")
+		require.NoError(t, td.GetError())
+
+		require.ErrorIs(t, to.orch.RetryJob("hash1"), orchestrator.ErrJobNotRetryable)
+	})
+
+	t.Run("RetryAfterError", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+
+		to.addApp("sonarr", "tv-sonarr")
+
+		var failing atomic.Bool
+		failing.Store(true)
+		to.mockTransfer.OnTransfer = func(_ context.Context, req transfer.Request, _ transfer.ProgressFunc) error {
+			if failing.Load() {
+				return errors.New("transfer failed")
+			}
+			return writeTransferredFile(req)
+		}
+
+		dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+		to.mockDL.AddDownload(dl, files)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateError, 2*time.Second),
+			"should reach error state on transfer failure")
+
+		failing.Store(false)
+
+		require.NoError(t, to.orch.RetryJob("hash1"))
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second),
+			"retried download should complete")
+	})
+
+	t.Run("UnknownJob", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+
+		require.ErrorIs(t, to.orch.CancelJob("missing"), orchestrator.ErrJobNotFound)
+		require.ErrorIs(t, to.orch.RetryJob("missing"), orchestrator.ErrJobNotFound)
+	})
+}
+
 // --- Import Error Tests ---
 
+func TestRunOnce(t *testing.T) {
+	to := newTestOrchestrator(t)
+	app := to.addApp("sonarr", "tv-sonarr")
+
+	to.mockTransfer.OnTransfer = func(
+		_ context.Context, req transfer.Request, onProgress transfer.ProgressFunc,
+	) error {
+		if strings.Contains(req.RemotePath, "Broken") {
+			return errors.New("transfer failed")
+		}
+		if onProgress != nil {
+			onProgress(transfer.Progress{Transferred: req.Size})
+		}
+		return writeTransferredFile(req)
+	}
+
+	complete, files := createTestDownload("hash1", "Complete.S01E01", "tv-sonarr")
+	to.mockDL.AddDownload(complete, files)
+
+	broken, files := createTestDownload("hash2", "Broken.S01E01", "tv-sonarr")
+	to.mockDL.AddDownload(broken, files)
+
+	// One of two files is still downloading on the seedbox
+	partial, files := createTestDownload("hash3", "Partial.S01E01", "tv-sonarr")
+	partial.State = download.TorrentStateDownloading
+	files[1].State = download.FileStateDownloading
+	to.mockDL.AddDownload(partial, files)
+
+	// No file has finished downloading
+	waiting, files := createTestDownload("hash4", "Waiting.S01E01", "tv-sonarr")
+	waiting.State = download.TorrentStateDownloading
+	for i := range files {
+		files[i].State = download.FileStateDownloading
+	}
+	to.mockDL.AddDownload(waiting, files)
+
+	report, err := to.orch.RunOnce(to.ctx)
+	require.NoError(t, err)
+
+	states := map[string]orchestrator.DownloadState{}
+	for _, e := range report.Downloads {
+		states[e.Name] = e.State
+		if e.State == orchestrator.StateError {
+			assert.ErrorContains(t, e.Error, "transfer failed")
+		}
+	}
+	assert.Equal(t, map[string]orchestrator.DownloadState{
+		"Broken.S01E01":   orchestrator.StateError,
+		"Complete.S01E01": orchestrator.StateComplete,
+		"Partial.S01E01":  orchestrator.StateSyncing,
+		"Waiting.S01E01":  orchestrator.StateDiscovered,
+	}, states)
+	assert.Equal(t, 1, report.Count(orchestrator.StateComplete))
+	assert.Equal(t, 1, report.Count(orchestrator.StateError))
+
+	assert.True(t, fileExists(filepath.Join(to.downloadsPath, "tv-sonarr", "Complete.S01E01", "file1.mkv")))
+	assert.Len(t, app.GetImportCalls(), 1, "only the complete download should be imported")
+}
+
+func TestDryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	downloadsPath := filepath.Join(tmpDir, "downloads")
+
+	mockDL := testutil.NewMockDownloader("test-downloader")
+	actionDL := &testutil.MockActionDownloader{MockDownloader: mockDL}
+	mockTransfer := testutil.NewMockTransferer()
+	dlRegistry := download.NewRegistry()
+	dlRegistry.Register("test-downloader", actionDL)
+
+	appRegistry := app.NewRegistry()
+	mockApp := testutil.NewMockApp("sonarr", "tv-sonarr", filepath.Join(downloadsPath, "tv-sonarr"))
+	mockApp.SetPostImport(config.PostImportConfig{Delete: config.PostImportDeleteTorrentAndData})
+	appRegistry.Register("sonarr", mockApp)
+
+	syncr := filesync.New(filepath.Join(tmpDir, "syncing"),
+		filesync.WithTransferer(mockTransfer),
+		filesync.WithDryRun(true),
+	)
+	tl := timeline.NewRecorder()
+	orch := orchestrator.New(dlRegistry, appRegistry, syncr, downloadsPath,
+		orchestrator.WithTimeline(tl),
+		orchestrator.WithDryRun(true),
+	)
+
+	dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+	mockDL.AddDownload(dl, files)
+
+	_, err := orch.RunOnce(t.Context())
+	require.NoError(t, err)
+
+	// Nothing was transferred, moved, imported or deleted
+	assert.Empty(t, mockTransfer.GetTransferCalls())
+	assert.Empty(t, mockApp.GetImportCalls())
+	assert.Empty(t, actionDL.Actions())
+	assert.NoDirExists(t, downloadsPath)
+
+	plan := orch.Plan()
+	require.Len(t, plan, 1)
+	assert.Equal(t, "TestShow.S01E01", plan[0].Name)
+	assert.Equal(t, []string{"sonarr"}, plan[0].Apps)
+	assert.Equal(t, filepath.Join(downloadsPath, "tv-sonarr"), plan[0].FinalPath)
+	assert.Len(t, plan[0].Files, 2)
+
+	var actions []timeline.EventType
+	for _, a := range plan[0].Actions {
+		actions = append(actions, a.Type)
+	}
+	assert.Equal(t, []timeline.EventType{
+		timeline.EventWouldSync,
+		timeline.EventWouldMove,
+		timeline.EventWouldImport,
+		timeline.EventWouldPostImport,
+	}, actions)
+
+	for _, e := range tl.GetAll() {
+		assert.NotContains(t, []timeline.EventType{
+			timeline.EventSyncStarted,
+			timeline.EventSyncComplete,
+			timeline.EventMoveComplete,
+			timeline.EventImportComplete,
+			timeline.EventPostImportAction,
+			timeline.EventComplete,
+		}, e.Type, "dry run should only record what it would do")
+	}
+}
+
 func TestImportErrors(t *testing.T) {
 	t.Run("ContinuesOnImportError", func(t *testing.T) {
 		to := newTestOrchestrator(t)
@@ -1029,6 +1353,425 @@ func TestImportErrors(t *testing.T) {
 	})
 }
 
+// --- Tag Routing Tests ---
+
+func TestTagRouting(t *testing.T) {
+	t.Run("TracksDownloadMatchedByTag", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+
+		sonarr := to.addApp("sonarr", "tv-sonarr", func(m *testutil.MockApp) {
+			m.SetTags([]string{"sonarr"}, app.TagMatchAny)
+		})
+
+		tagged, taggedFiles := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+		tagged.Tags = []string{"sonarr"}
+		to.mockDL.AddDownload(tagged, taggedFiles)
+
+		untagged, untaggedFiles := createTestDownload("hash2", "TestShow.S01E02", "tv-sonarr")
+		to.mockDL.AddDownload(untagged, untaggedFiles)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second),
+			"tagged download should complete")
+		assert.Nil(t, to.getTrackedDownload("hash2"), "untagged download should not be tracked")
+		assert.Len(t, sonarr.GetImportCalls(), 1)
+	})
+
+	t.Run("CategoryChangeKeepsTagRoutedDownload", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+
+		to.addApp("sonarr", "", func(m *testutil.MockApp) {
+			m.SetTags([]string{"sonarr"}, app.TagMatchAny)
+			m.SetCleanupOnCategoryChange(true)
+		})
+
+		dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv")
+		dl.Tags = []string{"sonarr"}
+		to.mockDL.AddDownload(dl, files)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second),
+			"download should complete")
+
+		to.mockDL.SetCategory("hash1", "tv-imported")
+
+		// Still matched by tag, so it stays tracked and files are kept
+		time.Sleep(200 * time.Millisecond)
+		td := to.getTrackedDownload("hash1")
+		require.NotNil(t, td, "download should remain tracked")
+		assert.Equal(t, orchestrator.StateComplete, td.GetState())
+		assert.True(t, fileExists(filepath.Join(to.downloadsPath, dl.Name, "file1.mkv")),
+			"synced files should not be cleaned up")
+	})
+
+	t.Run("TagChangeMigratesToMatchingApp", func(t *testing.T) {
+		tl := timeline.NewRecorder()
+		to := newTestOrchestrator(t, orchestrator.WithTimeline(tl))
+		defer to.stop()
+
+		sonarr := to.addApp("sonarr", "", func(m *testutil.MockApp) {
+			m.SetTags([]string{"sonarr"}, app.TagMatchAny)
+		})
+		anime := to.addApp("sonarr-anime", "", func(m *testutil.MockApp) {
+			m.SetTags([]string{"anime"}, app.TagMatchAny)
+		})
+
+		changes := func() []timeline.Event {
+			events, _ := tl.Query(timeline.Query{Types: []timeline.EventType{timeline.EventCategoryChanged}})
+			return events
+		}
+
+		dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv")
+		dl.Tags = []string{"sonarr"}
+		to.mockDL.AddDownload(dl, files)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second),
+			"download should complete")
+		require.Len(t, sonarr.GetImportCalls(), 1)
+
+		// A tag the apps don't route on changes nothing
+		to.mockDL.SetTags("hash1", []string{"sonarr", "seeding"})
+		time.Sleep(200 * time.Millisecond)
+		assert.Empty(t, changes())
+
+		to.mockDL.SetTags("hash1", []string{"anime"})
+
+		require.Eventually(t, func() bool {
+			return len(anime.GetImportCalls()) > 0
+		}, 2*time.Second, 10*time.Millisecond, "download should be imported by the app its tags now match")
+
+		require.Len(t, changes(), 1)
+		assert.Equal(t, "Tags changed: TestShow.S01E01", changes()[0].Message)
+	})
+}
+
+// --- Downloader Binding Tests ---
+
+func TestDownloaderBinding(t *testing.T) {
+	t.Run("SameCategoryRoutesPerDownloader", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+
+		otherDL := testutil.NewMockDownloader("other-downloader")
+		to.dlRegistry.Register("other-downloader", otherDL)
+
+		sonarrA := to.addApp("sonarr-a", "tv")
+		sonarrB := to.addApp("sonarr-b", "tv")
+		to.appRegistry.RegisterForDownloader("test-downloader", sonarrA)
+		to.appRegistry.RegisterForDownloader("other-downloader", sonarrB)
+
+		dlA, filesA := createTestDownload("hash1", "ShowA.S01E01", "tv")
+		to.mockDL.AddDownload(dlA, filesA)
+		dlB, filesB := createTestDownload("hash2", "ShowB.S01E01", "tv")
+		otherDL.AddDownload(dlB, filesB)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second))
+		require.True(t, to.waitForState("hash2", orchestrator.StateComplete, 2*time.Second))
+
+		assert.Len(t, sonarrA.GetImportCalls(), 1)
+		assert.Len(t, sonarrB.GetImportCalls(), 1)
+
+		tdA := to.getTrackedDownload("hash1")
+		require.NotNil(t, tdA)
+		require.Len(t, tdA.Apps, 1)
+		assert.Equal(t, "sonarr-a", tdA.Apps[0].Name())
+	})
+
+	t.Run("UnboundDownloaderIsNotPolled", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+
+		otherDL := testutil.NewMockDownloader("other-downloader")
+		otherPolled := make(chan struct{}, 1)
+		otherDL.OnListDownloads = func(_ context.Context, _ []string) ([]download.Download, error) {
+			select {
+			case otherPolled <- struct{}{}:
+			default:
+			}
+			return nil, nil
+		}
+		to.dlRegistry.Register("other-downloader", otherDL)
+
+		sonarr := to.addApp("sonarr", "tv")
+		to.appRegistry.RegisterForDownloader("test-downloader", sonarr)
+
+		to.start()
+		time.Sleep(150 * time.Millisecond)
+
+		select {
+		case <-otherPolled:
+			t.Fatal("downloader without apps should not be polled")
+		default:
+		}
+	})
+}
+
+// --- Retention Tests ---
+
+func TestRetention(t *testing.T) {
+	t.Run("PrunesCompletedAfterRetention", func(t *testing.T) {
+		recorder := timeline.NewRecorder()
+		to := newTestOrchestrator(t,
+			orchestrator.WithTimeline(recorder),
+			orchestrator.WithRetention(orchestrator.Retention{Completed: 100 * time.Millisecond}),
+			orchestrator.WithSweepInterval(20*time.Millisecond),
+		)
+		defer to.stop()
+
+		sonarr := to.addApp("sonarr", "tv-sonarr")
+
+		dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+		to.mockDL.AddDownload(dl, files)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second))
+		require.True(t, to.waitForUntracked("hash1", 2*time.Second), "should be pruned after retention")
+
+		// Still present in the downloader, but must not be rediscovered and re-imported
+		time.Sleep(200 * time.Millisecond)
+		assert.Nil(t, to.getTrackedDownload("hash1"))
+		assert.Len(t, sonarr.GetImportCalls(), 1)
+
+		var pruned []timeline.Event
+		for _, e := range recorder.GetByDownload("hash1") {
+			if e.Type == timeline.EventPruned {
+				pruned = append(pruned, e)
+			}
+		}
+		require.Len(t, pruned, 1)
+		assert.Equal(t, "completed", pruned[0].Details["reason"])
+	})
+
+	t.Run("PrunedErrorsAreNotRetried", func(t *testing.T) {
+		to := newTestOrchestrator(t,
+			orchestrator.WithRetention(orchestrator.Retention{Errored: 100 * time.Millisecond}),
+			orchestrator.WithSweepInterval(20*time.Millisecond),
+		)
+		defer to.stop()
+
+		to.addApp("sonarr", "tv-sonarr")
+
+		var attempts atomic.Int32
+		to.mockTransfer.OnTransfer = func(_ context.Context, _ transfer.Request, _ transfer.ProgressFunc) error {
+			attempts.Add(1)
+			return errors.New("transfer failed")
+		}
+
+		dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+		to.mockDL.AddDownload(dl, files)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateError, 2*time.Second))
+		require.True(t, to.waitForUntracked("hash1", 2*time.Second), "should be pruned after retention")
+		tried := attempts.Load()
+
+		// Still present in the downloader, but must not be rediscovered and synced again
+		time.Sleep(200 * time.Millisecond)
+		assert.Nil(t, to.getTrackedDownload("hash1"))
+		assert.Equal(t, tried, attempts.Load(), "pruned download should not be retried")
+	})
+
+	t.Run("NegativeRetentionKeepsCompleted", func(t *testing.T) {
+		to := newTestOrchestrator(t,
+			orchestrator.WithRetention(orchestrator.Retention{Completed: -1}),
+			orchestrator.WithSweepInterval(20*time.Millisecond),
+		)
+		defer to.stop()
+
+		to.addApp("sonarr", "tv-sonarr")
+
+		dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+		to.mockDL.AddDownload(dl, files)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second))
+		time.Sleep(100 * time.Millisecond)
+		assert.NotNil(t, to.getTrackedDownload("hash1"), "should be kept")
+	})
+
+	t.Run("RemovesOrphanedStagingDirectories", func(t *testing.T) {
+		recorder := timeline.NewRecorder()
+		to := newTestOrchestrator(t,
+			orchestrator.WithTimeline(recorder),
+			orchestrator.WithSweepInterval(20*time.Millisecond),
+		)
+		defer to.stop()
+
+		// A job of a previous run that crashed
+		crashed, _ := createTestDownload("crashed-job", "Crashed", "tv-sonarr")
+		orphan := filesync.New(to.syncingPath).CreateJob(crashed, "test-downloader", to.downloadsPath).LocalBase
+		require.DirExists(t, orphan)
+
+		to.start()
+
+		require.Eventually(t, func() bool {
+			return !fileExists(orphan)
+		}, 2*time.Second, 10*time.Millisecond, "orphaned directory should be removed")
+
+		require.Eventually(t, func() bool {
+			for _, e := range recorder.GetAll() {
+				if e.Type == timeline.EventPruned && e.Details["path"] == orphan {
+					return true
+				}
+			}
+			return false
+		}, time.Second, 10*time.Millisecond, "pruned event should be recorded")
+	})
+}
+
+// --- Post-Import Action Tests ---
+
+func TestPostImportActions(t *testing.T) {
+	// withActions swaps the test downloader for one that supports actions.
+	withActions := func(to *testOrchestrator) *testutil.MockActionDownloader {
+		actionDL := &testutil.MockActionDownloader{MockDownloader: to.mockDL}
+		to.dlRegistry.Register("test-downloader", actionDL)
+		return actionDL
+	}
+
+	t.Run("AppliesPolicyAfterImport", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+		actionDL := withActions(to)
+
+		to.addApp("sonarr", "tv-sonarr", func(m *testutil.MockApp) {
+			m.SetPostImport(config.PostImportConfig{
+				Pause:      true,
+				Tags:       []string{"synced"},
+				RatioLimit: 2,
+			})
+		})
+
+		dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+		to.mockDL.AddDownload(dl, files)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second),
+			"should reach complete state")
+
+		actions := actionDL.Actions()
+		require.Len(t, actions, 3)
+		assert.Equal(t, "setShareLimits", actions[0].Action)
+		assert.Equal(t, download.ShareLimits{RatioLimit: 2}, actions[0].Value)
+		assert.Equal(t, "addTags", actions[1].Action)
+		assert.Equal(t, "pause", actions[2].Action)
+		assert.Equal(t, "hash1", actions[2].ID)
+	})
+
+	t.Run("SkippedWhenImportFails", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+		actionDL := withActions(to)
+
+		a := to.addApp("sonarr", "tv-sonarr", func(m *testutil.MockApp) {
+			m.SetPostImport(config.PostImportConfig{Delete: config.PostImportDeleteTorrentAndData})
+		})
+		a.SetTriggerError(errors.New("import failed"))
+
+		dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+		to.mockDL.AddDownload(dl, files)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second),
+			"should reach complete state")
+		assert.Empty(t, actionDL.Actions(), "no actions should run after a failed import")
+	})
+
+	t.Run("DeleteKeepsSyncedFiles", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+		actionDL := withActions(to)
+
+		to.addApp("sonarr", "tv-sonarr", func(m *testutil.MockApp) {
+			m.SetCleanupOnRemove(true)
+			m.SetPostImport(config.PostImportConfig{Delete: config.PostImportDeleteTorrent})
+		})
+
+		filePath := to.setupCompleteDownload(t, "hash1", "TestShow.S01E01", "tv-sonarr")
+
+		require.True(t, to.waitForUntracked("hash1", 500*time.Millisecond),
+			"download should be untracked after the post-import delete")
+		require.Len(t, actionDL.Actions(), 1)
+		assert.True(t, fileExists(filePath), "imported files should not be cleaned up")
+	})
+
+	t.Run("CategoryChangeDoesNotMigrate", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+		actionDL := withActions(to)
+
+		to.addApp("sonarr", "tv-sonarr", func(m *testutil.MockApp) {
+			m.SetCleanupOnCategoryChange(true)
+			m.SetPostImport(config.PostImportConfig{Category: "movies"})
+		})
+		radarrApp := to.addApp("radarr", "movies")
+
+		filePath := to.setupCompleteDownload(t, "hash1", "TestShow.S01E01", "tv-sonarr")
+		require.Len(t, actionDL.Actions(), 1)
+
+		// Give the orchestrator a few polls to notice the category change
+		time.Sleep(200 * time.Millisecond)
+
+		td := to.getTrackedDownload("hash1")
+		require.NotNil(t, td, "download should stay tracked")
+		assert.Equal(t, orchestrator.StateComplete, td.GetState())
+		assert.True(t, fileExists(filePath), "imported files should not be cleaned up or moved")
+		assert.Empty(t, radarrApp.GetImportCalls(), "download should not be migrated to another app")
+	})
+
+	t.Run("WaitsForEveryApp", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+		actionDL := withActions(to)
+
+		to.addApp("sonarr", "tv-sonarr", func(m *testutil.MockApp) {
+			m.SetCleanupOnRemove(true)
+			m.SetPostImport(config.PostImportConfig{Delete: config.PostImportDeleteTorrentAndData})
+		})
+		failing := to.addApp("sonarr-4k", "tv-sonarr")
+		failing.SetTriggerError(errors.New("import failed"))
+
+		to.setupCompleteDownload(t, "hash1", "TestShow.S01E01", "tv-sonarr")
+
+		assert.Empty(t, actionDL.Actions(), "no actions should run before every app imported")
+	})
+
+	t.Run("ActionErrorDoesNotFailDownload", func(t *testing.T) {
+		to := newTestOrchestrator(t)
+		defer to.stop()
+		actionDL := withActions(to)
+		actionDL.ActionErr = errors.New("forbidden")
+
+		to.addApp("sonarr", "tv-sonarr", func(m *testutil.MockApp) {
+			m.SetPostImport(config.PostImportConfig{Pause: true})
+		})
+
+		dl, files := createTestDownload("hash1", "TestShow.S01E01", "tv-sonarr")
+		to.mockDL.AddDownload(dl, files)
+
+		to.start()
+
+		require.True(t, to.waitForState("hash1", orchestrator.StateComplete, 2*time.Second),
+			"should reach complete state despite action error")
+		td := to.getTrackedDownload("hash1")
+		require.NotNil(t, td)
+		assert.NoError(t, td.GetError())
+	})
+}
+
 // --- GetStats Tests ---
 
 func TestGetStats(t *testing.T) {
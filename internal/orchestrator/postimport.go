@@ -0,0 +1,189 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/seedreap/seedreap/internal/app"
+	"github.com/seedreap/seedreap/internal/config"
+	"github.com/seedreap/seedreap/internal/download"
+	"github.com/seedreap/seedreap/internal/timeline"
+)
+
+// postImportAction is a single action applied to a download in its downloader.
+type postImportAction struct {
+	name    string
+	details map[string]any
+	run     func(ctx context.Context, id string) error
+}
+
+// postImport runs the post-import actions of every app of a download once all
+// of them have been asked to import it. A download that any app failed to
+// import is left alone, since a delete or category change would take the
+// files away from it.
+func (o *Orchestrator) postImport(tracked *TrackedDownload, apps []app.App, allImported bool) {
+	tracked.mu.RLock()
+	downloadID := tracked.Download.ID
+	downloadName := tracked.Download.Name
+	downloaderName := tracked.DownloaderName
+	tracked.mu.RUnlock()
+
+	var withActions []app.App
+	for _, a := range apps {
+		if !a.PostImport().IsZero() {
+			withActions = append(withActions, a)
+		}
+	}
+	if len(withActions) == 0 {
+		return
+	}
+
+	if !allImported {
+		o.logger.Warn().
+			Str("download", downloadName).
+			Msg("skipping post-import actions, not every app imported the download")
+		return
+	}
+
+	// Mark the download first, so the next poll does not mistake the removal or
+	// category change for one made by the user
+	tracked.mu.Lock()
+	tracked.PostImported = true
+	tracked.mu.Unlock()
+
+	for _, a := range withActions {
+		o.runPostImportActions(a, downloadID, downloadName, downloaderName)
+	}
+}
+
+// runPostImportActions applies an app's post-import policy to a download in its
+// downloader. Each action is recorded on the timeline; a failed action does not
+// prevent the remaining actions from running.
+func (o *Orchestrator) runPostImportActions(a app.App, downloadID, downloadName, downloaderName string) {
+	policy := a.PostImport()
+	if policy.IsZero() {
+		return
+	}
+
+	actioner, ok := o.actioner(a, downloaderName)
+	if !ok {
+		return
+	}
+
+	for _, action := range postImportActions(actioner, policy) {
+		if err := action.run(o.ctx, downloadID); err != nil {
+			o.logger.Error().
+				Err(err).
+				Str("download", downloadName).
+				Str("app", a.Name()).
+				Str("action", action.name).
+				Msg("post-import action failed")
+
+			details := map[string]any{"action": action.name, "error": err.Error()}
+			o.recordEvent(
+				timeline.EventPostImportFailed,
+				fmt.Sprintf("Post-import %s failed: %s", action.name, downloadName),
+				downloadID, downloadName, a.Name(), downloaderName, details,
+			)
+			continue
+		}
+
+		o.logger.Info().
+			Str("download", downloadName).
+			Str("app", a.Name()).
+			Str("action", action.name).
+			Msg("applied post-import action")
+
+		details := map[string]any{"action": action.name}
+		maps.Copy(details, action.details)
+		o.recordEvent(
+			timeline.EventPostImportAction,
+			fmt.Sprintf("Post-import %s: %s", action.name, downloadName),
+			downloadID, downloadName, a.Name(), downloaderName, details,
+		)
+	}
+}
+
+// actioner returns the downloader of a download as an Actioner, logging a warning
+// if it does not support post-import actions.
+func (o *Orchestrator) actioner(a app.App, downloaderName string) (download.Actioner, bool) {
+	dl, ok := o.downloaders.Get(downloaderName)
+	if !ok {
+		return nil, false
+	}
+
+	actioner, ok := dl.(download.Actioner)
+	if !ok {
+		o.logger.Warn().
+			Str("downloader", downloaderName).
+			Str("app", a.Name()).
+			Msg("downloader does not support post-import actions")
+		return nil, false
+	}
+	return actioner, true
+}
+
+// postImportActions builds the ordered list of actions for a policy. Share limits
+// and tags are applied before the category change and pause so that they still
+// take effect if the download is moved out of a category seedreap watches.
+func postImportActions(actioner download.Actioner, policy config.PostImportConfig) []postImportAction {
+	var actions []postImportAction
+
+	if policy.Delete != "" {
+		deleteFiles := policy.Delete == config.PostImportDeleteTorrentAndData
+		return append(actions, postImportAction{
+			name:    "delete",
+			details: map[string]any{"delete_files": deleteFiles},
+			run: func(ctx context.Context, id string) error {
+				return actioner.Delete(ctx, id, deleteFiles)
+			},
+		})
+	}
+
+	if policy.RatioLimit != 0 || policy.SeedingTimeLimit != 0 {
+		limits := download.ShareLimits{
+			RatioLimit:       policy.RatioLimit,
+			SeedingTimeLimit: policy.SeedingTimeLimit,
+		}
+		actions = append(actions, postImportAction{
+			name: "share limits",
+			details: map[string]any{
+				"ratio_limit":        limits.RatioLimit,
+				"seeding_time_limit": limits.SeedingTimeLimit.String(),
+			},
+			run: func(ctx context.Context, id string) error {
+				return actioner.SetShareLimits(ctx, id, limits)
+			},
+		})
+	}
+
+	if len(policy.Tags) > 0 {
+		actions = append(actions, postImportAction{
+			name:    "add tags",
+			details: map[string]any{"tags": policy.Tags},
+			run: func(ctx context.Context, id string) error {
+				return actioner.AddTags(ctx, id, policy.Tags)
+			},
+		})
+	}
+
+	if policy.Category != "" {
+		actions = append(actions, postImportAction{
+			name:    "set category",
+			details: map[string]any{"category": policy.Category},
+			run: func(ctx context.Context, id string) error {
+				return actioner.SetCategory(ctx, id, policy.Category)
+			},
+		})
+	}
+
+	if policy.Pause {
+		actions = append(actions, postImportAction{
+			name: "pause",
+			run:  actioner.Pause,
+		})
+	}
+
+	return actions
+}
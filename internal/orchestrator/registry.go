@@ -0,0 +1,119 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/seedreap/seedreap/internal/app"
+	"github.com/seedreap/seedreap/internal/download"
+	"github.com/seedreap/seedreap/internal/timeline"
+)
+
+// AddDownloader registers a connected downloader while running, replacing and
+// closing any downloader with the same name. Downloads tracked from a replaced
+// downloader carry on with the new one from the next poll.
+func (o *Orchestrator) AddDownloader(dl download.Downloader) {
+	name := dl.Name()
+
+	old, replaced := o.downloaders.Get(name)
+	o.downloaders.Register(name, dl)
+
+	if replaced && old != dl {
+		if err := old.Close(); err != nil {
+			o.logger.Warn().Err(err).Str("downloader", name).Msg("error closing replaced downloader")
+		}
+	}
+
+	o.recordEvent(
+		timeline.EventDownloaderConnect,
+		fmt.Sprintf("Connected to downloader: %s", name),
+		"",
+		"",
+		"",
+		name,
+		map[string]any{
+			"type": dl.Type(),
+		},
+	)
+}
+
+// RemoveDownloader unregisters and closes a downloader while running. Transfers
+// already in progress finish, but its downloads are no longer polled, so they
+// are not imported.
+func (o *Orchestrator) RemoveDownloader(name string) {
+	dl, ok := o.downloaders.Unregister(name)
+	if !ok {
+		return
+	}
+
+	if active := o.countActive(name); active > 0 {
+		o.logger.Warn().
+			Str("downloader", name).
+			Int("active", active).
+			Msg("removed downloader has downloads in progress, they will not be imported")
+	}
+
+	if err := dl.Close(); err != nil {
+		o.logger.Warn().Err(err).Str("downloader", name).Msg("error closing removed downloader")
+	}
+}
+
+// AddApp registers an app while running, replacing any app with the same name,
+// and binds it to the given downloaders (all downloaders if none are given).
+// Tracked downloads that were handled by a replaced app use the new one.
+func (o *Orchestrator) AddApp(ctx context.Context, a app.App, downloaders []string) {
+	name := a.Name()
+
+	o.apps.RegisterWithDownloaders(name, a, downloaders)
+
+	o.trackedMu.RLock()
+	for _, tracked := range o.tracked {
+		tracked.mu.Lock()
+		if slices.ContainsFunc(tracked.Apps, func(existing app.App) bool { return existing.Name() == name }) {
+			// Readers iterate the slice outside the lock, so swap in a copy
+			apps := slices.Clone(tracked.Apps)
+			for i, existing := range apps {
+				if existing.Name() == name {
+					apps[i] = a
+				}
+			}
+			tracked.Apps = apps
+		}
+		tracked.mu.Unlock()
+	}
+	o.trackedMu.RUnlock()
+
+	if err := a.TestConnection(ctx); err != nil {
+		o.logger.Warn().Err(err).Str("app", name).Msg("failed to connect to app")
+		return
+	}
+	o.recordEvent(timeline.EventAppConnected, fmt.Sprintf("Connected to app: %s", name), "", "", name, "", map[string]any{
+		"type":     a.Type(),
+		"category": a.Category(),
+	})
+}
+
+// RemoveApp unregisters an app while running. New downloads are no longer routed
+// to it; downloads already assigned to it are still imported with it.
+func (o *Orchestrator) RemoveApp(name string) {
+	o.apps.Unregister(name)
+}
+
+// countActive returns the number of tracked downloads from a downloader that are
+// still in the sync pipeline.
+func (o *Orchestrator) countActive(downloaderName string) int {
+	o.trackedMu.RLock()
+	defer o.trackedMu.RUnlock()
+
+	count := 0
+	for _, tracked := range o.tracked {
+		tracked.mu.RLock()
+		if tracked.DownloaderName == downloaderName &&
+			tracked.State != StateComplete && tracked.State != StateError {
+			count++
+		}
+		tracked.mu.RUnlock()
+	}
+	return count
+}
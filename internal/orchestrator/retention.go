@@ -0,0 +1,199 @@
+package orchestrator
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/seedreap/seedreap/internal/filesync"
+	"github.com/seedreap/seedreap/internal/timeline"
+)
+
+// Default retention values.
+const (
+	defaultCompletedRetention = 24 * time.Hour
+	defaultErroredRetention   = 72 * time.Hour
+	defaultCancelledRetention = time.Hour
+	defaultSweepInterval      = 5 * time.Minute
+)
+
+// Retention reasons recorded on pruned timeline events.
+const (
+	pruneReasonCompleted = "completed"
+	pruneReasonErrored   = "errored"
+	pruneReasonCancelled = "cancelled"
+	pruneReasonOrphaned  = "orphaned"
+)
+
+// Retention controls how long finished downloads stay tracked before the sweeper
+// removes them. A negative duration keeps items until restart.
+type Retention struct {
+	// Completed is how long to keep downloads that finished successfully.
+	Completed time.Duration
+	// Errored is how long to keep downloads that failed.
+	Errored time.Duration
+	// Cancelled is how long to keep downloads whose sync job was cancelled.
+	Cancelled time.Duration
+}
+
+// WithRetention sets how long finished downloads are kept.
+func WithRetention(r Retention) Option {
+	return func(o *Orchestrator) {
+		o.retention = r
+	}
+}
+
+// WithSweepInterval sets how often the retention sweeper runs.
+func WithSweepInterval(d time.Duration) Option {
+	return func(o *Orchestrator) {
+		o.sweepInterval = d
+	}
+}
+
+func (o *Orchestrator) sweepLoop() {
+	ticker := time.NewTicker(o.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.ctx.Done():
+			return
+		case <-ticker.C:
+			o.sweep()
+		}
+	}
+}
+
+// sweep removes tracked downloads and sync jobs whose retention has expired, and
+// deletes orphaned staging directories left behind by crashed jobs.
+func (o *Orchestrator) sweep() {
+	now := time.Now()
+
+	o.trackedMu.RLock()
+	snapshot := maps.Clone(o.tracked)
+	o.trackedMu.RUnlock()
+
+	trackedJobs := make(map[*filesync.SyncJob]bool, len(snapshot))
+	for key, tracked := range snapshot {
+		reason, expired := o.retentionExpired(tracked, now)
+		if !expired {
+			if job := tracked.GetSyncJob(); job != nil {
+				trackedJobs[job] = true
+			}
+			continue
+		}
+
+		tracked.mu.RLock()
+		downloadID := tracked.Download.ID
+		downloadName := tracked.Download.Name
+		category := tracked.Download.Category
+		downloaderName := tracked.DownloaderName
+		tracked.mu.RUnlock()
+
+		// Record the tombstone first so a concurrent poll can't rediscover it.
+		// Failed downloads get one too, otherwise the next poll would quietly
+		// retry them.
+		o.trackedMu.Lock()
+		o.swept[key] = category
+		o.trackedMu.Unlock()
+		o.removeFromTracking(tracked, key)
+
+		o.logger.Info().
+			Str("download", downloadName).
+			Str("reason", reason).
+			Msg("retention expired, removed from tracking")
+
+		o.recordEvent(
+			timeline.EventPruned,
+			fmt.Sprintf("Pruned: %s", downloadName),
+			downloadID,
+			downloadName,
+			"",
+			downloaderName,
+			map[string]any{"reason": reason},
+		)
+	}
+
+	// Cancelled jobs no longer attached to a tracked download
+	if o.retention.Cancelled >= 0 {
+		for _, job := range o.syncer.GetAllJobs() {
+			cancelledAt := job.GetCancelledAt()
+			if trackedJobs[job] || cancelledAt.IsZero() || now.Sub(cancelledAt) < o.retention.Cancelled {
+				continue
+			}
+
+			o.syncer.RemoveJob(job.ID)
+			o.recordEvent(
+				timeline.EventPruned,
+				fmt.Sprintf("Pruned: %s", job.Name),
+				job.ID,
+				job.Name,
+				"",
+				job.Downloader,
+				map[string]any{"reason": pruneReasonCancelled},
+			)
+		}
+	}
+
+	removed, err := o.syncer.RemoveOrphans()
+	if err != nil {
+		o.logger.Warn().Err(err).Msg("failed to remove some orphaned staging directories")
+	}
+	for _, path := range removed {
+		o.recordEvent(
+			timeline.EventPruned,
+			fmt.Sprintf("Removed orphaned staging directory: %s", path),
+			"",
+			"",
+			"",
+			"",
+			map[string]any{"reason": pruneReasonOrphaned, "path": path},
+		)
+	}
+}
+
+// retentionExpired reports whether a tracked download has outlived its retention,
+// and which retention rule applied.
+func (o *Orchestrator) retentionExpired(tracked *TrackedDownload, now time.Time) (string, bool) {
+	tracked.mu.RLock()
+	state := tracked.State
+	completedAt := tracked.CompletedAt
+	failedAt := tracked.FailedAt
+	job := tracked.SyncJob
+	tracked.mu.RUnlock()
+
+	var reason string
+	var since time.Time
+	var keep time.Duration
+
+	switch {
+	case state == StateComplete:
+		reason, since, keep = pruneReasonCompleted, completedAt, o.retention.Completed
+	case state == StateError && job != nil && job.IsCancelled():
+		reason, since, keep = pruneReasonCancelled, job.GetCancelledAt(), o.retention.Cancelled
+	case state == StateError:
+		reason, since, keep = pruneReasonErrored, failedAt, o.retention.Errored
+	default:
+		return "", false
+	}
+
+	if keep < 0 || since.IsZero() || now.Sub(since) < keep {
+		return reason, false
+	}
+	return reason, true
+}
+
+// forgetSwept drops sweeper tombstones for downloads that are no longer listed by
+// their downloader, so they are discovered again if they reappear.
+func (o *Orchestrator) forgetSwept(seenKeys, listed map[string]bool) {
+	o.trackedMu.Lock()
+	defer o.trackedMu.Unlock()
+
+	for key := range o.swept {
+		downloaderName, _, _ := strings.Cut(key, ":")
+		if listed[downloaderName] && !seenKeys[key] {
+			delete(o.swept, key)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Package redact scrubs secret values from logs and API responses.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Value replaces secrets in scrubbed output.
+const Value = "REDACTED"
+
+// minSecretLength is the length below which secrets are not scrubbed, since
+// replacing every occurrence of a very short string would mangle the output.
+const minSecretLength = 4
+
+// Redactor replaces known secret values with Value. Secrets are only ever
+// added, so values from a configuration that was reloaded stay scrubbed. It is
+// safe for concurrent use.
+type Redactor struct {
+	mu       sync.RWMutex
+	secrets  []string
+	replacer *strings.Replacer
+}
+
+// New returns a Redactor scrubbing secrets.
+func New(secrets ...string) *Redactor {
+	r := &Redactor{}
+	r.Add(secrets...)
+	return r
+}
+
+// Add adds secrets to scrub. Each is also scrubbed in its JSON and URL
+// encoded forms, as it appears in log lines, API responses and request URIs.
+func (r *Redactor) Add(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, secret := range secrets {
+		if len(secret) < minSecretLength {
+			continue
+		}
+		for _, form := range encodings(secret) {
+			if !slices.Contains(r.secrets, form) {
+				r.secrets = append(r.secrets, form)
+			}
+		}
+	}
+
+	// Replace longer secrets first, so one containing another is replaced whole
+	slices.SortStableFunc(r.secrets, func(a, b string) int { return len(b) - len(a) })
+	pairs := make([]string, 0, 2*len(r.secrets)) //nolint:mnd // old and new string per secret
+	for _, secret := range r.secrets {
+		pairs = append(pairs, secret, Value)
+	}
+	r.replacer = strings.NewReplacer(pairs...)
+}
+
+// String returns s with the secrets replaced.
+func (r *Redactor) String(s string) string {
+	r.mu.RLock()
+	replacer := r.replacer
+	r.mu.RUnlock()
+
+	if replacer == nil {
+		return s
+	}
+	return replacer.Replace(s)
+}
+
+// Writer returns a writer that scrubs each write before passing it on to w.
+// Secrets split across two writes are not scrubbed, which suits writers like
+// zerolog's and encoding/json's that write whole messages at once.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return &writer{redactor: r, w: w}
+}
+
+// writer scrubs writes to an underlying writer.
+type writer struct {
+	redactor *Redactor
+	w        io.Writer
+}
+
+// Write writes p to the underlying writer with the secrets replaced.
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, w.redactor.String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// encodings returns secret as is and escaped the ways it may be written out.
+func encodings(secret string) []string {
+	forms := []string{secret, url.QueryEscape(secret), url.PathEscape(secret)}
+
+	// JSON with and without HTML escaping, as written by encoding/json and zerolog
+	for _, escapeHTML := range []bool{true, false} {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(escapeHTML)
+		if err := enc.Encode(secret); err == nil {
+			encoded := strings.TrimSuffix(buf.String(), "\n")
+			forms = append(forms, encoded[1:len(encoded)-1]) // Without the quotes
+		}
+	}
+
+	slices.Sort(forms)
+	return slices.Compact(forms)
+}
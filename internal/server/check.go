@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/rs/zerolog"
+
+	"github.com/seedreap/seedreap/internal/config"
+)
+
+// Check targets.
+const (
+	CheckDownloader = "downloader"
+	CheckApp        = "app"
+	CheckTransfer   = "transfer"
+)
+
+// errUnknownType is reported for downloaders and apps with an unsupported type.
+var errUnknownType = errors.New("unknown type")
+
+// CheckResult is the outcome of checking the connection to one target.
+type CheckResult struct {
+	Target string // downloader, app or transfer
+	Name   string // Configured name, or the backend name (and downloader) for a transfer backend
+	Err    error  // nil if the connection succeeded
+}
+
+// Check connects to every configured downloader, tests the connection to every
+// app and opens the transfer backends, without starting the server. Results are
+// sorted by target and name, with the shared transfer backend before those of
+// individual downloaders.
+func Check(ctx context.Context, cfg config.Config, logger zerolog.Logger) []CheckResult {
+	var results []CheckResult
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Downloaders)) {
+		result := CheckResult{Target: CheckDownloader, Name: name}
+		if dl := newDownloader(name, cfg.Downloaders[name], logger); dl == nil {
+			result.Err = errUnknownType
+		} else {
+			result.Err = dl.Connect(ctx)
+			_ = dl.Close()
+		}
+		results = append(results, result)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Apps)) {
+		result := CheckResult{Target: CheckApp, Name: name}
+		if a := newApp(name, cfg.Apps[name], logger); a == nil {
+			result.Err = errUnknownType
+		} else {
+			result.Err = a.TestConnection(ctx)
+		}
+		results = append(results, result)
+	}
+
+	if transferer := newTransferer(cfg, logger); transferer != nil {
+		results = append(results, CheckResult{
+			Target: CheckTransfer,
+			Name:   transferer.Name(),
+			Err:    transferer.TestConnection(ctx),
+		})
+		_ = transferer.Close()
+	}
+
+	transferers := newDownloaderTransferers(cfg, logger)
+	for _, name := range slices.Sorted(maps.Keys(transferers)) {
+		transferer := transferers[name]
+		results = append(results, CheckResult{
+			Target: CheckTransfer,
+			Name:   transferer.Name() + " (" + name + ")",
+			Err:    transferer.TestConnection(ctx),
+		})
+		_ = transferer.Close()
+	}
+
+	return results
+}
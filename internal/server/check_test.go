@@ -0,0 +1,96 @@
+//nolint:testpackage // tests access internal types
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	qbt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(qbt.Close)
+
+	sonarr := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(sonarr.Close)
+
+	cfg := loadConfigFromYAML(t, `
+downloaders:
+  seedbox:
+    type: qbittorrent
+    url: `+qbt.URL+`
+    ssh:
+      host: 127.0.0.1
+      port: 1
+      user: seeduser
+      keyFile: /nonexistent/key
+      ignoreHostKey: true
+apps:
+  tv:
+    type: passthrough
+    category: tv
+  sonarr:
+    type: sonarr
+    url: `+sonarr.URL+`
+    apiKey: abc123
+    category: tv-sonarr
+`)
+
+	results := Check(t.Context(), cfg, zerolog.Nop())
+	require.Len(t, results, 4)
+
+	assert.Equal(t, CheckDownloader, results[0].Target)
+	assert.Equal(t, "seedbox", results[0].Name)
+	require.NoError(t, results[0].Err)
+
+	assert.Equal(t, CheckApp, results[1].Target)
+	assert.Equal(t, "sonarr", results[1].Name)
+	require.Error(t, results[1].Err)
+
+	assert.Equal(t, CheckApp, results[2].Target)
+	assert.Equal(t, "tv", results[2].Name)
+	require.NoError(t, results[2].Err)
+
+	assert.Equal(t, CheckTransfer, results[3].Target)
+	assert.Equal(t, "rclone", results[3].Name)
+	require.ErrorContains(t, results[3].Err, "failed to connect to 127.0.0.1")
+}
+
+func TestCheckDownloaderTransfer(t *testing.T) {
+	qbt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(qbt.Close)
+
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(files.Close)
+
+	cfg := loadConfigFromYAML(t, `
+downloaders:
+  web:
+    type: qbittorrent
+    url: `+qbt.URL+`
+    transfer:
+      backend: http
+      url: `+files.URL+`/files/
+`)
+
+	results := Check(t.Context(), cfg, zerolog.Nop())
+	require.Len(t, results, 2)
+
+	assert.Equal(t, CheckDownloader, results[0].Target)
+	assert.Equal(t, "web", results[0].Name)
+	require.NoError(t, results[0].Err)
+
+	assert.Equal(t, CheckTransfer, results[1].Target)
+	assert.Equal(t, "http (web)", results[1].Name)
+	require.NoError(t, results[1].Err)
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+
+	"github.com/seedreap/seedreap/internal/api"
+	"github.com/seedreap/seedreap/internal/orchestrator"
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+// RunOnce runs a single sync cycle without starting the HTTP server, sends the
+// notifications for it and releases the server's resources. The server cannot
+// be used afterwards.
+func (s *Server) RunOnce(ctx context.Context) (orchestrator.Report, error) {
+	s.logger.Info().
+		Str("downloads_path", s.cfg.Sync.DownloadsPath).
+		Str("syncing_path", s.cfg.Sync.SyncingPath).
+		Msg("running a single sync cycle")
+
+	if s.notifier.Len() > 0 {
+		s.notifier.Start(ctx)
+	}
+
+	report, err := s.orchestrator.RunOnce(ctx)
+
+	s.notifier.Drain()
+
+	if closeErr := s.syncer.Close(); closeErr != nil {
+		s.logger.Error().Err(closeErr).Msg("syncer close error")
+	}
+	if closeErr := s.timeline.Close(); closeErr != nil {
+		s.logger.Error().Err(closeErr).Msg("timeline close error")
+	}
+
+	return report, err
+}
+
+// Plan returns what a dry run would do with each download. It is empty unless
+// sync.dryRun is set.
+func (s *Server) Plan() apitypes.Plan {
+	return api.NewPlan(s.orchestrator)
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/seedreap/seedreap/internal/config"
+	"github.com/seedreap/seedreap/internal/download"
+)
+
+// reloadDebounce is how long to wait for a config file to settle before reloading,
+// since editors and ConfigMap updates write in several steps.
+const reloadDebounce = 500 * time.Millisecond
+
+// ErrReloadUnavailable is returned by Reload when the server has no config loader.
+var ErrReloadUnavailable = errors.New("configuration reload is not available")
+
+// Reload loads the configuration again and applies downloader and app changes in
+// place, without interrupting transfers. Changes to any other setting are rejected
+// with an error wrapping config.ErrRestartRequired, and nothing is applied.
+func (s *Server) Reload(ctx context.Context) (config.Changes, error) {
+	if s.opts.LoadConfig == nil {
+		return config.Changes{}, ErrReloadUnavailable
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	cfg, err := s.opts.LoadConfig()
+	if err != nil {
+		return config.Changes{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	changes, err := config.Diff(s.cfg, cfg)
+	if err != nil {
+		return config.Changes{}, err
+	}
+	if changes.Empty() {
+		s.logger.Debug().Msg("configuration unchanged")
+		return changes, nil
+	}
+
+	// Connect new and changed downloaders before touching the running ones, so a
+	// failed connection leaves everything as it was
+	var connected []download.Downloader
+	for _, name := range slices.Concat(changes.AddedDownloaders, changes.ChangedDownloaders) {
+		dl := newDownloader(name, cfg.Downloaders[name], s.logger)
+		if dl == nil {
+			s.logger.Warn().Str("type", cfg.Downloaders[name].Type).Msg("unknown downloader type")
+			continue
+		}
+
+		if err = dl.Connect(ctx); err != nil {
+			for _, c := range connected {
+				_ = c.Close()
+			}
+			return config.Changes{}, fmt.Errorf("failed to connect to downloader %s: %w", name, err)
+		}
+		connected = append(connected, dl)
+	}
+
+	for _, name := range changes.RemovedApps {
+		s.orchestrator.RemoveApp(name)
+	}
+	for _, name := range changes.RemovedDownloaders {
+		s.orchestrator.RemoveDownloader(name)
+	}
+	for _, dl := range connected {
+		s.orchestrator.AddDownloader(dl)
+	}
+	for _, name := range slices.Concat(changes.AddedApps, changes.ChangedApps) {
+		appCfg := cfg.Apps[name]
+		a := newApp(name, appCfg, s.logger)
+		if a == nil {
+			s.logger.Warn().Str("type", appCfg.Type).Msg("unknown app type")
+			continue
+		}
+		s.orchestrator.AddApp(ctx, a, appCfg.Downloaders)
+	}
+
+	s.cfg = cfg
+
+	s.logger.Info().
+		Strs("added_downloaders", changes.AddedDownloaders).
+		Strs("removed_downloaders", changes.RemovedDownloaders).
+		Strs("changed_downloaders", changes.ChangedDownloaders).
+		Strs("added_apps", changes.AddedApps).
+		Strs("removed_apps", changes.RemovedApps).
+		Strs("changed_apps", changes.ChangedApps).
+		Msg("configuration reloaded")
+
+	return changes, nil
+}
+
+// watchConfig reloads the configuration whenever the config file changes, until
+// ctx is cancelled. It watches the file's directory rather than the file itself,
+// so files replaced by a rename (editors, Kubernetes ConfigMaps) are followed.
+func (s *Server) watchConfig(ctx context.Context, path string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err = watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	s.logger.Info().Str("path", path).Msg("watching config file for changes")
+
+	go func() {
+		defer watcher.Close()
+
+		// Stopped timer that fires once the file has settled
+		debounce := time.NewTimer(reloadDebounce)
+		debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				debounce.Stop()
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if isConfigEvent(path, event) {
+					debounce.Reset(reloadDebounce)
+				}
+
+			case watchErr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				s.logger.Warn().Err(watchErr).Msg("config watcher error")
+
+			case <-debounce.C:
+				s.reloadFromWatch(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// isConfigEvent reports whether a file system event may have changed the config file.
+func isConfigEvent(path string, event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+
+	// Kubernetes swaps ConfigMap contents by replacing the ..data symlink
+	name := filepath.Base(event.Name)
+	return filepath.Clean(event.Name) == filepath.Clean(path) || name == "..data"
+}
+
+// reloadFromWatch reloads the configuration after the config file changed.
+func (s *Server) reloadFromWatch(ctx context.Context) {
+	if _, err := s.Reload(ctx); err != nil {
+		s.logger.Error().Err(err).Msg("failed to reload configuration, keeping the running configuration")
+	}
+}
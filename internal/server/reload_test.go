@@ -0,0 +1,247 @@
+//nolint:testpackage // tests access internal types
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seedreap/seedreap/internal/app"
+	"github.com/seedreap/seedreap/internal/config"
+)
+
+// reloadConfigYAML is a config with a downloader at qbtURL and a "tv" app, plus
+// extra YAML appended to the downloaders and apps sections.
+func reloadConfigYAML(dir, qbtURL, maxConcurrent, downloaders, apps string) string {
+	return fmt.Sprintf(`
+sync:
+  downloadsPath: %[1]s/downloads
+  syncingPath: %[1]s/syncing
+  maxConcurrent: %[3]s
+
+downloaders:
+  seedbox:
+    type: qbittorrent
+    url: %[2]s
+    ssh:
+      host: seedbox.example.com
+      user: seeduser
+      keyFile: /path/to/key
+      ignoreHostKey: true
+%[4]s
+apps:
+  tv:
+    type: passthrough
+    category: tv
+%[5]s`, dir, qbtURL, maxConcurrent, downloaders, apps)
+}
+
+// newReloadServer creates a server whose config file can be rewritten and reloaded.
+func newReloadServer(t *testing.T, yaml string) (*Server, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(yaml), 0o600))
+
+	load := func() (config.Config, error) {
+		return config.Load(config.LoadOptions{ConfigFile: path})
+	}
+
+	cfg, err := load()
+	require.NoError(t, err)
+
+	srv, err := New(cfg, Options{Logger: zerolog.Nop(), LoadConfig: load, ConfigFile: path})
+	require.NoError(t, err)
+
+	return srv, path
+}
+
+func TestServerReload(t *testing.T) {
+	qbt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(qbt.Close)
+
+	dir := t.TempDir()
+	base := reloadConfigYAML(dir, qbt.URL, "2", "", "")
+
+	write := func(t *testing.T, path, yaml string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(path, []byte(yaml), 0o600))
+	}
+
+	t.Run("AppliesAppChanges", func(t *testing.T) {
+		srv, path := newReloadServer(t, base)
+
+		write(t, path, reloadConfigYAML(dir, qbt.URL, "2", "", `
+  movies:
+    type: passthrough
+    category: movies
+    downloaders: [seedbox]
+`))
+
+		changes, err := srv.Reload(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"movies"}, changes.AddedApps)
+		assert.Empty(t, changes.ChangedApps)
+
+		movies, ok := srv.apps.Get("movies")
+		require.True(t, ok)
+		assert.Equal(t, "movies", movies.Category())
+		assert.Equal(t, []string{"movies"}, appNames(srv.apps.GetByDownloader("seedbox")))
+
+		write(t, path, base)
+
+		changes, err = srv.Reload(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"movies"}, changes.RemovedApps)
+
+		_, ok = srv.apps.Get("movies")
+		assert.False(t, ok)
+		assert.Empty(t, srv.apps.GetByDownloader("seedbox"))
+	})
+
+	t.Run("AppliesDownloaderChanges", func(t *testing.T) {
+		srv, path := newReloadServer(t, base)
+		seedbox, _ := srv.downloaders.Get("seedbox")
+
+		write(t, path, reloadConfigYAML(dir, qbt.URL+"/", "2", `
+  other:
+    type: qbittorrent
+    url: `+qbt.URL+`
+    ssh:
+      host: seedbox.example.com # A second client on the same seedbox
+      user: seeduser
+      keyFile: /path/to/key
+      ignoreHostKey: true
+`, ""))
+
+		changes, err := srv.Reload(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other"}, changes.AddedDownloaders)
+		assert.Equal(t, []string{"seedbox"}, changes.ChangedDownloaders)
+
+		_, ok := srv.downloaders.Get("other")
+		assert.True(t, ok)
+		replaced, _ := srv.downloaders.Get("seedbox")
+		assert.NotSame(t, seedbox, replaced)
+
+		write(t, path, base)
+
+		changes, err = srv.Reload(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other"}, changes.RemovedDownloaders)
+
+		_, ok = srv.downloaders.Get("other")
+		assert.False(t, ok)
+	})
+
+	t.Run("RejectsRestartRequiredChanges", func(t *testing.T) {
+		srv, path := newReloadServer(t, base)
+
+		write(t, path, reloadConfigYAML(dir, qbt.URL, "4", "", `
+  movies:
+    type: passthrough
+    category: movies
+`))
+
+		_, err := srv.Reload(t.Context())
+		require.ErrorIs(t, err, config.ErrRestartRequired)
+		assert.Contains(t, err.Error(), "sync.maxConcurrent")
+
+		_, ok := srv.apps.Get("movies")
+		assert.False(t, ok, "nothing is applied when a restart is required")
+	})
+
+	t.Run("KeepsRunningConfigWhenConnectFails", func(t *testing.T) {
+		srv, path := newReloadServer(t, base)
+
+		down := httptest.NewServer(http.NotFoundHandler())
+		down.Close()
+
+		write(t, path, reloadConfigYAML(dir, qbt.URL, "2", `
+  other:
+    type: qbittorrent
+    url: `+down.URL+`
+    ssh:
+      host: seedbox.example.com # A second client on the same seedbox
+      user: seeduser
+      keyFile: /path/to/key
+      ignoreHostKey: true
+`, `
+  movies:
+    type: passthrough
+    category: movies
+`))
+
+		_, err := srv.Reload(t.Context())
+		require.ErrorContains(t, err, "failed to connect to downloader other")
+
+		_, ok := srv.downloaders.Get("other")
+		assert.False(t, ok)
+		_, ok = srv.apps.Get("movies")
+		assert.False(t, ok)
+
+		// The failed reload is retried in full
+		write(t, path, base)
+		changes, err := srv.Reload(t.Context())
+		require.NoError(t, err)
+		assert.True(t, changes.Empty())
+	})
+
+	t.Run("RejectsInvalidConfig", func(t *testing.T) {
+		srv, path := newReloadServer(t, base)
+
+		write(t, path, reloadConfigYAML(dir, qbt.URL, "2", "", `
+  broken:
+    type: nope
+`))
+
+		_, err := srv.Reload(t.Context())
+		require.ErrorContains(t, err, "failed to load config")
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		srv, err := New(config.Config{}, Options{Logger: zerolog.Nop()})
+		require.NoError(t, err)
+
+		_, err = srv.Reload(t.Context())
+		require.ErrorIs(t, err, ErrReloadUnavailable)
+	})
+
+	t.Run("WatchesConfigFile", func(t *testing.T) {
+		srv, path := newReloadServer(t, base)
+		require.NoError(t, srv.watchConfig(t.Context(), path))
+
+		// Replace the file with a rename, as editors do
+		tmp := path + ".tmp"
+		write(t, tmp, reloadConfigYAML(dir, qbt.URL, "2", "", `
+  movies:
+    type: passthrough
+    category: movies
+`))
+		require.NoError(t, os.Rename(tmp, path))
+
+		assert.Eventually(t, func() bool {
+			_, ok := srv.apps.Get("movies")
+			return ok
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+}
+
+// appNames returns the names of apps.
+func appNames(apps []app.App) []string {
+	names := make([]string, 0, len(apps))
+	for _, a := range apps {
+		names = append(names, a.Name())
+	}
+	return names
+}
@@ -5,6 +5,10 @@ import (
 	"context"
 	"embed"
 	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -14,12 +18,17 @@ import (
 	"github.com/seedreap/seedreap/internal/config"
 	"github.com/seedreap/seedreap/internal/download"
 	"github.com/seedreap/seedreap/internal/filesync"
+	"github.com/seedreap/seedreap/internal/notify"
 	"github.com/seedreap/seedreap/internal/orchestrator"
+	"github.com/seedreap/seedreap/internal/redact"
 	"github.com/seedreap/seedreap/internal/timeline"
 	"github.com/seedreap/seedreap/internal/transfer"
 )
 
-const defaultPollInterval = 30 * time.Second
+const (
+	defaultPollInterval  = 30 * time.Second
+	defaultSweepInterval = 5 * time.Minute
+)
 
 // Options holds additional server options not in config.
 type Options struct {
@@ -29,6 +38,17 @@ type Options struct {
 
 	// Logger
 	Logger zerolog.Logger
+
+	// LoadConfig loads the configuration again for a reload (optional). Without it,
+	// Reload returns ErrReloadUnavailable.
+	LoadConfig func() (config.Config, error)
+
+	// ConfigFile is watched for changes and reloaded automatically (optional, needs LoadConfig).
+	ConfigFile string
+
+	// Redactor scrubs secrets from API responses (optional). LoadConfig should add
+	// the secrets of reloaded configurations to it.
+	Redactor *redact.Redactor
 }
 
 // Server is the main application server.
@@ -36,9 +56,15 @@ type Server struct {
 	cfg          config.Config
 	opts         Options
 	apiServer    *api.Server
+	downloaders  *download.Registry
+	apps         *app.Registry
 	orchestrator *orchestrator.Orchestrator
 	syncer       *filesync.Syncer
+	timeline     timeline.Recorder
+	notifier     *notify.Dispatcher
 	logger       zerolog.Logger
+
+	reloadMu sync.Mutex // serializes reloads and guards cfg
 }
 
 // New creates a new server with the given configuration.
@@ -54,30 +80,17 @@ func New(cfg config.Config, opts Options) (*Server, error) {
 	dlRegistry := download.NewRegistry()
 	appRegistry := app.NewRegistry()
 
-	// Track SSH config for file transfers (use first downloader's config)
-	var transferSSHConfig config.SSHConfig
-
 	// Build downloaders from config
 	for name, dlCfg := range cfg.Downloaders {
 		logger.Debug().Str("name", name).Str("type", dlCfg.Type).Msg("configuring downloader")
 
-		switch dlCfg.Type {
-		case "qbittorrent":
-			// Store SSH config for file transfers
-			if transferSSHConfig.Host == "" {
-				transferSSHConfig = dlCfg.SSH
-			}
-
-			client := download.NewQBittorrent(
-				name,
-				dlCfg,
-				download.WithLogger(logger.With().Str("downloader", name).Logger()),
-			)
-			dlRegistry.Register(name, client)
-
-		default:
+		dl := newDownloader(name, dlCfg, logger)
+		if dl == nil {
 			logger.Warn().Str("type", dlCfg.Type).Msg("unknown downloader type")
+			continue
 		}
+
+		dlRegistry.Register(name, dl)
 	}
 
 	// Build apps from config
@@ -88,50 +101,14 @@ func New(cfg config.Config, opts Options) (*Server, error) {
 			Str("category", appCfg.Category).
 			Msg("configuring app")
 
-		// Build app config from entry config
-		arrCfg := app.ArrConfig{
-			URL:           appCfg.URL,
-			APIKey:        appCfg.APIKey,
-			Category:      appCfg.Category,
-			DownloadsPath: appCfg.DownloadsPath,
-			HTTPTimeout:   appCfg.HTTPTimeout,
-		}
-
-		switch appCfg.Type {
-		case "sonarr":
-			client := app.NewSonarr(
-				name,
-				arrCfg,
-				app.WithLogger(logger.With().Str("app", name).Logger()),
-				app.WithCleanupOnCategoryChange(appCfg.CleanupOnCategoryChange),
-				app.WithCleanupOnRemove(appCfg.CleanupOnRemove),
-			)
-			appRegistry.Register(name, client)
-
-		case "radarr":
-			client := app.NewRadarr(
-				name,
-				arrCfg,
-				app.WithLogger(logger.With().Str("app", name).Logger()),
-				app.WithCleanupOnCategoryChange(appCfg.CleanupOnCategoryChange),
-				app.WithCleanupOnRemove(appCfg.CleanupOnRemove),
-			)
-			appRegistry.Register(name, client)
-
-		case "passthrough":
-			client := app.NewPassthrough(
-				name,
-				appCfg.Category,
-				appCfg.DownloadsPath,
-				app.WithLogger(logger.With().Str("app", name).Logger()),
-				app.WithCleanupOnCategoryChange(appCfg.CleanupOnCategoryChange),
-				app.WithCleanupOnRemove(appCfg.CleanupOnRemove),
-			)
-			appRegistry.Register(name, client)
-
-		default:
+		a := newApp(name, appCfg, logger)
+		if a == nil {
 			logger.Warn().Str("type", appCfg.Type).Msg("unknown app type")
+			continue
 		}
+
+		// Bind the app to specific downloaders, if configured
+		appRegistry.RegisterWithDownloaders(name, a, appCfg.Downloaders)
 	}
 
 	// Log configuration summary
@@ -144,67 +121,86 @@ func New(cfg config.Config, opts Options) (*Server, error) {
 		logger.Warn().Msg("no apps configured - downloads will be synced but not imported to any app")
 	}
 
+	// Create timeline recorder
+	timelineOpts := []timeline.Option{
+		timeline.WithLogger(logger.With().Str("component", "timeline").Logger()),
+		timeline.WithMaxEvents(cfg.Timeline.MaxEvents),
+		timeline.WithMaxAge(cfg.Timeline.MaxAge),
+	}
+
+	var timelineRecorder timeline.Recorder
+	if cfg.Timeline.Path != "" {
+		var err error
+		timelineRecorder, err = timeline.NewFileRecorder(cfg.Timeline.Path, timelineOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open timeline: %w", err)
+		}
+	} else {
+		timelineRecorder = timeline.NewRecorder(timelineOpts...)
+	}
+
 	// Create syncer
 	maxConcurrent := cfg.Sync.MaxConcurrent
 	if maxConcurrent == 0 {
 		maxConcurrent = 2
 	}
 
-	parallelConnections := cfg.Sync.ParallelConnections
-	if parallelConnections == 0 {
-		parallelConnections = 8
-	}
-
 	// Create the transfer backend
-	var transferer transfer.Transferer
-	if transferSSHConfig.Host != "" {
-		transferOpts := transfer.Options{
-			SSH: transfer.SSHConfig{
-				Host:           transferSSHConfig.Host,
-				Port:           transferSSHConfig.Port,
-				User:           transferSSHConfig.User,
-				KeyFile:        transferSSHConfig.KeyFile,
-				KnownHostsFile: transferSSHConfig.KnownHostsFile,
-				IgnoreHostKey:  transferSSHConfig.IgnoreHostKey,
-			},
-			ParallelConnections: parallelConnections,
-			SpeedLimit:          cfg.Sync.TransferSpeedMax,
-		}
-
-		transferLogger := logger.With().Str("component", "transfer").Logger()
-
-		// rclone is the default (and currently only) transfer backend
-		transferer = transfer.NewRclone(transferOpts, transfer.WithLogger(transferLogger))
-		logger.Info().
-			Str("backend", "rclone").
-			Str("host", transferSSHConfig.Host).
-			Int("port", transferSSHConfig.Port).
-			Str("user", transferSSHConfig.User).
-			Int("parallel_connections", parallelConnections).
-			Msg("transfer backend configured")
-	}
+	transferer := newTransferer(cfg, logger)
 
 	syncerOpts := []filesync.Option{
 		filesync.WithLogger(logger.With().Str("component", "syncer").Logger()),
 		filesync.WithMaxConcurrent(maxConcurrent),
+		filesync.WithDryRun(cfg.Sync.DryRun),
+		filesync.WithMinFreeSpace(cfg.Sync.MinFreeSpace),
+		filesync.WithStallTimeout(cfg.Sync.StallTimeout),
+		filesync.WithMinSpeed(cfg.Sync.MinSpeed),
+		filesync.WithStallRetries(cfg.Sync.StallRetries),
+		filesync.WithOnStalled(func(stall filesync.Stall) { recordStall(timelineRecorder, stall) }),
 	}
 
 	if transferer != nil {
 		syncerOpts = append(syncerOpts, filesync.WithTransferer(transferer))
 	}
+	for name, t := range newDownloaderTransferers(cfg, logger) {
+		syncerOpts = append(syncerOpts, filesync.WithDownloaderTransferer(name, t))
+	}
 
 	syncr := filesync.New(cfg.Sync.SyncingPath, syncerOpts...)
 
-	// Create timeline recorder
-	timelineRecorder := timeline.NewRecorder(
-		timeline.WithLogger(logger.With().Str("component", "timeline").Logger()),
+	// Create notification dispatcher
+	dispatcher := notify.NewDispatcher(
+		timelineRecorder,
+		notify.WithLogger(logger.With().Str("component", "notify").Logger()),
 	)
 
+	for name, nCfg := range cfg.Notifications {
+		notifier, err := notify.New(name, nCfg)
+		if err == nil {
+			err = dispatcher.Add(notifier, notify.Rule{
+				Events:    nCfg.Events,
+				Title:     nCfg.Title,
+				Message:   nCfg.Message,
+				RateLimit: nCfg.RateLimit,
+			})
+		}
+		if err != nil {
+			_ = timelineRecorder.Close()
+			return nil, fmt.Errorf("notification %q: %w", name, err)
+		}
+
+		logger.Info().Str("name", name).Str("type", nCfg.Type).Msg("configured notification")
+	}
+
 	// Create orchestrator
 	pollInterval := cfg.Sync.PollInterval
 	if pollInterval == 0 {
 		pollInterval = defaultPollInterval
 	}
+	sweepInterval := cfg.Retention.SweepInterval
+	if sweepInterval == 0 {
+		sweepInterval = defaultSweepInterval
+	}
 
 	orch := orchestrator.New(
 		dlRegistry,
@@ -214,18 +210,53 @@ func New(cfg config.Config, opts Options) (*Server, error) {
 		orchestrator.WithLogger(logger.With().Str("component", "orchestrator").Logger()),
 		orchestrator.WithPollInterval(pollInterval),
 		orchestrator.WithTimeline(timelineRecorder),
+		orchestrator.WithRetention(orchestrator.Retention{
+			Completed: cfg.Retention.Completed,
+			Errored:   cfg.Retention.Errored,
+			Cancelled: cfg.Retention.Cancelled,
+		}),
+		orchestrator.WithSweepInterval(sweepInterval),
+		orchestrator.WithDryRun(cfg.Sync.DryRun),
 	)
 
+	if cfg.Sync.DryRun {
+		logger.Warn().Msg("dry run: downloads are planned but nothing is transferred, moved, imported or deleted")
+	}
+
 	// Create API server
+	srv := &Server{
+		cfg:          cfg,
+		opts:         opts,
+		downloaders:  dlRegistry,
+		apps:         appRegistry,
+		orchestrator: orch,
+		syncer:       syncr,
+		timeline:     timelineRecorder,
+		notifier:     dispatcher,
+		logger:       logger,
+	}
+
 	apiOpts := []api.Option{
 		api.WithLogger(logger.With().Str("component", "api").Logger()),
 	}
 
+	if opts.LoadConfig != nil {
+		apiOpts = append(apiOpts, api.WithConfigReloader(srv.Reload))
+	}
+	if cfg.Server.APIKey != "" {
+		apiOpts = append(apiOpts, api.WithAPIKey(cfg.Server.APIKey))
+	} else {
+		logger.Info().Msg("server.apiKey is not set, API requests that change state are disabled")
+	}
+	if opts.Redactor != nil {
+		apiOpts = append(apiOpts, api.WithRedactor(opts.Redactor))
+	}
+
 	if opts.UIFS != (embed.FS{}) {
 		apiOpts = append(apiOpts, api.WithUI(opts.UIFS, opts.UIPath))
 	}
 
-	apiServer := api.New(
+	srv.apiServer = api.New(
 		orch,
 		dlRegistry,
 		appRegistry,
@@ -233,14 +264,272 @@ func New(cfg config.Config, opts Options) (*Server, error) {
 		apiOpts...,
 	)
 
-	return &Server{
-		cfg:          cfg,
-		opts:         opts,
-		apiServer:    apiServer,
-		orchestrator: orch,
-		syncer:       syncr,
-		logger:       logger,
-	}, nil
+	return srv, nil
+}
+
+// recordStall records a transfer the sync watchdog cancelled in the timeline.
+func recordStall(recorder timeline.Recorder, stall filesync.Stall) {
+	message := fmt.Sprintf("Transfer stalled: %s", stall.Files[0])
+	if len(stall.Files) > 1 {
+		message = fmt.Sprintf("Transfer stalled: %d files of %s", len(stall.Files), stall.Job.Name)
+	}
+
+	recorder.Record(timeline.Event{
+		Type:         timeline.EventStalled,
+		Message:      message,
+		DownloadID:   stall.Job.ID,
+		DownloadName: stall.Job.Name,
+		Downloader:   stall.Job.Downloader,
+		Details: map[string]any{
+			"file":     stall.Files[0],
+			"files":    stall.Files,
+			"error":    stall.Err.Error(),
+			"attempt":  stall.Attempt,
+			"retrying": stall.Retrying,
+		},
+	})
+}
+
+// newDownloader creates a downloader client, or returns nil for an unknown type.
+func newDownloader(name string, dlCfg config.DownloaderConfig, logger zerolog.Logger) download.Downloader {
+	switch dlCfg.Type {
+	case "qbittorrent":
+		return download.NewQBittorrent(
+			name,
+			dlCfg,
+			download.WithLogger(logger.With().Str("downloader", name).Logger()),
+		)
+	default:
+		return nil
+	}
+}
+
+// newTransferer creates the shared transfer backend with the SSH settings of
+// the first downloader that has them and does not set its own transfer backend,
+// or returns nil if no downloader does.
+func newTransferer(cfg config.Config, logger zerolog.Logger) transfer.Transferer {
+	shared := cfg.SharedTransferDownloader()
+	if shared == "" {
+		return nil
+	}
+	sshCfg := cfg.Downloaders[shared].SSH
+
+	backend := transfer.Backend(cfg.Sync.TransferBackend)
+	if backend == "" {
+		backend = transfer.BackendRclone
+	}
+
+	return newSSHTransferer(backend, sshCfg, cfg.Sync, logger.With().Str("component", "transfer").Logger())
+}
+
+// newDownloaderTransferers creates the transfer backends of the downloaders
+// that set their own, keyed by downloader name.
+func newDownloaderTransferers(cfg config.Config, logger zerolog.Logger) map[string]transfer.Transferer {
+	transferers := make(map[string]transfer.Transferer)
+	for _, name := range slices.Sorted(maps.Keys(cfg.Downloaders)) {
+		dlCfg := cfg.Downloaders[name]
+		if dlCfg.Transfer.Backend == "" {
+			continue
+		}
+
+		transferLogger := logger.With().Str("component", "transfer").Str("downloader", name).Logger()
+		switch backend := transfer.Backend(dlCfg.Transfer.Backend); backend {
+		case transfer.BackendHTTP:
+			transferers[name] = newHTTPTransferer(dlCfg.Transfer, cfg.Sync, transferLogger)
+		case transfer.BackendFTP:
+			transferers[name] = newFTPTransferer(dlCfg.Transfer, cfg.Sync, transferLogger)
+		case transfer.BackendLocal:
+			transferers[name] = newLocalTransferer(dlCfg.Transfer, cfg.Sync, transferLogger)
+		case transfer.BackendRclone, transfer.BackendSFTP:
+			transferers[name] = newSSHTransferer(backend, dlCfg.SSH, cfg.Sync, transferLogger)
+		}
+	}
+	return transferers
+}
+
+// parallelConnections returns the configured connections per file, or the
+// default.
+func parallelConnections(syncCfg config.SyncConfig) int {
+	if syncCfg.ParallelConnections == 0 {
+		return 8 //nolint:mnd // default parallel connections
+	}
+	return syncCfg.ParallelConnections
+}
+
+// newSSHTransferer creates an rclone or sftp transfer backend.
+func newSSHTransferer(
+	backend transfer.Backend,
+	sshCfg config.SSHConfig,
+	syncCfg config.SyncConfig,
+	logger zerolog.Logger,
+) transfer.Transferer {
+	// Validation has already rejected malformed jump hosts
+	jumpHosts, _ := sshCfg.JumpHosts()
+	var transferJumps []transfer.JumpHost
+	for _, jump := range jumpHosts {
+		transferJumps = append(transferJumps, transfer.JumpHost{Host: jump.Host, Port: jump.Port, User: jump.User})
+	}
+
+	transferOpts := transfer.Options{
+		SSH: transfer.SSHConfig{
+			Host:              sshCfg.Host,
+			Port:              sshCfg.Port,
+			User:              sshCfg.User,
+			KeyFile:           sshCfg.KeyFile,
+			KnownHostsFile:    sshCfg.KnownHostsFile,
+			IgnoreHostKey:     sshCfg.IgnoreHostKey,
+			Password:          sshCfg.Password,
+			UseAgent:          sshCfg.UseAgent,
+			CertFile:          sshCfg.CertFile,
+			KeyPassphrase:     sshCfg.KeyPassphrase,
+			KeyPassphraseFile: sshCfg.KeyPassphraseFile,
+			JumpHosts:         transferJumps,
+			Timeout:           sshCfg.Timeout,
+		},
+		ParallelConnections: parallelConnections(syncCfg),
+		SpeedLimit:          syncCfg.TransferSpeedMax,
+	}
+
+	logger.Info().
+		Str("backend", string(backend)).
+		Str("host", sshCfg.Host).
+		Int("port", sshCfg.Port).
+		Str("user", sshCfg.User).
+		Int("jump_hosts", len(transferJumps)).
+		Int("parallel_connections", transferOpts.ParallelConnections).
+		Msg("transfer backend configured")
+
+	if backend == transfer.BackendSFTP {
+		return transfer.NewSFTP(transferOpts, transfer.WithLogger(logger))
+	}
+	return transfer.NewRclone(transferOpts, transfer.WithLogger(logger))
+}
+
+// newHTTPTransferer creates an http transfer backend.
+func newHTTPTransferer(
+	transferCfg config.TransferConfig,
+	syncCfg config.SyncConfig,
+	logger zerolog.Logger,
+) transfer.Transferer {
+	transferOpts := transfer.Options{
+		HTTP: transfer.HTTPConfig{
+			URL:        transferCfg.URL,
+			RemotePath: transferCfg.RemotePath,
+			Username:   transferCfg.Username,
+			Password:   transferCfg.Password,
+			Timeout:    transferCfg.Timeout,
+		},
+		ParallelConnections: parallelConnections(syncCfg),
+		SpeedLimit:          syncCfg.TransferSpeedMax,
+	}
+
+	var host string
+	if u, err := url.Parse(transferCfg.URL); err == nil {
+		host = u.Host
+	}
+
+	logger.Info().
+		Str("backend", string(transfer.BackendHTTP)).
+		Str("host", host).
+		Str("remote_path", transferCfg.RemotePath).
+		Int("parallel_connections", transferOpts.ParallelConnections).
+		Msg("transfer backend configured")
+
+	return transfer.NewHTTP(transferOpts, transfer.WithLogger(logger))
+}
+
+// newFTPTransferer creates an ftp transfer backend.
+func newFTPTransferer(
+	transferCfg config.TransferConfig,
+	syncCfg config.SyncConfig,
+	logger zerolog.Logger,
+) transfer.Transferer {
+	transferOpts := transfer.Options{
+		FTP: transfer.FTPConfig{
+			URL:                transferCfg.URL,
+			RemotePath:         transferCfg.RemotePath,
+			Username:           transferCfg.Username,
+			Password:           transferCfg.Password,
+			InsecureSkipVerify: transferCfg.InsecureSkipVerify,
+			DisableEPSV:        transferCfg.DisableEPSV,
+			IgnorePassiveIP:    transferCfg.IgnorePassiveIP,
+			Timeout:            transferCfg.Timeout,
+		},
+		ParallelConnections: parallelConnections(syncCfg),
+		SpeedLimit:          syncCfg.TransferSpeedMax,
+	}
+
+	var scheme, host string
+	if u, err := url.Parse(transferCfg.URL); err == nil {
+		scheme, host = u.Scheme, u.Host
+	}
+
+	logger.Info().
+		Str("backend", string(transfer.BackendFTP)).
+		Str("scheme", scheme).
+		Str("host", host).
+		Str("remote_path", transferCfg.RemotePath).
+		Int("parallel_connections", transferOpts.ParallelConnections).
+		Msg("transfer backend configured")
+
+	return transfer.NewFTP(transferOpts, transfer.WithLogger(logger))
+}
+
+// newLocalTransferer creates a local filesystem transfer backend.
+func newLocalTransferer(
+	transferCfg config.TransferConfig,
+	syncCfg config.SyncConfig,
+	logger zerolog.Logger,
+) transfer.Transferer {
+	transferOpts := transfer.Options{
+		Local: transfer.LocalConfig{
+			RemotePath: transferCfg.RemotePath,
+			MountPath:  transferCfg.MountPath,
+			NoHardlink: transferCfg.NoHardlink,
+		},
+		SpeedLimit: syncCfg.TransferSpeedMax,
+	}
+
+	logger.Info().
+		Str("backend", string(transfer.BackendLocal)).
+		Str("remote_path", transferCfg.RemotePath).
+		Str("mount_path", transferCfg.MountPath).
+		Bool("hardlink", !transferCfg.NoHardlink).
+		Msg("transfer backend configured")
+
+	return transfer.NewLocal(transferOpts, transfer.WithLogger(logger))
+}
+
+// newApp creates an app client, or returns nil for an unknown type.
+func newApp(name string, appCfg config.AppEntryConfig, logger zerolog.Logger) app.App {
+	opts := []app.Option{
+		app.WithLogger(logger.With().Str("app", name).Logger()),
+		app.WithCleanupOnCategoryChange(appCfg.CleanupOnCategoryChange),
+		app.WithCleanupOnRemove(appCfg.CleanupOnRemove),
+		app.WithPostImport(appCfg.PostImport),
+		app.WithTags(appCfg.Tags, app.TagMatch(appCfg.TagMatch)),
+	}
+
+	// Build app config from entry config
+	arrCfg := app.ArrConfig{
+		URL:           appCfg.URL,
+		APIKey:        appCfg.APIKey,
+		Category:      appCfg.Category,
+		DownloadsPath: appCfg.DownloadsPath,
+		HTTPTimeout:   appCfg.HTTPTimeout,
+		MapImportPath: appCfg.ImportPathMappings.Map,
+	}
+
+	switch appCfg.Type {
+	case "sonarr":
+		return app.NewSonarr(name, arrCfg, opts...)
+	case "radarr":
+		return app.NewRadarr(name, arrCfg, opts...)
+	case "passthrough":
+		return app.NewPassthrough(name, appCfg.Category, appCfg.DownloadsPath, opts...)
+	default:
+		return nil
+	}
 }
 
 // Run starts the server and blocks until the context is cancelled.
@@ -251,15 +540,28 @@ func (s *Server) Run(ctx context.Context) error {
 		Str("syncing_path", s.cfg.Sync.SyncingPath).
 		Msg("starting seedreap")
 
+	// Start notifications first so they see the orchestrator's startup events
+	if s.notifier.Len() > 0 {
+		s.notifier.Start(ctx)
+	}
+
 	// Start orchestrator
 	if err := s.orchestrator.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start orchestrator: %w", err)
 	}
 
+	// Reload the configuration when the config file changes
+	if s.opts.ConfigFile != "" && s.opts.LoadConfig != nil {
+		if err := s.watchConfig(ctx, s.opts.ConfigFile); err != nil {
+			s.logger.Warn().Err(err).Msg("config file changes will not be reloaded automatically")
+		}
+	}
+
 	// Start server in goroutine
+	listen := s.cfg.Server.Listen
 	errCh := make(chan error, 1)
 	go func() {
-		if err := s.apiServer.Start(s.cfg.Server.Listen); err != nil {
+		if err := s.apiServer.Start(listen); err != nil {
 			errCh <- err
 		}
 	}()
@@ -290,12 +592,17 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	}
 
 	s.orchestrator.Stop()
+	s.notifier.Stop()
 
 	// Close the syncer to release transfer backend resources
 	if err := s.syncer.Close(); err != nil {
 		s.logger.Error().Err(err).Msg("syncer close error")
 	}
 
+	if err := s.timeline.Close(); err != nil {
+		s.logger.Error().Err(err).Msg("timeline close error")
+	}
+
 	s.logger.Info().Msg("shutdown complete")
 	return nil
 }
@@ -0,0 +1,421 @@
+package testing
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+)
+
+// FTP server configuration constants.
+const (
+	ftpAcceptTimeout  = 5 * time.Second
+	ftpCertValidity   = 24 * time.Hour
+	ftpPortHighFactor = 256 // PASV replies encode the port as two bytes
+)
+
+// FTPTLS is how the test FTP server secures connections.
+type FTPTLS string
+
+// TLS modes of the test FTP server.
+const (
+	FTPTLSNone     FTPTLS = ""
+	FTPTLSExplicit FTPTLS = "explicit" // AUTH TLS after connecting
+	FTPTLSImplicit FTPTLS = "implicit" // TLS from the start
+)
+
+// FTPServerConfig configures the FTP server.
+type FTPServerConfig struct {
+	// Root is the directory served as /
+	Root string
+	// User and Password are the accepted credentials
+	User     string
+	Password string
+	// TLS is how connections are secured (default: plain FTP)
+	TLS FTPTLS
+	// PassiveIP is the address advertised in PASV replies (default: the listening address)
+	PassiveIP string
+	// DisableEPSV rejects EPSV, so clients have to use PASV
+	DisableEPSV bool
+}
+
+// FTPServer is a minimal in-process FTP server for integration tests. It
+// supports logging in, passive data connections, REST and RETR, over plain
+// FTP or FTPS.
+type FTPServer struct {
+	Host string
+	Port int
+	URL  string // ftp://, ftpes:// or ftps:// URL of the root
+
+	cfg       FTPServerConfig
+	listener  net.Listener
+	tlsConfig *tls.Config
+	wg        sync.WaitGroup
+
+	mu          sync.Mutex
+	conns       map[net.Conn]struct{}
+	logins      int
+	restOffsets []int64
+}
+
+// StartFTPServer starts an FTP server on a random local port.
+func StartFTPServer(cfg FTPServerConfig) (*FTPServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	s := &FTPServer{
+		cfg:      cfg,
+		listener: listener,
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	if cfg.TLS != FTPTLSNone {
+		if s.tlsConfig, err = selfSignedTLSConfig(); err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+	}
+
+	addr, _ := listener.Addr().(*net.TCPAddr)
+	s.Host = addr.IP.String()
+	s.Port = addr.Port
+
+	scheme := "ftp"
+	switch cfg.TLS {
+	case FTPTLSNone:
+	case FTPTLSExplicit:
+		scheme = "ftpes"
+	case FTPTLSImplicit:
+		scheme = "ftps"
+	}
+	s.URL = fmt.Sprintf("%s://%s/", scheme, listener.Addr())
+
+	s.wg.Go(s.acceptLoop)
+	return s, nil
+}
+
+// Close stops the server and closes all connections.
+func (s *FTPServer) Close() error {
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// Logins returns the number of successful logins.
+func (s *FTPServer) Logins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logins
+}
+
+// RestOffsets returns the offsets files were retrieved from, in order.
+func (s *FTPServer) RestOffsets() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.restOffsets...)
+}
+
+// CreateTestFile creates a file under the root with the given content.
+func (s *FTPServer) CreateTestFile(relativePath string, content []byte) error {
+	fullPath := s.localPath(relativePath)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(fullPath, content, 0600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", fullPath, err)
+	}
+	return nil
+}
+
+// localPath maps an FTP path to a path under the root.
+func (s *FTPServer) localPath(ftpPath string) string {
+	return filepath.Join(s.cfg.Root, filepath.FromSlash(path.Clean("/"+ftpPath)))
+}
+
+func (s *FTPServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.track(conn, true)
+		s.wg.Go(func() {
+			defer s.track(conn, false)
+			defer conn.Close()
+			s.serve(conn)
+		})
+	}
+}
+
+// track adds or removes a connection closed by Close.
+func (s *FTPServer) track(conn net.Conn, add bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if add {
+		s.conns[conn] = struct{}{}
+	} else {
+		delete(s.conns, conn)
+	}
+}
+
+// ftpSession is the state of a control connection.
+type ftpSession struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	user     string
+	loggedIn bool
+	protect  bool // PROT P: data connections use TLS
+	rest     int64
+	passive  net.Listener
+}
+
+func (sess *ftpSession) reply(code int, msg string) {
+	_, _ = fmt.Fprintf(sess.conn, "%d %s\r\n", code, msg)
+}
+
+//nolint:gocognit,cyclop,funlen // one case per supported command
+func (s *FTPServer) serve(conn net.Conn) {
+	if s.cfg.TLS == FTPTLSImplicit {
+		conn = tls.Server(conn, s.tlsConfig)
+	}
+	sess := &ftpSession{conn: conn, reader: bufio.NewReader(conn)}
+	defer func() {
+		if sess.passive != nil {
+			_ = sess.passive.Close()
+		}
+	}()
+
+	sess.reply(ftp.StatusReady, "seedreap test server ready")
+
+	for {
+		line, err := sess.reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		cmd = strings.ToUpper(cmd)
+
+		if !sess.loggedIn && !slices.Contains([]string{"AUTH", "USER", "PASS", "FEAT", "QUIT"}, cmd) {
+			sess.reply(ftp.StatusNotLoggedIn, "Not logged in")
+			continue
+		}
+
+		switch cmd {
+		case "AUTH":
+			if s.cfg.TLS != FTPTLSExplicit || !strings.EqualFold(arg, "TLS") {
+				sess.reply(ftp.StatusNotImplemented, "TLS not available")
+				continue
+			}
+			sess.reply(ftp.StatusAuthOK, "Proceed with negotiation")
+			sess.conn = tls.Server(sess.conn, s.tlsConfig)
+			sess.reader = bufio.NewReader(sess.conn)
+		case "USER":
+			sess.user = arg
+			sess.reply(ftp.StatusUserOK, "Password required")
+		case "PASS":
+			if sess.user != s.cfg.User || arg != s.cfg.Password {
+				sess.reply(ftp.StatusNotLoggedIn, "Login incorrect")
+				continue
+			}
+			sess.loggedIn = true
+			s.mu.Lock()
+			s.logins++
+			s.mu.Unlock()
+			sess.reply(ftp.StatusLoggedIn, "Logged in")
+		case "FEAT":
+			_, _ = io.WriteString(sess.conn, "211-Features:\r\n EPSV\r\n PASV\r\n REST STREAM\r\n SIZE\r\n UTF8\r\n211 End\r\n")
+		case "OPTS", "TYPE", "PBSZ", "NOOP":
+			sess.reply(ftp.StatusCommandOK, "OK")
+		case "PROT":
+			sess.protect = strings.EqualFold(arg, "P")
+			sess.reply(ftp.StatusCommandOK, "OK")
+		case "PWD":
+			sess.reply(ftp.StatusPathCreated, `"/" is the current directory`)
+		case "CWD":
+			if info, statErr := os.Stat(s.localPath(arg)); statErr != nil || !info.IsDir() {
+				sess.reply(ftp.StatusFileUnavailable, "No such directory")
+				continue
+			}
+			sess.reply(ftp.StatusRequestedFileActionOK, "OK")
+		case "SIZE":
+			info, statErr := os.Stat(s.localPath(arg))
+			if statErr != nil {
+				sess.reply(ftp.StatusFileUnavailable, "No such file")
+				continue
+			}
+			sess.reply(ftp.StatusFile, strconv.FormatInt(info.Size(), 10))
+		case "EPSV", "PASV":
+			s.openPassive(sess, cmd)
+		case "REST":
+			offset, parseErr := strconv.ParseInt(arg, 10, 64)
+			if parseErr != nil || offset < 0 {
+				sess.reply(ftp.StatusBadArguments, "Invalid offset")
+				continue
+			}
+			sess.rest = offset
+			sess.reply(ftp.StatusRequestFilePending, "Restarting")
+		case "RETR":
+			s.retrieve(sess, arg)
+		case "QUIT":
+			sess.reply(ftp.StatusClosing, "Bye")
+			return
+		default:
+			sess.reply(ftp.StatusNotImplemented, "Command not implemented")
+		}
+	}
+}
+
+// openPassive listens for the data connection of the next transfer.
+func (s *FTPServer) openPassive(sess *ftpSession, cmd string) {
+	if cmd == "EPSV" && s.cfg.DisableEPSV {
+		sess.reply(ftp.StatusNotImplemented, "EPSV not supported")
+		return
+	}
+	if sess.passive != nil {
+		_ = sess.passive.Close()
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(s.Host, "0"))
+	if err != nil {
+		sess.reply(ftp.StatusCanNotOpenDataConnection, "Cannot open data connection")
+		return
+	}
+	sess.passive = listener
+	addr, _ := listener.Addr().(*net.TCPAddr)
+	port := addr.Port
+
+	if cmd == "EPSV" {
+		sess.reply(ftp.StatusExtendedPassiveMode, fmt.Sprintf("Entering Extended Passive Mode (|||%d|)", port))
+		return
+	}
+
+	ip := s.cfg.PassiveIP
+	if ip == "" {
+		ip = s.Host
+	}
+	sess.reply(ftp.StatusPassiveMode, fmt.Sprintf("Entering Passive Mode (%s,%d,%d)",
+		strings.ReplaceAll(ip, ".", ","), port/ftpPortHighFactor, port%ftpPortHighFactor))
+}
+
+// retrieve sends a file over the passive data connection, from the REST offset.
+func (s *FTPServer) retrieve(sess *ftpSession, ftpPath string) {
+	offset := sess.rest
+	sess.rest = 0
+
+	listener := sess.passive
+	sess.passive = nil
+	if listener == nil {
+		sess.reply(ftp.StatusCanNotOpenDataConnection, "Use PASV or EPSV first")
+		return
+	}
+	defer listener.Close()
+
+	f, err := os.Open(s.localPath(ftpPath))
+	if err != nil {
+		sess.reply(ftp.StatusFileUnavailable, "No such file")
+		return
+	}
+	defer f.Close()
+
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		sess.reply(ftp.StatusFileUnavailable, "Invalid offset")
+		return
+	}
+
+	s.mu.Lock()
+	s.restOffsets = append(s.restOffsets, offset)
+	s.mu.Unlock()
+
+	sess.reply(ftp.StatusAboutToSend, "Opening data connection")
+
+	if tcpListener, ok := listener.(*net.TCPListener); ok {
+		_ = tcpListener.SetDeadline(time.Now().Add(ftpAcceptTimeout))
+	}
+	data, err := listener.Accept()
+	if err != nil {
+		sess.reply(ftp.StatusCanNotOpenDataConnection, "Cannot open data connection")
+		return
+	}
+	s.track(data, true)
+	defer s.track(data, false)
+
+	if sess.protect {
+		tlsData := tls.Server(data, s.tlsConfig)
+		ctx, cancel := context.WithTimeout(context.Background(), ftpAcceptTimeout)
+		err = tlsData.HandshakeContext(ctx)
+		cancel()
+		if err != nil {
+			_ = data.Close()
+			sess.reply(ftp.StatusCanNotOpenDataConnection, "TLS handshake failed")
+			return
+		}
+		data = tlsData
+	}
+
+	_, err = io.Copy(data, f)
+	if closeErr := data.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		sess.reply(ftp.StatusTransfertAborted, "Transfer aborted")
+		return
+	}
+	sess.reply(ftp.StatusClosingDataConnection, "Transfer complete")
+}
+
+// selfSignedTLSConfig returns a server TLS configuration with a self-signed
+// certificate for 127.0.0.1.
+func selfSignedTLSConfig() (*tls.Config, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "seedreap test server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(ftpCertValidity),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)}, //nolint:mnd // loopback address
+		DNSNames:     []string{"localhost"},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
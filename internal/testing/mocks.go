@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/seedreap/seedreap/internal/app"
 	"github.com/seedreap/seedreap/internal/config"
 	"github.com/seedreap/seedreap/internal/download"
 	"github.com/seedreap/seedreap/internal/transfer"
@@ -164,6 +165,100 @@ func (m *MockDownloader) SetCategory(id, category string) {
 	}
 }
 
+// SetTags changes a download's tags.
+func (m *MockDownloader) SetTags(id string, tags []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if dl, ok := m.downloads[id]; ok {
+		dl.Tags = tags
+	}
+}
+
+// MockAction records a call made through the download.Actioner interface.
+type MockAction struct {
+	Action string
+	ID     string
+	Value  any
+}
+
+// MockActionDownloader is a MockDownloader that also implements download.Actioner
+// and download.Adder.
+type MockActionDownloader struct {
+	*MockDownloader
+
+	actionMu sync.Mutex
+	actions  []MockAction
+
+	// ActionErr, if set, is returned by every action method.
+	ActionErr error
+}
+
+// NewMockActionDownloader creates a new mock downloader that supports actions.
+func NewMockActionDownloader(name string) *MockActionDownloader {
+	return &MockActionDownloader{MockDownloader: NewMockDownloader(name)}
+}
+
+// Pause records a pause action.
+func (m *MockActionDownloader) Pause(_ context.Context, id string) error {
+	return m.record("pause", id, nil)
+}
+
+// SetCategory records a category change and applies it to the download.
+func (m *MockActionDownloader) SetCategory(_ context.Context, id, category string) error {
+	if err := m.record("setCategory", id, category); err != nil {
+		return err
+	}
+	m.MockDownloader.SetCategory(id, category)
+	return nil
+}
+
+// AddTags records an add tags action.
+func (m *MockActionDownloader) AddTags(_ context.Context, id string, tags []string) error {
+	return m.record("addTags", id, tags)
+}
+
+// Delete records a delete action and removes the download.
+func (m *MockActionDownloader) Delete(_ context.Context, id string, deleteFiles bool) error {
+	if err := m.record("delete", id, deleteFiles); err != nil {
+		return err
+	}
+	m.RemoveDownload(id)
+	return nil
+}
+
+// SetShareLimits records a share limits action.
+func (m *MockActionDownloader) SetShareLimits(_ context.Context, id string, limits download.ShareLimits) error {
+	return m.record("setShareLimits", id, limits)
+}
+
+// Add records an add action; Value is the download.AddRequest.
+func (m *MockActionDownloader) Add(_ context.Context, req download.AddRequest) error {
+	return m.record("add", "", req)
+}
+
+// Actions returns a copy of the recorded actions.
+func (m *MockActionDownloader) Actions() []MockAction {
+	m.actionMu.Lock()
+	defer m.actionMu.Unlock()
+
+	result := make([]MockAction, len(m.actions))
+	copy(result, m.actions)
+	return result
+}
+
+func (m *MockActionDownloader) record(action, id string, value any) error {
+	if m.ActionErr != nil {
+		return m.ActionErr
+	}
+
+	m.actionMu.Lock()
+	defer m.actionMu.Unlock()
+
+	m.actions = append(m.actions, MockAction{Action: action, ID: id, Value: value})
+	return nil
+}
+
 // MockTransferer is a mock implementation of transfer.Transferer for testing.
 type MockTransferer struct {
 	mu    sync.RWMutex
@@ -220,6 +315,11 @@ func (m *MockTransferer) Name() string {
 	return "mock"
 }
 
+// TestConnection always succeeds.
+func (m *MockTransferer) TestConnection(_ context.Context) error {
+	return nil
+}
+
 // GetSpeed returns the current transfer speed.
 func (m *MockTransferer) GetSpeed() int64 {
 	m.mu.RLock()
@@ -251,6 +351,84 @@ func (m *MockTransferer) GetTransferCalls() []transfer.Request {
 	return result
 }
 
+// MockBatchTransferer is a MockTransferer that also implements
+// transfer.BatchTransferer.
+type MockBatchTransferer struct {
+	*MockTransferer
+
+	batchMu sync.Mutex
+
+	// Track batch calls
+	BatchCalls   [][]transfer.Request
+	BatchWorkers []int
+
+	// OnBatch, if set, replaces the default batch behavior
+	OnBatch func(
+		ctx context.Context,
+		reqs []transfer.Request,
+		workers int,
+		onProgress transfer.BatchProgressFunc,
+		onDone transfer.BatchDoneFunc,
+	) error
+}
+
+// NewMockBatchTransferer creates a new mock transferer that supports batches.
+func NewMockBatchTransferer() *MockBatchTransferer {
+	return &MockBatchTransferer{MockTransferer: NewMockTransferer()}
+}
+
+// TransferBatch copies a set of files (mock implementation). By default, each
+// file is transferred like MockTransferer.Transfer, using OnTransfer if set,
+// without being recorded as a transfer call.
+func (m *MockBatchTransferer) TransferBatch(
+	ctx context.Context,
+	reqs []transfer.Request,
+	workers int,
+	onProgress transfer.BatchProgressFunc,
+	onDone transfer.BatchDoneFunc,
+) error {
+	m.batchMu.Lock()
+	m.BatchCalls = append(m.BatchCalls, reqs)
+	m.BatchWorkers = append(m.BatchWorkers, workers)
+	m.batchMu.Unlock()
+
+	if m.OnBatch != nil {
+		return m.OnBatch(ctx, reqs, workers, onProgress, onDone)
+	}
+
+	for i, req := range reqs {
+		var err error
+		if m.OnTransfer != nil {
+			err = m.OnTransfer(ctx, req, func(p transfer.Progress) { onProgress(i, p) })
+		} else {
+			err = m.createFile(req.LocalPath, req.Size)
+		}
+		if err == nil {
+			onProgress(i, transfer.Progress{Transferred: req.Size})
+		}
+		onDone(i, err)
+	}
+	return nil
+}
+
+// GetBatchWorkers returns the number of workers of each recorded batch call.
+func (m *MockBatchTransferer) GetBatchWorkers() []int {
+	m.batchMu.Lock()
+	defer m.batchMu.Unlock()
+	result := make([]int, len(m.BatchWorkers))
+	copy(result, m.BatchWorkers)
+	return result
+}
+
+// GetBatchCalls returns the recorded batch calls.
+func (m *MockBatchTransferer) GetBatchCalls() [][]transfer.Request {
+	m.batchMu.Lock()
+	defer m.batchMu.Unlock()
+	result := make([][]transfer.Request, len(m.BatchCalls))
+	copy(result, m.BatchCalls)
+	return result
+}
+
 // MockApp is a mock implementation of app.App for testing.
 type MockApp struct {
 	name                    string
@@ -259,6 +437,9 @@ type MockApp struct {
 	downloadsPath           string
 	cleanupOnCategoryChange bool
 	cleanupOnRemove         bool
+	postImport              config.PostImportConfig
+	tags                    []string
+	tagMatch                app.TagMatch
 
 	mu           sync.RWMutex
 	ImportCalls  []string
@@ -291,6 +472,22 @@ func (m *MockApp) Category() string {
 	return m.category
 }
 
+// Tags returns the tags this app handles.
+func (m *MockApp) Tags() []string {
+	return m.tags
+}
+
+// TagMatch returns the tag match mode.
+func (m *MockApp) TagMatch() app.TagMatch {
+	return m.tagMatch
+}
+
+// SetTags sets the tags this app handles and how they are matched.
+func (m *MockApp) SetTags(tags []string, match app.TagMatch) {
+	m.tags = tags
+	m.tagMatch = match
+}
+
 // DownloadsPath returns the downloads path.
 func (m *MockApp) DownloadsPath() string {
 	return m.downloadsPath
@@ -306,6 +503,16 @@ func (m *MockApp) CleanupOnRemove() bool {
 	return m.cleanupOnRemove
 }
 
+// PostImport returns the post-import actions.
+func (m *MockApp) PostImport() config.PostImportConfig {
+	return m.postImport
+}
+
+// SetPostImport sets the post-import actions.
+func (m *MockApp) SetPostImport(v config.PostImportConfig) {
+	m.postImport = v
+}
+
 // SetCleanupOnCategoryChange sets the cleanup on category change flag.
 func (m *MockApp) SetCleanupOnCategoryChange(v bool) {
 	m.cleanupOnCategoryChange = v
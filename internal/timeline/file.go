@@ -0,0 +1,230 @@
+package timeline
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// logFileMode is the permission of the event log, which may contain download names.
+	logFileMode = 0o600
+
+	// logDirMode is the permission of directories created for the event log.
+	logDirMode = 0o750
+
+	// minCompactLines is the smallest number of stale lines worth compacting.
+	minCompactLines = 1000
+)
+
+// NewFileRecorder creates a timeline recorder that persists events to an
+// append-only JSON Lines file at path, so the timeline survives restarts.
+//
+// Existing events are loaded on start and retention is applied to them.
+// Events dropped by retention or Clear are removed from the file when it is
+// compacted, which happens once it holds twice as many lines as there are
+// retained events. Unreadable lines, such as one truncated by a crash, are
+// skipped. Call Close on shutdown.
+func NewFileRecorder(path string, opts ...Option) (Recorder, error) {
+	r := newRecorder(opts...)
+
+	log, events, skipped, err := openEventLog(path)
+	if err != nil {
+		return nil, err
+	}
+	r.log = log
+
+	for _, e := range events {
+		r.store.append(e)
+		r.observeID(e.ID)
+	}
+	r.applyRetention(time.Now())
+
+	if skipped > 0 {
+		r.logger.Warn().Str("path", path).Int("lines", skipped).Msg("skipped unreadable timeline events")
+	}
+
+	// Drop expired and unreadable lines now rather than waiting for the log to double
+	if log.lines != r.store.len() {
+		if err = log.rewrite(r.store.events); err != nil {
+			_ = log.close()
+			return nil, err
+		}
+	}
+
+	r.logger.Info().Str("path", path).Int("events", r.store.len()).Msg("timeline loaded")
+
+	return r, nil
+}
+
+// persist appends an event to the log and compacts the log when it has grown
+// to twice the retained events. The caller must hold r.mu.
+func (r *recorder) persist(event Event) {
+	if err := r.log.append(event); err != nil {
+		r.logger.Error().Err(err).Str("id", event.ID).Msg("failed to persist timeline event")
+		return
+	}
+
+	if r.log.lines-r.store.len() >= max(r.store.len(), minCompactLines) {
+		r.compact()
+	}
+}
+
+// compact rewrites the log with only the retained events. The caller must hold r.mu.
+func (r *recorder) compact() {
+	if err := r.log.rewrite(r.store.events); err != nil {
+		r.logger.Error().Err(err).Msg("failed to compact timeline")
+	}
+}
+
+// observeID advances the ID counter past a loaded event's ID so new IDs do not
+// collide with ones issued before a restart.
+func (r *recorder) observeID(id string) {
+	i := strings.LastIndexByte(id, '_')
+	if !strings.HasPrefix(id, "evt_") || i < 0 {
+		return
+	}
+	if n, err := strconv.ParseInt(id[i+1:], 10, 64); err == nil && n >= r.nextID {
+		r.nextID = n + 1
+	}
+}
+
+// eventLog is an append-only JSON Lines file of events, oldest first.
+type eventLog struct {
+	path  string
+	file  *os.File
+	lines int // events in the file, including ones no longer retained
+}
+
+// openEventLog opens the log at path, creating it if needed, and reads the
+// events in it. It returns the number of lines that could not be decoded.
+func openEventLog(path string) (*eventLog, []Event, int, error) {
+	if err := os.MkdirAll(filepath.Dir(path), logDirMode); err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to create timeline directory: %w", err)
+	}
+
+	events, skipped, err := readEvents(path)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, logFileMode)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to open timeline: %w", err)
+	}
+
+	// Count skipped lines so a truncated tail is rewritten before appending to it
+	return &eventLog{path: path, file: file, lines: len(events) + skipped}, events, skipped, nil
+}
+
+// readEvents decodes the events in the file at path. A missing file holds no events.
+func readEvents(path string) ([]Event, int, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open timeline: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		events  []Event
+		skipped int
+	)
+
+	reader := bufio.NewReader(f)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			// Every event is written with its newline, so a final line without
+			// one is a torn write.
+			var e Event
+			if readErr == nil && json.Unmarshal(line, &e) == nil && e.ID != "" {
+				events = append(events, e)
+			} else {
+				skipped++
+			}
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, 0, fmt.Errorf("failed to read timeline: %w", readErr)
+		}
+	}
+
+	return events, skipped, nil
+}
+
+// append writes an event to the end of the log.
+func (l *eventLog) append(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if _, err = l.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	l.lines++
+	return nil
+}
+
+// rewrite atomically replaces the log with the given events.
+func (l *eventLog) rewrite(events []Event) error {
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary timeline: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err = enc.Encode(e); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("failed to write timeline: %w", err)
+		}
+	}
+
+	if err = w.Flush(); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write timeline: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), l.path); err != nil {
+		return fmt.Errorf("failed to replace timeline: %w", err)
+	}
+
+	// Appends must go to the new file
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, logFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to reopen timeline: %w", err)
+	}
+	_ = l.file.Close()
+	l.file = file
+	l.lines = len(events)
+
+	return nil
+}
+
+// close closes the log file.
+func (l *eventLog) close() error {
+	return l.file.Close()
+}
@@ -0,0 +1,133 @@
+package timeline_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seedreap/seedreap/internal/timeline"
+)
+
+func TestFileRecorder(t *testing.T) {
+	t.Run("events survive a restart", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "data", "timeline.jsonl")
+
+		r, err := timeline.NewFileRecorder(path)
+		require.NoError(t, err)
+		r.Record(timeline.Event{Type: timeline.EventDiscovered, DownloadID: "dl-1", Message: "first"})
+		r.Record(timeline.Event{Type: timeline.EventComplete, DownloadID: "dl-1", Message: "second"})
+		first := r.GetAll()
+		require.NoError(t, r.Close())
+
+		r, err = timeline.NewFileRecorder(path)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = r.Close() })
+
+		events := r.GetByDownload("dl-1")
+		require.Len(t, events, 2)
+		assert.Equal(t, first[0].ID, events[0].ID)
+		assert.True(t, first[0].Timestamp.Equal(events[0].Timestamp))
+		assert.Equal(t, timeline.EventComplete, events[0].Type)
+
+		// New IDs continue after the loaded ones
+		r.Record(timeline.Event{Message: "third"})
+		events = r.GetAll()
+		require.Len(t, events, 3)
+		assert.NotEqual(t, first[0].ID, events[0].ID)
+		assert.NotEqual(t, first[1].ID, events[0].ID)
+	})
+
+	t.Run("retention applies to loaded events", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "timeline.jsonl")
+
+		r, err := timeline.NewFileRecorder(path)
+		require.NoError(t, err)
+		r.Record(timeline.Event{Timestamp: time.Now().Add(-48 * time.Hour), Message: "old"})
+		for range 5 {
+			r.Record(timeline.Event{Message: "new"})
+		}
+		require.NoError(t, r.Close())
+
+		r, err = timeline.NewFileRecorder(path, timeline.WithMaxAge(24*time.Hour), timeline.WithMaxEvents(3))
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		assert.Len(t, r.GetAll(), 3)
+
+		// The file is compacted to the retained events
+		assert.Equal(t, 3, countLines(t, path))
+	})
+
+	t.Run("unreadable lines are skipped", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "timeline.jsonl")
+		content := `{"id":"evt_1","type":"discovered","timestamp":"2025-01-01T00:00:00Z","message":"ok"}` + "\n" +
+			"not json\n" +
+			`{"id":"evt_2","type":"comp`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		r, err := timeline.NewFileRecorder(path)
+		require.NoError(t, err)
+		r.Record(timeline.Event{Message: "appended"})
+		require.NoError(t, r.Close())
+
+		r, err = timeline.NewFileRecorder(path)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = r.Close() })
+
+		events := r.GetAll()
+		require.Len(t, events, 2)
+		assert.Equal(t, "appended", events[0].Message)
+		assert.Equal(t, "ok", events[1].Message)
+	})
+
+	t.Run("clear is persisted", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "timeline.jsonl")
+
+		r, err := timeline.NewFileRecorder(path)
+		require.NoError(t, err)
+		r.Record(timeline.Event{DownloadID: "dl-1"})
+		r.Record(timeline.Event{DownloadID: "dl-2"})
+		r.Clear("dl-1")
+		require.NoError(t, r.Close())
+
+		r, err = timeline.NewFileRecorder(path)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = r.Close() })
+
+		events := r.GetAll()
+		require.Len(t, events, 1)
+		assert.Equal(t, "dl-2", events[0].DownloadID)
+	})
+
+	t.Run("log is compacted as events are displaced", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "timeline.jsonl")
+
+		r, err := timeline.NewFileRecorder(path, timeline.WithMaxEvents(10))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = r.Close() })
+
+		for range 2500 {
+			r.Record(timeline.Event{Message: "event"})
+		}
+
+		assert.Less(t, countLines(t, path), 1100)
+		assert.Len(t, r.GetAll(), 10)
+	})
+
+	t.Run("fails when the path is a directory", func(t *testing.T) {
+		_, err := timeline.NewFileRecorder(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Count(string(data), "\n")
+}
@@ -0,0 +1,76 @@
+package timeline
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Query selects a page of timeline events. Zero-valued fields match everything.
+type Query struct {
+	// Types restricts results to these event types.
+	Types []EventType
+	// Downloads restricts results to events for these download IDs.
+	Downloads []string
+	// Downloaders restricts results to events from these downloaders.
+	Downloaders []string
+	// Apps restricts results to events for these apps.
+	Apps []string
+	// Categories restricts results to events for downloads that were in one of
+	// these categories at the time, and to other events whose "category"
+	// detail is one of these.
+	Categories []string
+	// Search is a case-insensitive substring matched against the download name and message.
+	Search string
+	// Since excludes events recorded before this time.
+	Since time.Time
+	// Until excludes events recorded at or after this time.
+	Until time.Time
+	// Oldest returns events oldest first instead of newest first.
+	Oldest bool
+	// After is the ID of the last event of the previous page.
+	After string
+	// Limit is the maximum number of events to return (0 = no limit).
+	Limit int
+}
+
+// Query returns the events matching q and the ID to pass as After to fetch the
+// next page. The returned ID is empty when there are no more events.
+func (r *recorder) Query(q Query) ([]Event, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.store.query(q)
+}
+
+// matches reports whether e passes the query's filters. category is the category
+// e's download was in, and search must be lower-cased.
+func (q Query) matches(e Event, category, search string) bool {
+	if len(q.Types) > 0 && !slices.Contains(q.Types, e.Type) {
+		return false
+	}
+	if len(q.Downloads) > 0 && !slices.Contains(q.Downloads, e.DownloadID) {
+		return false
+	}
+	if len(q.Downloaders) > 0 && !slices.Contains(q.Downloaders, e.Downloader) {
+		return false
+	}
+	if len(q.Apps) > 0 && !slices.Contains(q.Apps, e.AppName) {
+		return false
+	}
+	if len(q.Categories) > 0 && !slices.Contains(q.Categories, category) {
+		return false
+	}
+	if !q.Since.IsZero() && e.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Timestamp.Before(q.Until) {
+		return false
+	}
+	if search != "" &&
+		!strings.Contains(strings.ToLower(e.DownloadName), search) &&
+		!strings.Contains(strings.ToLower(e.Message), search) {
+		return false
+	}
+	return true
+}
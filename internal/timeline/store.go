@@ -0,0 +1,351 @@
+package timeline
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// store holds events oldest first, indexed by ID, download, app and downloader.
+//
+// Events are addressed by position: the number of events appended before them.
+// Positions do not change when old events are trimmed from the front, so the
+// indexes only need occasional pruning rather than rewriting.
+type store struct {
+	events []Event // oldest first
+	first  int     // position of events[0]
+
+	byID         map[string]int
+	byDownload   map[string][]int
+	byApp        map[string][]int
+	byDownloader map[string][]int
+
+	// categories holds the categories of each download, in the order they
+	// were recorded, so events can be filtered by category even if they
+	// don't carry one themselves. They outlive trimmed events while events
+	// for the download remain.
+	categories map[string][]categoryChange
+
+	// ordered is true while timestamps never decrease, which allows time
+	// ranges to be found by binary search.
+	ordered bool
+
+	// stale counts trimmed events that may still have index entries.
+	stale int
+}
+
+func newStore() *store {
+	return &store{
+		byID:         make(map[string]int),
+		byDownload:   make(map[string][]int),
+		byApp:        make(map[string][]int),
+		byDownloader: make(map[string][]int),
+		categories:   make(map[string][]categoryChange),
+		ordered:      true,
+	}
+}
+
+// len returns the number of events held.
+func (s *store) len() int {
+	return len(s.events)
+}
+
+// end returns the position the next appended event will get.
+func (s *store) end() int {
+	return s.first + len(s.events)
+}
+
+// at returns the event at pos, which must be live.
+func (s *store) at(pos int) Event {
+	return s.events[pos-s.first]
+}
+
+// append adds an event as the newest.
+func (s *store) append(e Event) {
+	pos := s.end()
+
+	if n := len(s.events); n > 0 && e.Timestamp.Before(s.events[n-1].Timestamp) {
+		s.ordered = false
+	}
+
+	s.events = append(s.events, e)
+	s.byID[e.ID] = pos
+	addToIndex(s.byDownload, e.DownloadID, pos)
+	addToIndex(s.byApp, e.AppName, pos)
+	addToIndex(s.byDownloader, e.Downloader, pos)
+
+	if category := detailCategory(e); category != "" && e.DownloadID != "" {
+		s.categories[e.DownloadID] = append(s.categories[e.DownloadID], categoryChange{at: e.Timestamp, category: category})
+	}
+}
+
+func addToIndex(index map[string][]int, key string, pos int) {
+	if key != "" {
+		index[key] = append(index[key], pos)
+	}
+}
+
+// trim drops the n oldest events.
+func (s *store) trim(n int) {
+	n = min(n, len(s.events))
+	if n <= 0 {
+		return
+	}
+
+	// Release the trimmed events; append reallocates once capacity runs out,
+	// so the backing array does not grow without bound.
+	clear(s.events[:n])
+	s.events = s.events[n:]
+	s.first += n
+	s.stale += n
+
+	// Prune once as many events have been trimmed as are held, so the cost is
+	// amortised over the appends that caused the trimming.
+	if s.stale > len(s.events) {
+		s.prune()
+	}
+
+	if len(s.events) == 0 {
+		s.ordered = true
+	}
+}
+
+// trimBefore drops the oldest events recorded before t.
+func (s *store) trimBefore(t time.Time) {
+	n := 0
+	if s.ordered {
+		n = sort.Search(len(s.events), func(i int) bool { return !s.events[i].Timestamp.Before(t) })
+	} else {
+		for n < len(s.events) && s.events[n].Timestamp.Before(t) {
+			n++
+		}
+	}
+	s.trim(n)
+}
+
+// prune removes index entries for trimmed events.
+func (s *store) prune() {
+	for id, pos := range s.byID {
+		if pos < s.first {
+			delete(s.byID, id)
+		}
+	}
+	for _, index := range []map[string][]int{s.byDownload, s.byApp, s.byDownloader} {
+		for key, positions := range index {
+			if live := s.live(positions); len(live) == 0 {
+				delete(index, key)
+			} else if len(live) < len(positions) {
+				index[key] = slices.Clone(live)
+			}
+		}
+	}
+	for id := range s.categories {
+		if _, ok := s.byDownload[id]; !ok {
+			delete(s.categories, id)
+		}
+	}
+	s.stale = 0
+}
+
+// live returns the suffix of positions that has not been trimmed.
+func (s *store) live(positions []int) []int {
+	return positions[sort.SearchInts(positions, s.first):]
+}
+
+// remove drops all events for which drop returns true and rebuilds the indexes.
+// It returns the number of events removed.
+func (s *store) remove(drop func(Event) bool) int {
+	kept := make([]Event, 0, len(s.events))
+	for _, e := range s.events {
+		if !drop(e) {
+			kept = append(kept, e)
+		}
+	}
+
+	removed := len(s.events) - len(kept)
+	if removed > 0 {
+		categories := s.categories
+		*s = *newStore()
+		for _, e := range kept {
+			s.append(e)
+		}
+
+		// Keep categories recorded by events that were trimmed before
+		for id, changes := range categories {
+			if _, ok := s.byDownload[id]; ok {
+				s.categories[id] = changes
+			}
+		}
+	}
+	return removed
+}
+
+// all returns every event, newest first.
+func (s *store) all() []Event {
+	result := make([]Event, len(s.events))
+	for i, e := range s.events {
+		result[len(result)-1-i] = e
+	}
+	return result
+}
+
+// lookup returns the events at the live positions of an index entry, newest first.
+func (s *store) lookup(index map[string][]int, key string) []Event {
+	positions := s.live(index[key])
+	result := make([]Event, len(positions))
+	for i, pos := range positions {
+		result[len(result)-1-i] = s.at(pos)
+	}
+	return result
+}
+
+// position returns the position of the event with the given ID.
+func (s *store) position(id string) (int, bool) {
+	pos, ok := s.byID[id]
+	if !ok || pos < s.first {
+		return 0, false
+	}
+	return pos, true
+}
+
+// timeRange returns the positions [lo, hi) that can hold events in
+// [since, until). Without ordered timestamps it is the whole store.
+func (s *store) timeRange(since, until time.Time) (int, int) {
+	lo, hi := 0, len(s.events)
+	if s.ordered {
+		if !since.IsZero() {
+			lo = sort.Search(len(s.events), func(i int) bool { return !s.events[i].Timestamp.Before(since) })
+		}
+		if !until.IsZero() {
+			hi = sort.Search(len(s.events), func(i int) bool { return !s.events[i].Timestamp.Before(until) })
+		}
+	}
+	return s.first + lo, s.first + hi
+}
+
+// candidates returns the positions of events that can match q's download, app
+// and downloader filters, using the most selective index. ok is false if q has
+// none of those filters.
+func (s *store) candidates(q Query) ([]int, bool) {
+	var (
+		best []int
+		ok   bool
+	)
+
+	for _, f := range []struct {
+		index map[string][]int
+		keys  []string
+	}{
+		{s.byDownload, q.Downloads},
+		{s.byApp, q.Apps},
+		{s.byDownloader, q.Downloaders},
+	} {
+		if len(f.keys) == 0 {
+			continue
+		}
+
+		positions := make([]int, 0)
+		for _, key := range f.keys {
+			positions = append(positions, s.live(f.index[key])...)
+		}
+		if len(f.keys) > 1 {
+			slices.Sort(positions)
+		}
+
+		if !ok || len(positions) < len(best) {
+			best, ok = positions, true
+		}
+	}
+
+	return best, ok
+}
+
+// query returns the events matching q and the ID of the last one if more remain.
+func (s *store) query(q Query) ([]Event, string) {
+	result := make([]Event, 0)
+
+	lo, hi := s.timeRange(q.Since, q.Until)
+	if q.After != "" {
+		// If the cursor event has been trimmed, every event older than it is
+		// gone too: oldest first continues from the oldest event left, and
+		// newest first has nothing left to return.
+		pos, ok := s.position(q.After)
+		switch {
+		case q.Oldest && ok:
+			lo = max(lo, pos+1)
+		case !q.Oldest && !ok:
+			return result, ""
+		case !q.Oldest:
+			hi = min(hi, pos)
+		}
+	}
+	if lo >= hi {
+		return result, ""
+	}
+
+	positions, indexed := s.candidates(q)
+	if indexed {
+		positions = positions[sort.SearchInts(positions, lo):sort.SearchInts(positions, hi)]
+	}
+
+	count := hi - lo
+	if indexed {
+		count = len(positions)
+	}
+
+	search := strings.ToLower(q.Search)
+	for n := range count {
+		i := n
+		if !q.Oldest {
+			i = count - 1 - n
+		}
+
+		pos := lo + i
+		if indexed {
+			pos = positions[i]
+		}
+
+		e := s.at(pos)
+		if !q.matches(e, s.category(e), search) {
+			continue
+		}
+		if q.Limit > 0 && len(result) == q.Limit {
+			return result, result[len(result)-1].ID
+		}
+		result = append(result, e)
+	}
+
+	return result, ""
+}
+
+// categoryChange is a category a download was in from a point in time.
+type categoryChange struct {
+	at       time.Time
+	category string
+}
+
+// detailCategory returns the category an event records for its download.
+func detailCategory(e Event) string {
+	if category, ok := e.Details["new_category"].(string); ok {
+		return category
+	}
+	category, _ := e.Details["category"].(string)
+	return category
+}
+
+// category returns the category e's download was in when e was recorded. Events
+// recorded before the download's first known category get that category, and
+// events without a download get their own "category" detail.
+func (s *store) category(e Event) string {
+	changes := s.categories[e.DownloadID]
+	if e.DownloadID == "" || len(changes) == 0 {
+		return detailCategory(e)
+	}
+	for i := len(changes) - 1; i > 0; i-- {
+		if !changes[i].at.After(e.Timestamp) {
+			return changes[i].category
+		}
+	}
+	return changes[0].category
+}
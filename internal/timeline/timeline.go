@@ -16,23 +16,49 @@ const (
 	EventSystemStarted     EventType = "system_started"
 	EventDownloaderConnect EventType = "downloader_connected"
 	EventAppConnected      EventType = "app_connected"
+	EventAdded             EventType = "added"
 	EventDiscovered        EventType = "discovered"
+	EventWaitingForSpace   EventType = "waiting_for_space"
 	EventSyncStarted       EventType = "sync_started"
 	EventSyncProgress      EventType = "sync_progress"
 	EventSyncComplete      EventType = "sync_complete"
 	EventSyncCancelled     EventType = "sync_cancelled"
+	EventSyncRetried       EventType = "sync_retried"
+	EventStalled           EventType = "stalled"
 	EventMovingStarted     EventType = "moving_started"
 	EventMoveComplete      EventType = "move_complete"
 	EventImportStarted     EventType = "import_started"
 	EventImportComplete    EventType = "import_complete"
 	EventImportFailed      EventType = "import_failed"
+	EventPostImportAction  EventType = "post_import_action"
+	EventPostImportFailed  EventType = "post_import_failed"
 	EventCategoryChanged   EventType = "category_changed"
 	EventRemoved           EventType = "removed"
 	EventError             EventType = "error"
 	EventComplete          EventType = "complete"
 	EventCleanup           EventType = "cleanup"
+	EventPruned            EventType = "pruned"
+
+	// Dry-run events record what would have been done.
+	EventWouldSync       EventType = "would_sync"
+	EventWouldMove       EventType = "would_move"
+	EventWouldImport     EventType = "would_import"
+	EventWouldPostImport EventType = "would_post_import"
+	EventWouldCleanup    EventType = "would_cleanup"
 )
 
+// EventTypes returns all event types.
+func EventTypes() []EventType {
+	return []EventType{
+		EventSystemStarted, EventDownloaderConnect, EventAppConnected, EventAdded, EventDiscovered, EventWaitingForSpace,
+		EventSyncStarted, EventSyncProgress, EventSyncComplete, EventSyncCancelled, EventSyncRetried, EventStalled,
+		EventMovingStarted, EventMoveComplete, EventImportStarted, EventImportComplete, EventImportFailed,
+		EventPostImportAction, EventPostImportFailed, EventCategoryChanged, EventRemoved, EventError, EventComplete,
+		EventCleanup, EventPruned, EventWouldSync, EventWouldMove, EventWouldImport, EventWouldPostImport,
+		EventWouldCleanup,
+	}
+}
+
 // Event represents a single timeline event.
 type Event struct {
 	ID           string         `json:"id"`
@@ -63,17 +89,34 @@ type Recorder interface {
 	// GetByDownloader returns events for a specific downloader, newest first.
 	GetByDownloader(downloaderName string) []Event
 
+	// Query returns a filtered page of events and the cursor for the next page.
+	Query(q Query) ([]Event, string)
+
 	// Clear removes all events for a download.
 	Clear(downloadID string)
+
+	// Subscribe returns a channel that receives every event recorded from now
+	// on, and a function that cancels the subscription and closes the channel.
+	// Events are dropped for a subscriber whose buffer is full.
+	Subscribe(buffer int) (<-chan Event, func())
+
+	// Close flushes and releases any storage held by the recorder.
+	Close() error
 }
 
-// recorder is the default in-memory implementation of Recorder.
+// recorder is the default implementation of Recorder. It keeps events in
+// memory and, when created with NewFileRecorder, also persists them to disk.
 type recorder struct {
-	events    []Event
+	store     *store
+	log       *eventLog
 	mu        sync.RWMutex
 	logger    zerolog.Logger
 	maxEvents int
+	maxAge    time.Duration
 	nextID    int64
+
+	subscribers map[int]chan Event
+	nextSubID   int
 }
 
 // Option is a functional option for configuring the recorder.
@@ -93,18 +136,32 @@ func WithMaxEvents(maxEvents int) Option {
 	}
 }
 
+// WithMaxAge sets how long events are retained. Zero keeps events until they
+// are displaced by newer ones.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(r *recorder) {
+		r.maxAge = maxAge
+	}
+}
+
 // Default configuration values.
 const (
 	defaultMaxEvents = 10000
 )
 
-// NewRecorder creates a new timeline recorder.
+// NewRecorder creates a new in-memory timeline recorder.
 func NewRecorder(opts ...Option) Recorder {
+	return newRecorder(opts...)
+}
+
+func newRecorder(opts ...Option) *recorder {
 	r := &recorder{
-		events:    make([]Event, 0),
+		store:     newStore(),
 		logger:    zerolog.Nop(),
 		maxEvents: defaultMaxEvents,
 		nextID:    1,
+
+		subscribers: make(map[int]chan Event),
 	}
 
 	for _, opt := range opts {
@@ -129,12 +186,19 @@ func (r *recorder) Record(event Event) {
 		event.Timestamp = time.Now()
 	}
 
-	// Prepend event (newest first)
-	r.events = append([]Event{event}, r.events...)
+	r.store.append(event)
+	r.applyRetention(time.Now())
 
-	// Trim if over max
-	if len(r.events) > r.maxEvents {
-		r.events = r.events[:r.maxEvents]
+	if r.log != nil {
+		r.persist(event)
+	}
+
+	for _, ch := range r.subscribers {
+		select {
+		case ch <- event:
+		default:
+			r.logger.Warn().Str("id", event.ID).Msg("timeline subscriber is not keeping up, dropping event")
+		}
 	}
 
 	r.logger.Debug().
@@ -144,15 +208,22 @@ func (r *recorder) Record(event Event) {
 		Msg("timeline event recorded")
 }
 
+// applyRetention drops events beyond the configured count and age.
+func (r *recorder) applyRetention(now time.Time) {
+	if r.maxEvents > 0 && r.store.len() > r.maxEvents {
+		r.store.trim(r.store.len() - r.maxEvents)
+	}
+	if r.maxAge > 0 {
+		r.store.trimBefore(now.Add(-r.maxAge))
+	}
+}
+
 // GetAll returns all events, newest first.
 func (r *recorder) GetAll() []Event {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Return a copy
-	result := make([]Event, len(r.events))
-	copy(result, r.events)
-	return result
+	return r.store.all()
 }
 
 // GetByDownload returns events for a specific download, newest first.
@@ -160,13 +231,7 @@ func (r *recorder) GetByDownload(downloadID string) []Event {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var result []Event
-	for _, e := range r.events {
-		if e.DownloadID == downloadID {
-			result = append(result, e)
-		}
-	}
-	return result
+	return r.store.lookup(r.store.byDownload, downloadID)
 }
 
 // GetByApp returns events for a specific app, newest first.
@@ -174,13 +239,7 @@ func (r *recorder) GetByApp(appName string) []Event {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var result []Event
-	for _, e := range r.events {
-		if e.AppName == appName {
-			result = append(result, e)
-		}
-	}
-	return result
+	return r.store.lookup(r.store.byApp, appName)
 }
 
 // GetByDownloader returns events for a specific downloader, newest first.
@@ -188,13 +247,7 @@ func (r *recorder) GetByDownloader(downloaderName string) []Event {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var result []Event
-	for _, e := range r.events {
-		if e.Downloader == downloaderName {
-			result = append(result, e)
-		}
-	}
-	return result
+	return r.store.lookup(r.store.byDownloader, downloaderName)
 }
 
 // Clear removes all events for a download.
@@ -202,13 +255,49 @@ func (r *recorder) Clear(downloadID string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	var filtered []Event
-	for _, e := range r.events {
-		if e.DownloadID != downloadID {
-			filtered = append(filtered, e)
-		}
+	removed := r.store.remove(func(e Event) bool { return e.DownloadID == downloadID })
+	if removed > 0 && r.log != nil {
+		r.compact()
+	}
+}
+
+// Subscribe returns a channel of newly recorded events and a function that
+// cancels the subscription.
+func (r *recorder) Subscribe(buffer int) (<-chan Event, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextSubID
+	r.nextSubID++
+
+	ch := make(chan Event, buffer)
+	r.subscribers[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			delete(r.subscribers, id)
+			close(ch)
+		})
 	}
-	r.events = filtered
+}
+
+// Close closes the event log, if any. Events recorded afterwards are kept in
+// memory only.
+func (r *recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.log == nil {
+		return nil
+	}
+
+	err := r.log.close()
+	r.log = nil
+	return err
 }
 
 // generateID generates a unique event ID.
@@ -156,10 +156,13 @@ func TestRecorder_EventTypes(t *testing.T) {
 		timeline.EventDownloaderConnect,
 		timeline.EventAppConnected,
 		timeline.EventDiscovered,
+		timeline.EventWaitingForSpace,
 		timeline.EventSyncStarted,
 		timeline.EventSyncProgress,
 		timeline.EventSyncComplete,
 		timeline.EventSyncCancelled,
+		timeline.EventSyncRetried,
+		timeline.EventStalled,
 		timeline.EventMovingStarted,
 		timeline.EventMoveComplete,
 		timeline.EventImportStarted,
@@ -170,9 +173,250 @@ func TestRecorder_EventTypes(t *testing.T) {
 		timeline.EventError,
 		timeline.EventComplete,
 		timeline.EventCleanup,
+		timeline.EventWouldSync,
+		timeline.EventWouldMove,
+		timeline.EventWouldImport,
+		timeline.EventWouldPostImport,
+		timeline.EventWouldCleanup,
 	}
 
 	for _, et := range types {
 		assert.NotEmpty(t, string(et))
 	}
 }
+
+func TestRecorder_Query(t *testing.T) {
+	r := timeline.NewRecorder()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r.Record(timeline.Event{
+		Type: timeline.EventDiscovered, Timestamp: base, DownloadID: "dl-1", DownloadName: "Alpha.Show",
+		Downloader: "seedbox", Details: map[string]any{"category": "tv"},
+	})
+	r.Record(timeline.Event{
+		Type: timeline.EventSyncStarted, Timestamp: base.Add(time.Minute), DownloadID: "dl-1", DownloadName: "Alpha.Show",
+		Downloader: "seedbox", AppName: "sonarr",
+	})
+	r.Record(timeline.Event{
+		Type: timeline.EventDiscovered, Timestamp: base.Add(2 * time.Minute), DownloadID: "dl-2", DownloadName: "Some.Movie",
+		Downloader: "other", Details: map[string]any{"category": "movies"},
+	})
+
+	names := func(events []timeline.Event) []string {
+		result := make([]string, 0, len(events))
+		for _, e := range events {
+			result = append(result, e.DownloadName+"/"+string(e.Type))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		query    timeline.Query
+		expected []string
+	}{
+		{"All", timeline.Query{}, []string{
+			"Some.Movie/discovered", "Alpha.Show/sync_started", "Alpha.Show/discovered",
+		}},
+		{"Oldest", timeline.Query{Oldest: true}, []string{
+			"Alpha.Show/discovered", "Alpha.Show/sync_started", "Some.Movie/discovered",
+		}},
+		{"Types", timeline.Query{Types: []timeline.EventType{timeline.EventSyncStarted}}, []string{
+			"Alpha.Show/sync_started",
+		}},
+		{"Downloaders", timeline.Query{Downloaders: []string{"other"}}, []string{"Some.Movie/discovered"}},
+		{"Apps", timeline.Query{Apps: []string{"sonarr"}}, []string{"Alpha.Show/sync_started"}},
+		{"Categories", timeline.Query{Categories: []string{"tv"}}, []string{
+			"Alpha.Show/sync_started", "Alpha.Show/discovered",
+		}},
+		{"Search", timeline.Query{Search: "movie"}, []string{"Some.Movie/discovered"}},
+		{"TimeRange", timeline.Query{Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)}, []string{
+			"Alpha.Show/sync_started",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, next := r.Query(tt.query)
+			assert.Equal(t, tt.expected, names(events))
+			assert.Empty(t, next)
+		})
+	}
+
+	t.Run("Pagination", func(t *testing.T) {
+		events, next := r.Query(timeline.Query{Limit: 2})
+		assert.Equal(t, []string{"Some.Movie/discovered", "Alpha.Show/sync_started"}, names(events))
+		require.Equal(t, events[1].ID, next)
+
+		events, next = r.Query(timeline.Query{Limit: 2, After: next})
+		assert.Equal(t, []string{"Alpha.Show/discovered"}, names(events))
+		assert.Empty(t, next)
+	})
+
+	t.Run("UnknownCursor", func(t *testing.T) {
+		events, next := r.Query(timeline.Query{After: "evt_missing"})
+		assert.Empty(t, events)
+		assert.Empty(t, next)
+	})
+}
+
+func TestRecorder_QueryCategoryChange(t *testing.T) {
+	r := timeline.NewRecorder()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	record := func(offset time.Duration, message string, details map[string]any) {
+		r.Record(timeline.Event{Timestamp: base.Add(offset), DownloadID: "dl-1", Message: message, Details: details})
+	}
+	record(0, "discovered", map[string]any{"category": "tv"})
+	record(time.Minute, "synced", nil)
+	record(2*time.Minute, "moved", map[string]any{"old_category": "tv", "new_category": "movies"})
+	record(3*time.Minute, "imported", nil)
+
+	messages := func(categories ...string) []string {
+		events, _ := r.Query(timeline.Query{Categories: categories, Oldest: true})
+		result := make([]string, 0, len(events))
+		for _, e := range events {
+			result = append(result, e.Message)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"discovered", "synced"}, messages("tv"))
+	assert.Equal(t, []string{"moved", "imported"}, messages("movies"))
+}
+
+func TestRecorder_Retention(t *testing.T) {
+	t.Run("trimmed events leave the indexes", func(t *testing.T) {
+		r := timeline.NewRecorder(timeline.WithMaxEvents(3))
+
+		for i := range 20 {
+			r.Record(timeline.Event{
+				DownloadID: []string{"dl-1", "dl-2"}[i%2],
+				AppName:    "sonarr",
+				Message:    string(rune('a' + i)),
+			})
+		}
+
+		assert.Len(t, r.GetAll(), 3)
+		assert.Len(t, r.GetByApp("sonarr"), 3)
+
+		events := r.GetByDownload("dl-2")
+		require.Len(t, events, 2)
+		assert.Equal(t, "t", events[0].Message)
+		assert.Equal(t, "r", events[1].Message)
+
+		// The cursor of a trimmed event yields nothing newest first, and the
+		// oldest events left oldest first
+		page, next := r.Query(timeline.Query{After: "evt_missing", Downloads: []string{"dl-1"}})
+		assert.Empty(t, page)
+		assert.Empty(t, next)
+
+		page, next = r.Query(timeline.Query{After: "evt_missing", Oldest: true, Downloads: []string{"dl-1"}})
+		require.Len(t, page, 1)
+		assert.Equal(t, "s", page[0].Message)
+		assert.Empty(t, next)
+	})
+
+	t.Run("categories outlive trimmed events", func(t *testing.T) {
+		r := timeline.NewRecorder(timeline.WithMaxEvents(2))
+
+		r.Record(timeline.Event{DownloadID: "dl-1", Details: map[string]any{"category": "tv"}, Message: "discovered"})
+		for i := range 5 {
+			r.Record(timeline.Event{DownloadID: "dl-1", Message: string(rune('a' + i))})
+		}
+
+		events, _ := r.Query(timeline.Query{Categories: []string{"tv"}})
+		require.Len(t, events, 2)
+		assert.Equal(t, "e", events[0].Message)
+	})
+
+	t.Run("max age drops old events", func(t *testing.T) {
+		r := timeline.NewRecorder(timeline.WithMaxAge(time.Hour))
+
+		r.Record(timeline.Event{Timestamp: time.Now().Add(-2 * time.Hour), Message: "old"})
+		r.Record(timeline.Event{Message: "new"})
+
+		events := r.GetAll()
+		require.Len(t, events, 1)
+		assert.Equal(t, "new", events[0].Message)
+	})
+}
+
+func TestRecorder_QueryIndexed(t *testing.T) {
+	r := timeline.NewRecorder()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := range 6 {
+		r.Record(timeline.Event{
+			Timestamp:  base.Add(time.Duration(i) * time.Minute),
+			DownloadID: []string{"dl-1", "dl-2", "dl-3"}[i%3],
+			Downloader: "seedbox",
+			Message:    string(rune('a' + i)),
+		})
+	}
+
+	messages := func(events []timeline.Event) string {
+		var result string
+		for _, e := range events {
+			result += e.Message
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		query    timeline.Query
+		expected string
+	}{
+		{"Download", timeline.Query{Downloads: []string{"dl-1"}}, "da"},
+		{"Downloads", timeline.Query{Downloads: []string{"dl-1", "dl-3"}, Oldest: true}, "acdf"},
+		{"DownloadAndDownloader", timeline.Query{Downloads: []string{"dl-2"}, Downloaders: []string{"seedbox"}}, "eb"},
+		{"DownloadInTimeRange", timeline.Query{
+			Downloads: []string{"dl-1", "dl-2"},
+			Since:     base.Add(time.Minute),
+			Until:     base.Add(4 * time.Minute),
+		}, "db"},
+		{"UnknownDownload", timeline.Query{Downloads: []string{"dl-9"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, _ := r.Query(tt.query)
+			assert.Equal(t, tt.expected, messages(events))
+		})
+	}
+
+	t.Run("Pagination", func(t *testing.T) {
+		q := timeline.Query{Downloads: []string{"dl-1", "dl-2"}, Limit: 3}
+		events, next := r.Query(q)
+		assert.Equal(t, "edb", messages(events))
+		require.NotEmpty(t, next)
+
+		q.After = next
+		events, next = r.Query(q)
+		assert.Equal(t, "a", messages(events))
+		assert.Empty(t, next)
+	})
+}
+
+func TestRecorder_Subscribe(t *testing.T) {
+	r := timeline.NewRecorder()
+
+	events, cancel := r.Subscribe(1)
+	r.Record(timeline.Event{Message: "first"})
+	r.Record(timeline.Event{Message: "dropped"}) // buffer is full
+
+	e := <-events
+	assert.Equal(t, "first", e.Message)
+	assert.NotEmpty(t, e.ID)
+
+	cancel()
+	cancel() // safe to call twice
+
+	_, open := <-events
+	assert.False(t, open)
+
+	// Recording after cancelling does not block or panic
+	r.Record(timeline.Event{Message: "after"})
+	assert.Len(t, r.GetAll(), 3)
+}
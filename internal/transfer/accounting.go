@@ -0,0 +1,128 @@
+package transfer
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// progressInterval is how often the native backends report progress.
+	progressInterval = 500 * time.Millisecond
+
+	// partialSuffix is appended to the local path of a file while the native
+	// backends download it.
+	partialSuffix = ".partial"
+)
+
+// transferStats tracks the bytes and speed of an active transfer.
+type transferStats struct {
+	transferred atomic.Int64
+	bytesPerSec atomic.Int64
+}
+
+// activeTransfers tracks the active transfers of a transferer, so that its
+// speed covers only its own transfers.
+type activeTransfers struct {
+	mu        sync.Mutex
+	transfers map[*transferStats]struct{}
+}
+
+// start registers a new transfer.
+func (a *activeTransfers) start() *transferStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.transfers == nil {
+		a.transfers = make(map[*transferStats]struct{})
+	}
+	stats := &transferStats{}
+	a.transfers[stats] = struct{}{}
+	return stats
+}
+
+// finish unregisters a transfer.
+func (a *activeTransfers) finish(stats *transferStats) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.transfers, stats)
+}
+
+// speed returns the sum of the current speeds of the active transfers.
+func (a *activeTransfers) speed() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var speed int64
+	for stats := range a.transfers {
+		speed += stats.bytesPerSec.Load()
+	}
+	return speed
+}
+
+// monitorProgress periodically updates the speed of a transfer and reports its
+// progress until done is closed.
+func monitorProgress(stats *transferStats, onProgress ProgressFunc, done chan struct{}) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	lastBytes := stats.transferred.Load()
+	lastTime := time.Now()
+
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			bytes := stats.transferred.Load()
+
+			var speed int64
+			if elapsed := now.Sub(lastTime).Seconds(); elapsed > 0 {
+				speed = int64(float64(bytes-lastBytes) / elapsed)
+			}
+			lastBytes = bytes
+			lastTime = now
+			stats.bytesPerSec.Store(speed)
+
+			if onProgress != nil {
+				onProgress(Progress{
+					Transferred: bytes,
+					BytesPerSec: speed,
+				})
+			}
+		}
+	}
+}
+
+// newLimiter returns a limiter for speedLimit bytes per second that allows
+// reads of up to bufferSize bytes, or nil if speedLimit is 0.
+func newLimiter(speedLimit int64, bufferSize int) *rate.Limiter {
+	if speedLimit <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(speedLimit), max(int(speedLimit), bufferSize))
+}
+
+// segment is a byte range [start, end) of a file downloaded on its own.
+type segment struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// splitSegments splits size bytes into at most n segments of at least minSize
+// bytes. An empty file has a single empty segment.
+func splitSegments(size int64, n int, minSize int64) []segment {
+	count := int64(n)
+	if maxCount := size / minSize; maxCount < count {
+		count = max(maxCount, 1)
+	}
+	segmentSize := (size + count - 1) / count
+
+	segments := make([]segment, 0, count)
+	for start := int64(0); start < size || len(segments) == 0; start += segmentSize {
+		segments = append(segments, segment{Start: start, End: min(start+segmentSize, size)})
+	}
+	return segments
+}
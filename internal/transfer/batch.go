@@ -0,0 +1,80 @@
+package transfer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// transferEach transfers the files of reqs with transferFile, using up to
+// workers goroutines, and reports their progress and results. Each goroutine
+// passes its index, below workers, to transferFile so that it can keep a
+// session for the files it transfers. It stops starting transfers once ctx is
+// done and returns the context's error.
+func transferEach(
+	ctx context.Context,
+	reqs []Request,
+	workers int,
+	transferFile func(ctx context.Context, worker int, req Request, onProgress ProgressFunc) error,
+	onProgress BatchProgressFunc,
+	onDone BatchDoneFunc,
+) error {
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for worker := range min(max(workers, 1), len(reqs)) {
+		wg.Go(func() {
+			for i := range next {
+				var progress ProgressFunc
+				if onProgress != nil {
+					progress = func(p Progress) { onProgress(i, p) }
+				}
+
+				err := transferFile(ctx, worker, reqs[i], progress)
+				if onDone != nil {
+					onDone(i, err)
+				}
+			}
+		})
+	}
+
+feed:
+	for i := range reqs {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// localRoot returns the deepest directory that contains the local paths of all
+// of reqs.
+func localRoot(reqs []Request) string {
+	if len(reqs) == 0 {
+		return ""
+	}
+
+	root := filepath.Dir(reqs[0].LocalPath)
+	for _, req := range reqs[1:] {
+		for !withinDir(root, req.LocalPath) {
+			parent := filepath.Dir(root)
+			if parent == root {
+				break
+			}
+			root = parent
+		}
+	}
+	return root
+}
+
+// withinDir reports whether path is inside dir.
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
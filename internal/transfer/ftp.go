@@ -0,0 +1,660 @@
+package transfer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+// Default FTP configuration values.
+const (
+	ftpDefaultParallelConnections = 8
+	ftpDefaultPort                = 21
+	ftpDefaultImplicitTLSPort     = 990
+	ftpDefaultTimeout             = 30 * time.Second
+	ftpBufferSize                 = 256 * 1024      // Bytes read per write
+	ftpMinSegmentSize             = 10 * bytesPerMB // Don't split files if segments would be under 10MB
+)
+
+// ftpSecurity is how an FTP connection is secured.
+type ftpSecurity int
+
+const (
+	ftpPlain       ftpSecurity = iota // No TLS (ftp://)
+	ftpExplicitTLS                    // Upgraded with AUTH TLS after connecting (ftpes://)
+	ftpImplicitTLS                    // TLS from the start (ftps://)
+)
+
+// errFTPClosed is returned by transfers started after Close.
+var errFTPClosed = errors.New("ftp transferer is closed")
+
+// ftpDialer opens the network connections of one FTP session: its control
+// connection, then a data connection per transfer. It tracks them so a
+// cancelled transfer can interrupt blocked reads by closing them.
+type ftpDialer struct {
+	ctx             context.Context // Only bounds dialing the control connection
+	timeout         time.Duration
+	security        ftpSecurity
+	tlsConfig       *tls.Config
+	ignorePassiveIP bool
+
+	mu      sync.Mutex
+	control net.Conn
+	data    net.Conn
+}
+
+// dial opens the control connection on the first call and data connections on
+// later ones. It is called by the ftp client.
+func (d *ftpDialer) dial(network, addr string) (net.Conn, error) {
+	d.mu.Lock()
+	control := d.control
+	d.mu.Unlock()
+
+	dialer := net.Dialer{Timeout: d.timeout}
+	if control == nil {
+		conn, err := dialer.DialContext(d.ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		// Logging in is not cancellable, so bound it with a deadline instead
+		_ = conn.SetDeadline(time.Now().Add(d.timeout))
+
+		d.mu.Lock()
+		d.control = conn
+		d.mu.Unlock()
+
+		if d.security == ftpImplicitTLS {
+			return tls.Client(conn, d.tlsConfig), nil
+		}
+		return conn, nil
+	}
+
+	if d.ignorePassiveIP {
+		// Servers behind NAT often advertise their private address
+		host, _, _ := net.SplitHostPort(control.RemoteAddr().String())
+		_, port, _ := net.SplitHostPort(addr)
+		addr = net.JoinHostPort(host, port)
+	}
+
+	conn, err := dialer.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	d.data = conn
+	d.mu.Unlock()
+
+	// Data connections are protected once logged in with TLS (PROT P)
+	if d.security != ftpPlain {
+		return tls.Client(conn, d.tlsConfig), nil
+	}
+	return conn, nil
+}
+
+// loggedIn lifts the deadline set on the control connection for logging in.
+func (d *ftpDialer) loggedIn() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_ = d.control.SetDeadline(time.Time{})
+}
+
+// closeAll closes the network connections of the session.
+func (d *ftpDialer) closeAll() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.control != nil {
+		_ = d.control.Close()
+	}
+	if d.data != nil {
+		_ = d.data.Close()
+	}
+}
+
+// ftpConn is a logged in FTP session.
+type ftpConn struct {
+	conn   *ftp.ServerConn
+	dialer *ftpDialer
+}
+
+// close ends the session.
+func (c *ftpConn) close() {
+	_ = c.conn.Quit()
+	c.dialer.closeAll()
+}
+
+// abort closes the session's connections without ending it, which also
+// interrupts blocked reads.
+func (c *ftpConn) abort() {
+	c.dialer.closeAll()
+}
+
+// ftpTransferer implements Transferer over FTP and FTPS, for seedboxes that do
+// not offer SFTP. Files large enough are split into segments downloaded in
+// parallel, each over its own session starting at the segment with REST.
+// Sessions are pooled and all state, including the speed limit, belongs to
+// the instance.
+// It is private and only exposed via the Transferer interface.
+type ftpTransferer struct {
+	cfg                 FTPConfig
+	addr                string
+	dir                 string // Directory on the server serving root
+	root                string
+	security            ftpSecurity
+	tlsConfig           *tls.Config
+	configErr           error
+	parallelConnections int
+	speedLimit          int64
+	logger              zerolog.Logger
+
+	// Idle sessions, reused by later transfers
+	mu     sync.Mutex
+	idle   []*ftpConn
+	closed bool
+
+	transfers    activeTransfers
+	shuttingDown atomic.Bool
+}
+
+// setLogger implements configurable for shared options.
+func (t *ftpTransferer) setLogger(logger zerolog.Logger) {
+	t.logger = logger
+}
+
+// NewFTP creates a new FTP(S) transferer and returns it as Transferer.
+func NewFTP(opts Options, options ...Option) Transferer {
+	parallelConnections := opts.ParallelConnections
+	if parallelConnections == 0 {
+		parallelConnections = ftpDefaultParallelConnections
+	}
+
+	cfg := opts.FTP
+	if cfg.Timeout == 0 {
+		cfg.Timeout = ftpDefaultTimeout
+	}
+
+	t := &ftpTransferer{
+		cfg:                 cfg,
+		root:                servedRoot(cfg.RemotePath),
+		parallelConnections: parallelConnections,
+		speedLimit:          opts.SpeedLimit,
+		logger:              zerolog.Nop(),
+	}
+	t.configErr = t.parseURL(cfg.URL)
+
+	for _, opt := range options {
+		opt(t)
+	}
+
+	return t
+}
+
+// parseURL sets the address, directory, security and credentials from the
+// server URL.
+func (t *ftpTransferer) parseURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	port := ftpDefaultPort
+	switch u.Scheme {
+	case "ftp":
+		t.security = ftpPlain
+	case "ftpes":
+		t.security = ftpExplicitTLS
+	case "ftps":
+		t.security = ftpImplicitTLS
+		port = ftpDefaultImplicitTLSPort
+	default:
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Port() != "" {
+		if port, err = strconv.Atoi(u.Port()); err != nil {
+			return fmt.Errorf("invalid port: %w", err)
+		}
+	}
+
+	t.addr = net.JoinHostPort(u.Hostname(), strconv.Itoa(port))
+	t.dir = path.Clean("/" + u.Path)
+	t.tlsConfig = &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: t.cfg.InsecureSkipVerify, //nolint:gosec // only used when explicitly configured
+		MinVersion:         tls.VersionTLS12,
+		// Servers commonly require data connections to resume the control connection's TLS session
+		ClientSessionCache: tls.NewLRUClientSessionCache(0),
+	}
+
+	if t.cfg.Username == "" && u.User != nil {
+		t.cfg.Username = u.User.Username()
+		t.cfg.Password, _ = u.User.Password()
+	}
+	if t.cfg.Username == "" {
+		t.cfg.Username = "anonymous"
+		t.cfg.Password = "anonymous"
+	}
+	return nil
+}
+
+// Name returns the name of the transfer backend.
+func (t *ftpTransferer) Name() string {
+	return string(BackendFTP)
+}
+
+// PrepareShutdown suppresses transfer error logging during shutdown.
+func (t *ftpTransferer) PrepareShutdown() {
+	t.shuttingDown.Store(true)
+}
+
+// Close ends the idle sessions. Sessions in use by a transfer are ended when
+// it finishes.
+func (t *ftpTransferer) Close() error {
+	t.mu.Lock()
+	idle := t.idle
+	t.idle = nil
+	t.closed = true
+	t.mu.Unlock()
+
+	for _, c := range idle {
+		c.close()
+	}
+	return nil
+}
+
+// GetSpeed returns the sum of the current speeds of the active transfers.
+func (t *ftpTransferer) GetSpeed() int64 {
+	return t.transfers.speed()
+}
+
+// TestConnection logs into the FTP server and changes to the directory of the
+// URL.
+func (t *ftpTransferer) TestConnection(ctx context.Context) error {
+	if t.configErr != nil {
+		return fmt.Errorf("invalid url: %w", t.configErr)
+	}
+
+	c, err := t.getConn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", t.addr, err)
+	}
+	if err = c.conn.ChangeDir(t.dir); err != nil {
+		c.close()
+		return fmt.Errorf("failed to change to directory %s: %w", t.dir, err)
+	}
+	t.putConn(c)
+	return nil
+}
+
+// getConn returns an idle session or dials a new one.
+func (t *ftpTransferer) getConn(ctx context.Context) (*ftpConn, error) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, errFTPClosed
+	}
+	if n := len(t.idle); n > 0 {
+		c := t.idle[n-1]
+		t.idle = t.idle[:n-1]
+		t.mu.Unlock()
+		return c, nil
+	}
+	t.mu.Unlock()
+
+	return t.dial(ctx)
+}
+
+// putConn returns a healthy session to the pool.
+func (t *ftpTransferer) putConn(c *ftpConn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed || len(t.idle) >= t.parallelConnections {
+		c.close()
+		return
+	}
+	t.idle = append(t.idle, c)
+}
+
+// dial connects to the server and logs in.
+func (t *ftpTransferer) dial(ctx context.Context) (*ftpConn, error) {
+	d := &ftpDialer{
+		ctx:             ctx,
+		timeout:         t.cfg.Timeout,
+		security:        t.security,
+		tlsConfig:       t.tlsConfig,
+		ignorePassiveIP: t.cfg.IgnorePassiveIP,
+	}
+
+	dialOpts := []ftp.DialOption{
+		ftp.DialWithDialFunc(d.dial),
+		ftp.DialWithDisabledEPSV(t.cfg.DisableEPSV),
+	}
+	switch t.security {
+	case ftpPlain:
+	case ftpExplicitTLS:
+		dialOpts = append(dialOpts, ftp.DialWithExplicitTLS(t.tlsConfig))
+	case ftpImplicitTLS:
+		dialOpts = append(dialOpts, ftp.DialWithTLS(t.tlsConfig))
+	}
+
+	// Closing the connections is the only way to interrupt logging in
+	stop := context.AfterFunc(ctx, d.closeAll)
+	defer stop()
+
+	conn, err := ftp.Dial(t.addr, dialOpts...)
+	if err != nil {
+		d.closeAll()
+		return nil, fmt.Errorf("failed to dial %s: %w", t.addr, err)
+	}
+	if err = conn.Login(t.cfg.Username, t.cfg.Password); err != nil {
+		d.closeAll()
+		return nil, fmt.Errorf("ftp login failed: %w", err)
+	}
+	d.loggedIn()
+
+	t.logger.Debug().
+		Str("addr", t.addr).
+		Str("user", t.cfg.Username).
+		Msg("ftp connection established")
+
+	return &ftpConn{conn: conn, dialer: d}, nil
+}
+
+// ftpPath maps a path on the seedbox to its path on the FTP server.
+func (t *ftpTransferer) ftpPath(remotePath string) (string, error) {
+	if t.configErr != nil {
+		return "", fmt.Errorf("invalid url: %w", t.configErr)
+	}
+
+	rel, err := relativeToRoot(t.root, remotePath)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(t.dir, rel), nil
+}
+
+// TransferBatch copies a set of files, up to workers but no more than
+// parallelConnections at a time. Each worker takes one pooled session and
+// retrieves its files over it one after another, each in one piece.
+func (t *ftpTransferer) TransferBatch(
+	ctx context.Context,
+	reqs []Request,
+	workers int,
+	onProgress BatchProgressFunc,
+	onDone BatchDoneFunc,
+) error {
+	conns := make([]*ftpConn, min(max(workers, 1), t.parallelConnections))
+	defer func() {
+		for _, c := range conns {
+			if c != nil {
+				t.putConn(c)
+			}
+		}
+	}()
+
+	return transferEach(ctx, reqs, len(conns),
+		func(ctx context.Context, worker int, req Request, onFileProgress ProgressFunc) error {
+			return t.transfer(ctx, req, onFileProgress,
+				func(ctx context.Context, ftpPath string, size int64, f *os.File, stats *transferStats) error {
+					return t.downloadOver(ctx, &conns[worker], ftpPath, size, f, stats)
+				})
+		}, onProgress, onDone)
+}
+
+// Transfer copies a file from remote to local. The file is written next to
+// LocalPath with a .partial suffix and renamed once complete.
+func (t *ftpTransferer) Transfer(ctx context.Context, req Request, onProgress ProgressFunc) error {
+	return t.transfer(ctx, req, onProgress, t.download)
+}
+
+// transfer copies a file from remote to local, retrieving it into the partial
+// file with download.
+func (t *ftpTransferer) transfer(
+	ctx context.Context,
+	req Request,
+	onProgress ProgressFunc,
+	download func(context.Context, string, int64, *os.File, *transferStats) error,
+) error {
+	t.logger.Debug().
+		Str("remote", req.RemotePath).
+		Str("local", req.LocalPath).
+		Int64("size", req.Size).
+		Msg("starting ftp transfer")
+
+	ftpPath, err := t.ftpPath(req.RemotePath)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(req.LocalPath), 0750); err != nil {
+		return fmt.Errorf("failed to create local directory: %w", err)
+	}
+
+	partialPath := req.LocalPath + partialSuffix
+	f, err := os.OpenFile(partialPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+
+	stats := t.transfers.start()
+	defer t.transfers.finish(stats)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	startTime := time.Now()
+	wg.Go(func() {
+		monitorProgress(stats, onProgress, done)
+	})
+
+	err = download(ctx, ftpPath, req.Size, f, stats)
+
+	close(done)
+	wg.Wait()
+
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close local file: %w", closeErr)
+	}
+	if err == nil {
+		if renameErr := os.Rename(partialPath, req.LocalPath); renameErr != nil {
+			err = fmt.Errorf("failed to rename local file: %w", renameErr)
+		}
+	}
+	if err != nil {
+		_ = os.Remove(partialPath)
+		if !t.shuttingDown.Load() {
+			t.logger.Debug().Err(err).Str("remote", req.RemotePath).Msg("ftp transfer failed")
+		}
+		return err
+	}
+
+	elapsed := time.Since(startTime).Seconds()
+	var speed int64
+	if elapsed > 0 {
+		speed = int64(float64(req.Size) / elapsed)
+	}
+
+	if onProgress != nil {
+		onProgress(Progress{
+			Transferred: req.Size,
+			BytesPerSec: speed,
+		})
+	}
+
+	t.logger.Debug().
+		Str("file", req.RemotePath).
+		Int64("size", req.Size).
+		Float64("speed_mbps", float64(speed)/bytesPerMB).
+		Msg("ftp transfer complete")
+
+	return nil
+}
+
+// download reads the remote file into f, splitting it into segments that are
+// read in parallel over separate sessions.
+func (t *ftpTransferer) download(
+	ctx context.Context,
+	ftpPath string,
+	size int64,
+	f *os.File,
+	stats *transferStats,
+) error {
+	if err := f.Truncate(size); err != nil {
+		return fmt.Errorf("failed to allocate local file: %w", err)
+	}
+
+	// The limiter is shared by the segments so the limit applies to the whole file
+	limiter := newLimiter(t.speedLimit, ftpBufferSize)
+
+	g, gctx := errgroup.WithContext(ctx)
+	for _, seg := range splitSegments(size, t.parallelConnections, ftpMinSegmentSize) {
+		g.Go(func() error {
+			return t.downloadSegment(gctx, ftpPath, size, f, seg, limiter, stats)
+		})
+	}
+	return g.Wait()
+}
+
+// downloadSegment copies a segment of the remote file into f.
+func (t *ftpTransferer) downloadSegment(
+	ctx context.Context,
+	ftpPath string,
+	size int64,
+	f *os.File,
+	seg segment,
+	limiter *rate.Limiter,
+	stats *transferStats,
+) error {
+	c, err := t.getConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Closing the connections is the only way to interrupt a blocked read
+	stop := context.AfterFunc(ctx, c.abort)
+
+	reusable, err := copyFTPSegment(ctx, c, ftpPath, size, f, seg, limiter, stats)
+	if !stop() {
+		// The connections were closed by the cancellation
+		return ctx.Err()
+	}
+	if err != nil || !reusable {
+		c.abort()
+		return err
+	}
+	t.putConn(c)
+	return nil
+}
+
+// downloadOver retrieves the remote file into f in one piece over *c, taking a
+// pooled session if *c is nil. The session is kept in *c for the next file,
+// unless the retrieval failed.
+func (t *ftpTransferer) downloadOver(
+	ctx context.Context,
+	c **ftpConn,
+	ftpPath string,
+	size int64,
+	f *os.File,
+	stats *transferStats,
+) error {
+	if err := f.Truncate(size); err != nil {
+		return fmt.Errorf("failed to allocate local file: %w", err)
+	}
+
+	if *c == nil {
+		conn, err := t.getConn(ctx)
+		if err != nil {
+			return err
+		}
+		*c = conn
+	}
+	conn := *c
+
+	// Closing the connections is the only way to interrupt a blocked read
+	stop := context.AfterFunc(ctx, conn.abort)
+
+	limiter := newLimiter(t.speedLimit, ftpBufferSize)
+	reusable, err := copyFTPSegment(ctx, conn, ftpPath, size, f, segment{End: size}, limiter, stats)
+	if !stop() {
+		// The connections were closed by the cancellation
+		*c = nil
+		return ctx.Err()
+	}
+	if err != nil || !reusable {
+		conn.abort()
+		*c = nil
+		return err
+	}
+	return nil
+}
+
+// copyFTPSegment retrieves the remote file from the start of a segment over c
+// and writes the segment to f. It reports whether the session can be reused,
+// which is only the case when the server sent the file up to its end.
+func copyFTPSegment(
+	ctx context.Context,
+	c *ftpConn,
+	ftpPath string,
+	size int64,
+	f *os.File,
+	seg segment,
+	limiter *rate.Limiter,
+	stats *transferStats,
+) (bool, error) {
+	resp, err := c.conn.RetrFrom(ftpPath, uint64(seg.Start)) //nolint:gosec // segment offsets are never negative
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve remote file %q: %w", ftpPath, err)
+	}
+
+	buf := make([]byte, ftpBufferSize)
+	for offset := seg.Start; offset < seg.End; {
+		n := int(min(int64(len(buf)), seg.End-offset))
+		if limiter != nil {
+			if err = limiter.WaitN(ctx, n); err != nil {
+				return false, err
+			}
+		}
+
+		read, readErr := resp.Read(buf[:n])
+		if read > 0 {
+			if _, err = f.WriteAt(buf[:read], offset); err != nil {
+				return false, fmt.Errorf("failed to write local file: %w", err)
+			}
+			offset += int64(read)
+			stats.transferred.Add(int64(read))
+		}
+		if readErr != nil {
+			if errors.Is(readErr, io.EOF) && offset < seg.End {
+				return false, fmt.Errorf("remote file %q is shorter than expected", ftpPath)
+			}
+			if !errors.Is(readErr, io.EOF) {
+				return false, fmt.Errorf("failed to read remote file %q: %w", ftpPath, readErr)
+			}
+		}
+	}
+
+	// Stopping a retrieval midway leaves the session in an unknown state
+	if seg.End < size {
+		return false, nil
+	}
+	if _, err = resp.Read(buf[:1]); !errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	return resp.Close() == nil, nil
+}
@@ -0,0 +1,328 @@
+//go:build integration
+
+package transfer_test
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutil "github.com/seedreap/seedreap/internal/testing"
+	"github.com/seedreap/seedreap/internal/transfer"
+)
+
+const (
+	ftpTestUser       = "seeduser"
+	ftpTestPassword   = "seedpass"
+	ftpTestRemotePath = "/home/seeduser/downloads"
+)
+
+// startFTPServer starts a local FTP server serving a temporary directory.
+func startFTPServer(t *testing.T, cfg testutil.FTPServerConfig) *testutil.FTPServer {
+	t.Helper()
+
+	cfg.Root = t.TempDir()
+	cfg.User = ftpTestUser
+	cfg.Password = ftpTestPassword
+
+	server, err := testutil.StartFTPServer(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+	return server
+}
+
+// ftpOptions returns options for a transferer of server, which serves
+// ftpTestRemotePath at its root.
+func ftpOptions(server *testutil.FTPServer) transfer.Options {
+	return transfer.Options{
+		FTP: transfer.FTPConfig{
+			URL:                server.URL,
+			RemotePath:         ftpTestRemotePath,
+			Username:           ftpTestUser,
+			Password:           ftpTestPassword,
+			InsecureSkipVerify: true,
+			Timeout:            5 * time.Second,
+		},
+		ParallelConnections: 4,
+	}
+}
+
+// transferFTPFile creates a remote file of the given size and transfers it,
+// returning the created content and the transfer error.
+func transferFTPFile(
+	t *testing.T,
+	server *testutil.FTPServer,
+	transferer transfer.Transferer,
+	size int,
+) ([]byte, string, error) {
+	t.Helper()
+
+	content := make([]byte, size)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+	require.NoError(t, server.CreateTestFile("Show/episode.mkv", content))
+
+	localPath := filepath.Join(t.TempDir(), "episode.mkv")
+	err = transferer.Transfer(context.Background(), transfer.Request{
+		RemotePath: ftpTestRemotePath + "/Show/episode.mkv",
+		LocalPath:  localPath,
+		Size:       int64(size),
+	}, nil)
+	return content, localPath, err
+}
+
+func TestFTPIntegration_Transfer(t *testing.T) {
+	t.Run("SmallFile", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{})
+		transferer := transfer.NewFTP(ftpOptions(server))
+		defer func() { _ = transferer.Close() }()
+
+		content, localPath, err := transferFTPFile(t, server, transferer, 1024)
+		require.NoError(t, err)
+
+		downloaded, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+		assert.NoFileExists(t, localPath+".partial")
+	})
+
+	t.Run("EmptyFile", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{})
+		transferer := transfer.NewFTP(ftpOptions(server))
+		defer func() { _ = transferer.Close() }()
+
+		_, localPath, err := transferFTPFile(t, server, transferer, 0)
+		require.NoError(t, err)
+
+		info, err := os.Stat(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), info.Size())
+	})
+
+	t.Run("SegmentedFile", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{})
+		transferer := transfer.NewFTP(ftpOptions(server))
+		defer func() { _ = transferer.Close() }()
+
+		// 32MB splits into 3 segments of at least 10MB
+		size := 32 * 1024 * 1024
+		content, localPath, err := transferFTPFile(t, server, transferer, size)
+		require.NoError(t, err)
+
+		downloaded, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+
+		segmentSize := int64(size+2) / 3
+		assert.ElementsMatch(t, []int64{0, segmentSize, 2 * segmentSize}, server.RestOffsets())
+	})
+
+	t.Run("ReusesConnections", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{})
+		transferer := transfer.NewFTP(ftpOptions(server))
+		defer func() { _ = transferer.Close() }()
+
+		for range 3 {
+			_, _, err := transferFTPFile(t, server, transferer, 1024)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, server.Logins())
+	})
+
+	t.Run("ExplicitTLS", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{TLS: testutil.FTPTLSExplicit})
+		transferer := transfer.NewFTP(ftpOptions(server))
+		defer func() { _ = transferer.Close() }()
+
+		content, localPath, err := transferFTPFile(t, server, transferer, 12*1024*1024)
+		require.NoError(t, err)
+
+		downloaded, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+	})
+
+	t.Run("ImplicitTLS", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{TLS: testutil.FTPTLSImplicit})
+		transferer := transfer.NewFTP(ftpOptions(server))
+		defer func() { _ = transferer.Close() }()
+
+		content, localPath, err := transferFTPFile(t, server, transferer, 1024)
+		require.NoError(t, err)
+
+		downloaded, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+	})
+
+	t.Run("UntrustedCertificate", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{TLS: testutil.FTPTLSImplicit})
+		opts := ftpOptions(server)
+		opts.FTP.InsecureSkipVerify = false
+		transferer := transfer.NewFTP(opts)
+		defer func() { _ = transferer.Close() }()
+
+		_, _, err := transferFTPFile(t, server, transferer, 1024)
+		require.ErrorContains(t, err, "certificate")
+	})
+
+	t.Run("IgnorePassiveIP", func(t *testing.T) {
+		// The server advertises an address it does not listen on
+		server := startFTPServer(t, testutil.FTPServerConfig{PassiveIP: "127.0.0.2", DisableEPSV: true})
+
+		opts := ftpOptions(server)
+		opts.FTP.DisableEPSV = true
+		transferer := transfer.NewFTP(opts)
+		defer func() { _ = transferer.Close() }()
+
+		_, _, err := transferFTPFile(t, server, transferer, 1024)
+		require.Error(t, err, "data connection to the advertised address should fail")
+
+		opts.FTP.IgnorePassiveIP = true
+		ignoring := transfer.NewFTP(opts)
+		defer func() { _ = ignoring.Close() }()
+
+		content, localPath, err := transferFTPFile(t, server, ignoring, 1024)
+		require.NoError(t, err)
+
+		downloaded, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{})
+		transferer := transfer.NewFTP(ftpOptions(server))
+		defer func() { _ = transferer.Close() }()
+
+		localPath := filepath.Join(t.TempDir(), "missing.mkv")
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: ftpTestRemotePath + "/missing.mkv",
+			LocalPath:  localPath,
+			Size:       1024,
+		}, nil)
+		require.ErrorContains(t, err, "failed to retrieve remote file")
+		assert.NoFileExists(t, localPath+".partial")
+	})
+
+	t.Run("OutsideRemotePath", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{})
+		transferer := transfer.NewFTP(ftpOptions(server))
+		defer func() { _ = transferer.Close() }()
+
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: "/etc/passwd",
+			LocalPath:  filepath.Join(t.TempDir(), "passwd"),
+			Size:       1024,
+		}, nil)
+		require.ErrorContains(t, err, "outside of the served directory")
+	})
+
+	t.Run("ContextCancellation", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{})
+		opts := ftpOptions(server)
+		opts.SpeedLimit = 1024 * 1024
+		transferer := transfer.NewFTP(opts)
+		defer func() { _ = transferer.Close() }()
+
+		require.NoError(t, server.CreateTestFile("large.bin", make([]byte, 16*1024*1024)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(200*time.Millisecond, cancel)
+
+		localPath := filepath.Join(t.TempDir(), "large.bin")
+		err := transferer.Transfer(ctx, transfer.Request{
+			RemotePath: ftpTestRemotePath + "/large.bin",
+			LocalPath:  localPath,
+			Size:       16 * 1024 * 1024,
+		}, nil)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.NoFileExists(t, localPath+".partial")
+	})
+}
+
+func TestFTPIntegration_TransferBatch(t *testing.T) {
+	server := startFTPServer(t, testutil.FTPServerConfig{})
+	transferer := transfer.NewFTP(ftpOptions(server))
+	defer func() { _ = transferer.Close() }()
+
+	batcher, ok := transferer.(transfer.BatchTransferer)
+	require.True(t, ok, "ftp transferer should support batches")
+
+	const count = 20
+	localDir := t.TempDir()
+	reqs := make([]transfer.Request, count)
+	contents := make([][]byte, count)
+	for i := range count {
+		contents[i] = make([]byte, 1024+i)
+		_, err := rand.Read(contents[i])
+		require.NoError(t, err)
+
+		name := fmt.Sprintf("Discography/track-%02d.flac", i)
+		require.NoError(t, server.CreateTestFile(name, contents[i]))
+		reqs[i] = transfer.Request{
+			RemotePath: ftpTestRemotePath + "/" + name,
+			LocalPath:  filepath.Join(localDir, name),
+			Size:       int64(len(contents[i])),
+		}
+	}
+
+	var mu sync.Mutex
+	results := make(map[int]error)
+	err := batcher.TransferBatch(context.Background(), reqs, 2, nil, func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[i] = err
+	})
+	require.NoError(t, err)
+
+	require.Len(t, results, count)
+	for i, req := range reqs {
+		require.NoError(t, results[i])
+
+		downloaded, readErr := os.ReadFile(req.LocalPath)
+		require.NoError(t, readErr)
+		assert.Equal(t, contents[i], downloaded)
+	}
+
+	// Each of the two workers retrieves its files over one session
+	assert.LessOrEqual(t, server.Logins(), 2)
+}
+
+func TestFTPIntegration_TestConnection(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{TLS: testutil.FTPTLSExplicit})
+		transferer := transfer.NewFTP(ftpOptions(server))
+		defer func() { _ = transferer.Close() }()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("WrongPassword", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{})
+		opts := ftpOptions(server)
+		opts.FTP.Password = "wrong"
+		transferer := transfer.NewFTP(opts)
+		defer func() { _ = transferer.Close() }()
+
+		require.ErrorContains(t, transferer.TestConnection(context.Background()), "ftp login failed")
+	})
+
+	t.Run("MissingDirectory", func(t *testing.T) {
+		server := startFTPServer(t, testutil.FTPServerConfig{})
+		opts := ftpOptions(server)
+		opts.FTP.URL = server.URL + "missing/"
+		transferer := transfer.NewFTP(opts)
+		defer func() { _ = transferer.Close() }()
+
+		require.ErrorContains(t, transferer.TestConnection(context.Background()), "failed to change to directory")
+	})
+}
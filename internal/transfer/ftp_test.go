@@ -0,0 +1,53 @@
+package transfer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seedreap/seedreap/internal/transfer"
+)
+
+// Transfers are tested against a local FTP server in ftp_integration_test.go.
+
+func TestNewFTP(t *testing.T) {
+	t.Run("Name", func(t *testing.T) {
+		transferer := transfer.NewFTP(transfer.Options{})
+		assert.Equal(t, "ftp", transferer.Name())
+		assert.Equal(t, transfer.BackendFTP, transfer.Backend(transferer.Name()))
+	})
+
+	t.Run("NoSpeedWhenIdle", func(t *testing.T) {
+		transferer := transfer.NewFTP(transfer.Options{})
+		assert.Equal(t, int64(0), transferer.GetSpeed())
+	})
+
+	t.Run("MultipleCloseCallsSafe", func(t *testing.T) {
+		transferer := transfer.NewFTP(transfer.Options{})
+		transferer.PrepareShutdown()
+		assert.NoError(t, transferer.Close())
+		assert.NoError(t, transferer.Close())
+	})
+
+	t.Run("UnsupportedScheme", func(t *testing.T) {
+		transferer := transfer.NewFTP(transfer.Options{
+			FTP: transfer.FTPConfig{URL: "sftp://seedbox.example.com/"},
+		})
+		defer transferer.Close()
+
+		err := transferer.TestConnection(context.Background())
+		require.ErrorContains(t, err, `invalid url: unsupported scheme "sftp"`)
+	})
+
+	t.Run("ConnectionRefused", func(t *testing.T) {
+		transferer := transfer.NewFTP(transfer.Options{
+			FTP: transfer.FTPConfig{URL: "ftp://127.0.0.1:1/"},
+		})
+		defer transferer.Close()
+
+		err := transferer.TestConnection(context.Background())
+		require.ErrorContains(t, err, "failed to connect to 127.0.0.1:1")
+	})
+}
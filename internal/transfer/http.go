@@ -0,0 +1,607 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+// Default HTTP configuration values.
+const (
+	httpDefaultParallelConnections = 8
+	httpDefaultTimeout             = 30 * time.Second
+	httpBufferSize                 = 256 * 1024      // Bytes copied per write
+	httpMinSegmentSize             = 10 * bytesPerMB // Don't split files if segments would be under 10MB
+	httpMaxAttempts                = 3               // Failed requests per segment without progress
+	httpRetryDelay                 = time.Second     // Multiplied by the number of failures
+	httpStateSuffix                = ".state"        // Appended to the partial path for resumable downloads
+)
+
+// errHTTPRemoteChanged is returned when the remote file changed while it was downloaded.
+var errHTTPRemoteChanged = errors.New("remote file changed during download")
+
+// httpStatusError is returned for unexpected HTTP responses.
+type httpStatusError struct {
+	status int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.status, http.StatusText(e.status))
+}
+
+// retryable reports whether the request may succeed if sent again.
+func (e *httpStatusError) retryable() bool {
+	return e.status >= http.StatusInternalServerError || e.status == http.StatusTooManyRequests
+}
+
+// httpResumeState is saved next to a partial file so an interrupted download
+// can continue where it stopped.
+type httpResumeState struct {
+	Size      int64     `json:"size"`
+	Validator string    `json:"validator"` // ETag or Last-Modified of the remote file
+	Remaining []segment `json:"remaining"` // Byte ranges not downloaded yet
+}
+
+// httpTransferer implements Transferer over HTTP(S), for seedboxes that serve
+// their files as directory listings or over WebDAV. Files large enough are
+// split into segments downloaded in parallel with range requests, and
+// interrupted downloads are resumed when the server provides a validator.
+// It is private and only exposed via the Transferer interface.
+type httpTransferer struct {
+	base                *url.URL
+	baseErr             error
+	root                string
+	parallelConnections int
+	speedLimit          int64
+	logger              zerolog.Logger
+
+	client       *http.Client
+	auth         *httpAuth
+	transfers    activeTransfers
+	shuttingDown atomic.Bool
+}
+
+// setLogger implements configurable for shared options.
+func (t *httpTransferer) setLogger(logger zerolog.Logger) {
+	t.logger = logger
+}
+
+// NewHTTP creates a new HTTP(S) transferer and returns it as Transferer.
+func NewHTTP(opts Options, options ...Option) Transferer {
+	parallelConnections := opts.ParallelConnections
+	if parallelConnections == 0 {
+		parallelConnections = httpDefaultParallelConnections
+	}
+
+	cfg := opts.HTTP
+	if cfg.Timeout == 0 {
+		cfg.Timeout = httpDefaultTimeout
+	}
+
+	base, err := url.Parse(cfg.URL)
+	if err == nil && base.Scheme != "http" && base.Scheme != "https" {
+		err = fmt.Errorf("unsupported scheme %q", base.Scheme)
+	}
+
+	transport, _ := http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+	transport.DialContext = (&net.Dialer{Timeout: cfg.Timeout}).DialContext
+	transport.ResponseHeaderTimeout = cfg.Timeout
+	transport.MaxIdleConnsPerHost = parallelConnections
+
+	t := &httpTransferer{
+		base:                base,
+		baseErr:             err,
+		root:                servedRoot(cfg.RemotePath),
+		parallelConnections: parallelConnections,
+		speedLimit:          opts.SpeedLimit,
+		logger:              zerolog.Nop(),
+		// No overall timeout, since downloads can take hours
+		client: &http.Client{Transport: transport},
+		auth:   &httpAuth{username: cfg.Username, password: cfg.Password},
+	}
+
+	for _, opt := range options {
+		opt(t)
+	}
+
+	return t
+}
+
+// Name returns the name of the transfer backend.
+func (t *httpTransferer) Name() string {
+	return string(BackendHTTP)
+}
+
+// PrepareShutdown suppresses transfer error logging during shutdown.
+func (t *httpTransferer) PrepareShutdown() {
+	t.shuttingDown.Store(true)
+}
+
+// Close closes idle connections.
+func (t *httpTransferer) Close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// GetSpeed returns the sum of the current speeds of the active transfers.
+func (t *httpTransferer) GetSpeed() int64 {
+	return t.transfers.speed()
+}
+
+// TestConnection requests the base URL, which must be reachable with the
+// configured credentials.
+func (t *httpTransferer) TestConnection(ctx context.Context) error {
+	if t.baseErr != nil {
+		return fmt.Errorf("invalid url: %w", t.baseErr)
+	}
+
+	resp, err := t.do(ctx, http.MethodHead, t.base, nil)
+	if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+		_ = resp.Body.Close()
+		resp, err = t.do(ctx, http.MethodGet, t.base, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", t.base.Host, err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("failed to connect to %s: %w", t.base.Host, &httpStatusError{status: resp.StatusCode})
+	}
+	return nil
+}
+
+// do sends a request, authenticating and sending it again if the server asks for credentials.
+func (t *httpTransferer) do(
+	ctx context.Context,
+	method string,
+	u *url.URL,
+	header http.Header,
+) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		t.auth.apply(req)
+		return t.client.Do(req)
+	}
+
+	resp, err := send()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && t.auth.challenge(resp) {
+		_ = resp.Body.Close()
+		return send()
+	}
+	return resp, nil
+}
+
+// fileURL returns the URL of a remote file.
+func (t *httpTransferer) fileURL(remotePath string) (*url.URL, error) {
+	if t.baseErr != nil {
+		return nil, fmt.Errorf("invalid url: %w", t.baseErr)
+	}
+
+	rel, err := relativeToRoot(t.root, remotePath)
+	if err != nil {
+		return nil, err
+	}
+
+	elems := strings.Split(rel, "/")
+	for i, elem := range elems {
+		elems[i] = url.PathEscape(elem)
+	}
+	return t.base.JoinPath(elems...), nil
+}
+
+// Transfer copies a file from remote to local. The file is written next to
+// LocalPath with a .partial suffix and renamed once complete. If the transfer
+// fails, the partial file is kept and the next transfer of the same file
+// continues from where it stopped.
+//
+//nolint:funlen // transfer setup, progress monitoring and completion
+func (t *httpTransferer) Transfer(ctx context.Context, req Request, onProgress ProgressFunc) error {
+	t.logger.Debug().
+		Str("remote", req.RemotePath).
+		Str("local", req.LocalPath).
+		Int64("size", req.Size).
+		Msg("starting http transfer")
+
+	u, err := t.fileURL(req.RemotePath)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(req.LocalPath), 0750); err != nil {
+		return fmt.Errorf("failed to create local directory: %w", err)
+	}
+
+	stats := t.transfers.start()
+	defer t.transfers.finish(stats)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	startTime := time.Now()
+	wg.Go(func() {
+		monitorProgress(stats, onProgress, done)
+	})
+
+	partialPath := req.LocalPath + partialSuffix
+	err = t.download(ctx, u, req, partialPath, stats)
+
+	close(done)
+	wg.Wait()
+
+	if err == nil {
+		if renameErr := os.Rename(partialPath, req.LocalPath); renameErr != nil {
+			err = fmt.Errorf("failed to rename local file: %w", renameErr)
+		}
+	}
+	if err != nil {
+		if !t.shuttingDown.Load() {
+			t.logger.Debug().Err(err).Str("remote", req.RemotePath).Msg("http transfer failed")
+		}
+		return err
+	}
+
+	elapsed := time.Since(startTime).Seconds()
+	var speed int64
+	if elapsed > 0 {
+		speed = int64(float64(req.Size) / elapsed)
+	}
+
+	if onProgress != nil {
+		onProgress(Progress{
+			Transferred: req.Size,
+			BytesPerSec: speed,
+		})
+	}
+
+	t.logger.Debug().
+		Str("file", req.RemotePath).
+		Int64("size", req.Size).
+		Float64("speed_mbps", float64(speed)/bytesPerMB).
+		Msg("http transfer complete")
+
+	return nil
+}
+
+// download writes the remote file to partialPath. It probes the server with a
+// one-byte range request: if ranges are supported, the file is downloaded in
+// segments, otherwise the probe's response is the whole file.
+func (t *httpTransferer) download(
+	ctx context.Context,
+	u *url.URL,
+	req Request,
+	partialPath string,
+	stats *transferStats,
+) error {
+	statePath := partialPath + httpStateSuffix
+	limiter := newLimiter(t.speedLimit, httpBufferSize)
+
+	var header http.Header
+	if req.Size > 0 {
+		header = http.Header{"Range": {"bytes=0-0"}}
+	}
+	resp, err := t.do(ctx, http.MethodGet, u, header)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", req.RemotePath, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// No range support: stream the whole file, which cannot be resumed
+		defer resp.Body.Close()
+		_ = os.Remove(statePath)
+		return t.downloadStream(ctx, resp.Body, req, partialPath, limiter, stats)
+	case http.StatusPartialContent:
+		_ = resp.Body.Close()
+	default:
+		_ = resp.Body.Close()
+		return fmt.Errorf("failed to get %s: %w", req.RemotePath, &httpStatusError{status: resp.StatusCode})
+	}
+
+	if total := contentRangeSize(resp.Header.Get("Content-Range")); total >= 0 && total != req.Size {
+		return fmt.Errorf("remote file %q is %d bytes, expected %d", req.RemotePath, total, req.Size)
+	}
+
+	// A weak ETag cannot be used with If-Range, so fall back to Last-Modified
+	validator := resp.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = resp.Header.Get("Last-Modified")
+	}
+
+	f, state, err := openResumable(partialPath, statePath, req.Size, validator, t.parallelConnections)
+	if err != nil {
+		return err
+	}
+
+	remaining := req.Size
+	for _, seg := range state.Remaining {
+		remaining -= seg.End - seg.Start
+	}
+	if remaining > 0 {
+		t.logger.Debug().Str("remote", req.RemotePath).Int64("offset", remaining).Msg("resuming http transfer")
+	}
+	stats.transferred.Store(remaining)
+
+	err = t.downloadSegments(ctx, u, f, state, limiter, stats)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close local file: %w", closeErr)
+	}
+
+	switch {
+	case err == nil:
+		_ = os.Remove(statePath)
+	case errors.Is(err, errHTTPRemoteChanged) || validator == "":
+		_ = os.Remove(statePath)
+		_ = os.Remove(partialPath)
+	default:
+		saveResumeState(statePath, state)
+	}
+	return err
+}
+
+// downloadStream writes a response body to partialPath.
+func (t *httpTransferer) downloadStream(
+	ctx context.Context,
+	body io.Reader,
+	req Request,
+	partialPath string,
+	limiter *rate.Limiter,
+	stats *transferStats,
+) error {
+	f, err := os.OpenFile(partialPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+
+	seg := segment{End: req.Size}
+	err = copyHTTPBody(ctx, body, f, &seg, limiter, stats)
+	if err == nil && seg.Start != req.Size {
+		err = fmt.Errorf("remote file %q is shorter than expected", req.RemotePath)
+	}
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close local file: %w", closeErr)
+	}
+	if err != nil {
+		_ = os.Remove(partialPath)
+	}
+	return err
+}
+
+// downloadSegments downloads the remaining segments of state in parallel,
+// advancing each segment's start as its bytes are written.
+func (t *httpTransferer) downloadSegments(
+	ctx context.Context,
+	u *url.URL,
+	f *os.File,
+	state *httpResumeState,
+	limiter *rate.Limiter,
+	stats *transferStats,
+) error {
+	g, gctx := errgroup.WithContext(ctx)
+	for i := range state.Remaining {
+		seg := &state.Remaining[i]
+		if seg.Start >= seg.End {
+			continue
+		}
+		g.Go(func() error {
+			return t.downloadSegment(gctx, u, f, seg, state.Validator, limiter, stats)
+		})
+	}
+	return g.Wait()
+}
+
+// downloadSegment downloads a segment with range requests, resuming after
+// transient failures.
+func (t *httpTransferer) downloadSegment(
+	ctx context.Context,
+	u *url.URL,
+	f *os.File,
+	seg *segment,
+	validator string,
+	limiter *rate.Limiter,
+	stats *transferStats,
+) error {
+	failures := 0
+	for seg.Start < seg.End {
+		start := seg.Start
+		err := t.requestSegment(ctx, u, f, seg, validator, limiter, stats)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var statusErr *httpStatusError
+		if errors.Is(err, errHTTPRemoteChanged) || (errors.As(err, &statusErr) && !statusErr.retryable()) {
+			return err
+		}
+		if seg.Start > start {
+			failures = 0
+		}
+		if failures++; failures >= httpMaxAttempts {
+			return err
+		}
+
+		t.logger.Debug().Err(err).Int("failures", failures).Str("url", u.Redacted()).Msg("retrying http segment")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(failures) * httpRetryDelay):
+		}
+	}
+	return nil
+}
+
+// requestSegment sends a range request for the rest of a segment and writes the response to f.
+func (t *httpTransferer) requestSegment(
+	ctx context.Context,
+	u *url.URL,
+	f *os.File,
+	seg *segment,
+	validator string,
+	limiter *rate.Limiter,
+	stats *transferStats,
+) error {
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", seg.Start, seg.End-1)}}
+	if validator != "" {
+		header.Set("If-Range", validator)
+	}
+
+	resp, err := t.do(ctx, http.MethodGet, u, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK, http.StatusPreconditionFailed:
+		// If-Range sends the whole file when the validator no longer matches
+		return errHTTPRemoteChanged
+	default:
+		return &httpStatusError{status: resp.StatusCode}
+	}
+
+	if err = copyHTTPBody(ctx, resp.Body, f, seg, limiter, stats); err != nil {
+		return err
+	}
+	if seg.Start < seg.End {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// copyHTTPBody writes body to f from seg.Start until seg.End or the end of the
+// body, advancing seg.Start as bytes are written.
+func copyHTTPBody(
+	ctx context.Context,
+	body io.Reader,
+	f *os.File,
+	seg *segment,
+	limiter *rate.Limiter,
+	stats *transferStats,
+) error {
+	buf := make([]byte, httpBufferSize)
+	for seg.Start < seg.End {
+		n := int(min(int64(len(buf)), seg.End-seg.Start))
+		if limiter != nil {
+			if err := limiter.WaitN(ctx, n); err != nil {
+				return err
+			}
+		}
+
+		read, readErr := io.ReadFull(body, buf[:n])
+		if read > 0 {
+			if _, err := f.WriteAt(buf[:read], seg.Start); err != nil {
+				return fmt.Errorf("failed to write local file: %w", err)
+			}
+			seg.Start += int64(read)
+			stats.transferred.Add(int64(read))
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	return nil
+}
+
+// openResumable opens the partial file, continuing a previous download if its
+// saved state matches the remote file, or starting over otherwise.
+func openResumable(
+	partialPath, statePath string,
+	size int64,
+	validator string,
+	parallelConnections int,
+) (*os.File, *httpResumeState, error) {
+	if state, ok := loadResumeState(statePath); ok && state.Size == size && state.Validator == validator &&
+		validator != "" {
+		if info, err := os.Stat(partialPath); err == nil && info.Size() == size {
+			f, err := os.OpenFile(partialPath, os.O_WRONLY, 0600)
+			if err == nil {
+				return f, state, nil
+			}
+		}
+	}
+	_ = os.Remove(statePath)
+
+	f, err := os.OpenFile(partialPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create local file: %w", err)
+	}
+	if err = f.Truncate(size); err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("failed to allocate local file: %w", err)
+	}
+
+	return f, &httpResumeState{
+		Size:      size,
+		Validator: validator,
+		Remaining: splitSegments(size, parallelConnections, httpMinSegmentSize),
+	}, nil
+}
+
+// loadResumeState reads the state saved by an interrupted download.
+func loadResumeState(statePath string) (*httpResumeState, bool) {
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil, false
+	}
+	var state httpResumeState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, false
+	}
+	return &state, true
+}
+
+// saveResumeState saves the segments left to download. Failing to save it only
+// means the next attempt starts over.
+func saveResumeState(statePath string, state *httpResumeState) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(statePath, data, 0600)
+}
+
+// contentRangeSize returns the complete length from a Content-Range header such
+// as "bytes 0-0/1234", or -1 if it is unknown.
+func contentRangeSize(contentRange string) int64 {
+	_, total, ok := strings.Cut(contentRange, "/")
+	if !ok {
+		return -1
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
@@ -0,0 +1,482 @@
+package transfer_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // digest authentication test server
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seedreap/seedreap/internal/transfer"
+)
+
+// rangeRecorder wraps a handler and records the Range header of every GET request.
+type rangeRecorder struct {
+	handler http.Handler
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (r *rangeRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodGet {
+		r.mu.Lock()
+		r.ranges = append(r.ranges, req.Header.Get("Range"))
+		r.mu.Unlock()
+	}
+	r.handler.ServeHTTP(w, req)
+}
+
+// requests returns the Range headers recorded so far, and forgets them.
+func (r *rangeRecorder) requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ranges := r.ranges
+	r.ranges = nil
+	return ranges
+}
+
+// newHTTPFileServer serves dir over HTTP with range support and records range requests.
+func newHTTPFileServer(t *testing.T, dir string) (*httptest.Server, *rangeRecorder) {
+	t.Helper()
+	recorder := &rangeRecorder{handler: http.FileServer(http.Dir(dir))}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+	return server, recorder
+}
+
+// writeRemoteFile writes size random bytes to name in dir.
+func writeRemoteFile(t *testing.T, dir, name string, size int) []byte {
+	t.Helper()
+	path, data := writeRandomFile(t, size)
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0750))
+	require.NoError(t, os.Rename(path, filepath.Join(dir, name)))
+	return data
+}
+
+// httpOptions returns transfer options for a server serving the seedbox directory /data.
+func httpOptions(url string) transfer.Options {
+	return transfer.Options{
+		HTTP: transfer.HTTPConfig{
+			URL:        url + "/files/",
+			RemotePath: "/data",
+		},
+		ParallelConnections: 4,
+	}
+}
+
+// filesHandler strips the /files prefix served by httpOptions.
+func filesHandler(h http.Handler) http.Handler {
+	return http.StripPrefix("/files", h)
+}
+
+// digestAuth wraps a handler with MD5 Digest authentication for user:pass.
+func digestAuth(h http.Handler) http.Handler {
+	const realm, nonce = "seedbox", "abc123"
+	paramRe := regexp.MustCompile(`(\w+)=(?:"([^"]*)"|([^,\s]*))`)
+	hash := func(s string) string {
+		sum := md5.Sum([]byte(s)) //nolint:gosec // digest authentication test server
+		return hex.EncodeToString(sum[:])
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := map[string]string{}
+		if auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Digest "); ok {
+			for _, m := range paramRe.FindAllStringSubmatch(auth, -1) {
+				params[m[1]] = m[2] + m[3]
+			}
+		}
+
+		ha1 := hash("user:" + realm + ":pass")
+		ha2 := hash(r.Method + ":" + params["uri"])
+		expected := hash(ha1 + ":" + nonce + ":" + params["nc"] + ":" + params["cnonce"] + ":auth:" + ha2)
+		if params["username"] != "user" || params["uri"] != r.URL.RequestURI() || params["response"] != expected {
+			w.Header().Set("WWW-Authenticate",
+				fmt.Sprintf(`Digest realm=%q, nonce=%q, qop="auth,auth-int", algorithm=MD5`, realm, nonce))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func TestNewHTTP(t *testing.T) {
+	t.Run("Name", func(t *testing.T) {
+		transferer := transfer.NewHTTP(transfer.Options{})
+		assert.Equal(t, "http", transferer.Name())
+		assert.Equal(t, int64(0), transferer.GetSpeed())
+	})
+
+	t.Run("MultipleCloseCallsSafe", func(t *testing.T) {
+		transferer := transfer.NewHTTP(transfer.Options{})
+		transferer.PrepareShutdown()
+		assert.NoError(t, transferer.Close())
+		assert.NoError(t, transferer.Close())
+	})
+
+	t.Run("InvalidURL", func(t *testing.T) {
+		transferer := transfer.NewHTTP(transfer.Options{HTTP: transfer.HTTPConfig{URL: "ftp://seedbox"}})
+		require.ErrorContains(t, transferer.TestConnection(context.Background()), "unsupported scheme")
+	})
+}
+
+func TestHTTPTestConnection(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		server := httptest.NewServer(filesHandler(http.FileServer(http.Dir(t.TempDir()))))
+		defer server.Close()
+
+		transferer := transfer.NewHTTP(httpOptions(server.URL))
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("BasicAuth", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="seedbox"`)
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		defer server.Close()
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.Username = "user"
+		opts.HTTP.Password = "pass"
+		require.NoError(t, transfer.NewHTTP(opts).TestConnection(context.Background()))
+
+		opts.HTTP.Password = "wrong"
+		require.ErrorContains(t, transfer.NewHTTP(opts).TestConnection(context.Background()), "401")
+	})
+
+	t.Run("DigestAuth", func(t *testing.T) {
+		server := httptest.NewServer(digestAuth(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+		defer server.Close()
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.Username = "user"
+		opts.HTTP.Password = "pass"
+		require.NoError(t, transfer.NewHTTP(opts).TestConnection(context.Background()))
+
+		opts.HTTP.Password = "wrong"
+		require.ErrorContains(t, transfer.NewHTTP(opts).TestConnection(context.Background()), "401")
+	})
+
+	t.Run("HeadNotAllowed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}))
+		defer server.Close()
+
+		require.NoError(t, transfer.NewHTTP(httpOptions(server.URL)).TestConnection(context.Background()))
+	})
+}
+
+func TestHTTPTransfer(t *testing.T) {
+	t.Run("SmallFile", func(t *testing.T) {
+		dir := t.TempDir()
+		data := writeRemoteFile(t, dir, "Show S01/episode #1.mkv", 100*1024)
+		server, recorder := newHTTPFileServer(t, dir)
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.URL = server.URL + "/"
+		transferer := transfer.NewHTTP(opts)
+		defer transferer.Close()
+
+		localPath := filepath.Join(t.TempDir(), "nested", "episode.mkv")
+		var last transfer.Progress
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: "/data/Show S01/episode #1.mkv",
+			LocalPath:  localPath,
+			Size:       int64(len(data)),
+		}, func(p transfer.Progress) { last = p })
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+		assert.Equal(t, int64(len(data)), last.Transferred)
+		assert.Equal(t, []string{"bytes=0-0", fmt.Sprintf("bytes=0-%d", len(data)-1)}, recorder.requests())
+		assert.NoFileExists(t, localPath+".partial")
+	})
+
+	t.Run("EmptyFile", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRemoteFile(t, dir, "empty.bin", 0)
+		server, _ := newHTTPFileServer(t, dir)
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.URL = server.URL
+		transferer := transfer.NewHTTP(opts)
+		defer transferer.Close()
+
+		localPath := filepath.Join(t.TempDir(), "empty.bin")
+		require.NoError(t, transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: "/data/empty.bin",
+			LocalPath:  localPath,
+		}, nil))
+
+		info, err := os.Stat(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), info.Size())
+	})
+
+	t.Run("SegmentedFile", func(t *testing.T) {
+		dir := t.TempDir()
+		data := writeRemoteFile(t, dir, "large.bin", 30*1024*1024+12345)
+		server, recorder := newHTTPFileServer(t, dir)
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.URL = server.URL
+		transferer := transfer.NewHTTP(opts)
+		defer transferer.Close()
+
+		localPath := filepath.Join(t.TempDir(), "large.bin")
+		require.NoError(t, transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: "/data/large.bin",
+			LocalPath:  localPath,
+			Size:       int64(len(data)),
+		}, nil))
+
+		got, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(data, got), "content mismatch")
+		// The probe, then 3 segments of at least 10MB
+		assert.Len(t, recorder.requests(), 4)
+	})
+
+	t.Run("NoRangeSupport", func(t *testing.T) {
+		dir := t.TempDir()
+		data := writeRemoteFile(t, dir, "file.bin", 64*1024)
+		// ServeFile honors ranges, so strip them to simulate a server that ignores them
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Del("Range")
+			http.ServeFile(w, r, filepath.Join(dir, "file.bin"))
+		}))
+		defer server.Close()
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.URL = server.URL
+		transferer := transfer.NewHTTP(opts)
+		defer transferer.Close()
+
+		localPath := filepath.Join(t.TempDir(), "file.bin")
+		require.NoError(t, transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: "/data/file.bin",
+			LocalPath:  localPath,
+			Size:       int64(len(data)),
+		}, nil))
+
+		got, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+	})
+
+	t.Run("DigestAuth", func(t *testing.T) {
+		dir := t.TempDir()
+		data := writeRemoteFile(t, dir, "file.bin", 64*1024)
+		server := httptest.NewServer(digestAuth(filesHandler(http.FileServer(http.Dir(dir)))))
+		defer server.Close()
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.Username = "user"
+		opts.HTTP.Password = "pass"
+		transferer := transfer.NewHTTP(opts)
+		defer transferer.Close()
+
+		localPath := filepath.Join(t.TempDir(), "file.bin")
+		require.NoError(t, transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: "/data/file.bin",
+			LocalPath:  localPath,
+			Size:       int64(len(data)),
+		}, nil))
+
+		got, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+	})
+
+	t.Run("OutsideRemotePath", func(t *testing.T) {
+		transferer := transfer.NewHTTP(httpOptions("http://127.0.0.1:1"))
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: "/other/file.bin",
+			LocalPath:  filepath.Join(t.TempDir(), "file.bin"),
+			Size:       1,
+		}, nil)
+		require.ErrorContains(t, err, "outside of the served directory")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		server, _ := newHTTPFileServer(t, t.TempDir())
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.URL = server.URL
+		transferer := transfer.NewHTTP(opts)
+		defer transferer.Close()
+
+		localPath := filepath.Join(t.TempDir(), "missing.bin")
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: "/data/missing.bin",
+			LocalPath:  localPath,
+			Size:       1024,
+		}, nil)
+		require.ErrorContains(t, err, "404")
+		assert.NoFileExists(t, localPath)
+	})
+
+	t.Run("SizeMismatch", func(t *testing.T) {
+		dir := t.TempDir()
+		data := writeRemoteFile(t, dir, "file.bin", 1024)
+		server, _ := newHTTPFileServer(t, dir)
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.URL = server.URL
+		transferer := transfer.NewHTTP(opts)
+		defer transferer.Close()
+
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: "/data/file.bin",
+			LocalPath:  filepath.Join(t.TempDir(), "file.bin"),
+			Size:       int64(len(data) * 2),
+		}, nil)
+		require.ErrorContains(t, err, "expected 2048")
+	})
+}
+
+func TestHTTPTransferResume(t *testing.T) {
+	t.Run("RetriesInterruptedSegment", func(t *testing.T) {
+		dir := t.TempDir()
+		data := writeRemoteFile(t, dir, "file.bin", 512*1024)
+		fileServer := http.FileServer(http.Dir(dir))
+
+		// The first full request is cut off after 100KB
+		var cut atomic.Bool
+		recorder := &rangeRecorder{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "bytes=0-0" && cut.CompareAndSwap(false, true) {
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(data)-1, len(data)))
+				w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write(data[:100*1024])
+				return
+			}
+			fileServer.ServeHTTP(w, r)
+		})}
+		server := httptest.NewServer(recorder)
+		defer server.Close()
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.URL = server.URL
+		transferer := transfer.NewHTTP(opts)
+		defer transferer.Close()
+
+		localPath := filepath.Join(t.TempDir(), "file.bin")
+		require.NoError(t, transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: "/data/file.bin",
+			LocalPath:  localPath,
+			Size:       int64(len(data)),
+		}, nil))
+
+		got, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+		assert.Equal(t, []string{
+			"bytes=0-0",
+			fmt.Sprintf("bytes=0-%d", len(data)-1),
+			fmt.Sprintf("bytes=%d-%d", 100*1024, len(data)-1),
+		}, recorder.requests())
+	})
+
+	t.Run("ContinuesCancelledTransfer", func(t *testing.T) {
+		dir := t.TempDir()
+		data := writeRemoteFile(t, dir, "file.bin", 1024*1024)
+		server, recorder := newHTTPFileServer(t, dir)
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.URL = server.URL
+		opts.SpeedLimit = 256 * 1024
+		limited := transfer.NewHTTP(opts)
+		defer limited.Close()
+
+		req := transfer.Request{
+			RemotePath: "/data/file.bin",
+			LocalPath:  filepath.Join(t.TempDir(), "file.bin"),
+			Size:       int64(len(data)),
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(600*time.Millisecond, cancel)
+		require.ErrorIs(t, limited.Transfer(ctx, req, nil), context.Canceled)
+		assert.FileExists(t, req.LocalPath+".partial")
+		recorder.requests()
+
+		opts.SpeedLimit = 0
+		transferer := transfer.NewHTTP(opts)
+		defer transferer.Close()
+
+		var first transfer.Progress
+		var once sync.Once
+		require.NoError(t, transferer.Transfer(context.Background(), req, func(p transfer.Progress) {
+			once.Do(func() { first = p })
+		}))
+
+		got, err := os.ReadFile(req.LocalPath)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+		assert.NoFileExists(t, req.LocalPath+".partial")
+		assert.NoFileExists(t, req.LocalPath+".partial.state")
+
+		ranges := recorder.requests()
+		require.Len(t, ranges, 2)
+		assert.NotEqual(t, "bytes=0-"+fmt.Sprint(len(data)-1), ranges[1], "should not start over")
+		assert.Positive(t, first.Transferred)
+	})
+
+	t.Run("StartsOverWhenRemoteChanged", func(t *testing.T) {
+		dir := t.TempDir()
+		data := writeRemoteFile(t, dir, "file.bin", 1024*1024)
+		server, _ := newHTTPFileServer(t, dir)
+
+		opts := httpOptions(server.URL)
+		opts.HTTP.URL = server.URL
+		opts.SpeedLimit = 256 * 1024
+		limited := transfer.NewHTTP(opts)
+		defer limited.Close()
+
+		req := transfer.Request{
+			RemotePath: "/data/file.bin",
+			LocalPath:  filepath.Join(t.TempDir(), "file.bin"),
+			Size:       int64(len(data)),
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(600*time.Millisecond, cancel)
+		require.ErrorIs(t, limited.Transfer(ctx, req, nil), context.Canceled)
+
+		// Replace the remote file with one of the same size
+		data = writeRemoteFile(t, dir, "file.bin", len(data))
+		later := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, "file.bin"), later, later))
+
+		opts.SpeedLimit = 0
+		transferer := transfer.NewHTTP(opts)
+		defer transferer.Close()
+		require.NoError(t, transferer.Transfer(context.Background(), req, nil))
+
+		got, err := os.ReadFile(req.LocalPath)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(data, got), "content mismatch")
+	})
+}
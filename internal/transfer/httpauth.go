@@ -0,0 +1,216 @@
+package transfer
+
+import (
+	"crypto/md5" //nolint:gosec // MD5 is the default digest authentication algorithm
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Authentication schemes supported by the http backend.
+const (
+	authSchemeBasic  = "basic"
+	authSchemeDigest = "digest"
+)
+
+// digestChallenge holds the parameters of a Digest WWW-Authenticate challenge.
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string // "auth", or empty if the server does not support qop
+}
+
+// httpAuth authenticates requests with Basic or Digest authentication. The
+// scheme is learned from the first 401 response, after which credentials are
+// sent with every request.
+type httpAuth struct {
+	username string
+	password string
+
+	mu     sync.Mutex
+	scheme string // Empty until the server asked for credentials
+	digest digestChallenge
+	nc     uint32 // Requests sent with the current nonce
+}
+
+// apply adds credentials to req once the server's scheme is known.
+func (a *httpAuth) apply(req *http.Request) {
+	if a.username == "" {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch a.scheme {
+	case authSchemeBasic:
+		req.SetBasicAuth(a.username, a.password)
+	case authSchemeDigest:
+		a.nc++
+		req.Header.Set("Authorization", a.digestAuthorization(req.Method, req.URL.RequestURI()))
+	}
+}
+
+// challenge learns the scheme from the WWW-Authenticate headers of a 401
+// response, and reports whether the request should be sent again.
+func (a *httpAuth) challenge(resp *http.Response) bool {
+	if a.username == "" {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var basic bool
+	for _, value := range resp.Header.Values("WWW-Authenticate") {
+		scheme, params, _ := strings.Cut(strings.TrimSpace(value), " ")
+		switch strings.ToLower(scheme) {
+		case authSchemeDigest:
+			c := parseDigestChallenge(params)
+			if c.nonce == "" || !supportedDigestAlgorithm(c.algorithm) {
+				continue
+			}
+			// Credentials were rejected unless the nonce went stale
+			retry := a.scheme != authSchemeDigest || c.nonce != a.digest.nonce
+			a.scheme = authSchemeDigest
+			a.digest = c
+			a.nc = 0
+			return retry
+		case authSchemeBasic:
+			basic = true
+		}
+	}
+
+	if basic && a.scheme != authSchemeBasic {
+		a.scheme = authSchemeBasic
+		return true
+	}
+	return false
+}
+
+// digestAuthorization computes the Authorization header for a request, as
+// described in RFC 7616. It must be called with a.mu held.
+func (a *httpAuth) digestAuthorization(method, uri string) string {
+	c := a.digest
+	h := digestHash(c.algorithm)
+
+	cnonceBytes := make([]byte, 16) //nolint:mnd // 128-bit client nonce
+	_, _ = rand.Read(cnonceBytes)
+	cnonce := hex.EncodeToString(cnonceBytes)
+	nc := fmt.Sprintf("%08x", a.nc)
+
+	ha1 := h(a.username + ":" + c.realm + ":" + a.password)
+	if strings.HasSuffix(strings.ToLower(c.algorithm), "-sess") {
+		ha1 = h(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	ha2 := h(method + ":" + uri)
+
+	var response string
+	if c.qop != "" {
+		response = h(ha1 + ":" + c.nonce + ":" + nc + ":" + cnonce + ":" + c.qop + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + c.nonce + ":" + ha2)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username=%q, realm=%q, nonce=%q, uri=%q, response=%q`,
+		a.username, c.realm, c.nonce, uri, response)
+	if c.algorithm != "" {
+		fmt.Fprintf(&b, ", algorithm=%s", c.algorithm)
+	}
+	if c.opaque != "" {
+		fmt.Fprintf(&b, ", opaque=%q", c.opaque)
+	}
+	if c.qop != "" {
+		fmt.Fprintf(&b, ", qop=%s, nc=%s, cnonce=%q", c.qop, nc, cnonce)
+	}
+	return b.String()
+}
+
+// supportedDigestAlgorithm reports whether a Digest algorithm can be computed.
+func supportedDigestAlgorithm(algorithm string) bool {
+	switch strings.ToUpper(algorithm) {
+	case "", "MD5", "MD5-SESS", "SHA-256", "SHA-256-SESS":
+		return true
+	default:
+		return false
+	}
+}
+
+// digestHash returns the hex-encoded hash function of a Digest algorithm.
+func digestHash(algorithm string) func(string) string {
+	newHash := md5.New
+	if strings.HasPrefix(strings.ToUpper(algorithm), "SHA-256") {
+		newHash = sha256.New
+	}
+	return func(s string) string {
+		h := newHash()
+		h.Write([]byte(s))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// parseDigestChallenge parses the parameters of a Digest challenge.
+func parseDigestChallenge(params string) digestChallenge {
+	values := parseAuthParams(params)
+	c := digestChallenge{
+		realm:     values["realm"],
+		nonce:     values["nonce"],
+		opaque:    values["opaque"],
+		algorithm: values["algorithm"],
+	}
+
+	qops := strings.Split(values["qop"], ",")
+	for i := range qops {
+		qops[i] = strings.TrimSpace(qops[i])
+	}
+	if slices.Contains(qops, "auth") {
+		c.qop = "auth"
+	}
+	return c
+}
+
+// parseAuthParams parses comma-separated key=value pairs, where values may be
+// quoted strings containing commas and escaped quotes.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+
+	for s != "" {
+		s = strings.TrimLeft(s, " \t,")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value strings.Builder
+		if strings.HasPrefix(rest, `"`) {
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				value.WriteByte(rest[i])
+			}
+			s = rest[min(i+1, len(rest)):]
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			value.WriteString(strings.TrimSpace(rest[:end]))
+			s = rest[end:]
+		}
+		params[key] = value.String()
+	}
+
+	return params
+}
@@ -0,0 +1,236 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// localBufferSize is the number of bytes copied per write.
+const localBufferSize = 1024 * 1024
+
+// localTransferer implements Transferer for seedbox storage that is mounted
+// locally (NFS, SMB, sshfs) or for a download client on the same host. Files
+// are hardlinked when the source is on the same filesystem, unless disabled,
+// and copied otherwise.
+// It is private and only exposed via the Transferer interface.
+type localTransferer struct {
+	root       string
+	mountPath  string
+	noHardlink bool
+	speedLimit int64
+	logger     zerolog.Logger
+
+	transfers    activeTransfers
+	shuttingDown atomic.Bool
+}
+
+// setLogger implements configurable for shared options.
+func (t *localTransferer) setLogger(logger zerolog.Logger) {
+	t.logger = logger
+}
+
+// NewLocal creates a new local filesystem transferer and returns it as Transferer.
+func NewLocal(opts Options, options ...Option) Transferer {
+	t := &localTransferer{
+		root:       servedRoot(opts.Local.RemotePath),
+		mountPath:  opts.Local.MountPath,
+		noHardlink: opts.Local.NoHardlink,
+		speedLimit: opts.SpeedLimit,
+		logger:     zerolog.Nop(),
+	}
+
+	for _, opt := range options {
+		opt(t)
+	}
+
+	return t
+}
+
+// Name returns the name of the transfer backend.
+func (t *localTransferer) Name() string {
+	return string(BackendLocal)
+}
+
+// PrepareShutdown suppresses transfer error logging during shutdown.
+func (t *localTransferer) PrepareShutdown() {
+	t.shuttingDown.Store(true)
+}
+
+// Close is a no-op, since files are only open during a transfer.
+func (t *localTransferer) Close() error {
+	return nil
+}
+
+// GetSpeed returns the sum of the current speeds of the active transfers.
+func (t *localTransferer) GetSpeed() int64 {
+	return t.transfers.speed()
+}
+
+// TestConnection checks that the mount path is a readable directory.
+func (t *localTransferer) TestConnection(_ context.Context) error {
+	dir := t.mountPath
+	if dir == "" {
+		dir = t.root
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	// An empty mount point usually means the share is not mounted
+	if len(entries) == 0 && t.mountPath != "" {
+		t.logger.Warn().Str("path", dir).Msg("mount path is empty, is the share mounted?")
+	}
+	return nil
+}
+
+// sourcePath maps a path on the seedbox to its path on the local filesystem.
+func (t *localTransferer) sourcePath(remotePath string) (string, error) {
+	if t.mountPath == "" {
+		return filepath.FromSlash(remotePath), nil
+	}
+
+	rel, err := relativeToRoot(t.root, remotePath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(t.mountPath, filepath.FromSlash(rel)), nil
+}
+
+// Transfer hardlinks or copies a file to LocalPath. Copies are written next to
+// LocalPath with a .partial suffix and renamed once complete.
+func (t *localTransferer) Transfer(ctx context.Context, req Request, onProgress ProgressFunc) error {
+	src, err := t.sourcePath(req.RemotePath)
+	if err != nil {
+		return err
+	}
+
+	t.logger.Debug().
+		Str("remote", req.RemotePath).
+		Str("source", src).
+		Str("local", req.LocalPath).
+		Int64("size", req.Size).
+		Msg("starting local transfer")
+
+	if err = os.MkdirAll(filepath.Dir(req.LocalPath), 0750); err != nil {
+		return fmt.Errorf("failed to create local directory: %w", err)
+	}
+
+	partialPath := req.LocalPath + partialSuffix
+	_ = os.Remove(partialPath)
+
+	startTime := time.Now()
+	linked := !t.noHardlink && os.Link(src, partialPath) == nil
+	if !linked {
+		err = t.copy(ctx, src, partialPath, onProgress)
+	}
+	if err == nil {
+		if renameErr := os.Rename(partialPath, req.LocalPath); renameErr != nil {
+			err = fmt.Errorf("failed to rename local file: %w", renameErr)
+		}
+	}
+	if err != nil {
+		_ = os.Remove(partialPath)
+		if !t.shuttingDown.Load() {
+			t.logger.Debug().Err(err).Str("remote", req.RemotePath).Msg("local transfer failed")
+		}
+		return err
+	}
+
+	elapsed := time.Since(startTime).Seconds()
+	var speed int64
+	if elapsed > 0 {
+		speed = int64(float64(req.Size) / elapsed)
+	}
+
+	if onProgress != nil {
+		onProgress(Progress{
+			Transferred: req.Size,
+			BytesPerSec: speed,
+		})
+	}
+
+	t.logger.Debug().
+		Str("file", req.RemotePath).
+		Int64("size", req.Size).
+		Bool("hardlinked", linked).
+		Float64("speed_mbps", float64(speed)/bytesPerMB).
+		Msg("local transfer complete")
+
+	return nil
+}
+
+// copy copies src to dst, reporting progress and honouring the speed limit.
+func (t *localTransferer) copy(ctx context.Context, src, dst string, onProgress ProgressFunc) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+
+	stats := t.transfers.start()
+	defer t.transfers.finish(stats)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Go(func() {
+		monitorProgress(stats, onProgress, done)
+	})
+
+	err = copyLocalFile(ctx, in, out, t.speedLimit, stats)
+
+	close(done)
+	wg.Wait()
+
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close local file: %w", closeErr)
+	}
+	return err
+}
+
+// copyLocalFile copies in to out in chunks, so the copy can be cancelled and
+// rate limited.
+func copyLocalFile(ctx context.Context, in io.Reader, out io.Writer, speedLimit int64, stats *transferStats) error {
+	limiter := newLimiter(speedLimit, localBufferSize)
+	buf := make([]byte, localBufferSize)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, readErr := in.Read(buf)
+		if n > 0 {
+			if limiter != nil {
+				if err := limiter.WaitN(ctx, n); err != nil {
+					return err
+				}
+			}
+			if _, err := out.Write(buf[:n]); err != nil {
+				return fmt.Errorf("failed to write local file: %w", err)
+			}
+			stats.transferred.Add(int64(n))
+		}
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read source file: %w", readErr)
+		}
+	}
+}
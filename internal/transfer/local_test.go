@@ -0,0 +1,202 @@
+package transfer_test
+
+import (
+	"context"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seedreap/seedreap/internal/transfer"
+)
+
+// localFixture is a mounted seedbox directory with one file in it.
+type localFixture struct {
+	mountPath string
+	content   []byte
+	req       transfer.Request
+}
+
+// newLocalFixture creates a file of the given size under a temporary mount
+// path, which holds /home/user/downloads of the seedbox.
+func newLocalFixture(t *testing.T, size int) localFixture {
+	t.Helper()
+
+	mountPath := t.TempDir()
+	content := make([]byte, size)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(mountPath, "Show"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(mountPath, "Show", "episode.mkv"), content, 0600))
+
+	return localFixture{
+		mountPath: mountPath,
+		content:   content,
+		req: transfer.Request{
+			RemotePath: "/home/user/downloads/Show/episode.mkv",
+			LocalPath:  filepath.Join(t.TempDir(), "staging", "episode.mkv"),
+			Size:       int64(size),
+		},
+	}
+}
+
+// options returns options for a transferer that reads from the fixture.
+func (f localFixture) options() transfer.Options {
+	return transfer.Options{
+		Local: transfer.LocalConfig{
+			RemotePath: "/home/user/downloads",
+			MountPath:  f.mountPath,
+		},
+	}
+}
+
+func TestNewLocal(t *testing.T) {
+	t.Run("Name", func(t *testing.T) {
+		transferer := transfer.NewLocal(transfer.Options{})
+		assert.Equal(t, "local", transferer.Name())
+		assert.Equal(t, transfer.BackendLocal, transfer.Backend(transferer.Name()))
+	})
+
+	t.Run("NoSpeedWhenIdle", func(t *testing.T) {
+		transferer := transfer.NewLocal(transfer.Options{})
+		assert.Equal(t, int64(0), transferer.GetSpeed())
+	})
+
+	t.Run("MultipleCloseCallsSafe", func(t *testing.T) {
+		transferer := transfer.NewLocal(transfer.Options{})
+		transferer.PrepareShutdown()
+		assert.NoError(t, transferer.Close())
+		assert.NoError(t, transferer.Close())
+	})
+}
+
+func TestLocalTestConnection(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		fixture := newLocalFixture(t, 16)
+		transferer := transfer.NewLocal(fixture.options())
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("MissingMountPath", func(t *testing.T) {
+		transferer := transfer.NewLocal(transfer.Options{
+			Local: transfer.LocalConfig{MountPath: filepath.Join(t.TempDir(), "missing")},
+		})
+
+		require.ErrorContains(t, transferer.TestConnection(context.Background()), "failed to read")
+	})
+}
+
+func TestLocalTransfer(t *testing.T) {
+	t.Run("Hardlink", func(t *testing.T) {
+		fixture := newLocalFixture(t, 1024)
+		transferer := transfer.NewLocal(fixture.options())
+
+		var lastProgress transfer.Progress
+		err := transferer.Transfer(context.Background(), fixture.req, func(p transfer.Progress) {
+			lastProgress = p
+		})
+		require.NoError(t, err)
+
+		src, err := os.Stat(filepath.Join(fixture.mountPath, "Show", "episode.mkv"))
+		require.NoError(t, err)
+		dst, err := os.Stat(fixture.req.LocalPath)
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(src, dst), "file should be hardlinked")
+		assert.Equal(t, int64(1024), lastProgress.Transferred)
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		fixture := newLocalFixture(t, 3*1024*1024+17)
+		opts := fixture.options()
+		opts.Local.NoHardlink = true
+		transferer := transfer.NewLocal(opts)
+
+		var lastProgress transfer.Progress
+		err := transferer.Transfer(context.Background(), fixture.req, func(p transfer.Progress) {
+			lastProgress = p
+		})
+		require.NoError(t, err)
+
+		downloaded, err := os.ReadFile(fixture.req.LocalPath)
+		require.NoError(t, err)
+		assert.Equal(t, fixture.content, downloaded)
+		assert.Equal(t, fixture.req.Size, lastProgress.Transferred)
+		assert.NoFileExists(t, fixture.req.LocalPath+".partial")
+
+		src, err := os.Stat(filepath.Join(fixture.mountPath, "Show", "episode.mkv"))
+		require.NoError(t, err)
+		dst, err := os.Stat(fixture.req.LocalPath)
+		require.NoError(t, err)
+		assert.False(t, os.SameFile(src, dst), "file should be copied")
+	})
+
+	t.Run("ReplacesExistingFile", func(t *testing.T) {
+		fixture := newLocalFixture(t, 1024)
+		transferer := transfer.NewLocal(fixture.options())
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(fixture.req.LocalPath), 0750))
+		require.NoError(t, os.WriteFile(fixture.req.LocalPath, []byte("stale"), 0600))
+		require.NoError(t, os.WriteFile(fixture.req.LocalPath+".partial", []byte("stale"), 0600))
+
+		require.NoError(t, transferer.Transfer(context.Background(), fixture.req, nil))
+
+		downloaded, err := os.ReadFile(fixture.req.LocalPath)
+		require.NoError(t, err)
+		assert.Equal(t, fixture.content, downloaded)
+	})
+
+	t.Run("WithoutMountPath", func(t *testing.T) {
+		fixture := newLocalFixture(t, 1024)
+		transferer := transfer.NewLocal(transfer.Options{})
+
+		req := fixture.req
+		req.RemotePath = filepath.Join(fixture.mountPath, "Show", "episode.mkv")
+		require.NoError(t, transferer.Transfer(context.Background(), req, nil))
+
+		downloaded, err := os.ReadFile(req.LocalPath)
+		require.NoError(t, err)
+		assert.Equal(t, fixture.content, downloaded)
+	})
+
+	t.Run("SourceMissing", func(t *testing.T) {
+		fixture := newLocalFixture(t, 1024)
+		transferer := transfer.NewLocal(fixture.options())
+
+		req := fixture.req
+		req.RemotePath = "/home/user/downloads/Show/missing.mkv"
+		err := transferer.Transfer(context.Background(), req, nil)
+		require.ErrorContains(t, err, "failed to open source file")
+		assert.NoFileExists(t, req.LocalPath+".partial")
+	})
+
+	t.Run("OutsideRemotePath", func(t *testing.T) {
+		fixture := newLocalFixture(t, 1024)
+		transferer := transfer.NewLocal(fixture.options())
+
+		req := fixture.req
+		req.RemotePath = "/etc/passwd"
+		require.ErrorContains(t, transferer.Transfer(context.Background(), req, nil),
+			"outside of the served directory")
+	})
+
+	t.Run("ContextCancellation", func(t *testing.T) {
+		fixture := newLocalFixture(t, 4*1024*1024)
+		opts := fixture.options()
+		opts.Local.NoHardlink = true
+		opts.SpeedLimit = 1024 * 1024
+		transferer := transfer.NewLocal(opts)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := transferer.Transfer(ctx, fixture.req, nil)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.NoFileExists(t, fixture.req.LocalPath)
+		assert.NoFileExists(t, fixture.req.LocalPath+".partial")
+	})
+}
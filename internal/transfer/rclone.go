@@ -7,11 +7,14 @@ import (
 	"log" //nolint:depguard // needed to suppress rclone's internal error logging during shutdown
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/config/obscure"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rs/zerolog"
 
@@ -23,7 +26,6 @@ import (
 // Default rclone configuration values.
 const (
 	rcloneDefaultParallelConnections = 8
-	rcloneDefaultSSHPort             = 22
 	rcloneDefaultProgressInterval    = 500 * time.Millisecond
 	rcloneDefaultChunkSize           = "64k"           // SFTP chunk size
 	rcloneBytesPerMB                 = 1 << 20         // 1024 * 1024
@@ -49,6 +51,7 @@ var rcloneNewFsMu sync.Mutex
 // It is private and only exposed via the Transferer interface.
 type rcloneTransferer struct {
 	ssh                 SSHConfig
+	dialer              sshDialer
 	parallelConnections int
 	speedLimit          int64
 	logger              zerolog.Logger
@@ -57,6 +60,9 @@ type rcloneTransferer struct {
 	sftpFs   fs.Fs
 	sftpOnce sync.Once
 	sftpErr  error
+
+	// Relays rclone's connections through the jump hosts, if there are any
+	relay *socksRelay
 }
 
 // setLogger implements configurable for shared options.
@@ -71,20 +77,11 @@ func NewRclone(opts Options, options ...Option) Transferer {
 		parallelConnections = rcloneDefaultParallelConnections
 	}
 
-	sshPort := opts.SSH.Port
-	if sshPort == 0 {
-		sshPort = rcloneDefaultSSHPort
-	}
+	dialer := newSSHDialer(opts.SSH)
 
 	t := &rcloneTransferer{
-		ssh: SSHConfig{
-			Host:           opts.SSH.Host,
-			Port:           sshPort,
-			User:           opts.SSH.User,
-			KeyFile:        opts.SSH.KeyFile,
-			KnownHostsFile: opts.SSH.KnownHostsFile,
-			IgnoreHostKey:  opts.SSH.IgnoreHostKey,
-		},
+		ssh:                 dialer.cfg,
+		dialer:              dialer,
 		parallelConnections: parallelConnections,
 		speedLimit:          opts.SpeedLimit,
 		logger:              zerolog.Nop(),
@@ -157,6 +154,9 @@ func (t *rcloneTransferer) Close() error {
 			_ = shutdowner.Shutdown(context.Background())
 		}
 	}
+	if t.relay != nil {
+		_ = t.relay.Close()
+	}
 	return nil
 }
 
@@ -172,6 +172,14 @@ func (t *rcloneTransferer) GetSpeed() int64 {
 	return 0
 }
 
+// TestConnection opens the SFTP filesystem, which connects and authenticates.
+func (t *rcloneTransferer) TestConnection(ctx context.Context) error {
+	if _, err := t.getSFTPFs(ctx); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", t.ssh.Host, err)
+	}
+	return nil
+}
+
 // getSFTPFs returns a cached SFTP filesystem or creates a new one.
 func (t *rcloneTransferer) getSFTPFs(ctx context.Context) (fs.Fs, error) {
 	t.sftpOnce.Do(func() {
@@ -182,31 +190,11 @@ func (t *rcloneTransferer) getSFTPFs(ctx context.Context) (fs.Fs, error) {
 
 // createSFTPFs creates a new SFTP filesystem.
 func (t *rcloneTransferer) createSFTPFs(ctx context.Context) (fs.Fs, error) {
-	// Build connection string using rclone's backend connection string format.
-	// Using fs.NewFs with a connection string ensures all defaults are applied properly.
-	// Format: :sftp,option=value,option2=value2:/path
-	// See: https://github.com/rclone/rclone/issues/8666
-	//
-	// Note: If known_hosts_file is not set, rclone uses ssh.InsecureIgnoreHostKey()
-	// which allows any host key. Only set it when we have an explicit file.
-	knownHostsOpt := ""
-	if !t.ssh.IgnoreHostKey && t.ssh.KnownHostsFile != "" {
-		knownHostsOpt = fmt.Sprintf(",known_hosts_file=%s", t.ssh.KnownHostsFile)
+	connStr, err := t.connectionString()
+	if err != nil {
+		return nil, err
 	}
 
-	connStr := fmt.Sprintf(
-		":sftp,host=%s,port=%d,user=%s,key_file=%s%s,"+
-			"concurrency=%d,chunk_size=%s,disable_hashcheck=true,"+
-			"set_modtime=false,skip_links=true,shell_type=none:/",
-		t.ssh.Host,
-		t.ssh.Port,
-		t.ssh.User,
-		t.ssh.KeyFile,
-		knownHostsOpt,
-		t.parallelConnections,
-		rcloneDefaultChunkSize,
-	)
-
 	// Serialize fs.NewFs calls to work around race conditions in rclone's config loading
 	rcloneNewFsMu.Lock()
 	sftpFs, err := fs.NewFs(ctx, connStr)
@@ -225,6 +213,73 @@ func (t *rcloneTransferer) createSFTPFs(ctx context.Context) (fs.Fs, error) {
 	return sftpFs, nil
 }
 
+// connectionString builds the rclone connection string of the SFTP filesystem,
+// starting the relay to the jump hosts if there are any.
+func (t *rcloneTransferer) connectionString() (string, error) {
+	// Build connection string using rclone's backend connection string format.
+	// Using fs.NewFs with a connection string ensures all defaults are applied properly.
+	// Format: :sftp,option=value,option2=value2:/path
+	// See: https://github.com/rclone/rclone/issues/8666
+	//
+	// Values that may contain commas or colons are quoted.
+	opts := []string{
+		"host=" + rcloneQuote(t.ssh.Host),
+		"port=" + strconv.Itoa(t.ssh.Port),
+		"user=" + rcloneQuote(t.ssh.User),
+	}
+
+	// Note: If known_hosts_file is not set, rclone uses ssh.InsecureIgnoreHostKey()
+	// which allows any host key. Only set it when we have an explicit file.
+	if !t.ssh.IgnoreHostKey && t.ssh.KnownHostsFile != "" {
+		opts = append(opts, "known_hosts_file="+rcloneQuote(t.ssh.KnownHostsFile))
+	}
+
+	// rclone expects passwords in its obscured form
+	if t.ssh.KeyFile != "" {
+		opts = append(opts, "key_file="+rcloneQuote(t.ssh.KeyFile))
+		passphrase, err := t.dialer.keyPassphrase()
+		if err != nil {
+			return "", err
+		}
+		if passphrase != "" {
+			opts = append(opts, "key_file_pass="+obscure.MustObscure(passphrase))
+		}
+	}
+	if t.ssh.CertFile != "" {
+		opts = append(opts, "pubkey_file="+rcloneQuote(t.ssh.CertFile))
+	}
+	if t.ssh.Password != "" {
+		opts = append(opts, "pass="+obscure.MustObscure(t.ssh.Password))
+	}
+	if t.ssh.UseAgent {
+		opts = append(opts, "key_use_agent=true")
+	}
+
+	if len(t.ssh.JumpHosts) > 0 {
+		relay, err := newSOCKSRelay(t.dialer.addr(), t.dialer.dialTCP, t.ssh.Timeout, t.logger)
+		if err != nil {
+			return "", err
+		}
+		t.relay = relay
+		opts = append(opts, "socks_proxy="+rcloneQuote(relay.addr()))
+	}
+
+	opts = append(opts,
+		"concurrency="+strconv.Itoa(t.parallelConnections),
+		"chunk_size="+rcloneDefaultChunkSize,
+		"disable_hashcheck=true",
+		"set_modtime=false",
+		"skip_links=true",
+		"shell_type=none",
+	)
+	return ":sftp," + strings.Join(opts, ",") + ":/", nil
+}
+
+// rcloneQuote quotes a connection string value, doubling any quotes in it.
+func rcloneQuote(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
+
 // Transfer copies a file from remote to local using rclone.
 func (t *rcloneTransferer) Transfer(ctx context.Context, req Request, onProgress ProgressFunc) error {
 	t.logger.Debug().
@@ -253,20 +308,76 @@ func (t *rcloneTransferer) Transfer(ctx context.Context, req Request, onProgress
 		return fmt.Errorf("failed to create local filesystem: %w", err)
 	}
 
-	// Get the remote file object from our configured SFTP filesystem
-	// RemotePath is absolute (starts with /), but sftpFs is rooted at /
-	// so we need to strip the leading slash
-	remotePath := req.RemotePath
-	if len(remotePath) > 0 && remotePath[0] == '/' {
-		remotePath = remotePath[1:]
+	srcObj, err := remoteObject(ctx, sftpFs, req.RemotePath)
+	if err != nil {
+		return err
+	}
+
+	return t.copyWithProgress(ctx, localFs, srcObj, filepath.Base(req.LocalPath), onProgress)
+}
+
+// TransferBatch copies a set of files using rclone, up to workers but no more
+// than parallelConnections at a time. All files share the SFTP connections and
+// one local filesystem rooted at the directory that contains them, so only the
+// copy is set up per file.
+func (t *rcloneTransferer) TransferBatch(
+	ctx context.Context,
+	reqs []Request,
+	workers int,
+	onProgress BatchProgressFunc,
+	onDone BatchDoneFunc,
+) error {
+	if len(reqs) == 0 {
+		return nil
 	}
 
-	srcObj, err := sftpFs.NewObject(ctx, remotePath)
+	t.logger.Debug().
+		Int("files", len(reqs)).
+		Msg("starting rclone batch transfer")
+
+	sftpFs, err := t.getSFTPFs(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get remote file %q: %w", req.RemotePath, err)
+		return fmt.Errorf("failed to get sftp filesystem: %w", err)
 	}
 
-	return t.copyWithProgress(ctx, localFs, srcObj, filepath.Base(req.LocalPath), onProgress)
+	root := localRoot(reqs)
+	if mkdirErr := os.MkdirAll(root, 0750); mkdirErr != nil {
+		return fmt.Errorf("failed to create local directory: %w", mkdirErr)
+	}
+
+	// Serialize fs.NewFs calls to work around race conditions in rclone's config loading
+	rcloneNewFsMu.Lock()
+	localFs, err := fs.NewFs(ctx, root)
+	rcloneNewFsMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to create local filesystem: %w", err)
+	}
+
+	return transferEach(ctx, reqs, min(workers, t.parallelConnections),
+		func(ctx context.Context, _ int, req Request, onFileProgress ProgressFunc) error {
+			rel, relErr := filepath.Rel(root, req.LocalPath)
+			if relErr != nil {
+				return fmt.Errorf("failed to resolve local path: %w", relErr)
+			}
+
+			srcObj, objErr := remoteObject(ctx, sftpFs, req.RemotePath)
+			if objErr != nil {
+				return objErr
+			}
+
+			return t.copyWithProgress(ctx, localFs, srcObj, filepath.ToSlash(rel), onFileProgress)
+		}, onProgress, onDone)
+}
+
+// remoteObject returns the object of a file on the SFTP filesystem.
+func remoteObject(ctx context.Context, sftpFs fs.Fs, remotePath string) (fs.Object, error) {
+	// RemotePath is absolute (starts with /), but sftpFs is rooted at /
+	// so we need to strip the leading slash
+	srcObj, err := sftpFs.NewObject(ctx, strings.TrimPrefix(remotePath, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote file %q: %w", remotePath, err)
+	}
+	return srcObj, nil
 }
 
 // copyWithProgress copies a file and reports progress using per-transfer stats.
@@ -691,6 +691,122 @@ func TestRcloneIntegration_ConcurrentTransfers(t *testing.T) {
 	})
 }
 
+// --- Batch Transfer Tests ---
+
+func TestRcloneIntegration_TransferBatch(t *testing.T) {
+	sshContainer := getTestSSHContainer(t)
+	ctx := context.Background()
+
+	t.Run("ManySmallFiles", func(t *testing.T) {
+		const numFiles = 50
+
+		tmpDir := t.TempDir()
+		reqs := make([]transfer.Request, numFiles)
+		contents := make([][]byte, numFiles)
+		for i := range numFiles {
+			contents[i] = []byte(fmt.Sprintf("track %d of the discography", i))
+			name := fmt.Sprintf("CD%d/track-%02d.flac", i%3+1, i)
+			remotePath := filepath.Join("batch", name)
+
+			err := sshContainer.CreateTestFile(ctx, remotePath, contents[i])
+			require.NoError(t, err, "failed to create test file %d", i)
+
+			reqs[i] = transfer.Request{
+				RemotePath: filepath.Join(sshContainer.RemoteDir, remotePath),
+				LocalPath:  filepath.Join(tmpDir, "Discography", name),
+				Size:       int64(len(contents[i])),
+			}
+		}
+
+		transferer := transfer.NewRclone(transfer.Options{
+			SSH: transfer.SSHConfig{
+				Host:          sshContainer.Host,
+				Port:          sshContainer.Port,
+				User:          sshContainer.User,
+				KeyFile:       sshContainer.PrivateKey,
+				IgnoreHostKey: true,
+			},
+			ParallelConnections: 4,
+		})
+		defer func() { _ = transferer.Close() }()
+
+		batcher, ok := transferer.(transfer.BatchTransferer)
+		require.True(t, ok, "rclone transferer should support batches")
+
+		var mu sync.Mutex
+		progress := make(map[int]int64)
+		results := make(map[int]error)
+		err := batcher.TransferBatch(ctx, reqs, 4,
+			func(i int, p transfer.Progress) {
+				mu.Lock()
+				defer mu.Unlock()
+				progress[i] = p.Transferred
+			},
+			func(i int, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				results[i] = err
+			})
+		require.NoError(t, err, "batch should succeed")
+
+		require.Len(t, results, numFiles, "every file should be reported done")
+		for i, req := range reqs {
+			require.NoError(t, results[i], "file %d should transfer", i)
+			assert.Equal(t, req.Size, progress[i], "file %d should report final progress", i)
+
+			downloadedContent, readErr := os.ReadFile(req.LocalPath)
+			require.NoError(t, readErr, "should be able to read file %d", i)
+			assert.Equal(t, contents[i], downloadedContent, "content of file %d should match", i)
+		}
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		content := []byte("present")
+		err := sshContainer.CreateTestFile(ctx, "batch-missing/present.txt", content)
+		require.NoError(t, err, "failed to create test file")
+
+		tmpDir := t.TempDir()
+		reqs := []transfer.Request{
+			{
+				RemotePath: filepath.Join(sshContainer.RemoteDir, "batch-missing/present.txt"),
+				LocalPath:  filepath.Join(tmpDir, "present.txt"),
+				Size:       int64(len(content)),
+			},
+			{
+				RemotePath: filepath.Join(sshContainer.RemoteDir, "batch-missing/absent.txt"),
+				LocalPath:  filepath.Join(tmpDir, "absent.txt"),
+				Size:       1024,
+			},
+		}
+
+		transferer := transfer.NewRclone(transfer.Options{
+			SSH: transfer.SSHConfig{
+				Host:          sshContainer.Host,
+				Port:          sshContainer.Port,
+				User:          sshContainer.User,
+				KeyFile:       sshContainer.PrivateKey,
+				IgnoreHostKey: true,
+			},
+		})
+		defer func() { _ = transferer.Close() }()
+
+		batcher, ok := transferer.(transfer.BatchTransferer)
+		require.True(t, ok, "rclone transferer should support batches")
+
+		var mu sync.Mutex
+		results := make(map[int]error)
+		err = batcher.TransferBatch(ctx, reqs, 4, nil, func(i int, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			results[i] = err
+		})
+		require.NoError(t, err, "batch should complete")
+
+		require.NoError(t, results[0], "present file should transfer")
+		require.ErrorContains(t, results[1], "failed to get remote file", "missing file should fail")
+	})
+}
+
 // --- GetSpeed Tests ---
 
 func TestRcloneIntegration_GetSpeed(t *testing.T) {
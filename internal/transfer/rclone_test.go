@@ -0,0 +1,98 @@
+package transfer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/seedreap/seedreap/internal/transfer"
+)
+
+func TestRcloneAuthentication(t *testing.T) {
+	server := startSFTPServer(t)
+
+	t.Run("Password", func(t *testing.T) {
+		opts := server.options()
+		opts.SSH.KeyFile = ""
+		opts.SSH.Password = sftpTestPassword
+		transferer := transfer.NewRclone(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("EncryptedKeyPassphraseFile", func(t *testing.T) {
+		keyFile, pub := writeKeyFile(t, "hunter2")
+		server.authorize(pub)
+		passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+		require.NoError(t, os.WriteFile(passphraseFile, []byte("hunter2\n"), 0600))
+		opts := server.options()
+		opts.SSH.KeyFile = keyFile
+		opts.SSH.KeyPassphraseFile = passphraseFile
+		transferer := transfer.NewRclone(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("Certificate", func(t *testing.T) {
+		keyFile, pub := writeKeyFile(t, "")
+		opts := server.options()
+		opts.SSH.KeyFile = keyFile
+		opts.SSH.CertFile = server.writeCertFile(t, pub, "testuser")
+		transferer := transfer.NewRclone(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		startSSHAgent(t, server.clientKey)
+		opts := server.options()
+		opts.SSH.KeyFile = ""
+		opts.SSH.UseAgent = true
+		transferer := transfer.NewRclone(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+}
+
+func TestRcloneJumpHost(t *testing.T) {
+	target := startSFTPServer(t)
+	jump := startSFTPServer(t)
+	clientPub, err := ssh.NewPublicKey(target.clientKey.Public())
+	require.NoError(t, err)
+	jump.authorize(clientPub)
+
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	lines := knownhosts.Line([]string{target.listener.Addr().String()}, target.hostKey) + "\n" +
+		knownhosts.Line([]string{jump.listener.Addr().String()}, jump.hostKey) + "\n"
+	require.NoError(t, os.WriteFile(knownHostsFile, []byte(lines), 0600))
+
+	opts := target.options()
+	opts.SSH.KnownHostsFile = knownHostsFile
+	opts.SSH.JumpHosts = []transfer.JumpHost{{Host: jump.host, Port: jump.port}}
+	transferer := transfer.NewRclone(opts)
+	defer transferer.Close()
+
+	remotePath, data := writeRandomFile(t, 64*1024)
+	localPath := filepath.Join(t.TempDir(), "file.bin")
+	err = transferer.Transfer(context.Background(), transfer.Request{
+		RemotePath: remotePath,
+		LocalPath:  localPath,
+		Size:       int64(len(data)),
+	}, nil)
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(localPath)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.Positive(t, jump.connections())
+}
@@ -0,0 +1,31 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// errOutsideRoot is returned for remote paths outside of the served directory.
+var errOutsideRoot = errors.New("remote path is outside of the served directory")
+
+// servedRoot returns the cleaned absolute path of the directory on the seedbox
+// that a server serves at its base URL.
+func servedRoot(remotePath string) string {
+	return path.Clean("/" + remotePath)
+}
+
+// relativeToRoot returns remotePath relative to root, as returned by
+// servedRoot. The result is empty for root itself.
+func relativeToRoot(root, remotePath string) (string, error) {
+	clean := path.Clean("/" + remotePath)
+	switch {
+	case root == "/":
+		return strings.TrimPrefix(clean, "/"), nil
+	case strings.HasPrefix(clean, root+"/"):
+		return strings.TrimPrefix(clean, root+"/"), nil
+	default:
+		return "", fmt.Errorf("%w: %s is not under %s", errOutsideRoot, remotePath, root)
+	}
+}
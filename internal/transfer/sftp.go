@@ -0,0 +1,432 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+// Default SFTP configuration values.
+const (
+	sftpDefaultParallelConnections = 8
+	sftpBufferSize                 = 256 * 1024      // Bytes read per request
+	sftpMinSegmentSize             = 10 * bytesPerMB // Don't split files if segments would be under 10MB
+)
+
+// errSFTPClosed is returned by transfers started after Close.
+var errSFTPClosed = errors.New("sftp transferer is closed")
+
+// sftpConn is an SSH connection with an SFTP session on top of it.
+type sftpConn struct {
+	ssh  *ssh.Client
+	sftp *sftp.Client
+}
+
+// close closes the SFTP session and its SSH connection.
+func (c *sftpConn) close() {
+	_ = c.sftp.Close()
+	_ = c.ssh.Close()
+}
+
+// sftpTransferer implements Transferer with a native SFTP client. Files large
+// enough are split into segments downloaded in parallel, each over its own SSH
+// connection. Connections are pooled and all state, including the speed limit,
+// belongs to the instance.
+// It is private and only exposed via the Transferer interface.
+type sftpTransferer struct {
+	ssh                 SSHConfig
+	dialer              sshDialer
+	parallelConnections int
+	speedLimit          int64
+	logger              zerolog.Logger
+
+	// Idle connections, reused by later transfers
+	mu     sync.Mutex
+	idle   []*sftpConn
+	closed bool
+
+	transfers    activeTransfers
+	shuttingDown atomic.Bool
+}
+
+// setLogger implements configurable for shared options.
+func (t *sftpTransferer) setLogger(logger zerolog.Logger) {
+	t.logger = logger
+}
+
+// NewSFTP creates a new native SFTP transferer and returns it as Transferer.
+func NewSFTP(opts Options, options ...Option) Transferer {
+	parallelConnections := opts.ParallelConnections
+	if parallelConnections == 0 {
+		parallelConnections = sftpDefaultParallelConnections
+	}
+
+	dialer := newSSHDialer(opts.SSH)
+
+	t := &sftpTransferer{
+		ssh:                 dialer.cfg,
+		dialer:              dialer,
+		parallelConnections: parallelConnections,
+		speedLimit:          opts.SpeedLimit,
+		logger:              zerolog.Nop(),
+	}
+
+	for _, opt := range options {
+		opt(t)
+	}
+
+	return t
+}
+
+// Name returns the name of the transfer backend.
+func (t *sftpTransferer) Name() string {
+	return string(BackendSFTP)
+}
+
+// PrepareShutdown suppresses transfer error logging during shutdown.
+func (t *sftpTransferer) PrepareShutdown() {
+	t.shuttingDown.Store(true)
+}
+
+// Close closes the idle connections. Connections in use by a transfer are
+// closed when it finishes.
+func (t *sftpTransferer) Close() error {
+	t.mu.Lock()
+	idle := t.idle
+	t.idle = nil
+	t.closed = true
+	t.mu.Unlock()
+
+	for _, c := range idle {
+		c.close()
+	}
+	return nil
+}
+
+// GetSpeed returns the sum of the current speeds of the active transfers.
+func (t *sftpTransferer) GetSpeed() int64 {
+	return t.transfers.speed()
+}
+
+// TestConnection connects and logs into the SFTP server.
+func (t *sftpTransferer) TestConnection(ctx context.Context) error {
+	c, err := t.getConn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", t.ssh.Host, err)
+	}
+	t.putConn(c)
+	return nil
+}
+
+// getConn returns an idle connection or dials a new one.
+func (t *sftpTransferer) getConn(ctx context.Context) (*sftpConn, error) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, errSFTPClosed
+	}
+	if n := len(t.idle); n > 0 {
+		c := t.idle[n-1]
+		t.idle = t.idle[:n-1]
+		t.mu.Unlock()
+		return c, nil
+	}
+	t.mu.Unlock()
+
+	return t.dial(ctx)
+}
+
+// putConn returns a healthy connection to the pool.
+func (t *sftpTransferer) putConn(c *sftpConn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed || len(t.idle) >= t.parallelConnections {
+		c.close()
+		return
+	}
+	t.idle = append(t.idle, c)
+}
+
+// dial opens an SSH connection and starts an SFTP session on it.
+func (t *sftpTransferer) dial(ctx context.Context) (*sftpConn, error) {
+	sshClient, err := t.dialer.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sftpClient, err := sftp.NewClient(sshClient,
+		sftp.UseConcurrentReads(true),
+		sftp.MaxConcurrentRequestsPerFile(t.parallelConnections),
+	)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, fmt.Errorf("failed to start sftp session: %w", err)
+	}
+
+	t.logger.Debug().
+		Str("host", t.ssh.Host).
+		Int("port", t.ssh.Port).
+		Str("user", t.ssh.User).
+		Msg("sftp connection established")
+
+	return &sftpConn{ssh: sshClient, sftp: sftpClient}, nil
+}
+
+// TransferBatch copies a set of files, up to workers but no more than
+// parallelConnections at a time. Each worker takes one pooled connection and
+// reads its files over it one after another, each in one piece.
+func (t *sftpTransferer) TransferBatch(
+	ctx context.Context,
+	reqs []Request,
+	workers int,
+	onProgress BatchProgressFunc,
+	onDone BatchDoneFunc,
+) error {
+	conns := make([]*sftpConn, min(max(workers, 1), t.parallelConnections))
+	defer func() {
+		for _, c := range conns {
+			if c != nil {
+				t.putConn(c)
+			}
+		}
+	}()
+
+	return transferEach(ctx, reqs, len(conns),
+		func(ctx context.Context, worker int, req Request, onFileProgress ProgressFunc) error {
+			return t.transfer(ctx, req, onFileProgress,
+				func(ctx context.Context, req Request, f *os.File, stats *transferStats) error {
+					return t.downloadOver(ctx, &conns[worker], req, f, stats)
+				})
+		}, onProgress, onDone)
+}
+
+// Transfer copies a file from remote to local. The file is written next to
+// LocalPath with a .partial suffix and renamed once complete.
+func (t *sftpTransferer) Transfer(ctx context.Context, req Request, onProgress ProgressFunc) error {
+	return t.transfer(ctx, req, onProgress, t.download)
+}
+
+// transfer copies a file from remote to local, reading it into the partial
+// file with download.
+func (t *sftpTransferer) transfer(
+	ctx context.Context,
+	req Request,
+	onProgress ProgressFunc,
+	download func(context.Context, Request, *os.File, *transferStats) error,
+) error {
+	t.logger.Debug().
+		Str("remote", req.RemotePath).
+		Str("local", req.LocalPath).
+		Int64("size", req.Size).
+		Msg("starting sftp transfer")
+
+	if err := os.MkdirAll(filepath.Dir(req.LocalPath), 0750); err != nil {
+		return fmt.Errorf("failed to create local directory: %w", err)
+	}
+
+	partialPath := req.LocalPath + partialSuffix
+	f, err := os.OpenFile(partialPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+
+	stats := t.transfers.start()
+	defer t.transfers.finish(stats)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	startTime := time.Now()
+	wg.Go(func() {
+		monitorProgress(stats, onProgress, done)
+	})
+
+	err = download(ctx, req, f, stats)
+
+	close(done)
+	wg.Wait()
+
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close local file: %w", closeErr)
+	}
+	if err == nil {
+		if renameErr := os.Rename(partialPath, req.LocalPath); renameErr != nil {
+			err = fmt.Errorf("failed to rename local file: %w", renameErr)
+		}
+	}
+	if err != nil {
+		_ = os.Remove(partialPath)
+		if !t.shuttingDown.Load() {
+			t.logger.Debug().Err(err).Str("remote", req.RemotePath).Msg("sftp transfer failed")
+		}
+		return err
+	}
+
+	elapsed := time.Since(startTime).Seconds()
+	var speed int64
+	if elapsed > 0 {
+		speed = int64(float64(req.Size) / elapsed)
+	}
+
+	if onProgress != nil {
+		onProgress(Progress{
+			Transferred: req.Size,
+			BytesPerSec: speed,
+		})
+	}
+
+	t.logger.Debug().
+		Str("file", req.RemotePath).
+		Int64("size", req.Size).
+		Float64("speed_mbps", float64(speed)/bytesPerMB).
+		Msg("sftp transfer complete")
+
+	return nil
+}
+
+// download reads the remote file into f, splitting it into segments that are
+// read in parallel over separate connections.
+func (t *sftpTransferer) download(ctx context.Context, req Request, f *os.File, stats *transferStats) error {
+	if err := f.Truncate(req.Size); err != nil {
+		return fmt.Errorf("failed to allocate local file: %w", err)
+	}
+
+	// The limiter is shared by the segments so the limit applies to the whole file
+	limiter := newLimiter(t.speedLimit, sftpBufferSize)
+
+	g, gctx := errgroup.WithContext(ctx)
+	for _, seg := range splitSegments(req.Size, t.parallelConnections, sftpMinSegmentSize) {
+		g.Go(func() error {
+			return t.downloadSegment(gctx, req.RemotePath, f, seg, limiter, stats)
+		})
+	}
+	return g.Wait()
+}
+
+// downloadSegment copies a segment of the remote file into f.
+func (t *sftpTransferer) downloadSegment(
+	ctx context.Context,
+	remotePath string,
+	f *os.File,
+	seg segment,
+	limiter *rate.Limiter,
+	stats *transferStats,
+) error {
+	c, err := t.getConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Closing the connection is the only way to interrupt a blocked read
+	stop := context.AfterFunc(ctx, c.close)
+
+	err = copySFTPSegment(ctx, c, remotePath, f, seg, limiter, stats)
+	if !stop() {
+		// The connection was closed by the cancellation
+		return ctx.Err()
+	}
+	if err != nil {
+		c.close()
+		return err
+	}
+	t.putConn(c)
+	return nil
+}
+
+// downloadOver reads the remote file into f in one piece over *c, taking a
+// pooled connection if *c is nil. The connection is kept in *c for the next
+// file, unless the read failed.
+func (t *sftpTransferer) downloadOver(
+	ctx context.Context,
+	c **sftpConn,
+	req Request,
+	f *os.File,
+	stats *transferStats,
+) error {
+	if err := f.Truncate(req.Size); err != nil {
+		return fmt.Errorf("failed to allocate local file: %w", err)
+	}
+
+	if *c == nil {
+		conn, err := t.getConn(ctx)
+		if err != nil {
+			return err
+		}
+		*c = conn
+	}
+	conn := *c
+
+	// Closing the connection is the only way to interrupt a blocked read
+	stop := context.AfterFunc(ctx, conn.close)
+
+	limiter := newLimiter(t.speedLimit, sftpBufferSize)
+	err := copySFTPSegment(ctx, conn, req.RemotePath, f, segment{End: req.Size}, limiter, stats)
+	if !stop() {
+		// The connection was closed by the cancellation
+		*c = nil
+		return ctx.Err()
+	}
+	if err != nil {
+		conn.close()
+		*c = nil
+		return err
+	}
+	return nil
+}
+
+// copySFTPSegment reads a segment of the remote file over c and writes it to f.
+func copySFTPSegment(
+	ctx context.Context,
+	c *sftpConn,
+	remotePath string,
+	f *os.File,
+	seg segment,
+	limiter *rate.Limiter,
+	stats *transferStats,
+) error {
+	src, err := c.sftp.Open(remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file %q: %w", remotePath, err)
+	}
+	defer src.Close()
+
+	buf := make([]byte, sftpBufferSize)
+	for offset := seg.Start; offset < seg.End; {
+		n := int(min(int64(len(buf)), seg.End-offset))
+		if limiter != nil {
+			if err = limiter.WaitN(ctx, n); err != nil {
+				return err
+			}
+		}
+
+		read, readErr := src.ReadAt(buf[:n], offset)
+		if read > 0 {
+			if _, err = f.WriteAt(buf[:read], offset); err != nil {
+				return fmt.Errorf("failed to write local file: %w", err)
+			}
+			offset += int64(read)
+			stats.transferred.Add(int64(read))
+		}
+		if readErr != nil {
+			if errors.Is(readErr, io.EOF) && offset < seg.End {
+				return fmt.Errorf("remote file %q is shorter than expected", remotePath)
+			}
+			if !errors.Is(readErr, io.EOF) {
+				return fmt.Errorf("failed to read remote file %q: %w", remotePath, readErr)
+			}
+		}
+	}
+	return nil
+}
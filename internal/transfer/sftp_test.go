@@ -0,0 +1,892 @@
+package transfer_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/seedreap/seedreap/internal/transfer"
+)
+
+// sftpTestServer is an in-process SSH server with an SFTP subsystem serving the
+// local filesystem.
+type sftpTestServer struct {
+	listener       net.Listener
+	host           string
+	port           int
+	keyFile        string
+	knownHostsFile string
+	hostKey        ssh.PublicKey
+	clientKey      ed25519.PrivateKey
+	ca             ssh.Signer // Signs user certificates the server accepts
+
+	mu         sync.Mutex
+	conns      int
+	authorized []ssh.PublicKey
+}
+
+// sftpTestPassword is the password the test server accepts.
+const sftpTestPassword = "testpassword"
+
+// startSFTPServer starts an SFTP server that accepts a freshly generated client
+// key, sftpTestPassword and certificates signed by its CA. It forwards
+// direct-tcpip channels, so it can also be used as a jump host.
+func startSFTPServer(t *testing.T) *sftpTestServer {
+	t.Helper()
+	dir := t.TempDir()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	clientPub, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	clientSSHPub, err := ssh.NewPublicKey(clientPub)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	require.NoError(t, err)
+	keyFile := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600))
+
+	_, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	caSigner, err := ssh.NewSignerFromKey(caPriv)
+	require.NoError(t, err)
+
+	s := &sftpTestServer{
+		keyFile:    keyFile,
+		hostKey:    hostSigner.PublicKey(),
+		clientKey:  clientPriv,
+		ca:         caSigner,
+		authorized: []ssh.PublicKey{clientSSHPub},
+	}
+
+	certChecker := &ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return bytes.Equal(auth.Marshal(), caSigner.PublicKey().Marshal())
+		},
+		UserKeyFallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if s.isAuthorized(key) {
+				return &ssh.Permissions{}, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	serverCfg := &ssh.ServerConfig{
+		PublicKeyCallback: certChecker.Authenticate,
+		PasswordCallback: func(_ ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) == sftpTestPassword {
+				return &ssh.Permissions{}, nil
+			}
+			return nil, errors.New("wrong password")
+		},
+	}
+	serverCfg.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	require.True(t, ok)
+
+	knownHostsFile := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{listener.Addr().String()}, hostSigner.PublicKey())
+	require.NoError(t, os.WriteFile(knownHostsFile, []byte(line+"\n"), 0600))
+
+	s.listener = listener
+	s.host = addr.IP.String()
+	s.port = addr.Port
+	s.knownHostsFile = knownHostsFile
+
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(conn, serverCfg)
+		}
+	}()
+
+	return s
+}
+
+// serve handles an SSH connection, starting an SFTP server for each sftp subsystem request.
+func (s *sftpTestServer) serve(conn net.Conn, cfg *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChan := range chans {
+		if newChan.ChannelType() == "direct-tcpip" {
+			go forwardChannel(newChan)
+			continue
+		}
+		if newChan.ChannelType() != "session" {
+			_ = newChan.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		channel, requests, acceptErr := newChan.Accept()
+		if acceptErr != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if ok {
+					server, serverErr := sftp.NewServer(channel)
+					if serverErr != nil {
+						return
+					}
+					_ = server.Serve()
+					_ = channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+// forwardChannel connects a direct-tcpip channel to the address it asks for.
+func forwardChannel(newChan ssh.NewChannel) {
+	var target struct {
+		Host     string
+		Port     uint32
+		OrigHost string
+		OrigPort uint32
+	}
+	if err := ssh.Unmarshal(newChan.ExtraData(), &target); err != nil {
+		_ = newChan.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+	if err != nil {
+		_ = newChan.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChan.Accept()
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	go func() {
+		_, _ = io.Copy(channel, conn)
+		_ = channel.CloseWrite()
+	}()
+	_, _ = io.Copy(conn, channel)
+	_ = conn.Close()
+	_ = channel.Close()
+}
+
+// authorize makes the server accept key.
+func (s *sftpTestServer) authorize(key ssh.PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authorized = append(s.authorized, key)
+}
+
+// isAuthorized reports whether the server accepts key.
+func (s *sftpTestServer) isAuthorized(key ssh.PublicKey) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, authorized := range s.authorized {
+		if bytes.Equal(key.Marshal(), authorized.Marshal()) {
+			return true
+		}
+	}
+	return false
+}
+
+// connections returns the number of SSH connections accepted so far.
+func (s *sftpTestServer) connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conns
+}
+
+// options returns transfer options for connecting to the server.
+func (s *sftpTestServer) options() transfer.Options {
+	return transfer.Options{
+		SSH: transfer.SSHConfig{
+			Host:           s.host,
+			Port:           s.port,
+			User:           "testuser",
+			KeyFile:        s.keyFile,
+			KnownHostsFile: s.knownHostsFile,
+		},
+		ParallelConnections: 4,
+	}
+}
+
+// writeKeyFile writes a new private key, encrypted with passphrase if it is
+// set, and returns its path and public key.
+func writeKeyFile(t *testing.T, passphrase string) (string, ssh.PublicKey) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+
+	var block *pem.Block
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(priv, "")
+	}
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600))
+	return keyFile, sshPub
+}
+
+// writeCertFile signs a user certificate for key with the server's CA and
+// returns the path of the certificate file.
+func (s *sftpTestServer) writeCertFile(t *testing.T, key ssh.PublicKey, principal string) string {
+	t.Helper()
+
+	cert := &ssh.Certificate{
+		Key:             key,
+		CertType:        ssh.UserCert,
+		KeyId:           "test",
+		ValidPrincipals: []string{principal},
+		ValidBefore:     ssh.CertTimeInfinity,
+	}
+	require.NoError(t, cert.SignCert(rand.Reader, s.ca))
+
+	certFile := filepath.Join(t.TempDir(), "id_ed25519-cert.pub")
+	require.NoError(t, os.WriteFile(certFile, ssh.MarshalAuthorizedKey(cert), 0600))
+	return certFile
+}
+
+// startSSHAgent serves an ssh-agent holding key and points SSH_AUTH_SOCK at it.
+func startSSHAgent(t *testing.T, key ed25519.PrivateKey) {
+	t.Helper()
+
+	// Unix socket paths are limited in length, so avoid the long test temp dir
+	dir, err := os.MkdirTemp("", "agent")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: key}))
+
+	socket := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go func() {
+				_ = agent.ServeAgent(keyring, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	t.Setenv("SSH_AUTH_SOCK", socket)
+}
+
+// writeRandomFile writes size random bytes to a new file and returns its path and content.
+func writeRandomFile(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "remote-"+strconv.Itoa(size)+".bin")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path, data
+}
+
+func TestNewSFTP(t *testing.T) {
+	t.Run("Name", func(t *testing.T) {
+		transferer := transfer.NewSFTP(transfer.Options{})
+		assert.Equal(t, "sftp", transferer.Name())
+		assert.Equal(t, transfer.BackendSFTP, transfer.Backend(transferer.Name()))
+	})
+
+	t.Run("NoSpeedWhenIdle", func(t *testing.T) {
+		transferer := transfer.NewSFTP(transfer.Options{})
+		assert.Equal(t, int64(0), transferer.GetSpeed())
+	})
+
+	t.Run("MultipleCloseCallsSafe", func(t *testing.T) {
+		transferer := transfer.NewSFTP(transfer.Options{})
+		transferer.PrepareShutdown()
+		assert.NoError(t, transferer.Close())
+		assert.NoError(t, transferer.Close())
+	})
+}
+
+func TestSFTPTestConnection(t *testing.T) {
+	server := startSFTPServer(t)
+
+	t.Run("Success", func(t *testing.T) {
+		transferer := transfer.NewSFTP(server.options())
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("IgnoreHostKey", func(t *testing.T) {
+		opts := server.options()
+		opts.SSH.KnownHostsFile = ""
+		opts.SSH.IgnoreHostKey = true
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("UnknownHostKey", func(t *testing.T) {
+		opts := server.options()
+		opts.SSH.KnownHostsFile = filepath.Join(t.TempDir(), "known_hosts")
+		require.NoError(t, os.WriteFile(opts.SSH.KnownHostsFile, nil, 0600))
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.Error(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("MissingKeyFile", func(t *testing.T) {
+		opts := server.options()
+		opts.SSH.KeyFile = filepath.Join(t.TempDir(), "nonexistent_key")
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		err := transferer.TestConnection(context.Background())
+		require.ErrorContains(t, err, "failed to read key file")
+	})
+
+	t.Run("ConnectionRefused", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		opts := server.options()
+		_, port, err := net.SplitHostPort(addr)
+		require.NoError(t, err)
+		opts.SSH.Port, err = strconv.Atoi(port)
+		require.NoError(t, err)
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.Error(t, transferer.TestConnection(context.Background()))
+	})
+}
+
+func TestSFTPAuthentication(t *testing.T) {
+	server := startSFTPServer(t)
+
+	t.Run("Password", func(t *testing.T) {
+		opts := server.options()
+		opts.SSH.KeyFile = ""
+		opts.SSH.Password = sftpTestPassword
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("WrongPassword", func(t *testing.T) {
+		opts := server.options()
+		opts.SSH.KeyFile = ""
+		opts.SSH.Password = "wrong"
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.Error(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("PasswordAfterUnknownKey", func(t *testing.T) {
+		keyFile, _ := writeKeyFile(t, "")
+		opts := server.options()
+		opts.SSH.KeyFile = keyFile
+		opts.SSH.Password = sftpTestPassword
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("EncryptedKey", func(t *testing.T) {
+		keyFile, pub := writeKeyFile(t, "hunter2")
+		server.authorize(pub)
+		opts := server.options()
+		opts.SSH.KeyFile = keyFile
+		opts.SSH.KeyPassphrase = "hunter2"
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("EncryptedKeyPassphraseFile", func(t *testing.T) {
+		keyFile, pub := writeKeyFile(t, "hunter2")
+		server.authorize(pub)
+		passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+		require.NoError(t, os.WriteFile(passphraseFile, []byte("hunter2\n"), 0600))
+		opts := server.options()
+		opts.SSH.KeyFile = keyFile
+		opts.SSH.KeyPassphraseFile = passphraseFile
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("EncryptedKeyWithoutPassphrase", func(t *testing.T) {
+		keyFile, _ := writeKeyFile(t, "hunter2")
+		opts := server.options()
+		opts.SSH.KeyFile = keyFile
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		err := transferer.TestConnection(context.Background())
+		require.ErrorContains(t, err, "key file is encrypted and no passphrase is set")
+	})
+
+	t.Run("EncryptedKeyWrongPassphrase", func(t *testing.T) {
+		keyFile, _ := writeKeyFile(t, "hunter2")
+		opts := server.options()
+		opts.SSH.KeyFile = keyFile
+		opts.SSH.KeyPassphrase = "wrong"
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		err := transferer.TestConnection(context.Background())
+		require.ErrorContains(t, err, "failed to parse key file")
+	})
+
+	t.Run("Certificate", func(t *testing.T) {
+		keyFile, pub := writeKeyFile(t, "")
+		opts := server.options()
+		opts.SSH.KeyFile = keyFile
+		opts.SSH.CertFile = server.writeCertFile(t, pub, "testuser")
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("CertificateForOtherUser", func(t *testing.T) {
+		keyFile, pub := writeKeyFile(t, "")
+		opts := server.options()
+		opts.SSH.KeyFile = keyFile
+		opts.SSH.CertFile = server.writeCertFile(t, pub, "otheruser")
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.Error(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("CertificateForOtherKey", func(t *testing.T) {
+		keyFile, _ := writeKeyFile(t, "")
+		_, otherPub := writeKeyFile(t, "")
+		opts := server.options()
+		opts.SSH.KeyFile = keyFile
+		opts.SSH.CertFile = server.writeCertFile(t, otherPub, "testuser")
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		err := transferer.TestConnection(context.Background())
+		require.ErrorContains(t, err, "certificate does not match key file")
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		startSSHAgent(t, server.clientKey)
+		opts := server.options()
+		opts.SSH.KeyFile = ""
+		opts.SSH.UseAgent = true
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("AgentWithoutSocket", func(t *testing.T) {
+		t.Setenv("SSH_AUTH_SOCK", "")
+		opts := server.options()
+		opts.SSH.KeyFile = ""
+		opts.SSH.UseAgent = true
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		err := transferer.TestConnection(context.Background())
+		require.ErrorContains(t, err, "SSH_AUTH_SOCK is not set")
+	})
+}
+
+func TestSFTPJumpHost(t *testing.T) {
+	target := startSFTPServer(t)
+	jump := startSFTPServer(t)
+	clientPub, err := ssh.NewPublicKey(target.clientKey.Public())
+	require.NoError(t, err)
+	jump.authorize(clientPub)
+
+	// One known hosts file for both servers
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	lines := knownhosts.Line([]string{target.listener.Addr().String()}, target.hostKey) + "\n" +
+		knownhosts.Line([]string{jump.listener.Addr().String()}, jump.hostKey) + "\n"
+	require.NoError(t, os.WriteFile(knownHostsFile, []byte(lines), 0600))
+
+	t.Run("Transfer", func(t *testing.T) {
+		opts := target.options()
+		opts.SSH.KnownHostsFile = knownHostsFile
+		opts.SSH.JumpHosts = []transfer.JumpHost{{Host: jump.host, Port: jump.port}}
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		remotePath, data := writeRandomFile(t, 64*1024)
+		localPath := filepath.Join(t.TempDir(), "file.bin")
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: remotePath,
+			LocalPath:  localPath,
+			Size:       int64(len(data)),
+		}, nil)
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+		assert.Positive(t, jump.connections())
+	})
+
+	t.Run("JumpHostUser", func(t *testing.T) {
+		opts := target.options()
+		opts.SSH.KeyFile = ""
+		opts.SSH.Password = sftpTestPassword
+		opts.SSH.KnownHostsFile = knownHostsFile
+		opts.SSH.JumpHosts = []transfer.JumpHost{{Host: jump.host, Port: jump.port, User: "jumpuser"}}
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		require.NoError(t, transferer.TestConnection(context.Background()))
+	})
+
+	t.Run("UnknownJumpHostKey", func(t *testing.T) {
+		opts := target.options()
+		opts.SSH.JumpHosts = []transfer.JumpHost{{Host: jump.host, Port: jump.port}}
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		err := transferer.TestConnection(context.Background())
+		require.ErrorContains(t, err, "jump host")
+	})
+}
+
+func TestSFTPTransfer(t *testing.T) {
+	server := startSFTPServer(t)
+
+	t.Run("SmallFile", func(t *testing.T) {
+		remotePath, data := writeRandomFile(t, 100*1024)
+		localPath := filepath.Join(t.TempDir(), "nested", "file.bin")
+
+		transferer := transfer.NewSFTP(server.options())
+		defer transferer.Close()
+
+		var last transfer.Progress
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: remotePath,
+			LocalPath:  localPath,
+			Size:       int64(len(data)),
+		}, func(p transfer.Progress) { last = p })
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+		assert.Equal(t, int64(len(data)), last.Transferred)
+		assert.NoFileExists(t, localPath+".partial")
+	})
+
+	t.Run("EmptyFile", func(t *testing.T) {
+		remotePath, _ := writeRandomFile(t, 0)
+		localPath := filepath.Join(t.TempDir(), "empty.bin")
+
+		transferer := transfer.NewSFTP(server.options())
+		defer transferer.Close()
+
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: remotePath,
+			LocalPath:  localPath,
+		}, nil)
+		require.NoError(t, err)
+
+		info, err := os.Stat(localPath)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), info.Size())
+	})
+
+	t.Run("SegmentedFile", func(t *testing.T) {
+		// Large enough for 3 segments of at least 10MB
+		remotePath, data := writeRandomFile(t, 30*1024*1024+12345)
+		localPath := filepath.Join(t.TempDir(), "large.bin")
+
+		transferer := transfer.NewSFTP(server.options())
+		defer transferer.Close()
+
+		before := server.connections()
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: remotePath,
+			LocalPath:  localPath,
+			Size:       int64(len(data)),
+		}, nil)
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(localPath)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(data, got), "content mismatch")
+		assert.Equal(t, 3, server.connections()-before)
+
+		// The connections are reused by the next transfer
+		before = server.connections()
+		require.NoError(t, transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: remotePath,
+			LocalPath:  filepath.Join(t.TempDir(), "again.bin"),
+			Size:       int64(len(data)),
+		}, nil))
+		assert.Equal(t, 0, server.connections()-before)
+	})
+
+	t.Run("SpeedLimit", func(t *testing.T) {
+		remotePath, data := writeRandomFile(t, 1024*1024)
+		localPath := filepath.Join(t.TempDir(), "limited.bin")
+
+		opts := server.options()
+		opts.SpeedLimit = 512 * 1024
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		// The first 512KB are a burst, the rest takes a second
+		start := time.Now()
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: remotePath,
+			LocalPath:  localPath,
+			Size:       int64(len(data)),
+		}, nil)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 800*time.Millisecond)
+	})
+
+	t.Run("RemoteFileMissing", func(t *testing.T) {
+		localPath := filepath.Join(t.TempDir(), "missing.bin")
+
+		transferer := transfer.NewSFTP(server.options())
+		defer transferer.Close()
+
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: filepath.Join(t.TempDir(), "nonexistent.bin"),
+			LocalPath:  localPath,
+			Size:       1024,
+		}, nil)
+		require.Error(t, err)
+		assert.NoFileExists(t, localPath)
+		assert.NoFileExists(t, localPath+".partial")
+	})
+
+	t.Run("RemoteFileShorter", func(t *testing.T) {
+		remotePath, data := writeRandomFile(t, 1024)
+		localPath := filepath.Join(t.TempDir(), "short.bin")
+
+		transferer := transfer.NewSFTP(server.options())
+		defer transferer.Close()
+
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: remotePath,
+			LocalPath:  localPath,
+			Size:       int64(len(data) * 2),
+		}, nil)
+		require.ErrorContains(t, err, "shorter than expected")
+		assert.NoFileExists(t, localPath)
+	})
+
+	t.Run("ContextCancellation", func(t *testing.T) {
+		remotePath, data := writeRandomFile(t, 1024*1024)
+		localPath := filepath.Join(t.TempDir(), "cancelled.bin")
+
+		opts := server.options()
+		opts.SpeedLimit = 256 * 1024
+		transferer := transfer.NewSFTP(opts)
+		defer transferer.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		time.AfterFunc(200*time.Millisecond, cancel)
+
+		err := transferer.Transfer(ctx, transfer.Request{
+			RemotePath: remotePath,
+			LocalPath:  localPath,
+			Size:       int64(len(data)),
+		}, nil)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.NoFileExists(t, localPath)
+		assert.NoFileExists(t, localPath+".partial")
+	})
+
+	t.Run("AfterClose", func(t *testing.T) {
+		remotePath, data := writeRandomFile(t, 1024)
+
+		transferer := transfer.NewSFTP(server.options())
+		require.NoError(t, transferer.Close())
+
+		err := transferer.Transfer(context.Background(), transfer.Request{
+			RemotePath: remotePath,
+			LocalPath:  filepath.Join(t.TempDir(), "closed.bin"),
+			Size:       int64(len(data)),
+		}, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestSFTPTransferBatch(t *testing.T) {
+	server := startSFTPServer(t)
+
+	t.Run("ManyFiles", func(t *testing.T) {
+		remoteDir := t.TempDir()
+		localDir := filepath.Join(t.TempDir(), "Discography")
+
+		const count = 40
+		reqs := make([]transfer.Request, count)
+		contents := make([][]byte, count)
+		for i := range count {
+			contents[i] = make([]byte, 1024+i)
+			_, err := rand.Read(contents[i])
+			require.NoError(t, err)
+
+			name := fmt.Sprintf("CD%d/track-%02d.flac", i%2+1, i)
+			remotePath := filepath.Join(remoteDir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(remotePath), 0750))
+			require.NoError(t, os.WriteFile(remotePath, contents[i], 0600))
+
+			reqs[i] = transfer.Request{
+				RemotePath: remotePath,
+				LocalPath:  filepath.Join(localDir, name),
+				Size:       int64(len(contents[i])),
+			}
+		}
+
+		transferer := transfer.NewSFTP(server.options())
+		defer transferer.Close()
+		batcher, ok := transferer.(transfer.BatchTransferer)
+		require.True(t, ok, "sftp transferer should support batches")
+
+		var mu sync.Mutex
+		progress := make(map[int]int64)
+		results := make(map[int]error)
+
+		before := server.connections()
+		err := batcher.TransferBatch(context.Background(), reqs, 2,
+			func(i int, p transfer.Progress) {
+				mu.Lock()
+				defer mu.Unlock()
+				progress[i] = p.Transferred
+			},
+			func(i int, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				results[i] = err
+			})
+		require.NoError(t, err)
+
+		require.Len(t, results, count)
+		for i, req := range reqs {
+			require.NoError(t, results[i])
+			assert.Equal(t, req.Size, progress[i])
+
+			got, readErr := os.ReadFile(req.LocalPath)
+			require.NoError(t, readErr)
+			assert.Equal(t, contents[i], got)
+		}
+
+		// Each of the two workers reads its files over one connection
+		assert.LessOrEqual(t, server.connections()-before, 2)
+	})
+
+	t.Run("FileErrors", func(t *testing.T) {
+		remotePath, data := writeRandomFile(t, 1024)
+		reqs := []transfer.Request{
+			{RemotePath: remotePath, LocalPath: filepath.Join(t.TempDir(), "ok.bin"), Size: int64(len(data))},
+			{
+				RemotePath: filepath.Join(t.TempDir(), "nonexistent.bin"),
+				LocalPath:  filepath.Join(t.TempDir(), "missing.bin"),
+				Size:       1024,
+			},
+		}
+
+		transferer := transfer.NewSFTP(server.options())
+		defer transferer.Close()
+		batcher, ok := transferer.(transfer.BatchTransferer)
+		require.True(t, ok, "sftp transferer should support batches")
+
+		var mu sync.Mutex
+		results := make(map[int]error)
+		err := batcher.TransferBatch(context.Background(), reqs, 2, nil, func(i int, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			results[i] = err
+		})
+		require.NoError(t, err)
+
+		require.Len(t, results, 2)
+		require.NoError(t, results[0])
+		require.Error(t, results[1])
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		remotePath, data := writeRandomFile(t, 1024)
+		reqs := []transfer.Request{
+			{RemotePath: remotePath, LocalPath: filepath.Join(t.TempDir(), "file.bin"), Size: int64(len(data))},
+		}
+
+		transferer := transfer.NewSFTP(server.options())
+		defer transferer.Close()
+		batcher, ok := transferer.(transfer.BatchTransferer)
+		require.True(t, ok, "sftp transferer should support batches")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := batcher.TransferBatch(ctx, reqs, 1, nil, func(_ int, err error) {
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,271 @@
+package transfer
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// SOCKS5 protocol values used by the relay (RFC 1928).
+const (
+	socksVersion        = 0x05
+	socksNoAuth         = 0x00
+	socksNoAcceptable   = 0xff
+	socksCmdConnect     = 0x01
+	socksAddrIPv4       = 0x01
+	socksAddrDomain     = 0x03
+	socksAddrIPv6       = 0x04
+	socksReplySucceeded = 0x00
+	socksReplyFailure   = 0x01
+	socksReplyNotAllow  = 0x02
+	socksReplyCmdNotSup = 0x07
+)
+
+// socksDialFunc opens the upstream connection for a relayed connection.
+type socksDialFunc func(ctx context.Context, addr string) (net.Conn, error)
+
+// socksRelay is a SOCKS5 server on the loopback interface that only accepts
+// CONNECT requests to a single address and opens them with dial. It lets
+// rclone, which only knows about SOCKS proxies, reach an SSH server through
+// jump hosts.
+type socksRelay struct {
+	listener net.Listener
+	target   string
+	dial     socksDialFunc
+	timeout  time.Duration
+	logger   zerolog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// newSOCKSRelay starts a relay to target on a random loopback port.
+func newSOCKSRelay(
+	target string,
+	dial socksDialFunc,
+	timeout time.Duration,
+	logger zerolog.Logger,
+) (*socksRelay, error) {
+	var lc net.ListenConfig
+	listener, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start socks relay: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &socksRelay{
+		listener: listener,
+		target:   target,
+		dial:     dial,
+		timeout:  timeout,
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	r.wg.Go(r.serve)
+	return r, nil
+}
+
+// addr returns the address the relay listens on.
+func (r *socksRelay) addr() string {
+	return r.listener.Addr().String()
+}
+
+// Close stops the relay and closes the connections it relays.
+func (r *socksRelay) Close() error {
+	r.cancel()
+	err := r.listener.Close()
+
+	r.mu.Lock()
+	for conn := range r.conns {
+		_ = conn.Close()
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+	return err
+}
+
+// serve accepts connections until the relay is closed.
+func (r *socksRelay) serve() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		if !r.track(conn) {
+			_ = conn.Close()
+			return
+		}
+		r.wg.Go(func() {
+			defer r.untrack(conn)
+			r.handle(conn)
+		})
+	}
+}
+
+// track registers conn to be closed with the relay. It reports false if the
+// relay is already closed.
+func (r *socksRelay) track(conn net.Conn) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ctx.Err() != nil {
+		return false
+	}
+	r.conns[conn] = struct{}{}
+	return true
+}
+
+// untrack closes conn and forgets about it.
+func (r *socksRelay) untrack(conn net.Conn) {
+	_ = conn.Close()
+
+	r.mu.Lock()
+	delete(r.conns, conn)
+	r.mu.Unlock()
+}
+
+// handle runs the SOCKS handshake on conn and relays it to the target.
+func (r *socksRelay) handle(conn net.Conn) {
+	_ = conn.SetDeadline(time.Now().Add(r.timeout))
+
+	addr, err := r.handshake(conn)
+	if err != nil {
+		r.logger.Debug().Err(err).Msg("socks relay handshake failed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.timeout)
+	upstream, err := r.dial(ctx, addr)
+	cancel()
+	if err != nil {
+		r.logger.Warn().Err(err).Str("addr", addr).Msg("socks relay failed to connect")
+		_ = writeSOCKSReply(conn, socksReplyFailure)
+		return
+	}
+	if !r.track(upstream) {
+		_ = upstream.Close()
+		return
+	}
+	defer r.untrack(upstream)
+
+	if err = writeSOCKSReply(conn, socksReplySucceeded); err != nil {
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(upstream, conn)
+		_ = upstream.Close()
+	}()
+	_, _ = io.Copy(conn, upstream)
+	_ = conn.Close()
+	<-done
+}
+
+// handshake negotiates no authentication and reads the CONNECT request,
+// returning the requested address. Requests for any other address than the
+// target are refused.
+func (r *socksRelay) handshake(conn net.Conn) (string, error) {
+	header := make([]byte, 2) //nolint:mnd // version and number of methods
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", fmt.Errorf("failed to read greeting: %w", err)
+	}
+	if header[0] != socksVersion {
+		return "", fmt.Errorf("unsupported socks version %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return "", fmt.Errorf("failed to read auth methods: %w", err)
+	}
+	method := byte(socksNoAcceptable)
+	for _, m := range methods {
+		if m == socksNoAuth {
+			method = socksNoAuth
+		}
+	}
+	if _, err := conn.Write([]byte{socksVersion, method}); err != nil {
+		return "", fmt.Errorf("failed to write auth method: %w", err)
+	}
+	if method == socksNoAcceptable {
+		return "", errors.New("client does not support unauthenticated socks")
+	}
+
+	request := make([]byte, 4) //nolint:mnd // version, command, reserved and address type
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return "", fmt.Errorf("failed to read request: %w", err)
+	}
+	addr, err := readSOCKSAddr(conn, request[3])
+	if err != nil {
+		return "", err
+	}
+
+	if request[1] != socksCmdConnect {
+		_ = writeSOCKSReply(conn, socksReplyCmdNotSup)
+		return "", fmt.Errorf("unsupported socks command %d", request[1])
+	}
+	if addr != r.target {
+		_ = writeSOCKSReply(conn, socksReplyNotAllow)
+		return "", fmt.Errorf("connection to %s not allowed", addr)
+	}
+	return addr, nil
+}
+
+// readSOCKSAddr reads the address and port of a request with address type
+// addrType.
+func readSOCKSAddr(conn net.Conn, addrType byte) (string, error) {
+	var host string
+	switch addrType {
+	case socksAddrIPv4, socksAddrIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if addrType == socksAddrIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", fmt.Errorf("failed to read address: %w", err)
+		}
+		host = ip.String()
+	case socksAddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", fmt.Errorf("failed to read address: %w", err)
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", fmt.Errorf("failed to read address: %w", err)
+		}
+		host = string(domain)
+	default:
+		return "", fmt.Errorf("unsupported socks address type %d", addrType)
+	}
+
+	port := make([]byte, 2) //nolint:mnd // port is a 16-bit integer
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", fmt.Errorf("failed to read port: %w", err)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+// writeSOCKSReply writes a reply with status code reply and an empty bound
+// address.
+func writeSOCKSReply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socksVersion, reply, 0, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,331 @@
+package transfer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Default SSH configuration values.
+const (
+	sshDefaultPort    = 22
+	sshDefaultTimeout = 10 * time.Second
+)
+
+// sshDialer opens SSH connections with the authentication and host key
+// settings of an SSHConfig, directly or through its jump hosts.
+type sshDialer struct {
+	cfg SSHConfig
+}
+
+// newSSHDialer returns a dialer for cfg, with the default port and timeout
+// applied.
+func newSSHDialer(cfg SSHConfig) sshDialer {
+	if cfg.Port == 0 {
+		cfg.Port = sshDefaultPort
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = sshDefaultTimeout
+	}
+	return sshDialer{cfg: cfg}
+}
+
+// addr returns the address of the SSH server.
+func (d sshDialer) addr() string {
+	return net.JoinHostPort(d.cfg.Host, strconv.Itoa(d.cfg.Port))
+}
+
+// dial opens an SSH connection to the server.
+func (d sshDialer) dial(ctx context.Context) (*ssh.Client, error) {
+	auth, closeAgent, err := d.authMethods()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+
+	hostKeyCallback, err := d.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	addr := d.addr()
+	conn, err := d.dialConn(ctx, addr, auth, hostKeyCallback)
+	if err != nil {
+		return nil, err
+	}
+	return d.handshake(ctx, conn, addr, d.clientConfig(d.cfg.User, auth, hostKeyCallback))
+}
+
+// dialTCP opens a connection to addr, through the jump hosts if there are any.
+// Closing the connection also closes the connections to the jump hosts.
+func (d sshDialer) dialTCP(ctx context.Context, addr string) (net.Conn, error) {
+	auth, closeAgent, err := d.authMethods()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+
+	hostKeyCallback, err := d.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.dialConn(ctx, addr, auth, hostKeyCallback)
+}
+
+// dialConn opens a connection to addr, logging into each jump host on the way.
+func (d sshDialer) dialConn(
+	ctx context.Context,
+	addr string,
+	auth []ssh.AuthMethod,
+	hostKeyCallback ssh.HostKeyCallback,
+) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: d.cfg.Timeout}
+	if len(d.cfg.JumpHosts) == 0 {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+		}
+		return conn, nil
+	}
+
+	var jumps []*ssh.Client
+	closeJumps := func() {
+		for i := len(jumps) - 1; i >= 0; i-- {
+			_ = jumps[i].Close()
+		}
+	}
+
+	for _, jump := range d.cfg.JumpHosts {
+		jumpAddr := net.JoinHostPort(jump.Host, strconv.Itoa(cmp.Or(jump.Port, sshDefaultPort)))
+		user := cmp.Or(jump.User, d.cfg.User)
+
+		var conn net.Conn
+		var err error
+		if len(jumps) == 0 {
+			conn, err = dialer.DialContext(ctx, "tcp", jumpAddr)
+		} else {
+			conn, err = jumps[len(jumps)-1].DialContext(ctx, "tcp", jumpAddr)
+		}
+		if err != nil {
+			closeJumps()
+			return nil, fmt.Errorf("failed to dial jump host %s: %w", jumpAddr, err)
+		}
+
+		client, err := d.handshake(ctx, conn, jumpAddr, d.clientConfig(user, auth, hostKeyCallback))
+		if err != nil {
+			closeJumps()
+			return nil, fmt.Errorf("jump host %s: %w", jumpAddr, err)
+		}
+		jumps = append(jumps, client)
+	}
+
+	conn, err := jumps[len(jumps)-1].DialContext(ctx, "tcp", addr)
+	if err != nil {
+		closeJumps()
+		return nil, fmt.Errorf("failed to dial %s through jump hosts: %w", addr, err)
+	}
+	return &jumpConn{Conn: conn, closeJumps: closeJumps}, nil
+}
+
+// handshake runs the SSH handshake on conn. The handshake is not cancellable,
+// so conn is closed if it takes longer than the timeout or ctx is done.
+func (d sshDialer) handshake(
+	ctx context.Context,
+	conn net.Conn,
+	addr string,
+	clientCfg *ssh.ClientConfig,
+) (*ssh.Client, error) {
+	handshakeCtx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+	stop := context.AfterFunc(handshakeCtx, func() { _ = conn.Close() })
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, clientCfg)
+	if !stop() {
+		if err == nil {
+			_ = sshConn.Close()
+		}
+		return nil, fmt.Errorf("ssh handshake failed: %w", handshakeCtx.Err())
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("ssh handshake failed: %w", err)
+	}
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// clientConfig returns the SSH client configuration for logging in as user.
+func (d sshDialer) clientConfig(
+	user string,
+	auth []ssh.AuthMethod,
+	hostKeyCallback ssh.HostKeyCallback,
+) *ssh.ClientConfig {
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         d.cfg.Timeout,
+	}
+}
+
+// hostKeyCallback returns the callback that verifies host keys against the
+// known hosts file, or accepts any key if host key checking is disabled.
+func (d sshDialer) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if d.cfg.IgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil //nolint:gosec // only used when explicitly configured
+	}
+
+	callback, err := knownhosts.New(d.cfg.KnownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// authMethods returns the configured ways to log in: public keys from the key
+// file and the ssh-agent first, then the password. The returned function
+// closes the connection to the agent once logging in is done.
+func (d sshDialer) authMethods() ([]ssh.AuthMethod, func(), error) {
+	var signers []ssh.Signer
+	if d.cfg.KeyFile != "" {
+		signer, err := d.keySigner()
+		if err != nil {
+			return nil, nil, err
+		}
+		signers = append(signers, signer)
+	}
+
+	closeAgent := func() {}
+	var agentClient agent.ExtendedAgent
+	if d.cfg.UseAgent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, nil, errors.New("ssh agent requested but SSH_AUTH_SOCK is not set")
+		}
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to ssh agent: %w", err)
+		}
+		closeAgent = func() { _ = conn.Close() }
+		agentClient = agent.NewClient(conn)
+	}
+
+	var methods []ssh.AuthMethod
+	if len(signers) > 0 || agentClient != nil {
+		methods = append(methods, ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			if agentClient == nil {
+				return signers, nil
+			}
+			agentSigners, err := agentClient.Signers()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list ssh agent keys: %w", err)
+			}
+			return append(signers, agentSigners...), nil
+		}))
+	}
+
+	if d.cfg.Password != "" {
+		password := d.cfg.Password
+		methods = append(methods,
+			ssh.Password(password),
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		)
+	}
+
+	return methods, closeAgent, nil
+}
+
+// keySigner loads the private key, decrypting it with the passphrase if set,
+// and pairs it with its certificate if there is one.
+func (d sshDialer) keySigner() (ssh.Signer, error) {
+	key, err := os.ReadFile(d.cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	passphrase, err := d.keyPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	var signer ssh.Signer
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return nil, errors.New("key file is encrypted and no passphrase is set")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key file: %w", err)
+	}
+
+	if d.cfg.CertFile == "" {
+		return signer, nil
+	}
+
+	certData, err := os.ReadFile(d.cfg.CertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate file: %w", err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(certData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate file: %w", err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an OpenSSH certificate", d.cfg.CertFile)
+	}
+	certSigner, err := ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		return nil, fmt.Errorf("certificate does not match key file: %w", err)
+	}
+	return certSigner, nil
+}
+
+// keyPassphrase returns the passphrase of the key file, reading it from the
+// passphrase file if needed.
+func (d sshDialer) keyPassphrase() (string, error) {
+	if d.cfg.KeyPassphrase != "" || d.cfg.KeyPassphraseFile == "" {
+		return d.cfg.KeyPassphrase, nil
+	}
+
+	data, err := os.ReadFile(d.cfg.KeyPassphraseFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key passphrase file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// jumpConn is a connection relayed through jump hosts, which are closed with
+// it.
+type jumpConn struct {
+	net.Conn
+
+	closeJumps func()
+}
+
+// Close closes the connection and the connections to the jump hosts.
+func (c *jumpConn) Close() error {
+	err := c.Conn.Close()
+	c.closeJumps()
+	return err
+}
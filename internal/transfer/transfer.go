@@ -3,6 +3,7 @@ package transfer
 
 import (
 	"context"
+	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -28,6 +29,18 @@ type Backend string
 const (
 	// BackendRclone uses rclone for file transfers.
 	BackendRclone Backend = "rclone"
+
+	// BackendSFTP uses a native SFTP client for file transfers.
+	BackendSFTP Backend = "sftp"
+
+	// BackendHTTP downloads files over HTTP(S), from directory listings or WebDAV.
+	BackendHTTP Backend = "http"
+
+	// BackendFTP downloads files over FTP, optionally secured with TLS (FTPS).
+	BackendFTP Backend = "ftp"
+
+	// BackendLocal hardlinks or copies files from seedbox storage mounted locally.
+	BackendLocal Backend = "local"
 )
 
 // SSHConfig holds SSH connection configuration for transfer backends.
@@ -35,9 +48,67 @@ type SSHConfig struct {
 	Host           string
 	Port           int
 	User           string
-	KeyFile        string
+	KeyFile        string // Private key (empty to log in with the password or agent only)
 	KnownHostsFile string // Path to known_hosts file (empty if IgnoreHostKey is true)
 	IgnoreHostKey  bool   // Skip host key verification
+
+	Password string // Password for password or keyboard-interactive login (empty for none)
+	UseAgent bool   // Offer the keys of the ssh-agent at SSH_AUTH_SOCK
+	CertFile string // OpenSSH certificate for KeyFile (empty for none)
+
+	// KeyPassphrase decrypts KeyFile. KeyPassphraseFile is read instead when
+	// KeyPassphrase is empty.
+	KeyPassphrase     string
+	KeyPassphraseFile string
+
+	// JumpHosts are logged into in order to reach Host, like OpenSSH's ProxyJump.
+	JumpHosts []JumpHost
+
+	// Timeout bounds connecting and the SSH handshake (0 = 10s). rclone only uses it for jump hosts.
+	Timeout time.Duration
+}
+
+// JumpHost is an SSH server relaying the connection to the next host.
+type JumpHost struct {
+	Host string
+	Port int    // 0 = 22
+	User string // Empty for the user of SSHConfig
+}
+
+// HTTPConfig holds HTTP(S) configuration for the http backend.
+type HTTPConfig struct {
+	URL        string // Base URL serving RemotePath, e.g. https://seedbox.example.com/files/
+	RemotePath string // Directory on the seedbox served at URL (empty for /)
+	Username   string // Username for Basic or Digest authentication (empty for none)
+	Password   string
+
+	// Timeout bounds connecting and waiting for response headers (0 = 30s)
+	Timeout time.Duration
+}
+
+// FTPConfig holds FTP(S) configuration for the ftp backend.
+type FTPConfig struct {
+	// URL of the server and directory serving RemotePath. The scheme picks the
+	// security: ftp:// for plain FTP, ftpes:// for explicit TLS (AUTH TLS) and
+	// ftps:// for implicit TLS.
+	URL        string
+	RemotePath string // Directory on the seedbox served at URL (empty for /)
+	Username   string // Empty for anonymous login
+	Password   string
+
+	InsecureSkipVerify bool // Skip verification of the server's TLS certificate
+	DisableEPSV        bool // Use PASV instead of EPSV for passive data connections
+	IgnorePassiveIP    bool // Connect data connections to the control host instead of the PASV address
+
+	// Timeout bounds connecting and logging in, including the TLS handshake (0 = 30s)
+	Timeout time.Duration
+}
+
+// LocalConfig holds configuration for the local backend.
+type LocalConfig struct {
+	RemotePath string // Directory on the seedbox mounted at MountPath (empty for /)
+	MountPath  string // Local directory where RemotePath is mounted (empty to use remote paths as-is)
+	NoHardlink bool   // Always copy, even when the source is on the same filesystem
 }
 
 // Options holds configuration for creating a Transferer.
@@ -45,6 +116,15 @@ type Options struct {
 	// SSH configuration for remote connections
 	SSH SSHConfig
 
+	// HTTP configuration, only used by the http backend
+	HTTP HTTPConfig
+
+	// FTP configuration, only used by the ftp backend
+	FTP FTPConfig
+
+	// Local configuration, only used by the local backend
+	Local LocalConfig
+
 	// ParallelConnections is the number of parallel connections/streams per file
 	ParallelConnections int
 
@@ -86,6 +166,9 @@ type Transferer interface {
 	// Name returns the name of the transfer backend.
 	Name() string
 
+	// TestConnection checks that the remote server can be reached and logged into.
+	TestConnection(ctx context.Context) error
+
 	// GetSpeed returns the current aggregate transfer speed in bytes per second.
 	// This is the total speed across all active transfers.
 	GetSpeed() int64
@@ -97,3 +180,32 @@ type Transferer interface {
 	// Close releases any resources held by the transferer.
 	Close() error
 }
+
+// BatchProgressFunc is a callback for progress updates of the file at index in
+// a batch.
+type BatchProgressFunc func(index int, p Progress)
+
+// BatchDoneFunc is called when the file at index in a batch has finished, with
+// its transfer error or nil.
+type BatchDoneFunc func(index int, err error)
+
+// BatchTransferer is implemented by transfer backends that can copy a set of
+// files over shared connections. For many small files this avoids setting up
+// each transfer on its own. Callers check for it with a type assertion and fall
+// back to Transfer.
+type BatchTransferer interface {
+	Transferer
+
+	// TransferBatch copies the files of reqs from remote to local, up to
+	// workers at a time. onProgress reports the progress of each file and
+	// onDone is called once for every file that was attempted. Files without
+	// an onDone call were not transferred, because the batch was cancelled or
+	// could not start, which the returned error reports.
+	TransferBatch(
+		ctx context.Context,
+		reqs []Request,
+		workers int,
+		onProgress BatchProgressFunc,
+		onDone BatchDoneFunc,
+	) error
+}
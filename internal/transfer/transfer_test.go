@@ -19,6 +19,22 @@ func TestBackendConstants(t *testing.T) {
 	t.Run("BackendRclone", func(t *testing.T) {
 		assert.Equal(t, transfer.BackendRclone, transfer.Backend("rclone"))
 	})
+
+	t.Run("BackendSFTP", func(t *testing.T) {
+		assert.Equal(t, transfer.BackendSFTP, transfer.Backend("sftp"))
+	})
+
+	t.Run("BackendHTTP", func(t *testing.T) {
+		assert.Equal(t, transfer.BackendHTTP, transfer.Backend("http"))
+	})
+
+	t.Run("BackendFTP", func(t *testing.T) {
+		assert.Equal(t, transfer.BackendFTP, transfer.Backend("ftp"))
+	})
+
+	t.Run("BackendLocal", func(t *testing.T) {
+		assert.Equal(t, transfer.BackendLocal, transfer.Backend("local"))
+	})
 }
 
 // --- SSHConfig Tests ---
@@ -0,0 +1,218 @@
+// Package apitypes defines the request and response bodies of the SeedReap HTTP API.
+//
+// The server encodes these types and pkg/client decodes them, so the two cannot drift apart.
+// The same shapes are described in the OpenAPI document served at /api/openapi.json.
+package apitypes
+
+import "time"
+
+// Health is the response of GET /api/health.
+type Health struct {
+	Status string `json:"status"`
+}
+
+// Stats is the response of GET /api/stats.
+type Stats struct {
+	// TotalTracked is the number of downloads being tracked.
+	TotalTracked int `json:"total_tracked"`
+	// ByState counts tracked downloads per state.
+	ByState map[string]int `json:"by_state"`
+	// DownloadingOnSeedbox counts downloads still downloading in their download client.
+	DownloadingOnSeedbox int `json:"downloading_on_seedbox"`
+	// PausedOnSeedbox counts downloads paused in their download client.
+	PausedOnSeedbox int `json:"paused_on_seedbox"`
+}
+
+// Download is an item of GET /api/downloads.
+type Download struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Downloader   string    `json:"downloader"`
+	Category     string    `json:"category"`
+	Tags         []string  `json:"tags,omitempty"`
+	State        string    `json:"state"`
+	Progress     float64   `json:"progress"`
+	Size         int64     `json:"size"`
+	SyncedBytes  int64     `json:"synced_bytes"`
+	Error        string    `json:"error,omitempty"`
+	DiscoveredAt time.Time `json:"discovered_at"`
+	CompletedAt  time.Time `json:"completed_at,omitzero"`
+}
+
+// DownloadDetail is the response of GET /api/downloads/{id}.
+type DownloadDetail struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Downloader string   `json:"downloader"`
+	Category   string   `json:"category"`
+	Tags       []string `json:"tags"`
+	State      string   `json:"state"`
+	Progress   float64  `json:"progress"`
+	Size       int64    `json:"size"`
+	SavePath   string   `json:"save_path"`
+	// Files is only present once a sync job exists for the download.
+	Files []FileProgress `json:"files,omitempty"`
+}
+
+// AddDownloadRequest is the JSON body of POST /api/downloads.
+type AddDownloadRequest struct {
+	// Downloader is the downloader to add to. It may be omitted when only one is configured.
+	Downloader string `json:"downloader,omitempty"`
+	// URLs are magnet links or HTTP(S) URLs of .torrent files.
+	URLs []string `json:"urls,omitempty"`
+	// Torrents are .torrent files; Data is base64-encoded in JSON.
+	Torrents []TorrentFile `json:"torrents,omitempty"`
+	// Category is assigned to the added downloads. An app must handle it.
+	Category string `json:"category"`
+	// Tags are assigned to the added downloads.
+	Tags []string `json:"tags,omitempty"`
+	// Paused adds the downloads without starting them.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// TorrentFile is a .torrent file submitted with AddDownloadRequest.
+type TorrentFile struct {
+	Name string `json:"name"`
+	Data []byte `json:"data"`
+}
+
+// AddDownloadResponse is the response of POST /api/downloads.
+type AddDownloadResponse struct {
+	// Downloader is the downloader the downloads were added to.
+	Downloader string `json:"downloader"`
+	// Added is the number of URLs and torrent files submitted.
+	Added int `json:"added"`
+}
+
+// ConfigReload is the response of POST /api/config/reload.
+type ConfigReload struct {
+	Downloaders ConfigChanges `json:"downloaders"`
+	Apps        ConfigChanges `json:"apps"`
+}
+
+// ConfigChanges lists the names of the entries a configuration reload added,
+// removed and changed.
+type ConfigChanges struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+// FileProgress is the transfer progress of a single file.
+type FileProgress struct {
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	Transferred int64  `json:"transferred"`
+	Status      string `json:"status"`
+	BytesPerSec int64  `json:"bytes_per_sec"`
+}
+
+// Job is an item of GET /api/jobs.
+type Job struct {
+	ID              string  `json:"id"`
+	Name            string  `json:"name"`
+	Downloader      string  `json:"downloader"`
+	Category        string  `json:"category"`
+	App             string  `json:"app"`
+	Status          string  `json:"status"`
+	SeedboxState    string  `json:"seedbox_state"`
+	SeedboxProgress float64 `json:"seedbox_progress"`
+	TotalSize       int64   `json:"total_size"`
+	CompletedSize   int64   `json:"completed_size"`
+	TotalFiles      int     `json:"total_files"`
+	BytesPerSec     int64   `json:"bytes_per_sec"`
+}
+
+// JobDetail is the response of GET /api/jobs/{id}.
+type JobDetail struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Downloader    string `json:"downloader"`
+	Category      string `json:"category"`
+	Status        string `json:"status"`
+	TotalSize     int64  `json:"total_size"`
+	CompletedSize int64  `json:"completed_size"`
+	TotalFiles    int    `json:"total_files"`
+	// RemoteBase, LocalBase and FinalPath are only set while a sync job exists.
+	RemoteBase string         `json:"remote_base,omitempty"`
+	LocalBase  string         `json:"local_base,omitempty"`
+	FinalPath  string         `json:"final_path,omitempty"`
+	Files      []FileProgress `json:"files"`
+}
+
+// SpeedSample is an item of GET /api/speed-history.
+type SpeedSample struct {
+	// Speed is the aggregate transfer speed in bytes per second.
+	Speed int64 `json:"speed"`
+	// Timestamp is the sample time in Unix seconds.
+	Timestamp int64 `json:"timestamp"`
+}
+
+// Downloader is an item of GET /api/downloaders.
+type Downloader struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// App is an item of GET /api/apps.
+type App struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Category string   `json:"category"`
+	Tags     []string `json:"tags,omitempty"`
+	TagMatch string   `json:"tag_match,omitempty"`
+}
+
+// Event is an item of GET /api/timeline and the per-resource timeline endpoints.
+type Event struct {
+	ID           string         `json:"id"`
+	Type         string         `json:"type"`
+	Timestamp    time.Time      `json:"timestamp"`
+	Message      string         `json:"message"`
+	DownloadID   string         `json:"download_id,omitempty"`
+	DownloadName string         `json:"download_name,omitempty"`
+	AppName      string         `json:"app_name,omitempty"`
+	Downloader   string         `json:"downloader,omitempty"`
+	Details      map[string]any `json:"details,omitempty"`
+}
+
+// Error is the body of every non-2xx response.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Plan is the response of GET /api/plan.
+type Plan struct {
+	// DryRun is false, and Downloads empty, unless the server runs in dry-run mode.
+	DryRun    bool              `json:"dry_run"`
+	Downloads []PlannedDownload `json:"downloads"`
+}
+
+// PlannedDownload is what a dry run would do with a download.
+type PlannedDownload struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Downloader string   `json:"downloader"`
+	Category   string   `json:"category"`
+	State      string   `json:"state"`
+	Apps       []string `json:"apps"`
+	// FinalPath is where the files would be moved, once a sync is planned.
+	FinalPath string `json:"final_path,omitempty"`
+	// Size is the total size of Files.
+	Size    int64           `json:"size"`
+	Files   []PlannedFile   `json:"files"`
+	Actions []PlannedAction `json:"actions"`
+}
+
+// PlannedFile is a file a dry run would sync.
+type PlannedFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// PlannedAction is an action a dry run skipped, recorded in the timeline as a "would" event.
+type PlannedAction struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message"`
+}
@@ -0,0 +1,422 @@
+// Package client is a typed Go client for the SeedReap HTTP API.
+//
+// Responses are decoded into the types in pkg/apitypes, which the server also
+// uses to encode them. The API is described by the OpenAPI document served at
+// /api/openapi.json.
+//
+// Neither this client nor the OpenAPI document is generated: both are written
+// by hand and kept in sync with the server's routes by hand. A change to the
+// API updates the handlers, internal/api/openapi.json and this package together.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seedreap/seedreap/pkg/apitypes"
+)
+
+const (
+	// defaultTimeout is the request timeout of the default HTTP client.
+	defaultTimeout = 30 * time.Second
+
+	// nextCursorHeader carries the cursor for the next page of a list.
+	nextCursorHeader = "X-Next-Cursor"
+
+	// apiKeyHeader carries the API key.
+	apiKeyHeader = "X-Api-Key"
+)
+
+// Client talks to a SeedReap server.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	apiKey     string
+}
+
+// Option is a functional option for configuring the client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey sets the API key sent with every request, required by servers
+// with server.apiKey set for requests that change state.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// New creates a client for the server at baseURL, e.g. "http://localhost:8423".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// APIError is returned when the server responds with a non-2xx status.
+type APIError struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+	// Message is the error message from the response body.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("seedreap api: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsNotFound reports whether err is an APIError with status 404.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// ListOptions filters, sorts and paginates list endpoints. Zero values are omitted.
+type ListOptions struct {
+	// States restricts results to these states (downloads and jobs).
+	States []string
+	// Types restricts results to these event types (timeline).
+	Types []string
+	// Downloads restricts results to events for these download IDs (timeline).
+	Downloads []string
+	// Categories restricts results to these categories.
+	Categories []string
+	// Downloaders restricts results to these downloaders.
+	Downloaders []string
+	// Apps restricts results to items handled by these apps.
+	Apps []string
+	// Search is a case-insensitive name search.
+	Search string
+	// Since excludes items discovered (or events recorded) before this time.
+	Since time.Time
+	// Until excludes items discovered (or events recorded) at or after this time.
+	Until time.Time
+	// Sort is the field to sort by.
+	Sort string
+	// Order is "asc" or "desc".
+	Order string
+	// Limit is the page size.
+	Limit int
+	// Cursor is the cursor returned with the previous page.
+	Cursor string
+}
+
+func (o *ListOptions) values() url.Values {
+	v := url.Values{}
+	if o == nil {
+		return v
+	}
+
+	for key, list := range map[string][]string{
+		"state":      o.States,
+		"type":       o.Types,
+		"download":   o.Downloads,
+		"category":   o.Categories,
+		"downloader": o.Downloaders,
+		"app":        o.Apps,
+	} {
+		for _, item := range list {
+			v.Add(key, item)
+		}
+	}
+
+	set := func(key, value string) {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	set("q", o.Search)
+	set("sort", o.Sort)
+	set("order", o.Order)
+	set("cursor", o.Cursor)
+	if !o.Since.IsZero() {
+		v.Set("since", o.Since.Format(time.RFC3339Nano))
+	}
+	if !o.Until.IsZero() {
+		v.Set("until", o.Until.Format(time.RFC3339Nano))
+	}
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+
+	return v
+}
+
+// Health checks that the server is up.
+func (c *Client) Health(ctx context.Context) (*apitypes.Health, error) {
+	var out apitypes.Health
+	if _, err := c.get(ctx, "/api/health", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Stats returns orchestrator statistics.
+func (c *Client) Stats(ctx context.Context) (*apitypes.Stats, error) {
+	var out apitypes.Stats
+	if _, err := c.get(ctx, "/api/stats", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListDownloads returns a page of tracked downloads and the cursor for the
+// next page, which is empty on the last page.
+func (c *Client) ListDownloads(ctx context.Context, opts *ListOptions) ([]apitypes.Download, string, error) {
+	var out []apitypes.Download
+	next, err := c.get(ctx, "/api/downloads", opts.values(), &out)
+	return out, next, err
+}
+
+// GetDownload returns a tracked download.
+func (c *Client) GetDownload(ctx context.Context, id string) (*apitypes.DownloadDetail, error) {
+	var out apitypes.DownloadDetail
+	if _, err := c.get(ctx, "/api/downloads/"+url.PathEscape(id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AddDownload submits magnet links, torrent URLs or .torrent files to a downloader.
+func (c *Client) AddDownload(
+	ctx context.Context,
+	req apitypes.AddDownloadRequest,
+) (*apitypes.AddDownloadResponse, error) {
+	var out apitypes.AddDownloadResponse
+	if _, err := c.do(ctx, http.MethodPost, "/api/downloads", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReloadConfig reloads the server's configuration file and returns the
+// downloaders and apps that changed.
+func (c *Client) ReloadConfig(ctx context.Context) (*apitypes.ConfigReload, error) {
+	var out apitypes.ConfigReload
+	if _, err := c.do(ctx, http.MethodPost, "/api/config/reload", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListJobs returns a page of sync jobs and the cursor for the next page,
+// which is empty on the last page.
+func (c *Client) ListJobs(ctx context.Context, opts *ListOptions) ([]apitypes.Job, string, error) {
+	var out []apitypes.Job
+	next, err := c.get(ctx, "/api/jobs", opts.values(), &out)
+	return out, next, err
+}
+
+// GetJob returns a sync job with per-file progress.
+func (c *Client) GetJob(ctx context.Context, id string) (*apitypes.JobDetail, error) {
+	var out apitypes.JobDetail
+	if _, err := c.get(ctx, "/api/jobs/"+url.PathEscape(id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelJob stops syncing a download that is waiting or syncing. The download
+// stays listed with an error until it is retried or pruned.
+func (c *Client) CancelJob(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodPost, "/api/jobs/"+url.PathEscape(id)+"/cancel", nil, nil, nil)
+	return err
+}
+
+// RetryJob syncs a download that failed or was cancelled again.
+func (c *Client) RetryJob(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodPost, "/api/jobs/"+url.PathEscape(id)+"/retry", nil, nil, nil)
+	return err
+}
+
+// Plan returns what a server running in dry-run mode would do with each download.
+func (c *Client) Plan(ctx context.Context) (*apitypes.Plan, error) {
+	var out apitypes.Plan
+	if _, err := c.get(ctx, "/api/plan", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SpeedHistory returns recent aggregate transfer speeds.
+func (c *Client) SpeedHistory(ctx context.Context) ([]apitypes.SpeedSample, error) {
+	var out []apitypes.SpeedSample
+	_, err := c.get(ctx, "/api/speed-history", nil, &out)
+	return out, err
+}
+
+// ListDownloaders returns the configured downloaders.
+func (c *Client) ListDownloaders(ctx context.Context) ([]apitypes.Downloader, error) {
+	var out []apitypes.Downloader
+	_, err := c.get(ctx, "/api/downloaders", nil, &out)
+	return out, err
+}
+
+// ListApps returns the configured apps.
+func (c *Client) ListApps(ctx context.Context) ([]apitypes.App, error) {
+	var out []apitypes.App
+	_, err := c.get(ctx, "/api/apps", nil, &out)
+	return out, err
+}
+
+// Timeline returns a page of timeline events and the cursor for the next
+// page, which is empty on the last page.
+func (c *Client) Timeline(ctx context.Context, opts *ListOptions) ([]apitypes.Event, string, error) {
+	var out []apitypes.Event
+	next, err := c.get(ctx, "/api/timeline", opts.values(), &out)
+	return out, next, err
+}
+
+// JobTimeline returns the timeline events for a download, newest first.
+func (c *Client) JobTimeline(ctx context.Context, id string) ([]apitypes.Event, error) {
+	var out []apitypes.Event
+	_, err := c.get(ctx, "/api/jobs/"+url.PathEscape(id)+"/timeline", nil, &out)
+	return out, err
+}
+
+// AppTimeline returns the timeline events for an app, newest first.
+func (c *Client) AppTimeline(ctx context.Context, name string) ([]apitypes.Event, error) {
+	var out []apitypes.Event
+	_, err := c.get(ctx, "/api/apps/"+url.PathEscape(name)+"/timeline", nil, &out)
+	return out, err
+}
+
+// DownloaderTimeline returns the timeline events for a downloader, newest first.
+func (c *Client) DownloaderTimeline(ctx context.Context, name string) ([]apitypes.Event, error) {
+	var out []apitypes.Event
+	_, err := c.get(ctx, "/api/downloaders/"+url.PathEscape(name)+"/timeline", nil, &out)
+	return out, err
+}
+
+// ExportTimeline streams the timeline events matching opts in the given
+// format, "jsonl" or "csv", oldest first unless opts.Order is "desc". Without
+// a limit every matching event is exported. The caller must close the returned
+// reader.
+func (c *Client) ExportTimeline(ctx context.Context, format string, opts *ListOptions) (io.ReadCloser, error) {
+	query := opts.values()
+	if format != "" {
+		query.Set("format", format)
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, "/api/timeline/export", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// get performs a GET request and decodes the JSON response into out.
+// It returns the next-page cursor from the response headers, if any.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) (string, error) {
+	return c.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// do performs a request, sending body as JSON if it is not nil, and decodes
+// the JSON response into out unless out is nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) (string, error) {
+	resp, err := c.send(ctx, method, path, query, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return "", nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp.Header.Get(nextCursorHeader), nil
+}
+
+// send performs a request, sending body as JSON if it is not nil. It returns
+// an APIError for non-2xx responses; otherwise the caller must close the body.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	u := *c.baseURL
+	u.RawPath = ""
+	u.Path += path
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+
+	return resp, nil
+}
+
+// maxErrorBody limits how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// decodeError builds an APIError from a non-2xx response.
+func decodeError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	var apiErr apitypes.Error
+	if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Error == "" {
+		apiErr.Error = strings.TrimSpace(string(body))
+	}
+
+	return &APIError{StatusCode: resp.StatusCode, Message: apiErr.Error}
+}
@@ -0,0 +1,268 @@
+package client_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seedreap/seedreap/internal/api"
+	"github.com/seedreap/seedreap/internal/app"
+	"github.com/seedreap/seedreap/internal/download"
+	"github.com/seedreap/seedreap/internal/filesync"
+	"github.com/seedreap/seedreap/internal/orchestrator"
+	mockpkg "github.com/seedreap/seedreap/internal/testing"
+	"github.com/seedreap/seedreap/internal/timeline"
+	"github.com/seedreap/seedreap/pkg/apitypes"
+	"github.com/seedreap/seedreap/pkg/client"
+)
+
+// newTestClient starts a real API server with two downloads and returns a client for it.
+func newTestClient(t *testing.T) *client.Client {
+	t.Helper()
+
+	tempDir := t.TempDir()
+
+	dlRegistry := download.NewRegistry()
+	appRegistry := app.NewRegistry()
+
+	mockDL := mockpkg.NewMockActionDownloader("seedbox")
+	dlRegistry.Register("seedbox", mockDL)
+	appRegistry.Register("sonarr", mockpkg.NewMockApp("sonarr", "tv", tempDir+"/downloads/tv"))
+
+	for _, name := range []string{"Alpha.Show", "Beta.Show"} {
+		mockDL.AddDownload(&download.Download{
+			ID:       "id-" + name,
+			Name:     name,
+			Category: "tv",
+			State:    download.TorrentStateDownloading,
+			Size:     100,
+		}, []download.File{{Path: name + "/ep.mkv", Size: 100, State: download.FileStateDownloading, Priority: 1}})
+	}
+
+	syncer := filesync.New(tempDir+"/syncing", filesync.WithTransferer(mockpkg.NewMockTransferer()))
+	orch := orchestrator.New(
+		dlRegistry,
+		appRegistry,
+		syncer,
+		tempDir+"/downloads",
+		orchestrator.WithTimeline(timeline.NewRecorder()),
+	)
+	require.NoError(t, orch.Start(t.Context()))
+	t.Cleanup(orch.Stop)
+
+	// Give the orchestrator time to poll
+	time.Sleep(100 * time.Millisecond)
+
+	srv := httptest.NewServer(api.New(orch, dlRegistry, appRegistry, syncer, api.WithAPIKey("secret")))
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(srv.URL, client.WithAPIKey("secret"))
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		wantErr bool
+	}{
+		{"HTTP", "http://localhost:8423", false},
+		{"HTTPSWithPathAndSlash", "https://example.com/seedreap/", false},
+		{"MissingScheme", "localhost:8423", true},
+		{"Unparseable", "http://[::1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.New(tt.baseURL)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClientAgainstServer(t *testing.T) {
+	c := newTestClient(t)
+	ctx := t.Context()
+
+	t.Run("Health", func(t *testing.T) {
+		health, err := c.Health(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", health.Status)
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		stats, err := c.Stats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stats.TotalTracked)
+		assert.Equal(t, 2, stats.DownloadingOnSeedbox)
+	})
+
+	t.Run("ListDownloadsPaginates", func(t *testing.T) {
+		page, next, err := c.ListDownloads(ctx, &client.ListOptions{Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, "Alpha.Show", page[0].Name)
+		assert.False(t, page[0].DiscoveredAt.IsZero())
+		require.NotEmpty(t, next)
+
+		page, next, err = c.ListDownloads(ctx, &client.ListOptions{Limit: 1, Cursor: next})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, "Beta.Show", page[0].Name)
+		assert.Empty(t, next)
+	})
+
+	t.Run("ListDownloadsFilters", func(t *testing.T) {
+		page, _, err := c.ListDownloads(ctx, &client.ListOptions{Search: "beta", States: []string{"discovered"}})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, "id-Beta.Show", page[0].ID)
+	})
+
+	t.Run("GetDownload", func(t *testing.T) {
+		dl, err := c.GetDownload(ctx, "id-Alpha.Show")
+		require.NoError(t, err)
+		assert.Equal(t, "seedbox", dl.Downloader)
+		assert.Equal(t, "tv", dl.Category)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := c.GetJob(ctx, "missing")
+		require.Error(t, err)
+		assert.True(t, client.IsNotFound(err))
+
+		var apiErr *client.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "job not found", apiErr.Message)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		_, _, err := c.ListJobs(ctx, &client.ListOptions{Sort: "bogus"})
+		var apiErr *client.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Contains(t, apiErr.Message, "invalid sort")
+	})
+
+	t.Run("ListJobs", func(t *testing.T) {
+		jobs, _, err := c.ListJobs(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		assert.Equal(t, "sonarr", jobs[0].App)
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		downloaders, err := c.ListDownloaders(ctx)
+		require.NoError(t, err)
+		require.Len(t, downloaders, 1)
+		assert.Equal(t, "mock", downloaders[0].Type)
+
+		apps, err := c.ListApps(ctx)
+		require.NoError(t, err)
+		require.Len(t, apps, 1)
+		assert.Equal(t, "tv", apps[0].Category)
+	})
+
+	t.Run("AddDownload", func(t *testing.T) {
+		resp, err := c.AddDownload(ctx, apitypes.AddDownloadRequest{
+			URLs:     []string{"magnet:?xt=urn:btih:abc"},
+			Torrents: []apitypes.TorrentFile{{Name: "a.torrent", Data: []byte("d8:announce0:e")}},
+			Category: "tv",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "seedbox", resp.Downloader)
+		assert.Equal(t, 2, resp.Added)
+
+		_, err = c.AddDownload(ctx, apitypes.AddDownloadRequest{URLs: []string{"magnet:?x"}, Category: "movies"})
+		var apiErr *client.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	})
+
+	t.Run("ReloadConfig", func(t *testing.T) {
+		// The test server has no config loader
+		_, err := c.ReloadConfig(ctx)
+		var apiErr *client.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusNotImplemented, apiErr.StatusCode)
+	})
+
+	t.Run("Timeline", func(t *testing.T) {
+		events, _, err := c.Timeline(ctx, &client.ListOptions{Types: []string{"discovered"}})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "discovered", events[0].Type)
+
+		events, err = c.JobTimeline(ctx, "id-Alpha.Show")
+		require.NoError(t, err)
+		assert.NotEmpty(t, events)
+
+		events, err = c.AppTimeline(ctx, "no-such-app")
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("ExportTimeline", func(t *testing.T) {
+		body, err := c.ExportTimeline(ctx, "csv", &client.ListOptions{
+			Types:     []string{"discovered"},
+			Downloads: []string{"id-Beta.Show"},
+		})
+		require.NoError(t, err)
+		defer body.Close()
+
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[1], "Beta.Show")
+
+		_, err = c.ExportTimeline(ctx, "xml", nil)
+		var apiErr *client.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		plan, err := c.Plan(ctx)
+		require.NoError(t, err)
+		assert.False(t, plan.DryRun)
+		assert.Empty(t, plan.Downloads)
+	})
+
+	t.Run("JobActions", func(t *testing.T) {
+		require.NoError(t, c.CancelJob(ctx, "id-Alpha.Show"))
+		require.NoError(t, c.RetryJob(ctx, "id-Alpha.Show"))
+
+		err := c.RetryJob(ctx, "id-Alpha.Show")
+		var apiErr *client.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+
+		assert.True(t, client.IsNotFound(c.CancelJob(ctx, "missing")))
+	})
+}
+
+func TestWithAPIKey(t *testing.T) {
+	var gotKey string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-Api-Key")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(srv.URL, client.WithAPIKey("secret"))
+	require.NoError(t, err)
+	require.NoError(t, c.CancelJob(t.Context(), "abc"))
+	assert.Equal(t, "secret", gotKey)
+}
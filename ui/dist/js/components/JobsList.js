@@ -26,7 +26,7 @@ const FilterBar = {
     view: () => {
         // Get sorted lists from API data
         const allApps = appsList.map(a => a.name).sort();
-        const allCategories = [...new Set(appsList.map(a => a.category))].sort();
+        const allCategories = [...new Set(appsList.map(a => a.category).filter(Boolean))].sort();
         const allDownloaders = downloadersList.map(d => d.name).sort();
 
         return m('.flex.items-center.gap-2.flex-wrap.mb-4.bg-base-200.rounded-lg.p-2.border.border-base-300', [
@@ -22,7 +22,7 @@ function getAppsFromAPI() {
 }
 
 function getCategoriesFromAPI() {
-    return [...new Set(appsList.map(a => a.category))].sort();
+    return [...new Set(appsList.map(a => a.category).filter(Boolean))].sort();
 }
 
 function getDownloadersFromAPI() {
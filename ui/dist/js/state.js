@@ -49,12 +49,13 @@ export const jobStatusOrder = {
     downloading: 0,
     paused: 1,
     discovered: 2,
-    pending: 3,
-    syncing: 4,
-    importing: 5,
-    complete: 6,
-    skipped: 7,
-    error: 8
+    waiting_for_space: 3,
+    pending: 4,
+    syncing: 5,
+    importing: 6,
+    complete: 7,
+    skipped: 8,
+    error: 9
 };
 
 export const fileStatusOrder = {
@@ -4,21 +4,33 @@ export const eventTypeConfig = {
     system_started: { label: 'System Started', badgeClass: 'badge-info' },
     downloader_connected: { label: 'Downloader Connected', badgeClass: 'badge-success' },
     app_connected: { label: 'App Connected', badgeClass: 'badge-success' },
+    added: { label: 'Added', badgeClass: 'badge-primary' },
     discovered: { label: 'Discovered', badgeClass: 'badge-primary' },
+    waiting_for_space: { label: 'Waiting for Space', badgeClass: 'badge-warning' },
     sync_started: { label: 'Sync Started', badgeClass: 'badge-info' },
     sync_progress: { label: 'Sync Progress', badgeClass: 'badge-info' },
     sync_complete: { label: 'Sync Complete', badgeClass: 'badge-success' },
     sync_cancelled: { label: 'Sync Cancelled', badgeClass: 'badge-warning' },
+    sync_retried: { label: 'Sync Retried', badgeClass: 'badge-info' },
+    stalled: { label: 'Stalled', badgeClass: 'badge-warning' },
     moving_started: { label: 'Moving Started', badgeClass: 'badge-info' },
     move_complete: { label: 'Move Complete', badgeClass: 'badge-success' },
     import_started: { label: 'Import Started', badgeClass: 'badge-info' },
     import_complete: { label: 'Import Complete', badgeClass: 'badge-success' },
     import_failed: { label: 'Import Failed', badgeClass: 'badge-error' },
+    post_import_action: { label: 'Post-Import', badgeClass: 'badge-info' },
+    post_import_failed: { label: 'Post-Import Failed', badgeClass: 'badge-error' },
     category_changed: { label: 'Category Changed', badgeClass: 'badge-warning' },
     removed: { label: 'Removed', badgeClass: 'badge-warning' },
     error: { label: 'Error', badgeClass: 'badge-error' },
     complete: { label: 'Complete', badgeClass: 'badge-success' },
-    cleanup: { label: 'Cleanup', badgeClass: 'badge-ghost' }
+    cleanup: { label: 'Cleanup', badgeClass: 'badge-ghost' },
+    pruned: { label: 'Pruned', badgeClass: 'badge-ghost' },
+    would_sync: { label: 'Would Sync', badgeClass: 'badge-outline' },
+    would_move: { label: 'Would Move', badgeClass: 'badge-outline' },
+    would_import: { label: 'Would Import', badgeClass: 'badge-outline' },
+    would_post_import: { label: 'Would Post-Import', badgeClass: 'badge-outline' },
+    would_cleanup: { label: 'Would Clean Up', badgeClass: 'badge-outline' }
 };
 
 // Helper functions
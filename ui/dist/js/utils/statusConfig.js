@@ -5,6 +5,7 @@ export const jobStatusConfig = {
     downloading: { label: 'Downloading', badgeClass: 'badge-info', progressClass: 'progress-info', tooltip: 'Downloading on seedbox' },
     paused: { label: 'Paused', badgeClass: 'badge-warning', progressClass: 'progress-warning', tooltip: 'Paused on seedbox' },
     discovered: { label: 'Discovered', badgeClass: 'badge-accent', progressClass: 'progress-primary', tooltip: 'Discovered, waiting to sync' },
+    waiting_for_space: { label: 'Waiting for Space', badgeClass: 'badge-warning', progressClass: 'progress-warning', tooltip: 'Not enough free disk space to start syncing' },
     pending: { label: 'Pending', badgeClass: 'badge-ghost', progressClass: 'progress-primary', tooltip: 'Pending sync' },
     syncing: { label: 'Syncing', badgeClass: 'badge-warning', progressClass: 'progress-warning', tooltip: 'Syncing files from seedbox' },
     importing: { label: 'Importing', badgeClass: 'badge-secondary', progressClass: 'progress-primary', tooltip: 'Triggering import in app' },